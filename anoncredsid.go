@@ -0,0 +1,195 @@
+package indyclient
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// The ids of anoncreds objects come in two formats: the legacy Indy format,
+// such as did:2:name:version for schemas, which the ledger uses, and the
+// did:indy object URIs of the AnonCreds specification, such as
+// did:indy:sovrin:did/anoncreds/v0/SCHEMA/name/version, which also name
+// the network of the object. Did holds the bare DID of the issuer in both
+// cases, and Namespace the did:indy namespace of URIs, which legacy ids
+// lack. String returns the legacy format and URI the other one.
+
+// anoncredsPath is the path of object URIs under the DID of their issuer.
+const anoncredsPath = "/anoncreds/v0/"
+
+// SchemaId is the id of an anoncreds schema.
+type SchemaId struct {
+	Did       string
+	Namespace string
+	Name      string
+	Version   string
+}
+
+// ParseSchemaId parses a schema id, in either format.
+func ParseSchemaId(id string) (SchemaId, error) {
+	if d, segs, ok := parseObjectURI(id, "SCHEMA", 2); ok {
+		return SchemaId{Did: d.Id, Namespace: d.Namespace, Name: segs[0], Version: segs[1]}, nil
+	}
+	// Names may hold colons, versions may not.
+	parts := strings.SplitN(id, ":", 3)
+	i := strings.LastIndex(id, ":")
+	if len(parts) != 3 || parts[1] != "2" || parts[0] == "" || i <= len(parts[0])+3 || i == len(id)-1 {
+		return SchemaId{}, fmt.Errorf("invalid schema id %v", id)
+	}
+	return SchemaId{Did: parts[0], Name: id[len(parts[0])+3 : i], Version: id[i+1:]}, nil
+}
+
+// String returns the id in the legacy format did:2:name:version.
+func (id SchemaId) String() string {
+	return schemaId(id.Did, id.Name, id.Version)
+}
+
+// URI returns the id as a did:indy object URI in id.Namespace.
+func (id SchemaId) URI() string {
+	return objectURI(id.Namespace, id.Did, "SCHEMA", id.Name, id.Version)
+}
+
+// CredDefId is the id of a credential definition. Schemas are referred to
+// by the seqNo of their transaction.
+type CredDefId struct {
+	Did           string
+	Namespace     string
+	SignatureType string // CL
+	SchemaSeqNo   int
+	Tag           string
+}
+
+// ParseCredDefId parses a credential definition id, in either format. The
+// tag of legacy ids without one is "tag".
+func ParseCredDefId(id string) (CredDefId, error) {
+	if d, segs, ok := parseObjectURI(id, "CLAIM_DEF", 2); ok {
+		seqNo, err := strconv.Atoi(segs[0])
+		if err != nil {
+			return CredDefId{}, fmt.Errorf("invalid credential definition id %v: %v", id, err)
+		}
+		return CredDefId{Did: d.Id, Namespace: d.Namespace, SignatureType: "CL", SchemaSeqNo: seqNo, Tag: segs[1]}, nil
+	}
+	parts := strings.SplitN(id, ":", 5)
+	if len(parts) < 4 || parts[1] != "3" || parts[0] == "" {
+		return CredDefId{}, fmt.Errorf("invalid credential definition id %v", id)
+	}
+	seqNo, err := strconv.Atoi(parts[3])
+	if err != nil {
+		return CredDefId{}, fmt.Errorf("invalid credential definition id %v: %v", id, err)
+	}
+	c := CredDefId{Did: parts[0], SignatureType: parts[2], SchemaSeqNo: seqNo, Tag: "tag"}
+	if len(parts) == 5 {
+		c.Tag = parts[4]
+	}
+	return c, nil
+}
+
+// String returns the id in the legacy format
+// did:3:signatureType:schemaSeqNo:tag.
+func (id CredDefId) String() string {
+	return credDefId(id.Did, id.SignatureType, id.SchemaSeqNo, id.Tag)
+}
+
+// URI returns the id as a did:indy object URI in id.Namespace.
+func (id CredDefId) URI() string {
+	return objectURI(id.Namespace, id.Did, "CLAIM_DEF", strconv.Itoa(id.SchemaSeqNo), id.Tag)
+}
+
+// RevocRegId is the id of the definition of a CL_ACCUM revocation
+// registry. Object URIs leave out the DID of the issuer of the credential
+// definition, which is that of the registry.
+type RevocRegId struct {
+	Did       string
+	Namespace string
+	CredDef   CredDefId
+	Tag       string
+}
+
+// ParseRevocRegId parses the id of a revocation registry definition, in
+// either format.
+func ParseRevocRegId(id string) (RevocRegId, error) {
+	if d, segs, ok := parseObjectURI(id, "REV_REG_DEF", 3); ok {
+		seqNo, err := strconv.Atoi(segs[0])
+		if err != nil {
+			return RevocRegId{}, fmt.Errorf("invalid revocation registry id %v: %v", id, err)
+		}
+		credDef := CredDefId{Did: d.Id, Namespace: d.Namespace, SignatureType: "CL", SchemaSeqNo: seqNo, Tag: segs[1]}
+		return RevocRegId{Did: d.Id, Namespace: d.Namespace, CredDef: credDef, Tag: segs[2]}, nil
+	}
+	// did:4:credDefId:CL_ACCUM:tag, where the credential definition id
+	// holds colons.
+	parts := strings.SplitN(id, ":", 3)
+	if len(parts) != 3 || parts[1] != "4" || parts[0] == "" {
+		return RevocRegId{}, fmt.Errorf("invalid revocation registry id %v", id)
+	}
+	rest := parts[2]
+	i := strings.LastIndex(rest, ":")
+	if i < 0 {
+		return RevocRegId{}, fmt.Errorf("invalid revocation registry id %v", id)
+	}
+	j := strings.LastIndex(rest[:i], ":")
+	if j < 0 || rest[j+1:i] != "CL_ACCUM" {
+		return RevocRegId{}, fmt.Errorf("invalid revocation registry id %v", id)
+	}
+	credDef, err := ParseCredDefId(rest[:j])
+	if err != nil {
+		return RevocRegId{}, fmt.Errorf("invalid revocation registry id %v: %v", id, err)
+	}
+	return RevocRegId{Did: parts[0], CredDef: credDef, Tag: rest[i+1:]}, nil
+}
+
+// String returns the id in the legacy format
+// did:4:credDefId:CL_ACCUM:tag.
+func (id RevocRegId) String() string {
+	return revocRegDefId(id.Did, id.CredDef.String(), id.Tag)
+}
+
+// URI returns the id as a did:indy object URI in id.Namespace.
+func (id RevocRegId) URI() string {
+	return objectURI(id.Namespace, id.Did, "REV_REG_DEF", strconv.Itoa(id.CredDef.SchemaSeqNo), id.CredDef.Tag, id.Tag)
+}
+
+// legacyRevocRegId returns the revocation registry id in the legacy format
+// used by the ledger, converting object URIs.
+func legacyRevocRegId(id string) (string, error) {
+	if !strings.HasPrefix(id, "did:") {
+		return id, nil
+	}
+	r, err := ParseRevocRegId(id)
+	if err != nil {
+		return "", err
+	}
+	return r.String(), nil
+}
+
+// parseObjectURI parses the did:indy object URI of an object of type typ,
+// whose path holds n segments after the type, and reports whether s is
+// one.
+func parseObjectURI(s, typ string, n int) (*Did, []string, bool) {
+	u, err := ParseDidURL(s)
+	if err != nil || u.Did.Method != "indy" || !strings.HasPrefix(u.Path, anoncredsPath+typ+"/") {
+		return nil, nil, false
+	}
+	segs := strings.Split(strings.TrimPrefix(u.Path, anoncredsPath+typ+"/"), "/")
+	if len(segs) != n {
+		return nil, nil, false
+	}
+	for i, seg := range segs {
+		if segs[i], err = url.PathUnescape(seg); err != nil || segs[i] == "" {
+			return nil, nil, false
+		}
+	}
+	return &u.Did, segs, true
+}
+
+// objectURI returns the did:indy object URI of an object of type typ
+// written by did.
+func objectURI(namespace, did, typ string, segs ...string) string {
+	d := Did{Method: "indy", Namespace: namespace, Id: did}
+	s := d.String() + anoncredsPath + typ
+	for _, seg := range segs {
+		s += "/" + url.PathEscape(seg)
+	}
+	return s
+}
@@ -0,0 +1,66 @@
+package indyclient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchemaId(t *testing.T) {
+	id, err := ParseSchemaId("V4SGRU86Z58d6TV7PBUe6f:2:degree:schema:1.0")
+	require.NoError(t, err)
+	require.Equal(t, SchemaId{Did: "V4SGRU86Z58d6TV7PBUe6f", Name: "degree:schema", Version: "1.0"}, id)
+	require.Equal(t, "V4SGRU86Z58d6TV7PBUe6f:2:degree:schema:1.0", id.String())
+
+	id.Namespace = "sovrin:staging"
+	uri := id.URI()
+	require.Equal(t, "did:indy:sovrin:staging:V4SGRU86Z58d6TV7PBUe6f/anoncreds/v0/SCHEMA/degree:schema/1.0", uri)
+	parsed, err := ParseSchemaId(uri)
+	require.NoError(t, err)
+	require.Equal(t, id, parsed)
+
+	for _, bad := range []string{"", "V4SGRU86Z58d6TV7PBUe6f:2:degree", "V4SGRU86Z58d6TV7PBUe6f:3:degree:1.0", ":2:degree:1.0",
+		"V4SGRU86Z58d6TV7PBUe6f:2::1.0", "did:indy:sovrin:V4SGRU86Z58d6TV7PBUe6f/anoncreds/v0/SCHEMA/degree"} {
+		_, err := ParseSchemaId(bad)
+		require.Error(t, err, bad)
+	}
+}
+
+func TestParseCredDefId(t *testing.T) {
+	id, err := ParseCredDefId("V4SGRU86Z58d6TV7PBUe6f:3:CL:12:default")
+	require.NoError(t, err)
+	require.Equal(t, CredDefId{Did: "V4SGRU86Z58d6TV7PBUe6f", SignatureType: "CL", SchemaSeqNo: 12, Tag: "default"}, id)
+	id.Namespace = "sovrin"
+	require.Equal(t, "did:indy:sovrin:V4SGRU86Z58d6TV7PBUe6f/anoncreds/v0/CLAIM_DEF/12/default", id.URI())
+	parsed, err := ParseCredDefId(id.URI())
+	require.NoError(t, err)
+	require.Equal(t, id, parsed)
+
+	id, err = ParseCredDefId("V4SGRU86Z58d6TV7PBUe6f:3:CL:12")
+	require.NoError(t, err)
+	require.Equal(t, "tag", id.Tag)
+
+	_, err = ParseCredDefId("V4SGRU86Z58d6TV7PBUe6f:3:CL:schema:default")
+	require.Error(t, err)
+}
+
+func TestParseRevocRegId(t *testing.T) {
+	legacy := "V4SGRU86Z58d6TV7PBUe6f:4:V4SGRU86Z58d6TV7PBUe6f:3:CL:12:default:CL_ACCUM:r1"
+	id, err := ParseRevocRegId(legacy)
+	require.NoError(t, err)
+	require.Equal(t, "V4SGRU86Z58d6TV7PBUe6f", id.Did)
+	require.Equal(t, 12, id.CredDef.SchemaSeqNo)
+	require.Equal(t, "default", id.CredDef.Tag)
+	require.Equal(t, "r1", id.Tag)
+	require.Equal(t, legacy, id.String())
+
+	id.Namespace = "sovrin"
+	uri := id.URI()
+	require.Equal(t, "did:indy:sovrin:V4SGRU86Z58d6TV7PBUe6f/anoncreds/v0/REV_REG_DEF/12/default/r1", uri)
+	converted, err := legacyRevocRegId(uri)
+	require.NoError(t, err)
+	require.Equal(t, legacy, converted)
+
+	_, err = ParseRevocRegId("V4SGRU86Z58d6TV7PBUe6f:4:V4SGRU86Z58d6TV7PBUe6f:3:CL:12:default:CL_OTHER:r1")
+	require.Error(t, err)
+}
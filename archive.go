@@ -0,0 +1,249 @@
+package indyclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/mr-tron/base58"
+)
+
+// A ledger archive, as written by ExportArchive, is a sequence of JSON
+// values, one per line: a header, the transactions of the ledger in order,
+// as stored by the validators, and a trailer. The header holds the
+// multi-signature of the latest state of the ledger known when the export
+// started, which signs the Merkle root hash of the ledger at that time;
+// the trailer gives the size and root hash of the whole archive and the
+// number of transactions under the signed root.
+const archiveFormat = "indy-ledger-archive"
+
+type archiveHeader struct {
+	Format         string          `json:"format"`
+	Version        int             `json:"version"`
+	Ledger         LedgerId        `json:"ledger"`
+	MultiSignature *multiSignature `json:"multiSignature,omitempty"`
+}
+
+type archiveTrailer struct {
+	Size       int    `json:"size"`
+	RootHash   string `json:"rootHash"`
+	SignedSize int    `json:"signedSize,omitempty"`
+}
+
+// ArchiveInfo describes a ledger archive.
+type ArchiveInfo struct {
+	Ledger     LedgerId
+	Size       int       // number of transactions
+	RootHash   string    // Merkle root hash of the transactions, in base58
+	SignedSize int       // number of transactions under the multi-signed root, 0 if none
+	SignedTime time.Time // time of the multi-signed state, zero if none
+	// SignatureVerified tells whether the multi-signature was checked
+	// against the BLS keys of the validators, which is skipped when the
+	// BLSVerifier of the Pool is disabled.
+	SignatureVerified bool
+}
+
+// ErrInvalidArchive is returned by VerifyArchive when an archive does not
+// hold a ledger consistent with its root hashes.
+var ErrInvalidArchive = errors.New("invalid ledger archive")
+
+// ExportArchive downloads ledger with CatchupLedger, in batches of
+// batchSize transactions checked against the ledger root, and writes it to
+// w as an archive which VerifyArchive checks offline. Archives of the
+// domain and config ledgers carry a multi-signature of the pool over a root
+// hash of the ledger, which ties the transactions to the pool; it is
+// checked unless the BLSVerifier of the Pool was disabled.
+func (p *Pool) ExportArchive(ctx context.Context, w io.Writer, ledger LedgerId, batchSize int) (*ArchiveInfo, error) {
+	ms, err := p.ledgerMultiSignature(ctx, ledger)
+	if err != nil {
+		return nil, err
+	}
+	a, err := newArchiveWriter(w, ledger, ms)
+	if err != nil {
+		return nil, err
+	}
+	err = p.catchupLedger(ctx, ledger, 1, 0, batchSize, func(_ []*Block, raws []json.RawMessage) error {
+		for _, raw := range raws {
+			if err := a.txn(raw); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	info, err := a.end()
+	if err != nil {
+		return nil, err
+	}
+	if ms != nil && p.blsVerifier != nil {
+		if err := p.verifyMultiSig(ms); err != nil {
+			return nil, err
+		}
+		info.SignatureVerified = true
+	}
+	return info, nil
+}
+
+// ledgerMultiSignature returns the multi-signature of the latest state of
+// ledger, taken from the state proof of a read, or nil for ledgers without
+// state reads.
+func (p *Pool) ledgerMultiSignature(ctx context.Context, ledger LedgerId) (*multiSignature, error) {
+	var op interface{}
+	switch ledger {
+	case DomainLedger:
+		// The absence of a NYM is proven like any other state.
+		op = getNymOp{Type: idGetNym, Dest: defaultIdent}
+	case ConfigLedger:
+		op = getTAAOp{Type: idGetTAA}
+	default:
+		return nil, nil
+	}
+	r, err := p.read(ctx, op)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkReply(r); err != nil {
+		return nil, err
+	}
+	var res struct {
+		StateProof *stateProof `json:"state_proof"`
+	}
+	if err := json.Unmarshal(r.Result, &res); err != nil {
+		return nil, err
+	}
+	if res.StateProof == nil {
+		return nil, nil
+	}
+	return res.StateProof.MultiSignature, nil
+}
+
+// archiveWriter writes an archive, computing its root hashes.
+type archiveWriter struct {
+	w          io.Writer
+	info       ArchiveInfo
+	signedRoot []byte
+	tree       merkleTree
+}
+
+func newArchiveWriter(w io.Writer, ledger LedgerId, ms *multiSignature) (*archiveWriter, error) {
+	a := &archiveWriter{w: w, info: ArchiveInfo{Ledger: ledger}}
+	if ms != nil {
+		root, err := base58.Decode(ms.Value.TxnRootHash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signed root hash: %v", err)
+		}
+		a.signedRoot = root
+		a.info.SignedTime = time.Unix(ms.Value.Timestamp, 0)
+	}
+	return a, a.line(archiveHeader{Format: archiveFormat, Version: 1, Ledger: ledger, MultiSignature: ms})
+}
+
+func (a *archiveWriter) line(v interface{}) error {
+	m, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = a.w.Write(append(m, '\n'))
+	return err
+}
+
+func (a *archiveWriter) txn(raw json.RawMessage) error {
+	var b bytes.Buffer
+	if err := json.Compact(&b, raw); err != nil {
+		return err
+	}
+	h, err := leafHash(raw)
+	if err != nil {
+		return err
+	}
+	a.tree.append(h)
+	if a.signedRoot != nil && a.info.SignedSize == 0 && bytes.Equal(a.tree.root(), a.signedRoot) {
+		a.info.SignedSize = a.tree.size
+	}
+	b.WriteByte('\n')
+	_, err = a.w.Write(b.Bytes())
+	return err
+}
+
+func (a *archiveWriter) end() (*ArchiveInfo, error) {
+	if a.signedRoot != nil && a.info.SignedSize == 0 {
+		return nil, fmt.Errorf("%w: no prefix of the ledger has the multi-signed root hash", ErrInconsistentLedger)
+	}
+	a.info.Size = a.tree.size
+	a.info.RootHash = base58.Encode(a.tree.root())
+	err := a.line(struct {
+		End archiveTrailer `json:"end"`
+	}{archiveTrailer{a.info.Size, a.info.RootHash, a.info.SignedSize}})
+	if err != nil {
+		return nil, err
+	}
+	return &a.info, nil
+}
+
+// VerifyArchive reads an archive written by ExportArchive and checks that
+// its transactions are consecutive and have the root hashes recorded in
+// it, including the root hash signed by the pool. The multi-signature is
+// checked against the BLS keys of the validators of p unless its
+// BLSVerifier was disabled; p need not be connected, but its validators must be those
+// which made the signature, as found in the pool ledger at the time of the
+// export.
+func (p *Pool) VerifyArchive(r io.Reader) (*ArchiveInfo, error) {
+	d := json.NewDecoder(r)
+	var h archiveHeader
+	if err := d.Decode(&h); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrInvalidArchive, err)
+	}
+	if h.Format != archiveFormat || h.Version != 1 {
+		return nil, fmt.Errorf("%w: unknown format %v version %v", ErrInvalidArchive, h.Format, h.Version)
+	}
+	a, err := newArchiveWriter(ioutil.Discard, h.Ledger, h.MultiSignature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+
+	for {
+		var raw json.RawMessage
+		if err := d.Decode(&raw); err != nil {
+			if err == io.EOF {
+				err = errors.New("no trailer")
+			}
+			return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+		var probe struct {
+			End         *archiveTrailer `json:"end"`
+			TxnMetadata TxnMetadata     `json:"txnMetadata"`
+		}
+		if err := json.Unmarshal(raw, &probe); err != nil {
+			return nil, fmt.Errorf("%w: transaction %v: %v", ErrInvalidArchive, a.tree.size+1, err)
+		}
+		if probe.End != nil {
+			info, err := a.end()
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+			}
+			if *probe.End != (archiveTrailer{info.Size, info.RootHash, info.SignedSize}) {
+				return nil, fmt.Errorf("%w: transactions do not match the trailer", ErrInvalidArchive)
+			}
+			if h.MultiSignature != nil && p.blsVerifier != nil {
+				if err := p.verifyMultiSig(h.MultiSignature); err != nil {
+					return nil, err
+				}
+				info.SignatureVerified = true
+			}
+			return info, nil
+		}
+		if probe.TxnMetadata.SeqNo != a.tree.size+1 {
+			return nil, fmt.Errorf("%w: transaction %v has seqNo %v", ErrInvalidArchive, a.tree.size+1, probe.TxnMetadata.SeqNo)
+		}
+		if err := a.txn(raw); err != nil {
+			return nil, fmt.Errorf("%w: transaction %v: %v", ErrInvalidArchive, a.tree.size+1, err)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package indyclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mr-tron/base58"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool_VerifyArchive(t *testing.T) {
+	var tree merkleTree
+	h, err := leafHash(json.RawMessage(testLedger[0]))
+	require.NoError(t, err)
+	tree.append(h)
+	ms := &multiSignature{Participants: []string{"Node1"}, Value: multiSignatureValue{
+		LedgerId:    1,
+		Timestamp:   1500000000,
+		TxnRootHash: base58.Encode(tree.root()),
+	}}
+
+	var buf bytes.Buffer
+	a, err := newArchiveWriter(&buf, DomainLedger, ms)
+	require.NoError(t, err)
+	for _, txn := range testLedger {
+		require.NoError(t, a.txn(json.RawMessage(txn)))
+	}
+	written, err := a.end()
+	require.NoError(t, err)
+	require.Equal(t, 2, written.Size)
+	require.Equal(t, 1, written.SignedSize)
+	require.Equal(t, 4, strings.Count(buf.String(), "\n"))
+
+	pool := testPool(t, fakeTransport{}, WithBLSVerifier(nil))
+	info, err := pool.VerifyArchive(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, written, info)
+	require.False(t, info.SignatureVerified)
+
+	// Altering a transaction changes the root hashes.
+	for _, tc := range []struct{ old, new string }{
+		{"V4SGRU86Z58d6TV7PBUe6f", "V4SGRU86Z58d6TV7PBUe6g"},
+		{"Th7MpTaRZVRYnPiabds81Y", "Th7MpTaRZVRYnPiabds81Z"},
+		{`"seqNo":2`, `"seqNo":3`},
+	} {
+		tampered := strings.Replace(buf.String(), tc.old, tc.new, 1)
+		_, err = pool.VerifyArchive(strings.NewReader(tampered))
+		require.Error(t, err, tc.new)
+		require.True(t, errors.Is(err, ErrInvalidArchive))
+	}
+
+	// So does dropping the last one.
+	lines := strings.SplitAfter(buf.String(), "\n")
+	_, err = pool.VerifyArchive(strings.NewReader(lines[0] + lines[1] + lines[3]))
+	require.True(t, errors.Is(err, ErrInvalidArchive))
+}
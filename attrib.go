@@ -0,0 +1,162 @@
+package indyclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// attribOp is the operation of both ATTRIB and GET_ATTRIB.
+type attribOp struct {
+	Type protoId `json:"type,string"`
+	Dest string  `json:"dest"`
+	Raw  string  `json:"raw,omitempty"`
+	Hash string  `json:"hash,omitempty"`
+	Enc  string  `json:"enc,omitempty"`
+}
+
+// Attrib is an attribute of a DID, as returned by GET_ATTRIB. Attributes are
+// stored in one of three ways: in the clear (raw), as the hash of a value
+// kept off the ledger, or encrypted. Exactly one of Value, Hash and Enc is
+// set accordingly.
+type Attrib struct {
+	Dest    string
+	Name    string          // name of a raw attribute
+	Value   json.RawMessage // value of a raw attribute
+	Hash    string
+	Enc     string
+	SeqNo   int
+	TxnTime int64
+}
+
+// Decode decodes the value of a raw attribute into v.
+func (a *Attrib) Decode(v interface{}) error {
+	if a.Value == nil {
+		return errors.New("not a raw attribute")
+	}
+	return json.Unmarshal(a.Value, v)
+}
+
+// GetAttrib fetches the raw attribute name of did. It returns ErrNoData if
+// the DID has no such attribute.
+func (p *Pool) GetAttrib(ctx context.Context, did, name string, opts ...ReadOption) (*Attrib, error) {
+	return p.getAttrib(ctx, did, attribOp{Raw: name}, opts)
+}
+
+// GetAttribHash fetches the attribute of did stored as hash, the hex encoded
+// SHA-256 of its value. It returns ErrNoData if the DID has no such
+// attribute.
+func (p *Pool) GetAttribHash(ctx context.Context, did, hash string, opts ...ReadOption) (*Attrib, error) {
+	return p.getAttrib(ctx, did, attribOp{Hash: hash}, opts)
+}
+
+// GetAttribEnc fetches the encrypted attribute enc of did. It returns
+// ErrNoData if the DID has no such attribute.
+func (p *Pool) GetAttribEnc(ctx context.Context, did, enc string, opts ...ReadOption) (*Attrib, error) {
+	return p.getAttrib(ctx, did, attribOp{Enc: enc}, opts)
+}
+
+func (p *Pool) getAttrib(ctx context.Context, did string, op attribOp, opts []ReadOption) (*Attrib, error) {
+	id, err := didId(did)
+	if err != nil {
+		return nil, err
+	}
+	op.Type = idGetAttr
+	op.Dest = id
+	r, err := p.read(ctx, op, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkReply(r); err != nil {
+		return nil, err
+	}
+	return attribFromReply(r)
+}
+
+// attribFromReply decodes the result of a GET_ATTRIB reply.
+func attribFromReply(r *Reply) (*Attrib, error) {
+	var res struct {
+		Dest    string
+		Raw     string
+		Hash    string
+		Enc     string
+		Data    json.RawMessage
+		SeqNo   int
+		TxnTime int64
+	}
+	if err := json.Unmarshal(r.Result, &res); err != nil {
+		return nil, err
+	}
+	a := &Attrib{
+		Dest:    res.Dest,
+		SeqNo:   res.SeqNo,
+		TxnTime: res.TxnTime,
+	}
+
+	switch {
+	case res.Raw != "":
+		// The data is {name: value}, usually as a JSON string.
+		var attr map[string]json.RawMessage
+		if err := decodeData(res.Data, &attr); err != nil {
+			return nil, err
+		}
+		v, ok := attr[res.Raw]
+		if !ok {
+			return nil, fmt.Errorf("attribute %v missing from reply", res.Raw)
+		}
+		a.Name = res.Raw
+		a.Value = v
+	case res.Hash != "":
+		if err := decodeString(res.Data, &a.Hash); err != nil {
+			return nil, err
+		}
+	case res.Enc != "":
+		if err := decodeString(res.Data, &a.Enc); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("reply names no attribute")
+	}
+	return a, nil
+}
+
+// decodeString decodes a data field holding a plain string.
+func decodeString(data json.RawMessage, s *string) error {
+	if len(data) == 0 || string(data) == "null" {
+		return ErrNoData
+	}
+	return json.Unmarshal(data, s)
+}
+
+// WriteAttrib writes the raw attribute name of did with the JSON encoding of
+// value, signed by signer, and returns the transaction once the pool has
+// ordered it. Agent endpoints are published with the name "endpoint".
+func (p *Pool) WriteAttrib(ctx context.Context, signer Signer, did, name string, value interface{}) (*Block, error) {
+	req, err := NewAttribRequest(did, name, value)
+	if err != nil {
+		return nil, err
+	}
+	return p.write(ctx, req.Operation, signer)
+}
+
+// WriteAttribHash writes an attribute of did stored as hash, the hex encoded
+// SHA-256 of a value kept off the ledger.
+func (p *Pool) WriteAttribHash(ctx context.Context, signer Signer, did, hash string) (*Block, error) {
+	return p.writeAttrib(ctx, signer, did, attribOp{Hash: hash})
+}
+
+// WriteAttribEnc writes an attribute of did stored encrypted as enc.
+func (p *Pool) WriteAttribEnc(ctx context.Context, signer Signer, did, enc string) (*Block, error) {
+	return p.writeAttrib(ctx, signer, did, attribOp{Enc: enc})
+}
+
+func (p *Pool) writeAttrib(ctx context.Context, signer Signer, did string, op attribOp) (*Block, error) {
+	id, err := didId(did)
+	if err != nil {
+		return nil, err
+	}
+	op.Type = idAttrib
+	op.Dest = id
+	return p.write(ctx, op, signer)
+}
@@ -0,0 +1,37 @@
+package indyclient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttribFromReply(t *testing.T) {
+	r := &Reply{Result: []byte(`{"type":"104","dest":"V4SGRU86Z58d6TV7PBUe6f","raw":"endpoint","data":"{\"endpoint\":{\"endpoint\":\"http://10.0.0.1:8020\"}}","seqNo":12,"txnTime":1500000000}`)}
+	a, err := attribFromReply(r)
+	require.NoError(t, err)
+	require.Equal(t, "endpoint", a.Name)
+	require.Equal(t, 12, a.SeqNo)
+	require.Equal(t, int64(1500000000), a.TxnTime)
+	var ep struct{ Endpoint string }
+	require.NoError(t, a.Decode(&ep))
+	require.Equal(t, "http://10.0.0.1:8020", ep.Endpoint)
+
+	r = &Reply{Result: []byte(`{"type":"104","dest":"V4SGRU86Z58d6TV7PBUe6f","hash":"83d9","data":"83d9","seqNo":13}`)}
+	a, err = attribFromReply(r)
+	require.NoError(t, err)
+	require.Equal(t, "83d9", a.Hash)
+	require.Error(t, a.Decode(&ep))
+
+	r = &Reply{Result: []byte(`{"type":"104","dest":"V4SGRU86Z58d6TV7PBUe6f","enc":"c2VjcmV0","data":null,"seqNo":null}`)}
+	_, err = attribFromReply(r)
+	require.Equal(t, ErrNoData, err)
+}
+
+func TestCheckReply_Refused(t *testing.T) {
+	r, err := parseReply([]string{`{"op":"REJECT","reqId":7,"identifier":"V4SGRU86Z58d6TV7PBUe6f","reason":"client request invalid: UnauthorizedClientRequest"}`})
+	require.NoError(t, err)
+	err = checkReply(r)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "UnauthorizedClientRequest")
+}
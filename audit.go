@@ -0,0 +1,125 @@
+package indyclient
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mr-tron/base58"
+)
+
+// ErrInvalidAuditPath is returned by VerifyAuditPath when the audit path of
+// a GET_TXN reply does not prove that the transaction is in the ledger.
+var ErrInvalidAuditPath = errors.New("invalid audit path")
+
+// VerifyAuditPath checks that the transaction in a GET_TXN reply is the
+// transaction at its seqNo in the ledger whose Merkle root hash and size
+// are given in the reply, using the reply's audit path. It returns
+// ErrNoData for replies without a transaction.
+//
+// The root hash is only as trustworthy as the validator which sent it, but
+// an audit path ties the transaction to the ledger's history: a validator
+// cannot alter one transaction without changing the root hash it reports.
+func VerifyAuditPath(r *Reply) error {
+	_, _, err := verifyAuditPath(r)
+	return err
+}
+
+// verifyAuditPath is VerifyAuditPath, also returning the size and the root
+// hash of the ledger which the audit path leads to.
+func verifyAuditPath(r *Reply) (int, []byte, error) {
+	if err := checkReply(r); err != nil {
+		return 0, nil, err
+	}
+	var data map[string]interface{}
+	if err := unmarshalNumbers(resultData(r), &data); err != nil {
+		return 0, nil, err
+	}
+
+	var proof struct {
+		AuditPath  []string    `json:"auditPath"`
+		LedgerSize int         `json:"ledgerSize"`
+		RootHash   string      `json:"rootHash"`
+		Meta       TxnMetadata `json:"txnMetadata"`
+	}
+	if err := decodeData(resultData(r), &proof); err != nil {
+		return 0, nil, err
+	}
+	if proof.RootHash == "" || proof.LedgerSize == 0 {
+		return 0, nil, fmt.Errorf("%w: reply has no root hash", ErrInvalidAuditPath)
+	}
+	root, err := base58.Decode(proof.RootHash)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: root hash: %v", ErrInvalidAuditPath, err)
+	}
+	path := make([][]byte, len(proof.AuditPath))
+	for i, h := range proof.AuditPath {
+		if path[i], err = base58.Decode(h); err != nil {
+			return 0, nil, fmt.Errorf("%w: audit path: %v", ErrInvalidAuditPath, err)
+		}
+	}
+
+	// The leaf is the transaction as stored in the ledger, without the
+	// proof added to the reply.
+	delete(data, "auditPath")
+	delete(data, "ledgerSize")
+	delete(data, "rootHash")
+	txn, err := msgpackEncode(nil, data)
+	if err != nil {
+		return 0, nil, err
+	}
+	leaf := sha256.Sum256(append([]byte{0}, txn...))
+
+	seqNo := proof.Meta.SeqNo
+	if seqNo < 1 || seqNo > proof.LedgerSize {
+		return 0, nil, fmt.Errorf("%w: seqNo %v outside ledger of size %v", ErrInvalidAuditPath, seqNo, proof.LedgerSize)
+	}
+	if !verifyInclusion(leaf[:], seqNo-1, proof.LedgerSize, path, root) {
+		return 0, nil, ErrInvalidAuditPath
+	}
+	return proof.LedgerSize, root, nil
+}
+
+// resultData returns the data field of the result of r.
+func resultData(r *Reply) json.RawMessage {
+	var res struct {
+		Data json.RawMessage `json:"data"`
+	}
+	json.Unmarshal(r.Result, &res)
+	return res.Data
+}
+
+// verifyInclusion checks the inclusion proof path of the leaf with hash
+// leaf at index in a Merkle tree of size leaves with the given root, as
+// specified by RFC 6962, which Indy ledgers follow.
+func verifyInclusion(leaf []byte, index, size int, path [][]byte, root []byte) bool {
+	fn, sn := index, size-1
+	r := leaf
+	for _, p := range path {
+		if sn == 0 {
+			return false
+		}
+		if fn&1 == 1 || fn == sn {
+			r = nodeHash(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = nodeHash(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	return sn == 0 && bytes.Equal(r, root)
+}
+
+func nodeHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{1})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
@@ -0,0 +1,54 @@
+//go:build docker
+// +build docker
+
+package indyclient_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/indyclient"
+	"go.dedis.ch/indyclient/indyclienttest"
+)
+
+// TestCaptureAuditPaths checks the audit paths of the GET_TXN replies of a
+// pool running in Docker, and that exporting its domain ledger with
+// verification links their root hashes. With -update, it stores the
+// replies for TestVerifyAuditPath_Captured.
+func TestCaptureAuditPaths(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	d, err := indyclienttest.StartDocker(ctx)
+	require.NoError(t, err)
+	defer d.Close()
+	pool := d.Pool
+
+	for _, ledger := range []indyclient.LedgerId{indyclient.PoolLedger, indyclient.DomainLedger} {
+		r, err := pool.GetTransaction(ctx, ledger, 1)
+		require.NoError(t, err, ledger)
+		require.NoError(t, indyclient.VerifyAuditPath(r), ledger)
+
+		if *update {
+			m, err := json.Marshal(struct {
+				Op     string          `json:"op"`
+				Result json.RawMessage `json:"result"`
+			}{r.Op, r.Result})
+			require.NoError(t, err)
+			dir := filepath.Join("testdata", "auditpath")
+			require.NoError(t, os.MkdirAll(dir, 0755))
+			name := fmt.Sprintf("get_txn_%v.json", ledger)
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), append(m, '\n'), 0644))
+		}
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, pool.Export(ctx, &out, indyclient.DomainLedger, indyclient.WithVerification(), indyclient.WithWorkers(4)))
+}
@@ -0,0 +1,86 @@
+package indyclient
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mr-tron/base58"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMsgpackEncode(t *testing.T) {
+	var v interface{}
+	require.NoError(t, unmarshalNumbers([]byte(`{"c":"x","a":1,"b":[true,null,-1,300,70000]}`), &v))
+	b, err := msgpackEncode(nil, v)
+	require.NoError(t, err)
+	require.Equal(t, "83a16101a16295c3c0ffcd012cce00011170a163a178", fmt.Sprintf("%x", b))
+}
+
+func TestVerifyAuditPath(t *testing.T) {
+	txns := make([]string, 3)
+	leaves := make([][]byte, 3)
+	for i := range txns {
+		txns[i] = fmt.Sprintf(`{"reqSignature":{},"txn":{"data":{"dest":"V4SGRU86Z58d6TV7PBUe6f"},"metadata":{},"type":"1"},"txnMetadata":{"seqNo":%v,"txnTime":1500000000},"ver":"1"}`, i+1)
+		var v interface{}
+		require.NoError(t, unmarshalNumbers([]byte(txns[i]), &v))
+		m, err := msgpackEncode(nil, v)
+		require.NoError(t, err)
+		h := sha256.Sum256(append([]byte{0}, m...))
+		leaves[i] = h[:]
+	}
+	// The tree of three leaves: root = H(H(l0, l1), l2).
+	left := nodeHash(leaves[0], leaves[1])
+	root := base58.Encode(nodeHash(left, leaves[2]))
+
+	reply := func(i int, path ...[]byte) *Reply {
+		var enc []string
+		for _, p := range path {
+			enc = append(enc, `"`+base58.Encode(p)+`"`)
+		}
+		data := strings.TrimSuffix(txns[i], "}") + fmt.Sprintf(`,"auditPath":[%v],"ledgerSize":3,"rootHash":"%v"}`, strings.Join(enc, ","), root)
+		return &Reply{Op: "REPLY", Result: json.RawMessage(`{"type":"3","seqNo":1,"data":` + data + `}`)}
+	}
+
+	require.NoError(t, VerifyAuditPath(reply(0, leaves[1], leaves[2])))
+	require.NoError(t, VerifyAuditPath(reply(1, leaves[0], leaves[2])))
+	require.NoError(t, VerifyAuditPath(reply(2, left)))
+
+	err := VerifyAuditPath(reply(0, leaves[2], leaves[1]))
+	require.True(t, errors.Is(err, ErrInvalidAuditPath), "%v", err)
+	err = VerifyAuditPath(reply(2, leaves[0]))
+	require.True(t, errors.Is(err, ErrInvalidAuditPath), "%v", err)
+
+	r := reply(1, leaves[0], leaves[2])
+	r.Result = json.RawMessage(strings.Replace(string(r.Result), `"type":"1"`, `"type":"101"`, 1))
+	err = VerifyAuditPath(r)
+	require.True(t, errors.Is(err, ErrInvalidAuditPath), "%v", err)
+
+	require.Equal(t, ErrNoData, VerifyAuditPath(&Reply{Op: "REPLY", Result: []byte(`{"type":"3","data":null}`)}))
+}
+
+// TestVerifyAuditPath_Captured checks the audit paths of the GET_TXN
+// replies of indy-node in testdata/auditpath, which TestCaptureAuditPaths
+// captures:
+//
+//	go test -tags docker -run TestCaptureAuditPaths -update
+//
+// Like those of TestVerifyStateProof_Captured, missing replies are an error.
+func TestVerifyAuditPath_Captured(t *testing.T) {
+	for _, ledger := range []LedgerId{PoolLedger, DomainLedger} {
+		f := filepath.Join("testdata", "auditpath", fmt.Sprintf("get_txn_%v.json", ledger))
+		m, err := ioutil.ReadFile(f)
+		require.NoError(t, err, "capture it with TestCaptureAuditPaths")
+		r, err := parseReply([]string{string(m)})
+		require.NoError(t, err, f)
+		require.NoError(t, VerifyAuditPath(r), f)
+
+		r.Result = tamperData(t, r.Result)
+		require.Error(t, VerifyAuditPath(r), f)
+	}
+}
@@ -0,0 +1,105 @@
+package indyclient
+
+import "context"
+
+// AuthRule is a rule of the ledger's authorization map, which decides who
+// may perform an action on a transaction type. AuthType is the code of the
+// transaction type, AuthAction is ADD or EDIT, and Field, OldValue and
+// NewValue select the change the rule applies to, "*" matching any value.
+type AuthRule struct {
+	AuthType   string          `json:"auth_type"`
+	AuthAction string          `json:"auth_action"`
+	Field      string          `json:"field"`
+	OldValue   string          `json:"old_value,omitempty"`
+	NewValue   string          `json:"new_value"`
+	Constraint *AuthConstraint `json:"constraint,omitempty"`
+}
+
+// AuthConstraint is a node of the constraint tree of an AuthRule. A ROLE
+// constraint requires SigCount signatures from DIDs with Role ("*" for any
+// role), possibly of the owner of the object; AND and OR constraints combine
+// AuthConstraints; FORBIDDEN allows nobody.
+type AuthConstraint struct {
+	ConstraintId       string                 `json:"constraint_id"`
+	Role               string                 `json:"role,omitempty"`
+	SigCount           int                    `json:"sig_count,omitempty"`
+	NeedToBeOwner      bool                   `json:"need_to_be_owner,omitempty"`
+	OffLedgerSignature bool                   `json:"off_ledger_signature,omitempty"`
+	Metadata           map[string]interface{} `json:"metadata,omitempty"`
+	AuthConstraints    []AuthConstraint       `json:"auth_constraints,omitempty"`
+}
+
+type getAuthRuleOp struct {
+	Type       protoId `json:"type,string"`
+	AuthType   string  `json:"auth_type,omitempty"`
+	AuthAction string  `json:"auth_action,omitempty"`
+	Field      string  `json:"field,omitempty"`
+	OldValue   string  `json:"old_value,omitempty"`
+	NewValue   string  `json:"new_value,omitempty"`
+}
+
+type authRuleOp struct {
+	Type protoId `json:"type,string"`
+	AuthRule
+}
+
+type authRulesOp struct {
+	Type  protoId    `json:"type,string"`
+	Rules []AuthRule `json:"rules"`
+}
+
+// GetAuthRules fetches the whole authorization map of the ledger.
+func (p *Pool) GetAuthRules(ctx context.Context, opts ...ReadOption) ([]AuthRule, error) {
+	return p.getAuthRules(ctx, getAuthRuleOp{Type: idGetAuthRule}, opts)
+}
+
+// GetAuthRule fetches the rule with the key of rule, whose constraint is
+// ignored.
+func (p *Pool) GetAuthRule(ctx context.Context, rule AuthRule, opts ...ReadOption) (*AuthRule, error) {
+	rules, err := p.getAuthRules(ctx, getAuthRuleOp{
+		Type:       idGetAuthRule,
+		AuthType:   rule.AuthType,
+		AuthAction: rule.AuthAction,
+		Field:      rule.Field,
+		OldValue:   rule.OldValue,
+		NewValue:   rule.NewValue,
+	}, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return nil, ErrNoData
+	}
+	return &rules[0], nil
+}
+
+func (p *Pool) getAuthRules(ctx context.Context, op getAuthRuleOp, opts []ReadOption) ([]AuthRule, error) {
+	r, err := p.read(ctx, op, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkReply(r); err != nil {
+		return nil, err
+	}
+	return authRulesFromReply(r)
+}
+
+func authRulesFromReply(r *Reply) ([]AuthRule, error) {
+	var rules []AuthRule
+	if err := r.DecodeResult(&rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// WriteAuthRule writes an AUTH_RULE transaction, which only trustees may
+// do, replacing the constraint of the rule with the key of rule.
+func (p *Pool) WriteAuthRule(ctx context.Context, signer Signer, rule AuthRule) (*Block, error) {
+	return p.write(ctx, authRuleOp{Type: idAuthRule, AuthRule: rule}, signer)
+}
+
+// WriteAuthRules writes an AUTH_RULES transaction, which only trustees may
+// do, replacing the constraints of several rules at once.
+func (p *Pool) WriteAuthRules(ctx context.Context, signer Signer, rules []AuthRule) (*Block, error) {
+	return p.write(ctx, authRulesOp{Type: idAuthRules, Rules: rules}, signer)
+}
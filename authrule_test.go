@@ -0,0 +1,32 @@
+package indyclient
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthRules(t *testing.T) {
+	r := &Reply{Op: "REPLY", Result: []byte(`{"type":"121","data":[{"auth_type":"1","auth_action":"ADD","field":"role","new_value":"101","constraint":{"constraint_id":"OR","auth_constraints":[{"constraint_id":"ROLE","role":"0","sig_count":1,"need_to_be_owner":false,"metadata":{}},{"constraint_id":"ROLE","role":"2","sig_count":1,"need_to_be_owner":false,"metadata":{}}]}}]}`)}
+	v, err := r.Decode()
+	require.NoError(t, err)
+	rules := v.([]AuthRule)
+	require.Len(t, rules, 1)
+	require.Equal(t, "101", rules[0].NewValue)
+	c := rules[0].Constraint
+	require.Equal(t, "OR", c.ConstraintId)
+	require.Len(t, c.AuthConstraints, 2)
+	require.Equal(t, "2", c.AuthConstraints[1].Role)
+
+	m, err := json.Marshal(authRuleOp{Type: idAuthRule, AuthRule: AuthRule{
+		AuthType:   "1",
+		AuthAction: "EDIT",
+		Field:      "role",
+		OldValue:   "0",
+		NewValue:   "",
+		Constraint: &AuthConstraint{ConstraintId: "ROLE", Role: "0", SigCount: 2},
+	}})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"type":"120","auth_type":"1","auth_action":"EDIT","field":"role","old_value":"0","new_value":"","constraint":{"constraint_id":"ROLE","role":"0","sig_count":2}}`, string(m))
+}
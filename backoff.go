@@ -0,0 +1,101 @@
+package indyclient
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// Defaults of WithBackoff.
+const (
+	defaultBackoffBase = 100 * time.Millisecond
+	defaultBackoffMax  = 5 * time.Second
+)
+
+// backoff computes the delays between connection attempts.
+type backoff struct {
+	base, max time.Duration
+}
+
+// delay returns the delay before retrying after attempt failed. It doubles
+// with every attempt up to max, and is randomized so that clients which
+// lost their validator at the same time do not reconnect in lockstep.
+func (b backoff) delay(attempt int) time.Duration {
+	d := b.base
+	for i := 0; i < attempt && d < b.max; i++ {
+		d *= 2
+	}
+	if d > b.max {
+		d = b.max
+	}
+	if d <= 0 {
+		return 0
+	}
+	// Randomize over the upper half of d.
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// sleep waits for d, or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WithBackoff sets the delays between failed connection attempts: the first
+// retry waits about base, and the delay doubles with every further attempt
+// up to max. Delays are randomized by up to half. The number of attempts is
+// bounded by WithRetryBudget. The defaults are 100ms and 5s.
+func WithBackoff(base, max time.Duration) Option {
+	return func(p *Pool) {
+		p.backoff = backoff{base: base, max: max}
+	}
+}
+
+// KeepAlive probes the open connections of the Pool every interval with a
+// lightweight GET_TXN, until ctx is done. Connections whose validator does
+// not answer within interval are closed, and the next requests reconnect,
+// instead of discovering the dead connection themselves. It is meant to be
+// run in its own goroutine, and stops when the Pool is closed:
+//
+//	go pool.KeepAlive(ctx, time.Minute)
+func (p *Pool) KeepAlive(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		case <-p.done:
+			return
+		}
+
+		p.mu.Lock()
+		conns := append([]*conn(nil), p.conns...)
+		p.mu.Unlock()
+		for _, c := range conns {
+			if err := p.probe(ctx, c, interval); err != nil && ctx.Err() == nil {
+				p.log.Log(LevelWarn, "dropping dead connection", "node", c.alias, "err", err)
+				p.dropConnection(c)
+			}
+		}
+	}
+}
+
+// probe checks that the validator of c answers a GET_TXN within timeout.
+func (p *Pool) probe(ctx context.Context, c *conn, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	reqId, m := p.getTxnRequest(PoolLedger, 1)
+	r, err := p.exchangeWith(ctx, c, reqId, m)
+	if err != nil {
+		return err
+	}
+	return checkReply(r)
+}
@@ -0,0 +1,26 @@
+package indyclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoff_Delay(t *testing.T) {
+	b := backoff{base: 100 * time.Millisecond, max: time.Second}
+	for attempt, want := range []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	} {
+		for i := 0; i < 20; i++ {
+			d := b.delay(attempt)
+			require.True(t, d >= want/2 && d <= want, "attempt %v: %v", attempt, d)
+		}
+	}
+	require.Equal(t, time.Duration(0), backoff{}.delay(3))
+}
@@ -0,0 +1,142 @@
+package indyclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mr-tron/base58"
+)
+
+// A BLSVerifier verifies BLS multi-signatures as produced by the
+// validators. Indy signs with indy-crypto, which uses the pairing of the
+// BN254 curve of the AMCL library: sig is a serialized G1 point and the
+// keys are serialized G2 points, as found in NODE transactions. Pools verify
+// them with their own implementation of that pairing unless given another
+// BLSVerifier, for instance to use a faster pairing library.
+type BLSVerifier interface {
+	// VerifyMultiSig returns nil if sig is the aggregated signature of msg
+	// by all of keys.
+	VerifyMultiSig(sig, msg []byte, keys [][]byte) error
+}
+
+// WithBLSVerifier makes the Pool verify the multi-signatures of state
+// proofs with v, in VerifyMultiSignature and in reads using
+// WithStateProof, instead of the built-in one. A nil v disables the
+// verification of multi-signatures.
+func WithBLSVerifier(v BLSVerifier) Option {
+	return func(p *Pool) {
+		p.blsVerifier = v
+	}
+}
+
+// ErrNoBLSVerifier is returned by VerifyMultiSignature, and by reads using
+// WithStateProof, when the BLSVerifier of the Pool was disabled.
+var ErrNoBLSVerifier = errors.New("no BLS verifier configured")
+
+// ErrInvalidMultiSignature is returned by VerifyMultiSignature when the
+// multi-signature of a reply does not prove that the pool agreed on it.
+var ErrInvalidMultiSignature = errors.New("invalid multi-signature")
+
+// VerifyMultiSignature checks that the multi-signature of the state proof
+// of r was made by at least n-f validators of the pool, the quorum the
+// validators use, using their BLS keys from the genesis transactions.
+// Together with VerifyStateProof, this proves that the pool agreed on the
+// reply.
+func (p *Pool) VerifyMultiSignature(r *Reply) error {
+	if p.blsVerifier == nil {
+		return ErrNoBLSVerifier
+	}
+	var res struct {
+		StateProof *stateProof `json:"state_proof"`
+	}
+	if err := json.Unmarshal(r.Result, &res); err != nil {
+		return err
+	}
+	if res.StateProof == nil || res.StateProof.MultiSignature == nil {
+		return ErrNoStateProof
+	}
+	return p.verifyMultiSig(res.StateProof.MultiSignature)
+}
+
+// verifyMultiSig checks that ms was made by at least n-f validators of the
+// pool.
+func (p *Pool) verifyMultiSig(ms *multiSignature) error {
+	if p.blsVerifier == nil {
+		return ErrNoBLSVerifier
+	}
+	keys, err := p.blsKeys(ms.Participants)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidMultiSignature, err)
+	}
+	sig, err := base58.Decode(ms.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: signature: %v", ErrInvalidMultiSignature, err)
+	}
+	msg, err := multiSigMessage(ms.Value)
+	if err != nil {
+		return err
+	}
+	if err := p.blsVerifier.VerifyMultiSig(sig, msg, keys); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidMultiSignature, err)
+	}
+	return nil
+}
+
+// blsKeys returns the BLS keys of the validators with the given aliases,
+// which must be at least a quorum of n-f distinct validators.
+func (p *Pool) blsKeys(participants []string) ([][]byte, error) {
+	byAlias := make(map[string]*Validator)
+	vs := p.validators()
+	n := 0
+	for i := range vs {
+		v := &vs[i]
+		if v.IsObserver() {
+			continue
+		}
+		byAlias[v.Alias] = v
+		n++
+	}
+
+	seen := make(map[string]bool)
+	var keys [][]byte
+	var unknown []string
+	for _, alias := range participants {
+		v, ok := byAlias[alias]
+		if !ok || v.BlsKey == "" {
+			unknown = append(unknown, alias)
+			continue
+		}
+		if seen[alias] {
+			continue
+		}
+		seen[alias] = true
+		k, err := base58.Decode(v.BlsKey)
+		if err != nil {
+			return nil, fmt.Errorf("BLS key of %v: %v", alias, err)
+		}
+		keys = append(keys, k)
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("no BLS key for %v", strings.Join(unknown, ", "))
+	}
+	if quorum := n - (n-1)/3; len(keys) < quorum {
+		return nil, fmt.Errorf("%v participants, need %v", len(keys), quorum)
+	}
+	return keys, nil
+}
+
+// multiSigMessage returns the message signed by a multi-signature: its
+// value serialized with MessagePack, with sorted keys.
+func multiSigMessage(v multiSignatureValue) ([]byte, error) {
+	m, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var data interface{}
+	if err := unmarshalNumbers(m, &data); err != nil {
+		return nil, err
+	}
+	return msgpackEncode(nil, data)
+}
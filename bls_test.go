@@ -0,0 +1,101 @@
+package indyclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mr-tron/base58"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeVerifier struct {
+	msg  []byte
+	keys [][]byte
+}
+
+func (f *fakeVerifier) VerifyMultiSig(sig, msg []byte, keys [][]byte) error {
+	f.msg, f.keys = msg, keys
+	if string(sig) != "good" {
+		return errors.New("bad signature")
+	}
+	return nil
+}
+
+func TestPool_VerifyMultiSignature(t *testing.T) {
+	f := &fakeVerifier{}
+	pool, err := NewPoolFromBytes(testGenesis(t, "10.0.0.1:9702", "10.0.0.2:9702", "10.0.0.3:9702", "10.0.0.4:9702"), WithBLSVerifier(f))
+	require.NoError(t, err)
+	for i := range pool.Validators {
+		pool.Validators[i].BlsKey = base58.Encode([]byte(fmt.Sprintf("key%v", i+1)))
+	}
+
+	reply := func(sig string, participants string) *Reply {
+		return &Reply{Op: "REPLY", Result: []byte(fmt.Sprintf(`{"type":"105","state_proof":{"multi_signature":{"signature":"%v","participants":[%v],"value":{"ledger_id":1,"pool_state_root_hash":"p","state_root_hash":"s","timestamp":1500000000,"txn_root_hash":"t"}}}}`,
+			base58.Encode([]byte(sig)), participants))}
+	}
+
+	require.NoError(t, pool.VerifyMultiSignature(reply("good", `"Node1","Node2","Node4"`)))
+	require.Equal(t, "\x85\xa9ledger_id\x01\xb4pool_state_root_hash\xa1p\xafstate_root_hash\xa1s\xa9timestamp\xceYh/\x00\xadtxn_root_hash\xa1t", string(f.msg))
+	require.Equal(t, [][]byte{[]byte("key1"), []byte("key2"), []byte("key4")}, f.keys)
+
+	for _, r := range []*Reply{
+		reply("bad", `"Node1","Node2","Node4"`),
+		reply("good", `"Node1","Node2"`),
+		reply("good", `"Node1","Node1","Node2"`),
+		reply("good", `"Node1","Node2","Node5"`),
+	} {
+		err := pool.VerifyMultiSignature(r)
+		require.True(t, errors.Is(err, ErrInvalidMultiSignature), "%v", err)
+	}
+
+	pool.blsVerifier = nil
+	require.Equal(t, ErrNoBLSVerifier, pool.VerifyMultiSignature(reply("good", `"Node1"`)))
+}
+
+func TestPool_StateProofRead(t *testing.T) {
+	dest := "V4SGRU86Z58d6TV7PBUe6f"
+	value := `{"identifier":"V4SGRU86Z58d6TV7PBUe6f","role":"0","seqNo":12,"txnTime":1500000000,"verkey":"~CoRER63DVYnWZtK8uAzNbx"}`
+	key := sha256.Sum256([]byte(dest))
+	other := key
+	other[0] ^= 0xf0
+	rootHash, proof := testTrie(key[:], []byte(value), other[:], []byte(`{}`))
+	root := base58.Encode(rootHash)
+	data := `"{\"dest\":\"V4SGRU86Z58d6TV7PBUe6f\",\"identifier\":\"V4SGRU86Z58d6TV7PBUe6f\",\"role\":\"0\",\"seqNo\":12,\"txnTime\":1500000000,\"verkey\":\"~CoRER63DVYnWZtK8uAzNbx\"}"`
+
+	var sent int
+	v := func(sig string) fakeValidator {
+		return func(m []byte) [][]byte {
+			var req struct {
+				ReqId seqNo `json:"reqId"`
+			}
+			json.Unmarshal(m, &req)
+			sent++
+			return [][]byte{[]byte(fmt.Sprintf(
+				`{"op":"REPLY","result":{"type":"105","reqId":%v,"dest":"%v","data":%v,"seqNo":12,"txnTime":1500000000,"state_proof":{"root_hash":"%v","proof_nodes":"%v","multi_signature":{"signature":"%v","participants":["Node1","Node2","Node3"],"value":{"ledger_id":1,"pool_state_root_hash":"p","state_root_hash":"%v","timestamp":1500000100,"txn_root_hash":"t"}}}}}`,
+				req.ReqId, dest, data, root, base64.StdEncoding.EncodeToString(proof), base58.Encode([]byte(sig)), root))}
+		}
+	}
+	transport := fakeTransport{"Node1": v("bad"), "Node2": v("good"), "Node3": v("good"), "Node4": v("good")}
+
+	// Without a BLSVerifier, the root hash of the proof cannot be trusted.
+	pool := testPool(t, transport, WithBLSVerifier(nil))
+	_, err := pool.read(context.Background(), getNymOp{Type: idGetNym, Dest: dest}, WithStateProof())
+	require.Equal(t, ErrNoBLSVerifier, err)
+	require.Equal(t, 0, sent)
+
+	// Replies with a bad multi-signature are skipped.
+	pool = testPool(t, transport, WithBLSVerifier(&fakeVerifier{}))
+	for i := range pool.Validators {
+		pool.Validators[i].BlsKey = base58.Encode([]byte(fmt.Sprintf("key%v", i+1)))
+	}
+	r, err := pool.read(context.Background(), getNymOp{Type: idGetNym, Dest: dest}, WithStateProof())
+	require.NoError(t, err)
+	require.True(t, r.Verified)
+	require.Equal(t, "Node2", r.Node)
+	require.Equal(t, 2, sent)
+}
@@ -0,0 +1,465 @@
+package indyclient
+
+import (
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/mr-tron/base58"
+)
+
+// The validators sign with indy-crypto, which uses the BN254 curve of the
+// AMCL library: E: y² = x³ + 2 over Fp, with the group G1 = E(Fp) of prime
+// order r, and G2 in its sextic twist E': y² = x³ + 2/ξ over Fp2 = Fp[i],
+// with i² = -1 and ξ = 1+i. BLS signatures are points of G1 and keys points
+// of G2. Checking a signature only compares two pairings, so the ate pairing
+// is computed with math/big, in affine coordinates, which takes a tenth of a
+// second: a read is slower to come back from the validators anyway.
+
+var (
+	// bnU is the parameter of the curve, from which p and r derive.
+	bnU = big.NewInt(-0x4080000000000001)
+	bnP = bnPoly(36, 36, 24, 6, 1)
+	bnR = bnPoly(36, 36, 18, 6, 1)
+	// bnB is the constant of E, and bnB2 = 2/ξ = 1-i that of E'.
+	bnB  = big.NewInt(2)
+	bnB2 = fp2{big.NewInt(1), new(big.Int).Sub(bnP, big.NewInt(1))}
+	// bnAteLoop is t-1 = 6u², the length of the Miller loop of the ate
+	// pairing, t being the trace of Frobenius.
+	bnAteLoop = new(big.Int).Mul(big.NewInt(6), new(big.Int).Mul(bnU, bnU))
+	// bnHardExp is (p⁴-p²+1)/r, the part of the final exponentiation
+	// (p¹²-1)/r = (p⁶-1)(p²+1)(p⁴-p²+1)/r which is not a Frobenius map.
+	bnHardExp = bnHardExponent()
+	// bnFrobenius[j][k] is ξ^(k(p²ʲ-1)/6), by which x -> x^(p²ʲ) multiplies
+	// the coefficient of wᵏ: it leaves Fp2 unchanged, and w^(p²ʲ) is
+	// w·ξ^((p²ʲ-1)/6) since w⁶ = ξ.
+	bnFrobenius = frobeniusConstants()
+	// bnSqrtExp is (p+1)/4: p = 3 mod 4, so a square a has the root
+	// a^((p+1)/4).
+	bnSqrtExp = new(big.Int).Rsh(new(big.Int).Add(bnP, big.NewInt(1)), 2)
+
+	// blsGenerator is the generator of G2 which the validators' keys are
+	// multiples of, as configured in indy-plenum.
+	blsGenerator = mustDecodeG2("3LHpUjiyFC2q2hD7MnwwNmVXiuaFbQx2XkAFJWzswCjgN1utjsCeLzHsKk1nJvFEaS4fcrUmVAkdhtPCYbrVyATZcmzwJReTcJqwqBCPTmTQ9uWPwz6rEncKb2pYYYFcdHa8N17HzVyTqKfgPi4X9pMetfT3A5xCHq54R2pDNYWVLDX")
+)
+
+// bnPoly returns c4·u⁴ + c3·u³ + c2·u² + c1·u + c0.
+func bnPoly(c4, c3, c2, c1, c0 int64) *big.Int {
+	v := big.NewInt(c4)
+	for _, c := range []int64{c3, c2, c1, c0} {
+		v.Mul(v, bnU)
+		v.Add(v, big.NewInt(c))
+	}
+	return v
+}
+
+func bnHardExponent() *big.Int {
+	p2 := new(big.Int).Mul(bnP, bnP)
+	e := new(big.Int).Mul(p2, p2)
+	e.Sub(e, p2).Add(e, big.NewInt(1))
+	return e.Div(e, bnR)
+}
+
+func frobeniusConstants() (c [4][6]fp2) {
+	xi := fp2{big.NewInt(1), big.NewInt(1)}
+	pj := big.NewInt(1)
+	for j := range c {
+		e := new(big.Int).Sub(pj, big.NewInt(1))
+		g := xi.exp(e.Div(e, big.NewInt(6)))
+		c[j][0] = fp2One()
+		for k := 1; k < 6; k++ {
+			c[j][k] = c[j][k-1].mul(g)
+		}
+		pj.Mul(pj, bnP).Mul(pj, bnP)
+	}
+	return c
+}
+
+// fpMod reduces x modulo p, in place, and returns it.
+func fpMod(x *big.Int) *big.Int {
+	return x.Mod(x, bnP)
+}
+
+// fp2 is the element a + b·i of Fp2, with reduced coordinates.
+type fp2 struct {
+	a, b *big.Int
+}
+
+func fp2Zero() fp2 { return fp2{new(big.Int), new(big.Int)} }
+func fp2One() fp2  { return fp2{big.NewInt(1), new(big.Int)} }
+
+func (x fp2) isZero() bool { return x.a.Sign() == 0 && x.b.Sign() == 0 }
+
+func (x fp2) equal(y fp2) bool { return x.a.Cmp(y.a) == 0 && x.b.Cmp(y.b) == 0 }
+
+func (x fp2) add(y fp2) fp2 {
+	return fp2{fpMod(new(big.Int).Add(x.a, y.a)), fpMod(new(big.Int).Add(x.b, y.b))}
+}
+
+func (x fp2) sub(y fp2) fp2 {
+	return fp2{fpMod(new(big.Int).Sub(x.a, y.a)), fpMod(new(big.Int).Sub(x.b, y.b))}
+}
+
+func (x fp2) neg() fp2 {
+	return fp2Zero().sub(x)
+}
+
+func (x fp2) mul(y fp2) fp2 {
+	// (a + bi)(c + di) = ac - bd + ((a+b)(c+d) - ac - bd)i
+	ac := new(big.Int).Mul(x.a, y.a)
+	bd := new(big.Int).Mul(x.b, y.b)
+	m := new(big.Int).Mul(new(big.Int).Add(x.a, x.b), new(big.Int).Add(y.a, y.b))
+	m.Sub(m, ac).Sub(m, bd)
+	return fp2{fpMod(ac.Sub(ac, bd)), fpMod(m)}
+}
+
+// mulFp multiplies x by the element k of Fp.
+func (x fp2) mulFp(k *big.Int) fp2 {
+	return fp2{fpMod(new(big.Int).Mul(x.a, k)), fpMod(new(big.Int).Mul(x.b, k))}
+}
+
+// mulXi multiplies x by ξ = 1+i.
+func (x fp2) mulXi() fp2 {
+	return fp2{fpMod(new(big.Int).Sub(x.a, x.b)), fpMod(new(big.Int).Add(x.a, x.b))}
+}
+
+func (x fp2) exp(e *big.Int) fp2 {
+	z := fp2One()
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		z = z.mul(z)
+		if e.Bit(i) == 1 {
+			z = z.mul(x)
+		}
+	}
+	return z
+}
+
+func (x fp2) inverse() fp2 {
+	// 1/(a + bi) = (a - bi)/(a² + b²)
+	d := new(big.Int).Mul(x.a, x.a)
+	d.Add(d, new(big.Int).Mul(x.b, x.b))
+	d.ModInverse(fpMod(d), bnP)
+	return fp2{fpMod(new(big.Int).Mul(x.a, d)), fpMod(new(big.Int).Neg(new(big.Int).Mul(x.b, d)))}
+}
+
+// fp12 is the element Σ c[k]·wᵏ of Fp12 = Fp2[w]/(w⁶ - ξ), into which the
+// twist maps back as (x, y) -> (x·w², y·w³).
+type fp12 [6]fp2
+
+func fp12One() fp12 {
+	return fp12{fp2One(), fp2Zero(), fp2Zero(), fp2Zero(), fp2Zero(), fp2Zero()}
+}
+
+func (x *fp12) isOne() bool {
+	if !x[0].equal(fp2One()) {
+		return false
+	}
+	for _, c := range x[1:] {
+		if !c.isZero() {
+			return false
+		}
+	}
+	return true
+}
+
+func (x *fp12) mul(y *fp12) *fp12 {
+	var c [11]fp2
+	for k := range c {
+		c[k] = fp2Zero()
+	}
+	for i := range x {
+		if x[i].isZero() {
+			continue
+		}
+		for j := range y {
+			if !y[j].isZero() {
+				c[i+j] = c[i+j].add(x[i].mul(y[j]))
+			}
+		}
+	}
+	// w⁶ = ξ
+	for k := 10; k >= 6; k-- {
+		c[k-6] = c[k-6].add(c[k].mulXi())
+	}
+	var z fp12
+	copy(z[:], c[:6])
+	return &z
+}
+
+func (x *fp12) exp(e *big.Int) *fp12 {
+	z := fp12One()
+	r := &z
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		r = r.mul(r)
+		if e.Bit(i) == 1 {
+			r = r.mul(x)
+		}
+	}
+	return r
+}
+
+// frobenius returns x^(p²ʲ), for 0 <= j < 4.
+func (x *fp12) frobenius(j int) *fp12 {
+	var z fp12
+	for k := range x {
+		z[k] = x[k].mul(bnFrobenius[j][k])
+	}
+	return &z
+}
+
+// inverse returns 1/x, for x != 0. The product of the conjugates of x over
+// a subfield is in that subfield, so that 1/x = x^(p⁶)/x^(p⁶+1), where
+// x^(p⁶+1) is in Fp6 = Fp2[w²], whose inverse is found likewise from its
+// conjugates x -> x^(p²) and x -> x^(p⁴) over Fp2.
+func (x *fp12) inverse() *fp12 {
+	a := x.frobenius(3)
+	n6 := x.mul(a)
+	b := n6.frobenius(1).mul(n6.frobenius(2))
+	n2 := n6.mul(b)[0].inverse()
+	z := a.mul(b)
+	for k := range z {
+		z[k] = z[k].mul(n2)
+	}
+	return z
+}
+
+// finalExp returns x^((p¹²-1)/r), which maps the result of the Miller loop
+// to the group of r-th roots of unity.
+func (x *fp12) finalExp() *fp12 {
+	y := x.frobenius(3).mul(x.inverse())
+	y = y.frobenius(1).mul(y)
+	return y.exp(bnHardExp)
+}
+
+// g1 is a point of E(Fp) in affine coordinates.
+type g1 struct {
+	x, y *big.Int
+}
+
+func (pt g1) onCurve() bool {
+	y2 := fpMod(new(big.Int).Mul(pt.y, pt.y))
+	x3 := new(big.Int).Mul(pt.x, pt.x)
+	x3.Mul(x3, pt.x).Add(x3, bnB)
+	return y2.Cmp(fpMod(x3)) == 0
+}
+
+func (pt g1) neg() g1 {
+	return g1{pt.x, fpMod(new(big.Int).Neg(pt.y))}
+}
+
+// g2 is a point of E'(Fp2) in affine coordinates, or the point at infinity.
+type g2 struct {
+	x, y fp2
+	inf  bool
+}
+
+func (q g2) onCurve() bool {
+	return q.y.mul(q.y).equal(q.x.mul(q.x).mul(q.x).add(bnB2))
+}
+
+// slope returns the slope of the line through q and s, the tangent if they
+// are equal, and false if it is vertical.
+func (q g2) slope(s g2) (fp2, bool) {
+	if q.x.equal(s.x) {
+		if !q.y.equal(s.y) || q.y.isZero() {
+			return fp2{}, false
+		}
+		n := q.x.mul(q.x).mulFp(big.NewInt(3))
+		return n.mul(q.y.add(q.y).inverse()), true
+	}
+	return s.y.sub(q.y).mul(s.x.sub(q.x).inverse()), true
+}
+
+func (q g2) add(s g2) g2 {
+	if q.inf {
+		return s
+	}
+	if s.inf {
+		return q
+	}
+	l, ok := q.slope(s)
+	if !ok {
+		return g2{inf: true}
+	}
+	x := l.mul(l).sub(q.x).sub(s.x)
+	return g2{x: x, y: l.mul(q.x.sub(x)).sub(q.y)}
+}
+
+func (q g2) scalarMul(k *big.Int) g2 {
+	r := g2{inf: true}
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		r = r.add(r)
+		if k.Bit(i) == 1 {
+			r = r.add(q)
+		}
+	}
+	return r
+}
+
+// line returns the value at pt of the line of slope l through t, both
+// mapped back from the twist: y - l·x·w + (l·x_t - y_t)·w³. The vertical
+// lines of the Miller loop are left out, as the final exponentiation maps
+// them to 1.
+func line(t g2, l fp2, pt g1) *fp12 {
+	return &fp12{
+		{pt.y, new(big.Int)},
+		l.mulFp(pt.x).neg(),
+		fp2Zero(),
+		l.mul(t.x).sub(t.y),
+		fp2Zero(),
+		fp2Zero(),
+	}
+}
+
+// miller returns the Miller loop of the ate pairing of q and pt.
+func miller(q g2, pt g1) *fp12 {
+	one := fp12One()
+	f := &one
+	t := q
+	for i := bnAteLoop.BitLen() - 2; i >= 0; i-- {
+		l, _ := t.slope(t)
+		f = f.mul(f).mul(line(t, l, pt))
+		t = t.add(t)
+		if bnAteLoop.Bit(i) == 1 {
+			l, _ = t.slope(q)
+			f = f.mul(line(t, l, pt))
+			t = t.add(q)
+		}
+	}
+	return f
+}
+
+// hashToG1 maps msg to G1 like indy-crypto: the SHA-256 hash of msg is the
+// first candidate x coordinate, incremented until x³ + 2 is a square, whose
+// root a^((p+1)/4) is the y coordinate.
+func hashToG1(msg []byte) g1 {
+	h := sha256.Sum256(msg)
+	x := fpMod(new(big.Int).SetBytes(h[:]))
+	for {
+		rhs := new(big.Int).Mul(x, x)
+		rhs = fpMod(rhs.Mul(rhs, x).Add(rhs, bnB))
+		if big.Jacobi(rhs, bnP) == 1 {
+			return g1{x, new(big.Int).Exp(rhs, bnSqrtExp, bnP)}
+		}
+		x = fpMod(x.Add(x, big.NewInt(1)))
+	}
+}
+
+// fpFromBytes decodes a coordinate, which must be reduced.
+func fpFromBytes(b []byte) (*big.Int, error) {
+	x := new(big.Int).SetBytes(b)
+	if x.Cmp(bnP) >= 0 {
+		return nil, errors.New("coordinate out of range")
+	}
+	return x, nil
+}
+
+// decodeG1 decodes a point of G1 serialized by AMCL: 0x04 and the
+// coordinates x and y in 32 bytes each, padded with zeros by indy-crypto.
+func decodeG1(b []byte) (g1, error) {
+	if len(b) < 65 || b[0] != 0x04 {
+		return g1{}, errors.New("invalid G1 point encoding")
+	}
+	for _, c := range b[65:] {
+		if c != 0 {
+			return g1{}, errors.New("invalid G1 point encoding")
+		}
+	}
+	x, err := fpFromBytes(b[1:33])
+	if err != nil {
+		return g1{}, err
+	}
+	y, err := fpFromBytes(b[33:65])
+	if err != nil {
+		return g1{}, err
+	}
+	pt := g1{x, y}
+	// G1 is all of E(Fp), whose order is r.
+	if !pt.onCurve() {
+		return g1{}, errors.New("G1 point not on the curve")
+	}
+	return pt, nil
+}
+
+// decodeG2 decodes a point of G2 serialized by AMCL: the coordinates x and
+// y, each as its a and b parts in 32 bytes.
+func decodeG2(b []byte) (g2, error) {
+	if len(b) != 128 {
+		return g2{}, errors.New("invalid G2 point encoding")
+	}
+	var c [4]*big.Int
+	for k := range c {
+		var err error
+		if c[k], err = fpFromBytes(b[32*k : 32*(k+1)]); err != nil {
+			return g2{}, err
+		}
+	}
+	q := g2{x: fp2{c[0], c[1]}, y: fp2{c[2], c[3]}}
+	if !q.onCurve() {
+		return g2{}, errors.New("G2 point not on the curve")
+	}
+	// Unlike G1, G2 is a subgroup of the twist.
+	if !q.scalarMul(bnR).inf {
+		return g2{}, errors.New("G2 point not in the group")
+	}
+	return q, nil
+}
+
+func mustDecodeG2(s string) g2 {
+	b, err := base58.Decode(s)
+	if err != nil {
+		panic(err)
+	}
+	q, err := decodeG2(b)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// indyCryptoVerifier is the BLSVerifier of the Pool unless set with
+// WithBLSVerifier. It verifies multi-signatures like indy-crypto: sig is
+// valid if e(sig, g) = e(H(msg), Σ keys), g being blsGenerator.
+type indyCryptoVerifier struct{}
+
+// blsKeyCache maps the BLS keys seen by indyCryptoVerifier to their decoded
+// points, which saves checking again that they are in G2.
+var blsKeyCache sync.Map
+
+// decodeBLSKey decodes a key of a validator, a point of G2.
+func decodeBLSKey(b []byte) (g2, error) {
+	if q, ok := blsKeyCache.Load(string(b)); ok {
+		return q.(g2), nil
+	}
+	q, err := decodeG2(b)
+	if err != nil {
+		return g2{}, err
+	}
+	blsKeyCache.Store(string(b), q)
+	return q, nil
+}
+
+func (indyCryptoVerifier) VerifyMultiSig(sig, msg []byte, keys [][]byte) error {
+	s, err := decodeG1(sig)
+	if err != nil {
+		return err
+	}
+	key := g2{inf: true}
+	for _, b := range keys {
+		k, err := decodeBLSKey(b)
+		if err != nil {
+			return err
+		}
+		key = key.add(k)
+	}
+	if key.inf {
+		return errors.New("no keys")
+	}
+	// e(sig, g) · e(-H(msg), key) = 1
+	f := miller(blsGenerator, s).mul(miller(key, hashToG1(msg).neg()))
+	if !f.finalExp().isOne() {
+		return errors.New("pairing check failed")
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package indyclient
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/mr-tron/base58"
+	"github.com/stretchr/testify/require"
+)
+
+// g1Add returns pt + s, for points which are not opposite.
+func g1Add(pt, s g1) g1 {
+	var l *big.Int
+	if pt.x.Cmp(s.x) == 0 {
+		l = new(big.Int).Mul(pt.x, pt.x)
+		l.Mul(l, big.NewInt(3))
+		l.Mul(l, new(big.Int).ModInverse(fpMod(new(big.Int).Add(pt.y, pt.y)), bnP))
+	} else {
+		l = new(big.Int).Sub(s.y, pt.y)
+		l.Mul(l, new(big.Int).ModInverse(fpMod(new(big.Int).Sub(s.x, pt.x)), bnP))
+	}
+	fpMod(l)
+	x := new(big.Int).Mul(l, l)
+	x = fpMod(x.Sub(x, pt.x).Sub(x, s.x))
+	y := new(big.Int).Sub(pt.x, x)
+	y = fpMod(y.Mul(y, l).Sub(y, pt.y))
+	return g1{x, y}
+}
+
+// g1Mul returns k·pt, for 0 < k < r.
+func g1Mul(pt g1, k *big.Int) g1 {
+	var r *g1
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		if r != nil {
+			d := g1Add(*r, *r)
+			r = &d
+		}
+		if k.Bit(i) == 1 {
+			if r == nil {
+				r = &g1{pt.x, pt.y}
+			} else {
+				s := g1Add(*r, pt)
+				r = &s
+			}
+		}
+	}
+	return *r
+}
+
+func TestPairing(t *testing.T) {
+	gen := g1{new(big.Int).Sub(bnP, big.NewInt(1)), big.NewInt(1)}
+	a, b := big.NewInt(5), big.NewInt(7)
+	e := func(q g2, pt g1) *fp12 { return miller(q, pt).finalExp() }
+	e1 := e(blsGenerator.scalarMul(b), g1Mul(gen, a))
+	e2 := e(blsGenerator, g1Mul(gen, big.NewInt(35)))
+	require.Equal(t, e1, e2)
+	require.False(t, e1.isOne())
+
+	// The final exponentiation is that of (p¹²-1)/r.
+	f := miller(blsGenerator, gen)
+	p12 := new(big.Int).Exp(bnP, big.NewInt(12), nil)
+	require.Equal(t, f.exp(p12.Div(p12.Sub(p12, big.NewInt(1)), bnR)), f.finalExp())
+	require.True(t, f.mul(f.inverse()).isOne())
+}
+
+// encodeG1 and encodeG2 serialize points like indy-crypto.
+func encodeG1(pt g1) []byte {
+	b := make([]byte, 128)
+	b[0] = 0x04
+	fpToBytes(b[1:33], pt.x)
+	fpToBytes(b[33:65], pt.y)
+	return b
+}
+
+func encodeG2(q g2) []byte {
+	b := make([]byte, 128)
+	for k, c := range []*big.Int{q.x.a, q.x.b, q.y.a, q.y.b} {
+		fpToBytes(b[32*k:32*(k+1)], c)
+	}
+	return b
+}
+
+func fpToBytes(b []byte, x *big.Int) {
+	v := x.Bytes()
+	copy(b[len(b)-len(v):], v)
+}
+
+func TestDecodeG2_Genesis(t *testing.T) {
+	// The BLS keys of the validators of the indy-node test pool, whose
+	// secret keys derive from public seeds.
+	for _, k := range []string{
+		"4N8aUNHSgjQVgkpm8nhNEfDf6txHznoYREg9kirmJrkivgL4oSEimFF6nsQ6M41QvhM2Z33nves5vfSn9n1UwNFJBYtWVnHYMATn76vLuL3zU88KyeAYcHfsih3He6UHcXDxcaecHVz6jhCYz1P2UZn2bDVruL5wXpehgBfBaLKm3Ba",
+		"37rAPpXVoxzKhz7d9gkUe52XuXryuLXoM6P6LbWDB7LSbG62Lsb33sfG7zqS8TK1MXwuCHj1FKNzVpsnafmqLG1vXN88rt38mNFs9TENzm4QHdBzsvCuoBnPH7rpYYDo9DZNJePaDvRvqJKByCabubJz3XXKbEeshzpz4Ma5QYpJqjk",
+		"3WFpdbg7C5cnLYZwFZevJqhubkFALBfCBBok15GdrKMUhUjGsk3jV6QKj6MZgEubF7oqCafxNdkm7eswgA4sdKTRc82tLGzZBd6vNqU8dupzup6uYUf32KTHTPQbuUM8Yk4QFXjEf2Usu2TJcNkdgpyeUSX42u5LqdDDpNSWUK5deC5",
+		"2zN3bHM1m4rLz54MJHYSwvqzPchYp8jkHswveCLAEJVcX6Mm1wHQD1SkPYMzUDTZvWvhuE6VNAkK3KxVeEmsanSmvjVkReDeBEMxeDaayjcZjFGPydyey1qxBHmTvAnBKoPydvuTAqx5f7YNNRAdeLmUi99gERUU7TD8KfAa6MpQ9bw",
+	} {
+		b, err := base58.Decode(k)
+		require.NoError(t, err)
+		q, err := decodeG2(b)
+		require.NoError(t, err)
+		require.Equal(t, b, encodeG2(q))
+	}
+
+	// That of Node1 is sk·g, for the secret key from its seed.
+	sk, _ := new(big.Int).SetString("74a6077b3aac5413c2c7295518c34196ad7b263b00c636ed8cf45ac2aacf09b", 16)
+	require.Equal(t, "4N8aUNHSgjQVgkpm8nhNEfDf6txHznoYREg9kirmJrkivgL4oSEimFF6nsQ6M41QvhM2Z33nves5vfSn9n1UwNFJBYtWVnHYMATn76vLuL3zU88KyeAYcHfsih3He6UHcXDxcaecHVz6jhCYz1P2UZn2bDVruL5wXpehgBfBaLKm3Ba",
+		base58.Encode(encodeG2(blsGenerator.scalarMul(sk))))
+
+	_, err := decodeG2(make([]byte, 128))
+	require.Error(t, err)
+	_, err = decodeG2(make([]byte, 64))
+	require.Error(t, err)
+}
+
+func TestIndyCryptoVerifier(t *testing.T) {
+	msg := []byte("message")
+	var keys [][]byte
+	sig := g1{}
+	for i, sk := range []int64{3, 5, 11} {
+		keys = append(keys, encodeG2(blsGenerator.scalarMul(big.NewInt(sk))))
+		s := g1Mul(hashToG1(msg), big.NewInt(sk))
+		if i == 0 {
+			sig = s
+		} else {
+			sig = g1Add(sig, s)
+		}
+	}
+
+	var v indyCryptoVerifier
+	require.NoError(t, v.VerifyMultiSig(encodeG1(sig), msg, keys))
+	require.NoError(t, v.VerifyMultiSig(encodeG1(sig)[:65], msg, keys))
+	require.Error(t, v.VerifyMultiSig(encodeG1(sig), []byte("other message"), keys))
+	require.Error(t, v.VerifyMultiSig(encodeG1(sig), msg, keys[:2]))
+	require.Error(t, v.VerifyMultiSig(encodeG1(sig.neg()), msg, keys))
+	require.Error(t, v.VerifyMultiSig(encodeG1(sig), msg, nil))
+
+	bad := encodeG1(sig)
+	bad[40] ^= 1
+	require.Error(t, v.VerifyMultiSig(bad, msg, keys))
+	bad = encodeG1(sig)
+	bad[100] = 1
+	require.Error(t, v.VerifyMultiSig(bad, msg, keys))
+}
@@ -0,0 +1,106 @@
+package indyclient
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Defaults of WithRetryBudget.
+const (
+	defaultBudgetAttempts = 10
+	defaultBudgetTime     = 30 * time.Second
+)
+
+// ErrBudgetExhausted is returned when a request used up its retry budget
+// without any attempt failing with a more specific error.
+var ErrBudgetExhausted = errors.New("retry budget exhausted")
+
+// budget bounds the combined retry and failover effort of one logical
+// operation, across all validators it talks to. A nil *budget is
+// unlimited. It is safe for concurrent use.
+type budget struct {
+	mu       sync.Mutex
+	left     int
+	deadline time.Time
+	best     error
+	answered bool // best was returned by a validator which answered
+}
+
+func (p *Pool) newBudget() *budget {
+	return &budget{
+		left:     p.budgetAttempts,
+		deadline: time.Now().Add(p.budgetTime),
+	}
+}
+
+// context returns a context which is done when the budget's time is up.
+func (b *budget) context(parent context.Context) (context.Context, context.CancelFunc) {
+	if b == nil {
+		return context.WithCancel(parent)
+	}
+	return context.WithDeadline(parent, b.deadline)
+}
+
+// take uses up one attempt, and reports whether it was still available.
+func (b *budget) take() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.left <= 0 || time.Now().After(b.deadline) {
+		return false
+	}
+	b.left--
+	return true
+}
+
+// refund gives back an attempt, used by a validator which answered but
+// whose reply was not good enough yet, such as a stale one, so that waiting
+// for a better reply is bounded by time rather than attempts.
+func (b *budget) refund() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.left++
+}
+
+// exhausted reports whether no attempt is left.
+func (b *budget) exhausted() bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.left <= 0 || time.Now().After(b.deadline)
+}
+
+// failed records the error of a failed attempt. Errors of validators which
+// answered, such as stale or malformed replies, tell more than connection
+// errors and are kept over them.
+func (b *budget) failed(err error, answered bool) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if answered || !b.answered {
+		b.best = err
+		b.answered = answered
+	}
+}
+
+// err returns the error to report once the budget is exhausted: the best
+// error seen, or ErrBudgetExhausted.
+func (b *budget) err() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.best == nil {
+		return ErrBudgetExhausted
+	}
+	return b.best
+}
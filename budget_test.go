@@ -0,0 +1,35 @@
+package indyclient
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBudget(t *testing.T) {
+	p := &Pool{budgetAttempts: 2, budgetTime: time.Minute}
+	b := p.newBudget()
+	require.Equal(t, ErrBudgetExhausted, b.err())
+
+	require.True(t, b.take())
+	require.True(t, b.take())
+	require.False(t, b.take())
+
+	// Errors of validators which answered win over connection errors.
+	connErr := errors.New("connection refused")
+	b.failed(connErr, false)
+	require.Equal(t, connErr, b.err())
+	b.failed(ErrNotFresh, true)
+	b.failed(connErr, false)
+	require.Equal(t, ErrNotFresh, b.err())
+
+	p.budgetTime = 0
+	b = p.newBudget()
+	time.Sleep(time.Millisecond)
+	require.False(t, b.take())
+
+	var unlimited *budget
+	require.True(t, unlimited.take())
+}
@@ -0,0 +1,142 @@
+package indyclient
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// A TxnCache stores ledger transactions, which never change once ordered,
+// so that GetTransaction and the functions built on it fetch each of them
+// once. Transactions are stored as the data of their GET_TXN reply.
+// Implementations, for example on top of an embedded key-value store, must
+// be safe for concurrent use.
+type TxnCache interface {
+	// Get returns the transaction seqNo of ledger, or nil if it is not
+	// cached.
+	Get(ledger LedgerId, seqNo int) ([]byte, error)
+	// Put stores the transaction seqNo of ledger.
+	Put(ledger LedgerId, seqNo int, txn []byte) error
+}
+
+// WithCache makes the Pool look up transactions in c before fetching them,
+// and store those it fetches in c.
+func WithCache(c TxnCache) Option {
+	return func(p *Pool) {
+		p.cache = c
+	}
+}
+
+// DirCache is a TxnCache keeping each transaction in a file of the
+// directory Dir, grouped by ledger and thousands of seqNo, such as
+// Dir/1/12/12345.json.
+type DirCache struct {
+	Dir string
+}
+
+func (c *DirCache) path(ledger LedgerId, seqNo int) string {
+	return filepath.Join(c.Dir, strconv.Itoa(int(ledger)), strconv.Itoa(seqNo/1000), strconv.Itoa(seqNo)+".json")
+}
+
+func (c *DirCache) Get(ledger LedgerId, seqNo int) ([]byte, error) {
+	txn, err := ioutil.ReadFile(c.path(ledger, seqNo))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return txn, err
+}
+
+func (c *DirCache) Put(ledger LedgerId, seqNo int, txn []byte) error {
+	path := c.path(ledger, seqNo)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	// Write to a temporary file first, so that an interrupted Put does not
+	// leave a truncated transaction behind.
+	f, err := ioutil.TempFile(filepath.Dir(path), ".txn")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(txn)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// cachedTxn returns the reply to the GET_TXN request for the transaction
+// seqNo of ledger from the cache of p, or nil if it is not cached. The reply
+// is Verified if the audit path stored with the transaction verifies, but
+// carries no ledgerSize, as the ledger has likely grown since.
+func (p *Pool) cachedTxn(ledger LedgerId, seqNo int) *Reply {
+	txn, err := p.cache.Get(ledger, seqNo)
+	if err != nil {
+		p.log.Log(LevelWarn, "reading transaction cache", "ledger", ledger, "seqNo", seqNo, "err", err)
+		return nil
+	}
+	if txn == nil {
+		return nil
+	}
+	r, err := getTxnReply(seqNo, txn)
+	if err != nil {
+		return nil
+	}
+	size, root, err := verifyAuditPath(r)
+	verified := err == nil
+
+	var data map[string]json.RawMessage
+	if err := json.Unmarshal(txn, &data); err != nil {
+		return nil
+	}
+	delete(data, "ledgerSize")
+	if txn, err = json.Marshal(data); err != nil {
+		return nil
+	}
+	if r, err = getTxnReply(seqNo, txn); err != nil {
+		return nil
+	}
+	r.Verified = verified
+	r.ledgerSize, r.rootHash = size, root
+	return r
+}
+
+// getTxnReply returns a reply to the GET_TXN request for seqNo holding txn.
+func getTxnReply(seqNo int, txn []byte) (*Reply, error) {
+	result, err := json.Marshal(map[string]interface{}{
+		"type":  strconv.Itoa(int(idGetTxn)),
+		"seqNo": seqNo,
+		"data":  json.RawMessage(txn),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Reply{Op: "REPLY", Result: result}, nil
+}
+
+// cacheTxn stores the transaction of the GET_TXN reply r in the cache of
+// p, with its audit path and the root hash and size of the ledger it leads
+// to, so that cached transactions can still be verified.
+func (p *Pool) cacheTxn(ledger LedgerId, seqNo int, r *Reply) {
+	if r.Op != "REPLY" {
+		return
+	}
+	var data map[string]json.RawMessage
+	if err := r.DecodeResult(&data); err != nil {
+		return
+	}
+	txn, err := json.Marshal(data)
+	if err == nil {
+		err = p.cache.Put(ledger, seqNo, txn)
+	}
+	if err != nil {
+		p.log.Log(LevelWarn, "writing transaction cache", "ledger", ledger, "seqNo", seqNo, "err", err)
+	}
+}
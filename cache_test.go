@@ -0,0 +1,81 @@
+package indyclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mr-tron/base58"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool_Cache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "indyclient")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	cache := &DirCache{Dir: dir}
+
+	v := ledgerValidator(testLedger)
+	pool := testPool(t, fakeTransport{"Node1": v, "Node2": v, "Node3": v, "Node4": v}, WithCache(cache))
+	res, err := pool.GetTxn(context.Background(), DomainLedger, 2)
+	require.NoError(t, err)
+	require.Equal(t, "Th7MpTaRZVRYnPiabds81Y", res.Txn.Txn.Data.Dest)
+	txn, err := cache.Get(DomainLedger, 2)
+	require.NoError(t, err)
+	require.NotNil(t, txn)
+	txn, err = cache.Get(DomainLedger, 3)
+	require.NoError(t, err)
+	require.Nil(t, txn)
+
+	// A Pool whose validators are down still serves cached transactions.
+	pool = testPool(t, fakeTransport{}, WithCache(cache))
+	r, err := pool.GetTransaction(context.Background(), DomainLedger, 2)
+	require.NoError(t, err)
+	require.Equal(t, "", r.Node)
+	res, err = r.GetTxnResult()
+	require.NoError(t, err)
+	require.Equal(t, "Th7MpTaRZVRYnPiabds81Y", res.Txn.Txn.Data.Dest)
+	require.Equal(t, 2, res.SeqNo)
+}
+
+func TestPool_CacheVerify(t *testing.T) {
+	dir, err := ioutil.TempDir("", "indyclient")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	cache := &DirCache{Dir: dir}
+
+	// A validator serving a ledger of two transactions with audit paths.
+	var txns [2]string
+	var leaves [2][]byte
+	for i := range txns {
+		txns[i] = fmt.Sprintf(`{"reqSignature":{},"txn":{"data":{"dest":"V4SGRU86Z58d6TV7PBUe6f"},"metadata":{},"type":"1"},"txnMetadata":{"seqNo":%v,"txnTime":1500000000},"ver":"1"}`, i+1)
+		leaves[i], err = leafHash(json.RawMessage(txns[i]))
+		require.NoError(t, err)
+	}
+	root := base58.Encode(nodeHash(leaves[0], leaves[1]))
+	var ledger []string
+	for i, txn := range txns {
+		ledger = append(ledger, strings.TrimSuffix(txn, "}")+fmt.Sprintf(`,"auditPath":["%v"],"ledgerSize":2,"rootHash":"%v"}`, base58.Encode(leaves[1-i]), root))
+	}
+	v := ledgerValidator(ledger)
+
+	export := func(pool *Pool) string {
+		var b bytes.Buffer
+		require.NoError(t, pool.Export(context.Background(), &b, DomainLedger, WithRange(1, 2), WithVerification()))
+		return b.String()
+	}
+	require.Contains(t, export(testPool(t, fakeTransport{"Node1": v, "Node2": v, "Node3": v, "Node4": v}, WithCache(cache))), `"seqNo":2`)
+
+	// Transactions served from the cache still verify, but do not tell the
+	// size of the ledger, which may have grown since.
+	pool := testPool(t, fakeTransport{}, WithCache(cache))
+	require.Contains(t, export(pool), `"seqNo":2`)
+	res, err := pool.GetTxn(context.Background(), DomainLedger, 2)
+	require.NoError(t, err)
+	require.Equal(t, 0, res.LedgerSize)
+}
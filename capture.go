@@ -0,0 +1,39 @@
+package indyclient
+
+import "time"
+
+// A Frame is a message exchanged with a validator, as seen by the hook of
+// WithFrameHook.
+type Frame struct {
+	Node    string // alias of the validator
+	Address string // client address of the validator
+	Sent    bool   // whether the client sent the frame, rather than received it
+	Time    time.Time
+	Data    []byte
+}
+
+// WithFrameHook makes the Pool call hook with every frame it sends to or
+// receives from a validator, including requests sent again and replies
+// nobody waits for anymore, for debugging or to keep a log of the exchanges
+// with the pool. hook is called synchronously and must not modify Data. It
+// must be safe for concurrent use.
+func WithFrameHook(hook func(Frame)) Option {
+	return func(p *Pool) {
+		p.frameHook = hook
+	}
+}
+
+// newConn wraps a connection to v dialed by p.
+func (p *Pool) newConn(t Connection, v Validator) *conn {
+	c := newConn(t, v.Alias)
+	c.address = v.Address
+	c.hook = p.frameHook
+	return c
+}
+
+// capture passes frame to the frame hook of c, if any.
+func (c *conn) capture(sent bool, frame []byte) {
+	if c.hook != nil {
+		c.hook(Frame{Node: c.alias, Address: c.address, Sent: sent, Time: time.Now(), Data: frame})
+	}
+}
@@ -0,0 +1,38 @@
+package indyclient
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool_FrameHook(t *testing.T) {
+	var mu sync.Mutex
+	var frames []Frame
+	v := ledgerValidator(testLedger)
+	pool := testPool(t, fakeTransport{"Node1": v, "Node2": v, "Node3": v, "Node4": v},
+		WithFrameHook(func(f Frame) {
+			mu.Lock()
+			frames = append(frames, f)
+			mu.Unlock()
+		}))
+
+	reply, err := pool.GetTransaction(context.Background(), DomainLedger, 1)
+	require.NoError(t, err)
+	require.Equal(t, "Node1", reply.Node)
+	require.Equal(t, "10.0.0.1:9702", reply.Address)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.True(t, len(frames) >= 2)
+	require.True(t, frames[0].Sent)
+	require.Contains(t, string(frames[0].Data), `"type":"3"`)
+	last := frames[len(frames)-1]
+	require.False(t, last.Sent)
+	require.Equal(t, "Node1", last.Node)
+	require.Equal(t, "10.0.0.1:9702", last.Address)
+	require.True(t, strings.Contains(string(last.Data), `"op":"REPLY"`))
+}
@@ -0,0 +1,448 @@
+package indyclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mr-tron/base58"
+)
+
+type catchupReq struct {
+	Op          string `json:"op"`
+	LedgerID    int    `json:"ledgerId"`
+	SeqNoStart  int    `json:"seqNoStart"`
+	SeqNoEnd    int    `json:"seqNoEnd"`
+	CatchupTill int    `json:"catchupTill"`
+}
+
+type catchupRep struct {
+	Op        string                     `json:"op"`
+	LedgerID  int                        `json:"ledgerId"`
+	Txns      map[string]json.RawMessage `json:"txns"`
+	ConsProof []string                   `json:"consProof"`
+}
+
+type ledgerStatus struct {
+	Op              string `json:"op"`
+	LedgerID        int    `json:"ledgerId"`
+	TxnSeqNo        int    `json:"txnSeqNo"`
+	ViewNo          *int   `json:"viewNo"`
+	PpSeqNo         *int   `json:"ppSeqNo"`
+	MerkleRoot      string `json:"merkleRoot"`
+	ProtocolVersion int    `json:"protocolVersion"`
+}
+
+// consistencyProof is sent by validators instead of their LEDGER_STATUS if
+// the ledger of the requester is behind. Hashes prove that their ledger,
+// of size SeqNoEnd, extends that of the requester, of size SeqNoStart.
+type consistencyProof struct {
+	Op            string   `json:"op"`
+	LedgerID      int      `json:"ledgerId"`
+	SeqNoStart    int      `json:"seqNoStart"`
+	SeqNoEnd      int      `json:"seqNoEnd"`
+	ViewNo        int      `json:"viewNo"`
+	PpSeqNo       int      `json:"ppSeqNo"`
+	OldMerkleRoot string   `json:"oldMerkleRoot"`
+	NewMerkleRoot string   `json:"newMerkleRoot"`
+	Hashes        []string `json:"hashes"`
+}
+
+// nodeMessage sends m, a message of the protocol between validators, to
+// the current validator and returns the first message received which
+// accept takes. Validators may send other messages on the connection, which
+// are skipped.
+func (p *Pool) nodeMessage(ctx context.Context, m []byte, accept func(frame string) (bool, error)) error {
+	c, err := p.connection(ctx, nil, p.newBudget())
+	if err != nil {
+		return err
+	}
+	return p.nodeMessageOn(ctx, c, m, accept)
+}
+
+// nodeMessageOn is nodeMessage on the connection c. The answer is awaited
+// for at most the reply timeout of the Pool.
+func (p *Pool) nodeMessageOn(ctx context.Context, c *conn, m []byte, accept func(frame string) (bool, error)) error {
+	if p.replyTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.replyTimeout)
+		defer cancel()
+	}
+	ch := c.subscribe()
+	defer c.unsubscribe(ch)
+	if err := c.send(m); err != nil {
+		return err
+	}
+	for {
+		frame, err := c.wait(ctx, ch)
+		if err != nil {
+			return err
+		}
+		ok, err := accept(frame)
+		if err != nil || ok {
+			return err
+		}
+	}
+}
+
+// LedgerStatus returns the size and the base58 encoded Merkle root hash of
+// ledger, once f+1 validators, where f is the number of faulty validators
+// the pool tolerates, reported the same with a LEDGER_STATUS message. It
+// returns ErrNoConsensus if they do not agree, for example because the
+// ledger grows too fast.
+func (p *Pool) LedgerStatus(ctx context.Context, ledger LedgerId) (int, string, error) {
+	st, err := p.ledgerQuorum(ctx, ledger, 0)
+	if err != nil {
+		return 0, "", err
+	}
+	return st.size, base58.Encode(st.root), nil
+}
+
+// ledgerState is the state of a ledger as reported by a validator in
+// answer to a LEDGER_STATUS message claiming that the ledger has size
+// prefix: its size and root hash and, if it is larger than prefix, the
+// root hash of its first prefix transactions with the consistency proof
+// that it extends them. If it is smaller than prefix, prefixRoot is nil.
+type ledgerState struct {
+	size       int
+	root       []byte
+	prefix     int
+	prefixRoot []byte
+	proof      [][]byte
+}
+
+// ledgerProof sends a LEDGER_STATUS to the validator of c, claiming that
+// ledger has size prefix and root hash prefixRoot, and returns the state
+// of its copy of ledger, once checked with the consistency proof it
+// answers with. It returns ErrInconsistentLedger if the validator's ledger
+// does not extend the claimed one. If prefixRoot is nil, the root hash of
+// the prefix sent by the validator is taken, and the state of a ledger
+// smaller than prefix is returned as is.
+func (p *Pool) ledgerProof(ctx context.Context, c *conn, ledger LedgerId, prefix int, prefixRoot []byte) (*ledgerState, error) {
+	claimed := prefixRoot
+	if claimed == nil {
+		// Validators only look at the size of the ledger of the
+		// requester, and send the root hash of theirs with the proof.
+		claimed = new(merkleTree).root()
+	}
+	m, _ := json.Marshal(ledgerStatus{
+		Op:              "LEDGER_STATUS",
+		LedgerID:        int(ledger),
+		TxnSeqNo:        prefix,
+		MerkleRoot:      base58.Encode(claimed),
+		ProtocolVersion: 2,
+	})
+	var st *ledgerState
+	err := p.nodeMessageOn(ctx, c, m, func(frame string) (bool, error) {
+		var msg struct {
+			Op       string `json:"op"`
+			LedgerID int    `json:"ledgerId"`
+		}
+		if err := decodeFrame(frame, &msg); err != nil {
+			return false, err
+		}
+		if msg.LedgerID != int(ledger) {
+			return false, nil
+		}
+		var err error
+		switch msg.Op {
+		case "LEDGER_STATUS":
+			var ls ledgerStatus
+			if err := decodeFrame(frame, &ls); err != nil {
+				return false, err
+			}
+			st, err = ls.state(prefix, prefixRoot)
+			return true, err
+		case "CONSISTENCY_PROOF":
+			var cp consistencyProof
+			if err := decodeFrame(frame, &cp); err != nil {
+				return false, err
+			}
+			st, err = cp.state(prefix, prefixRoot)
+			return true, err
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", c.alias, err)
+	}
+	return st, nil
+}
+
+// state returns the state of the ledger of the validator which sent the
+// LEDGER_STATUS ls, in answer to one claiming that the ledger has size
+// prefix and, unless nil, root hash prefixRoot.
+func (ls *ledgerStatus) state(prefix int, prefixRoot []byte) (*ledgerState, error) {
+	root, err := base58.Decode(ls.MerkleRoot)
+	if err != nil {
+		return nil, fmt.Errorf("invalid merkle root: %v", err)
+	}
+	st := &ledgerState{size: ls.TxnSeqNo, root: root, prefix: prefix}
+	switch {
+	case ls.TxnSeqNo > prefix:
+		// Validators send a consistency proof instead.
+		return nil, fmt.Errorf("LEDGER_STATUS of size %v without consistency proof", ls.TxnSeqNo)
+	case ls.TxnSeqNo < prefix:
+		if prefixRoot != nil {
+			return nil, fmt.Errorf("validator is behind: ledger of size %v", ls.TxnSeqNo)
+		}
+		return st, nil
+	case prefixRoot != nil && !bytes.Equal(root, prefixRoot):
+		return nil, fmt.Errorf("%w: ledger of size %v", ErrInconsistentLedger, prefix)
+	}
+	st.prefixRoot = root
+	return st, nil
+}
+
+// state returns the state of the ledger of the validator which sent the
+// CONSISTENCY_PROOF cp, in answer to a LEDGER_STATUS claiming that the
+// ledger has size prefix and, unless nil, root hash prefixRoot.
+func (cp *consistencyProof) state(prefix int, prefixRoot []byte) (*ledgerState, error) {
+	if cp.SeqNoStart != prefix {
+		return nil, fmt.Errorf("consistency proof from size %v, expected %v", cp.SeqNoStart, prefix)
+	}
+	root, err := base58.Decode(cp.NewMerkleRoot)
+	if err != nil {
+		return nil, fmt.Errorf("invalid merkle root: %v", err)
+	}
+	if prefixRoot == nil {
+		if prefixRoot, err = base58.Decode(cp.OldMerkleRoot); err != nil {
+			return nil, fmt.Errorf("invalid merkle root: %v", err)
+		}
+	}
+	proof, err := decodeHashes(cp.Hashes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid consistency proof: %v", err)
+	}
+	if prefix == 0 {
+		// Any ledger extends the empty one, whatever the proof.
+		prefixRoot, proof = new(merkleTree).root(), nil
+	}
+	if cp.SeqNoEnd <= prefix || !verifyConsistency(prefix, cp.SeqNoEnd, prefixRoot, root, proof) {
+		return nil, fmt.Errorf("%w: ledger of size %v", ErrInconsistentLedger, prefix)
+	}
+	return &ledgerState{size: cp.SeqNoEnd, root: root, prefix: prefix, prefixRoot: prefixRoot, proof: proof}, nil
+}
+
+// prefixTree returns the compact Merkle tree of the first st.prefix
+// transactions of the ledger, which must be larger.
+func (st *ledgerState) prefixTree() merkleTree {
+	if st.prefix == 0 {
+		return merkleTree{}
+	}
+	return consistencyPrefix(st.prefix, st.size, st.prefixRoot, st.proof)
+}
+
+// ledgerQuorum asks the validators for the state of ledger with
+// LEDGER_STATUS messages claiming that it has size prefix, and returns it
+// once f+1 of them reported the same one.
+func (p *Pool) ledgerQuorum(ctx context.Context, ledger LedgerId, prefix int) (*ledgerState, error) {
+	vs := p.validators()
+	need := faulty(vs) + 1
+	votes := make(map[string]int)
+	var failed []string
+	var agreed *ledgerState
+
+	p.fanOut(ctx, vs, func(ctx context.Context, v Validator) (interface{}, error) {
+		s, err := p.dial(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+		c := p.newConn(s, v)
+		defer c.close()
+		return p.ledgerProof(ctx, c, ledger, prefix, nil)
+	}, func(v Validator, val interface{}, err error) bool {
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%v (%v)", v.Alias, err))
+			return false
+		}
+		st := val.(*ledgerState)
+		k := fmt.Sprintf("%v %x %x", st.size, st.root, st.prefixRoot)
+		votes[k]++
+		if votes[k] >= need {
+			agreed = st
+			return true
+		}
+		return false
+	})
+	if agreed != nil {
+		return agreed, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	sort.Strings(failed)
+	return nil, fmt.Errorf("%w: %v ledger states, need %v agreeing; failed: %v",
+		ErrNoConsensus, len(votes), need, strings.Join(failed, ", "))
+}
+
+// decodeHashes decodes the base58 encoded hashes of a proof.
+func decodeHashes(hashes []string) ([][]byte, error) {
+	proof := make([][]byte, len(hashes))
+	for i, h := range hashes {
+		var err error
+		if proof[i], err = base58.Decode(h); err != nil {
+			return nil, err
+		}
+	}
+	return proof, nil
+}
+
+// catchup sends a CATCHUP_REQ for the transactions from seqNo from to seqNo
+// to of ledger, against the ledger of size till, and returns the reply.
+func (p *Pool) catchup(ctx context.Context, ledger LedgerId, from, to, till int) (*catchupRep, error) {
+	req := catchupReq{
+		Op:          "CATCHUP_REQ",
+		LedgerID:    int(ledger),
+		SeqNoStart:  from,
+		SeqNoEnd:    to,
+		CatchupTill: till,
+	}
+	m, _ := json.Marshal(req)
+
+	var rep catchupRep
+	err := p.nodeMessage(ctx, m, func(frame string) (bool, error) {
+		rep = catchupRep{}
+		if err := decodeFrame(frame, &rep); err != nil {
+			return false, err
+		}
+		return rep.Op == "CATCHUP_REP" && rep.LedgerID == req.LedgerID, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(rep.Txns) != to-from+1 {
+		return nil, fmt.Errorf("got %v transactions, expected %v", len(rep.Txns), to-from+1)
+	}
+	return &rep, nil
+}
+
+// blocks returns the transactions of the reply in order, from seqNo from,
+// with their encodings.
+func (rep *catchupRep) blocks(from int) ([]*Block, []json.RawMessage, error) {
+	blocks := make([]*Block, 0, len(rep.Txns))
+	raws := make([]json.RawMessage, 0, len(rep.Txns))
+	for seqNo := from; seqNo < from+len(rep.Txns); seqNo++ {
+		raw, ok := rep.Txns[strconv.Itoa(seqNo)]
+		if !ok {
+			return nil, nil, fmt.Errorf("transaction %v missing from catchup reply", seqNo)
+		}
+		b := new(Block)
+		if err := json.Unmarshal(raw, b); err != nil {
+			return nil, nil, fmt.Errorf("transaction %v: %v", seqNo, err)
+		}
+		if b.TxnMetadata.SeqNo != seqNo {
+			return nil, nil, fmt.Errorf("transaction %v has seqNo %v", seqNo, b.TxnMetadata.SeqNo)
+		}
+		blocks = append(blocks, b)
+		raws = append(raws, raw)
+	}
+	return blocks, raws, nil
+}
+
+// Catchup fetches the transactions from seqNo from to seqNo to (inclusive)
+// with a single CATCHUP_REQ, the message validators use to replicate
+// ledgers between themselves. This is much cheaper than one GET_TXN per
+// transaction when copying large parts of a ledger. The transactions are
+// checked like those of CatchupLedger. It fails if to exceeds the size of
+// the ledger.
+func (p *Pool) Catchup(ctx context.Context, ledger LedgerId, from, to int) ([]*Block, error) {
+	if from < 1 || to < from {
+		return nil, fmt.Errorf("invalid catchup range [%v, %v]", from, to)
+	}
+	var blocks []*Block
+	err := p.catchupLedger(ctx, ledger, from, to, to-from+1, func(b []*Block, _ []json.RawMessage) error {
+		blocks = b
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(blocks) < to-from+1 {
+		return nil, fmt.Errorf("catchup range [%v, %v] past the end of the ledger", from, to)
+	}
+	return blocks, nil
+}
+
+// ErrInconsistentLedger is returned by CatchupLedger when transactions do
+// not match the ledger's Merkle root, and by Export when validators serve
+// transactions of different ledgers.
+var ErrInconsistentLedger = errors.New("transactions inconsistent with ledger root")
+
+// CatchupLedger downloads the whole of ledger with CATCHUP_REQ messages of
+// batchSize transactions, and calls fn with each batch in order. It first
+// asks for the ledger size and root hash with LedgerStatus, which f+1
+// validators must agree on, and checks each batch with the consistency
+// proof which comes with it: together with the transactions before it, the
+// batch must be the beginning of the ledger with that root hash. Batches
+// are only passed to fn once verified.
+func (p *Pool) CatchupLedger(ctx context.Context, ledger LedgerId, batchSize int, fn func([]*Block) error) error {
+	return p.catchupLedger(ctx, ledger, 1, 0, batchSize, func(blocks []*Block, _ []json.RawMessage) error {
+		return fn(blocks)
+	})
+}
+
+// catchupLedger is CatchupLedger for the transactions from seqNo start to
+// seqNo end, or the end of the ledger if end is 0 or past it, also passing
+// the encoding of the transactions to fn. The transactions before start
+// are not downloaded: the root hash of the ledger they form, and the
+// hashes needed to append the next ones to it, come from the consistency
+// proof which f+1 validators agree on.
+func (p *Pool) catchupLedger(ctx context.Context, ledger LedgerId, start, end, batchSize int, fn func([]*Block, []json.RawMessage) error) error {
+	if batchSize < 1 {
+		return fmt.Errorf("invalid batch size %v", batchSize)
+	}
+	if start < 1 {
+		return ErrInvalidSeqNo
+	}
+	st, err := p.ledgerQuorum(ctx, ledger, start-1)
+	if err != nil {
+		return err
+	}
+	if st.size < start {
+		return nil
+	}
+	size, root := st.size, st.root
+	if end == 0 || end > size {
+		end = size
+	}
+
+	tree := st.prefixTree()
+	for from := start; from <= end; from += batchSize {
+		to := from + batchSize - 1
+		if to > end {
+			to = end
+		}
+		rep, err := p.catchup(ctx, ledger, from, to, size)
+		if err != nil {
+			return err
+		}
+		blocks, raws, err := rep.blocks(from)
+		if err != nil {
+			return err
+		}
+		for _, raw := range raws {
+			h, err := leafHash(raw)
+			if err != nil {
+				return err
+			}
+			tree.append(h)
+		}
+		proof, err := decodeHashes(rep.ConsProof)
+		if err != nil {
+			return fmt.Errorf("invalid consistency proof: %v", err)
+		}
+		if !verifyConsistency(to, size, tree.root(), root, proof) {
+			return fmt.Errorf("%w: transactions [%v, %v]", ErrInconsistentLedger, from, to)
+		}
+		if err := fn(blocks, raws); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package indyclient_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mr-tron/base58"
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/indyclient"
+	"go.dedis.ch/indyclient/indyclienttest"
+)
+
+// catchupNetwork returns a Network whose domain ledger holds n NYMs, the
+// first one for first.
+func catchupNetwork(t *testing.T, n int, first string) *indyclienttest.Network {
+	var txns strings.Builder
+	for i := 1; i <= n; i++ {
+		dest := fmt.Sprintf("dest%v", i)
+		if i == 1 {
+			dest = first
+		}
+		fmt.Fprintf(&txns, `{"txn":{"type":"1","data":{"dest":"%v"}},"txnMetadata":{"seqNo":%v}}`+"\n", dest, i)
+	}
+	nw := indyclienttest.NewNetwork(4)
+	require.NoError(t, nw.LoadTxns(indyclient.DomainLedger, strings.NewReader(txns.String())))
+	return nw
+}
+
+// alteringTransport passes the messages received from validators through
+// alter, by validator alias, and connects to the validators in forked
+// through fork instead of Transport.
+type alteringTransport struct {
+	indyclient.Transport
+	fork   indyclient.Transport
+	forked map[string]bool
+	alter  func(alias string, m []byte) []byte
+}
+
+func (t *alteringTransport) Dial(ctx context.Context, v indyclient.Validator) (indyclient.Connection, error) {
+	dial := t.Transport.Dial
+	if t.forked[v.Alias] {
+		dial = t.fork.Dial
+	}
+	c, err := dial(ctx, v)
+	if err != nil || t.alter == nil {
+		return c, err
+	}
+	return &alteringConn{Connection: c, alias: v.Alias, alter: t.alter}, nil
+}
+
+type alteringConn struct {
+	indyclient.Connection
+	alias string
+	alter func(alias string, m []byte) []byte
+}
+
+func (c *alteringConn) Receive(timeout time.Duration) ([]byte, error) {
+	m, err := c.Connection.Receive(timeout)
+	if err != nil || m == nil {
+		return m, err
+	}
+	return c.alter(c.alias, m), nil
+}
+
+// alterMessage returns an alter function for alteringTransport which
+// applies fn to the messages with the given op, decoded.
+func alterMessage(op string, fn func(m map[string]interface{})) func(string, []byte) []byte {
+	return func(_ string, b []byte) []byte {
+		var m map[string]interface{}
+		if json.Unmarshal(b, &m) != nil || m["op"] != op {
+			return b
+		}
+		fn(m)
+		b, _ = json.Marshal(m)
+		return b
+	}
+}
+
+func TestPool_CatchupLedger(t *testing.T) {
+	ctx := context.Background()
+	n := catchupNetwork(t, 10, "dest1")
+	// The first transaction sent in a CATCHUP_REP.
+	var mu sync.Mutex
+	first := 0
+	pool, err := n.Pool(indyclient.WithTransport(&alteringTransport{
+		Transport: n.Transport(),
+		alter: alterMessage("CATCHUP_REP", func(m map[string]interface{}) {
+			mu.Lock()
+			defer mu.Unlock()
+			for k := range m["txns"].(map[string]interface{}) {
+				if seqNo, _ := strconv.Atoi(k); first == 0 || seqNo < first {
+					first = seqNo
+				}
+			}
+		}),
+	}))
+	require.NoError(t, err)
+
+	size, _, err := pool.LedgerStatus(ctx, indyclient.DomainLedger)
+	require.NoError(t, err)
+	require.Equal(t, 10, size)
+
+	var seqNos []int
+	require.NoError(t, pool.CatchupLedger(ctx, indyclient.DomainLedger, 3, func(blocks []*indyclient.Block) error {
+		for _, b := range blocks {
+			seqNos = append(seqNos, b.TxnMetadata.SeqNo)
+		}
+		return nil
+	}))
+	require.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, seqNos)
+
+	blocks, err := pool.Catchup(ctx, indyclient.DomainLedger, 4, 6)
+	require.NoError(t, err)
+	require.Len(t, blocks, 3)
+	require.Equal(t, "dest6", blocks[2].Txn.Data.Dest)
+	_, err = pool.Catchup(ctx, indyclient.DomainLedger, 8, 12)
+	require.Error(t, err)
+
+	// Exports from the middle of the ledger do not download its beginning.
+	mu.Lock()
+	first = 0
+	mu.Unlock()
+	var out bytes.Buffer
+	require.NoError(t, pool.Export(ctx, &out, indyclient.DomainLedger, indyclient.WithCatchup(3),
+		indyclient.WithRange(5, 8), indyclient.WithFormat(indyclient.FormatNDJSON)))
+	require.Equal(t, 4, strings.Count(out.String(), "\n"))
+	require.Contains(t, out.String(), `"seqNo":8`)
+	require.Equal(t, 5, first)
+}
+
+func TestPool_CatchupTampered(t *testing.T) {
+	ctx := context.Background()
+	n := catchupNetwork(t, 10, "dest1")
+	wrongHash := base58.Encode(make([]byte, 32))
+
+	for _, tc := range []struct {
+		name    string
+		alter   func(string, []byte) []byte
+		err     error
+		written []int // the batches before the tampered one
+	}{
+		{"transaction", func(_ string, m []byte) []byte {
+			return bytes.Replace(m, []byte(`"dest5"`), []byte(`"evil5"`), 1)
+		}, indyclient.ErrInconsistentLedger, []int{3, 4}},
+		{"catchup proof", alterMessage("CATCHUP_REP", func(m map[string]interface{}) {
+			if proof := m["consProof"].([]interface{}); len(proof) > 0 {
+				proof[0] = wrongHash
+			}
+		}), indyclient.ErrInconsistentLedger, nil},
+		{"ledger proof", alterMessage("CONSISTENCY_PROOF", func(m map[string]interface{}) {
+			m["hashes"].([]interface{})[0] = wrongHash
+		}), indyclient.ErrNoConsensus, nil},
+	} {
+		pool, err := n.Pool(indyclient.WithTransport(&alteringTransport{Transport: n.Transport(), alter: tc.alter}))
+		require.NoError(t, err)
+
+		var seqNos []int
+		err = pool.Export(ctx, &bytes.Buffer{}, indyclient.DomainLedger, indyclient.WithCatchup(2),
+			indyclient.WithRange(3, 0), indyclient.WithProgress(func(seqNo int) {
+				seqNos = append(seqNos, seqNo)
+			}))
+		require.True(t, errors.Is(err, tc.err), "%v: %v", tc.name, err)
+		require.Equal(t, tc.written, seqNos, tc.name)
+
+		_, err = pool.Catchup(ctx, indyclient.DomainLedger, 5, 6)
+		require.True(t, errors.Is(err, tc.err), "%v: %v", tc.name, err)
+	}
+}
+
+func TestPool_CatchupQuorum(t *testing.T) {
+	ctx := context.Background()
+	honest := catchupNetwork(t, 10, "dest1")
+	fork := catchupNetwork(t, 12, "evil1")
+
+	for _, alias := range []string{"Node1", "Node4"} {
+		pool, err := honest.Pool(indyclient.WithTransport(&alteringTransport{
+			Transport: honest.Transport(),
+			fork:      fork.Transport(),
+			forked:    map[string]bool{alias: true},
+		}))
+		require.NoError(t, err)
+
+		// The forked validator is outvoted.
+		size, _, err := pool.LedgerStatus(ctx, indyclient.DomainLedger)
+		require.NoError(t, err)
+		require.Equal(t, 10, size)
+
+		err = pool.CatchupLedger(ctx, indyclient.DomainLedger, 5, func([]*indyclient.Block) error {
+			return nil
+		})
+		if alias == "Node1" {
+			// It is still asked for the transactions, which are rejected.
+			require.True(t, errors.Is(err, indyclient.ErrInconsistentLedger), "%v", err)
+		} else {
+			require.NoError(t, err)
+		}
+	}
+}
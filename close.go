@@ -0,0 +1,31 @@
+package indyclient
+
+import "errors"
+
+// ErrClosed is returned by the operations of a Pool which was closed.
+var ErrClosed = errors.New("pool is closed")
+
+// Close closes the connections of the Pool to the validators and stops its
+// background work, such as KeepAlive and Watch. Requests in flight fail,
+// and later ones return ErrClosed. Close is safe to call more than once.
+func (p *Pool) Close() error {
+	p.closeOnce.Do(func() {
+		if p.done != nil {
+			close(p.done)
+		}
+		p.mu.Lock()
+		p.closeConnections()
+		p.mu.Unlock()
+	})
+	return nil
+}
+
+// closed reports whether Close was called.
+func (p *Pool) closed() bool {
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
+	}
+}
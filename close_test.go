@@ -0,0 +1,35 @@
+package indyclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool_Close(t *testing.T) {
+	v := ledgerValidator(testLedger)
+	pool := testPool(t, fakeTransport{"Node1": v, "Node2": v, "Node3": v, "Node4": v})
+	ctx := context.Background()
+
+	_, err := pool.GetTransaction(ctx, DomainLedger, 1)
+	require.NoError(t, err)
+	blocks, errs := pool.Watch(ctx, DomainLedger, time.Hour, WatchFrom(1))
+	for range testLedger {
+		<-blocks
+	}
+
+	require.NoError(t, pool.Close())
+	require.NoError(t, pool.Close())
+
+	_, err = pool.GetTransaction(ctx, DomainLedger, 2)
+	require.True(t, errors.Is(err, ErrClosed))
+	for _, h := range pool.Health(ctx).Validators {
+		require.True(t, errors.Is(h.Err, ErrClosed))
+	}
+	_, ok := <-blocks
+	require.False(t, ok)
+	require.Equal(t, ErrClosed, <-errs)
+}
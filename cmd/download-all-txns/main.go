@@ -0,0 +1,202 @@
+// Command download-all-txns downloads all the transactions of an Indy
+// ledger and writes them out as a JSON array, newline-delimited JSON, CSV,
+// SQL statements for sqlite3 or a SQLite database:
+//
+//	download-all-txns -genesis pool_transactions_genesis -format sql | sqlite3 ledger.db
+//	download-all-txns -genesis pool_transactions_genesis -format sqlite -out ledger.db
+//
+// With -state, the seqNo of the last transaction written is recorded in a
+// file, and -resume continues from there, appending to the output file.
+// The output of a resumed download is then a sequence of JSON arrays, one
+// per run, which jq and json.Decoder read one after the other; the other
+// formats can simply be appended to, except for SQLite databases, which are
+// written anew.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/signal"
+
+	"go.dedis.ch/indyclient"
+)
+
+var (
+	genesis    = flag.String("genesis", "", "path to the pool_transactions_genesis file of the network")
+	ledgerName = flag.String("ledger", "domain", "ledger to download: pool, domain, config, audit or a ledger number")
+	format     = flag.String("format", "json", "output format: json, ndjson, csv, sql or sqlite")
+	out        = flag.String("out", "-", "output file, - for stdout")
+	gz         = flag.Bool("gzip", false, "compress the output with gzip")
+	verify     = flag.Bool("verify", false, "check the Merkle audit path of every transaction")
+	cache      = flag.String("cache", "", "directory keeping the downloaded transactions across runs, so that they are not downloaded again")
+	start      = flag.Int("start", 1, "seqNo of the first transaction to download")
+	end        = flag.Int("end", 0, "seqNo of the last transaction to download, 0 for the end of the ledger")
+	stateFile  = flag.String("state", "", "file recording the seqNo of the last transaction written")
+	resume     = flag.Bool("resume", false, "continue after the transaction recorded in the -state file, appending to -out")
+	workers    = flag.Int("workers", 1, "number of GET_TXN requests in flight at the same time, spread over as many validators")
+	batch      = flag.Int("batch", 0, "download with catchup requests of this many transactions, verified against the ledger root; 0 uses one GET_TXN per transaction")
+)
+
+func main() {
+	flag.Parse()
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	if *genesis == "" {
+		return errors.New("-genesis is required")
+	}
+	ledger, err := indyclient.ParseLedger(*ledgerName)
+	if err != nil {
+		return err
+	}
+
+	outFormat, err := indyclient.ParseExportFormat(*format)
+	if err != nil {
+		return err
+	}
+
+	if outFormat == indyclient.FormatSQLite && (*out == "-" || *resume || *gz) {
+		return errors.New("-format sqlite needs -out, and no -resume nor -gzip")
+	}
+
+	first := *start
+	if *resume {
+		if *stateFile == "" || *out == "-" {
+			return errors.New("-resume needs -state and -out")
+		}
+		last, err := readState(*stateFile, *ledgerName)
+		if err != nil {
+			return err
+		}
+		if last >= first {
+			first = last + 1
+		}
+	}
+	if *end > 0 && first > *end {
+		log.Printf("nothing to download: transactions up to %v were written", first-1)
+		return nil
+	}
+
+	g, err := os.Open(*genesis)
+	if err != nil {
+		return err
+	}
+	popts := []indyclient.Option{indyclient.WithLogger(indyclient.NewStdLogger(log.New(os.Stderr, "", log.LstdFlags), indyclient.LevelInfo))}
+	if *workers > 1 {
+		if *batch > 0 {
+			return errors.New("-workers and -batch are exclusive")
+		}
+		popts = append(popts, indyclient.WithConnections(*workers))
+	}
+	if *cache != "" {
+		popts = append(popts, indyclient.WithCache(&indyclient.DirCache{Dir: *cache}))
+	}
+	pool, err := indyclient.NewPool(g, popts...)
+	g.Close()
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	var f *os.File
+	if *out != "-" {
+		flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		if *resume {
+			flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+		}
+		f, err = os.OpenFile(*out, flags, 0644)
+		if err != nil {
+			return err
+		}
+		w = f
+	}
+
+	// Stop cleanly on interrupt, so that the output is a complete (if
+	// partial) document.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	go func() {
+		<-sig
+		cancel()
+	}()
+
+	last := 0
+	opts := []indyclient.ExportOption{
+		indyclient.WithRange(first, *end),
+		indyclient.WithProgress(func(seqNo int) { last = seqNo }),
+		indyclient.WithFormat(outFormat),
+	}
+	if *gz {
+		opts = append(opts, indyclient.WithGzip())
+	}
+	if *verify {
+		opts = append(opts, indyclient.WithVerification())
+	}
+	if *workers > 1 {
+		opts = append(opts, indyclient.WithWorkers(*workers))
+	}
+	if *batch > 0 {
+		opts = append(opts, indyclient.WithCatchup(*batch))
+	}
+	err = pool.Export(ctx, w, ledger, opts...)
+	// A failed close may lose the end of the output, so it fails the run.
+	if f != nil {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if *stateFile != "" && last > 0 {
+		if serr := writeState(*stateFile, *ledgerName, last); err == nil {
+			err = serr
+		}
+	}
+	return err
+}
+
+// state is the content of the -state file.
+type state struct {
+	Ledger string `json:"ledger"`
+	Last   int    `json:"last"`
+}
+
+// readState returns the seqNo of the last transaction of ledger recorded
+// in the file path, or 0 if there is no such file.
+func readState(path, ledger string) (int, error) {
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var st state
+	if err := json.Unmarshal(b, &st); err != nil {
+		return 0, fmt.Errorf("%v: %v", path, err)
+	}
+	if st.Ledger != ledger {
+		return 0, fmt.Errorf("%v records the %v ledger, not %v", path, st.Ledger, ledger)
+	}
+	return st.Last, nil
+}
+
+// writeState records last as the seqNo of the last transaction of ledger
+// written.
+func writeState(path, ledger string, last int) error {
+	b, err := json.Marshal(state{Ledger: ledger, Last: last})
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(b, '\n'), 0644)
+}
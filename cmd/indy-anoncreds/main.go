@@ -0,0 +1,96 @@
+// Command indy-anoncreds fetches a schema or a credential definition by id
+// and prints it as JSON. The schema of a credential definition, which its
+// id only refers to by seqNo, is fetched and printed along with it:
+//
+//	indy-anoncreds -network sovrin-stagingnet V4SGRU86Z58d6TV7PBUe6f:2:degree:1.0
+//	indy-anoncreds -network sovrin-stagingnet V4SGRU86Z58d6TV7PBUe6f:3:CL:10:default
+//
+// Ids may also be given as did:indy object URIs, such as
+// did:indy:sovrin:staging:V4SGRU86Z58d6TV7PBUe6f/anoncreds/v0/SCHEMA/degree/1.0.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"go.dedis.ch/indyclient"
+)
+
+var (
+	network = flag.String("network", "sovrin-mainnet", "known network holding the objects")
+	genesis = flag.String("genesis", "", "path to the pool_transactions_genesis file of the network, instead of -network")
+	keys    = flag.Bool("keys", true, "print the public keys of credential definitions")
+	timeout = flag.Duration("timeout", time.Minute, "time allowed for the lookup")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %v [flags] schema-or-cred-def-id\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(flag.Arg(0)); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	var pool *indyclient.Pool
+	var err error
+	if *genesis != "" {
+		g, err := os.Open(*genesis)
+		if err != nil {
+			return err
+		}
+		defer g.Close()
+		pool, err = indyclient.NewPool(g)
+		if err != nil {
+			return err
+		}
+	} else if pool, err = indyclient.KnownNetwork(ctx, *network); err != nil {
+		return err
+	}
+	var out interface{}
+	if _, cerr := indyclient.ParseCredDefId(id); cerr == nil {
+		out, err = credDef(ctx, pool, id)
+	} else {
+		out, err = pool.GetSchemaById(ctx, id)
+	}
+	if err != nil {
+		return err
+	}
+	e := json.NewEncoder(os.Stdout)
+	e.SetIndent("", "  ")
+	return e.Encode(out)
+}
+
+// credDef returns the credential definition id with its schema.
+func credDef(ctx context.Context, pool *indyclient.Pool, id string) (interface{}, error) {
+	cd, err := pool.GetCredDef(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("credential definition %v: %w", id, err)
+	}
+	if !*keys {
+		cd.Value = nil
+	}
+	schema, err := pool.GetSchemaBySeqNo(ctx, cd.SchemaSeqNo)
+	if err != nil {
+		return nil, fmt.Errorf("schema %v: %w", cd.SchemaSeqNo, err)
+	}
+	return struct {
+		CredDef *indyclient.CredentialDefinition
+		Schema  *indyclient.Schema
+	}{cd, schema}, nil
+}
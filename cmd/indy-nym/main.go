@@ -0,0 +1,182 @@
+// Command indy-nym writes a NYM transaction: it registers a DID, changes
+// its role or alias, or rotates its verkey. The transaction is signed with
+// the key of -seed, or of the seed in $INDY_SEED:
+//
+//	indy-nym -network sovrin-stagingnet -seed $STEWARD_SEED -verkey 5vqV... -role ENDORSER
+//	indy-nym -network sovrin-stagingnet -seed $OLD_SEED -target-did WRfX... -verkey 7ab4...
+//	indy-nym -network sovrin-stagingnet -seed $TRUSTEE_SEED -target-did WRfX... -remove-role
+//
+// Without -target-did, the DID registered is the one derived from -verkey.
+// On pools with a transaction author agreement, the agreement is shown and
+// must be accepted, unless -accept-taa names the acceptance mechanism.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"go.dedis.ch/indyclient"
+)
+
+var (
+	network    = flag.String("network", "sovrin-buildernet", "known network to write to")
+	genesis    = flag.String("genesis", "", "path to the pool_transactions_genesis file of the network, instead of -network")
+	seed       = flag.String("seed", "", "seed of the key signing the transaction, instead of $INDY_SEED")
+	signerDid  = flag.String("did", "", "DID signing the transaction, if not derived from the verkey of -seed")
+	target     = flag.String("target-did", "", "DID to register or update")
+	verkey     = flag.String("verkey", "", "verkey of the target DID")
+	role       = flag.String("role", "", "role of the target DID: TRUSTEE, STEWARD, ENDORSER, NETWORK_MONITOR or a role code")
+	alias      = flag.String("alias", "", "alias of the target DID")
+	removeRole = flag.Bool("remove-role", false, "remove the role of the target DID")
+	acceptTAA  = flag.String("accept-taa", "", "acceptance mechanism of the transaction author agreement, instead of asking")
+	timeout    = flag.Duration("timeout", 2*time.Minute, "time allowed for the write")
+)
+
+func main() {
+	flag.Parse()
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	signer, err := newSigner()
+	if err != nil {
+		return err
+	}
+	dest := *target
+	if dest == "" {
+		if *verkey == "" {
+			return errors.New("-target-did or -verkey is required")
+		}
+		if dest, err = indyclient.DidFromVerkey(*verkey); err != nil {
+			return err
+		}
+	}
+	var roleCode string
+	if *role != "" {
+		if *removeRole {
+			return errors.New("-role and -remove-role are exclusive")
+		}
+		if roleCode, err = indyclient.ParseRole(*role); err != nil {
+			return err
+		}
+	}
+
+	var pool *indyclient.Pool
+	if *genesis != "" {
+		g, err := os.Open(*genesis)
+		if err != nil {
+			return err
+		}
+		defer g.Close()
+		pool, err = indyclient.NewPool(g)
+		if err != nil {
+			return err
+		}
+	} else if pool, err = indyclient.KnownNetwork(ctx, *network); err != nil {
+		return err
+	}
+	if err := accept(ctx, pool); err != nil {
+		return err
+	}
+
+	var b *indyclient.Block
+	if *removeRole {
+		if *verkey != "" || *alias != "" {
+			return errors.New("-remove-role only changes the role")
+		}
+		b, err = pool.RemoveRole(ctx, signer, dest)
+	} else {
+		b, err = pool.WriteNym(ctx, signer, dest, *verkey, roleCode, *alias)
+	}
+	if err != nil {
+		return err
+	}
+	e := json.NewEncoder(os.Stdout)
+	e.SetIndent("", "  ")
+	return e.Encode(b)
+}
+
+// newSigner returns the Signer of -seed and -did.
+func newSigner() (indyclient.Signer, error) {
+	var s []byte
+	var err error
+	if *seed != "" {
+		s, err = indyclient.ParseSeed(*seed)
+	} else {
+		s, err = indyclient.SeedFromEnv("INDY_SEED")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("no seed: %v", err)
+	}
+	if *signerDid == "" {
+		return indyclient.SignerFromSeed(s)
+	}
+	key, _, _, err := indyclient.KeypairFromSeed(s)
+	if err != nil {
+		return nil, err
+	}
+	return indyclient.NewSigner(*signerDid, key)
+}
+
+// accept makes the writes of pool accept its transaction author agreement,
+// if it has one.
+func accept(ctx context.Context, pool *indyclient.Pool) error {
+	taa, err := pool.GetTransactionAuthorAgreement(ctx)
+	if errors.Is(err, indyclient.ErrNoData) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("transaction author agreement: %v", err)
+	}
+	mechanism := *acceptTAA
+	if mechanism == "" {
+		aml, err := pool.GetAcceptanceMechanisms(ctx)
+		if err != nil {
+			return fmt.Errorf("acceptance mechanisms: %v", err)
+		}
+		if mechanism, err = ask(taa, aml); err != nil {
+			return err
+		}
+	}
+	pool.AcceptTAA(taa.Accept(mechanism, time.Now()))
+	return nil
+}
+
+// ask shows taa and returns the acceptance mechanism of aml chosen on the
+// standard input.
+func ask(taa *indyclient.TAA, aml *indyclient.AML) (string, error) {
+	fmt.Fprintf(os.Stderr, "Transaction author agreement, version %v:\n\n%v\n\n", taa.Version, taa.Text)
+	var labels []string
+	for label := range aml.AML {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	fmt.Fprintln(os.Stderr, "Acceptance mechanisms:")
+	for i, label := range labels {
+		fmt.Fprintf(os.Stderr, "  %v. %v: %v\n", i+1, label, aml.AML[label])
+	}
+	fmt.Fprint(os.Stderr, "Accept the agreement with mechanism number (empty to abort): ")
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", errors.New("transaction author agreement not accepted")
+	}
+	var n int
+	if _, err := fmt.Sscan(line, &n); err != nil || n < 1 || n > len(labels) {
+		return "", errors.New("transaction author agreement not accepted")
+	}
+	return labels[n-1], nil
+}
@@ -0,0 +1,144 @@
+// Command indy-resolve resolves a did:sov or did:indy DID, or dereferences a
+// DID URL, and prints the result as JSON:
+//
+//	indy-resolve did:sov:WRfXPg8dantKVubE3HX8pw
+//	indy-resolve -network sovrin-stagingnet 'did:sov:WRfXPg8dantKVubE3HX8pw#key-1'
+//	indy-resolve did:indy:sovrin:staging:WRfXPg8dantKVubE3HX8pw
+//
+// did:sov DIDs are resolved on -network, or on the pool of -genesis.
+// did:indy DIDs are resolved on the known network of their namespace,
+// unless -genesis is given.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"go.dedis.ch/indyclient"
+)
+
+// namespaces maps the did:indy namespaces of known networks to their names.
+var namespaces = map[string]string{
+	"sovrin":         "sovrin-mainnet",
+	"sovrin:staging": "sovrin-stagingnet",
+	"sovrin:builder": "sovrin-buildernet",
+	"indicio":        "indicio-mainnet",
+	"indicio:test":   "indicio-testnet",
+	"indicio:demo":   "indicio-demonet",
+	"bcovrin:test":   "bcovrin-test",
+	"bcovrin:dev":    "bcovrin-dev",
+	"idunion:test":   "idunion-testnet",
+}
+
+var (
+	network  = flag.String("network", "sovrin-mainnet", "known network resolving did:sov DIDs")
+	genesis  = flag.String("genesis", "", "path to the pool_transactions_genesis file of the network, instead of -network")
+	raw      = flag.Bool("raw", false, "print the NYM and endpoint ATTRIB of the DID instead of its DID Document")
+	metadata = flag.Bool("metadata", false, "print the DID Document with its metadata")
+	timeout  = flag.Duration("timeout", time.Minute, "time allowed for the resolution")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %v [flags] did\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(flag.Arg(0)); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(did string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	if !strings.HasPrefix(did, "did:") {
+		did = "did:sov:" + did
+	}
+	u, err := indyclient.ParseDidURL(did)
+	if err != nil {
+		return err
+	}
+	pool, err := poolFor(ctx, &u.Did)
+	if err != nil {
+		return err
+	}
+	var out interface{}
+	switch {
+	case *raw:
+		out, err = rawData(ctx, pool, u.Did.Id)
+	default:
+		r := indyclient.NewResolver(pool)
+		if u.Did.Method == "indy" {
+			r.AddNamespace(u.Did.Namespace, pool)
+		}
+		var res interface{}
+		var meta *indyclient.DocumentMetadata
+		res, meta, err = r.Dereference(ctx, did)
+		out = res
+		if *metadata {
+			out = struct {
+				Document interface{}                  `json:"didDocument"`
+				Metadata *indyclient.DocumentMetadata `json:"didDocumentMetadata"`
+			}{res, meta}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	e := json.NewEncoder(os.Stdout)
+	e.SetIndent("", "  ")
+	return e.Encode(out)
+}
+
+// poolFor returns the Pool holding d.
+func poolFor(ctx context.Context, d *indyclient.Did) (*indyclient.Pool, error) {
+	if *genesis != "" {
+		g, err := os.Open(*genesis)
+		if err != nil {
+			return nil, err
+		}
+		defer g.Close()
+		return indyclient.NewPool(g)
+	}
+	name := *network
+	if d.Method == "indy" {
+		var ok bool
+		name, ok = namespaces[d.Namespace]
+		if !ok {
+			return nil, fmt.Errorf("unknown did:indy namespace %v: use -genesis", d.Namespace)
+		}
+	}
+	return indyclient.KnownNetwork(ctx, name)
+}
+
+// rawData returns the NYM and the endpoint ATTRIB of the DID id.
+func rawData(ctx context.Context, pool *indyclient.Pool, id string) (interface{}, error) {
+	nym, err := pool.GetNym(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("GET_NYM: %w", err)
+	}
+	endpoint, err := pool.GetAttrib(ctx, id, "endpoint")
+	if errors.Is(err, indyclient.ErrNoData) {
+		endpoint, err = nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("GET_ATTRIB: %w", err)
+	}
+	return struct {
+		Nym      *indyclient.Nym    `json:"nym"`
+		Endpoint *indyclient.Attrib `json:"endpoint,omitempty"`
+	}{nym, endpoint}, nil
+}
@@ -0,0 +1,96 @@
+// Command ledger-export downloads a whole ledger with catchup requests,
+// verifying every batch against the ledger root, and writes it as an
+// archive holding the transactions, their Merkle root hashes and the
+// multi-signature of the pool over a root hash. With -check, it verifies
+// such an archive offline instead:
+//
+//	ledger-export -genesis pool_transactions_genesis -out domain.archive
+//	ledger-export -genesis pool_transactions_genesis -check domain.archive
+//
+// Both check the multi-signature against the BLS keys of the validators in
+// the genesis transactions, besides the root hashes.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"go.dedis.ch/indyclient"
+)
+
+var (
+	genesis    = flag.String("genesis", "", "path to the pool_transactions_genesis file of the network")
+	ledgerName = flag.String("ledger", "domain", "ledger to export: pool, domain, config, audit or a ledger number")
+	out        = flag.String("out", "-", "archive file, - for stdout")
+	batch      = flag.Int("batch", 1000, "number of transactions per catchup request")
+	check      = flag.String("check", "", "archive file to verify, instead of exporting")
+)
+
+func main() {
+	flag.Parse()
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	if *genesis == "" {
+		return errors.New("-genesis is required")
+	}
+	g, err := os.Open(*genesis)
+	if err != nil {
+		return err
+	}
+	defer g.Close()
+	pool, err := indyclient.NewPool(g)
+	if err != nil {
+		return err
+	}
+
+	var info *indyclient.ArchiveInfo
+	if *check != "" {
+		f, err := os.Open(*check)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if info, err = pool.VerifyArchive(f); err != nil {
+			return err
+		}
+	} else {
+		ledger, err := indyclient.ParseLedger(*ledgerName)
+		if err != nil {
+			return err
+		}
+		w := os.Stdout
+		if *out != "-" {
+			if w, err = os.Create(*out); err != nil {
+				return err
+			}
+		}
+		info, err = pool.ExportArchive(context.Background(), w, ledger, *batch)
+		if w != os.Stdout {
+			if cerr := w.Close(); err == nil {
+				err = cerr
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Printf("ledger %v: %v transactions, root hash %v", info.Ledger, info.Size, info.RootHash)
+	switch {
+	case info.SignedSize == 0:
+		log.Printf("no multi-signature")
+	case info.SignatureVerified:
+		log.Printf("first %v transactions multi-signed by the pool at %v", info.SignedSize, info.SignedTime.UTC().Format(time.RFC3339))
+	default:
+		log.Printf("first %v transactions under the root hash multi-signed at %v; signature not checked", info.SignedSize, info.SignedTime.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+// Command pool-status probes every validator of a pool and reports its
+// latency, whether its domain ledger agrees with that of the pool, and how
+// fresh its state is:
+//
+//	pool-status -network sovrin-stagingnet
+//	pool-status -genesis pool_transactions_genesis -json
+//
+// It exits with status 1 if fewer than f+1 validators agree on the ledger,
+// where f is the number of faulty validators the pool tolerates.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"go.dedis.ch/indyclient"
+)
+
+var (
+	network  = flag.String("network", "sovrin-mainnet", "known network to probe")
+	genesis  = flag.String("genesis", "", "path to the pool_transactions_genesis file of the pool to probe, instead of -network")
+	asJSON   = flag.Bool("json", false, "print the report as JSON instead of a table")
+	maxAge   = flag.Duration("max-age", 10*time.Minute, "age of the signed state past which a validator is reported stale")
+	timeout  = flag.Duration("timeout", 30*time.Second, "time allowed for the validators to answer")
+	parallel = flag.Int("parallel", 32, "number of validators probed at the same time")
+)
+
+// validatorReport is the JSON report of a validator.
+type validatorReport struct {
+	Alias      string  `json:"alias"`
+	Status     string  `json:"status"`
+	LatencyMs  float64 `json:"latencyMs,omitempty"`
+	LedgerSize int     `json:"ledgerSize,omitempty"`
+	RootHash   string  `json:"rootHash,omitempty"`
+	StateTime  string  `json:"stateTime,omitempty"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// report is the JSON report of the pool.
+type report struct {
+	LedgerSize int               `json:"ledgerSize"`
+	RootHash   string            `json:"rootHash"`
+	Validators []validatorReport `json:"validators"`
+}
+
+func main() {
+	flag.Parse()
+	ok, err := run()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !ok {
+		os.Exit(1)
+	}
+}
+
+func run() (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	var pool *indyclient.Pool
+	var err error
+	opt := indyclient.WithMaxParallel(*parallel)
+	if *genesis != "" {
+		g, err := os.Open(*genesis)
+		if err != nil {
+			return false, err
+		}
+		defer g.Close()
+		pool, err = indyclient.NewPool(g, opt)
+		if err != nil {
+			return false, err
+		}
+	} else if pool, err = indyclient.KnownNetwork(ctx, *network, opt); err != nil {
+		return false, err
+	}
+
+	h := pool.Health(ctx)
+	rep := report{LedgerSize: h.LedgerSize, RootHash: h.RootHash}
+	now := time.Now()
+	for _, v := range h.Validators {
+		vr := validatorReport{Alias: v.Alias, Status: status(&v, now)}
+		if v.Err != nil {
+			vr.Error = v.Err.Error()
+		} else {
+			vr.LatencyMs = float64(v.Latency) / float64(time.Millisecond)
+			vr.LedgerSize, vr.RootHash = v.LedgerSize, v.RootHash
+			if !v.StateTime.IsZero() {
+				vr.StateTime = v.StateTime.UTC().Format(time.RFC3339)
+			}
+		}
+		rep.Validators = append(rep.Validators, vr)
+	}
+
+	if *asJSON {
+		e := json.NewEncoder(os.Stdout)
+		e.SetIndent("", "  ")
+		err = e.Encode(rep)
+	} else {
+		err = printTable(&rep, h, now)
+	}
+	return h.RootHash != "", err
+}
+
+// status sums up the health of v: ok, stale, out of sync or down.
+func status(v *indyclient.ValidatorHealth, now time.Time) string {
+	switch {
+	case v.Err != nil:
+		return "down"
+	case !v.InSync:
+		return "out of sync"
+	case !v.StateTime.IsZero() && now.Sub(v.StateTime) > *maxAge:
+		return "stale"
+	}
+	return "ok"
+}
+
+func printTable(rep *report, h *indyclient.PoolHealth, now time.Time) error {
+	if rep.RootHash != "" {
+		fmt.Printf("domain ledger: %v transactions, root %v\n\n", rep.LedgerSize, rep.RootHash)
+	} else {
+		fmt.Printf("domain ledger: validators do not agree\n\n")
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VALIDATOR\tSTATUS\tLATENCY\tSIZE\tSTATE AGE\tERROR")
+	for i, vr := range rep.Validators {
+		v := h.Validators[i]
+		var latency, size, age string
+		if v.Err == nil {
+			latency = v.Latency.Round(time.Millisecond).String()
+			size = fmt.Sprint(v.LedgerSize)
+			if !v.StateTime.IsZero() {
+				age = now.Sub(v.StateTime).Round(time.Second).String()
+			}
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\n", vr.Alias, vr.Status, latency, size, age, vr.Error)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,84 @@
+// Command resolve-did resolves a DID on one of the Sovrin networks and
+// prints its DID Document as JSON:
+//
+//	resolve-did -network StagingNet -did did:sov:WRfXPg8dantKVubE3HX8pw
+//
+// It exits with status 1 if the DID is not on the ledger or cannot be
+// resolved. indy-resolve also resolves did:indy DIDs and DID URLs, on any
+// known network.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"go.dedis.ch/indyclient"
+)
+
+var (
+	did     = flag.String("did", "", "DID to resolve, such as did:sov:WRfXPg8dantKVubE3HX8pw or its bare identifier")
+	network = flag.String("network", "MainNet", "Sovrin network holding the DID: MainNet, StagingNet or BuilderNet")
+	timeout = flag.Duration("timeout", time.Minute, "time allowed for the resolution")
+)
+
+// networks are the values of -network.
+var networks = []string{"MainNet", "StagingNet", "BuilderNet"}
+
+func main() {
+	flag.Parse()
+	name := networkName(*network)
+	if *did == "" || name == "" || flag.NArg() > 0 {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %v -did did [-network %v]\n", os.Args[0], strings.Join(networks, "|"))
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	pool, err := indyclient.NewPool(indyclient.SovrinPool(name))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "resolve-did: %v: %v\n", name, err)
+		os.Exit(1)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	os.Exit(run(ctx, pool, name, *did, os.Stdout, os.Stderr))
+}
+
+// networkName returns the name of the Sovrin network n, matched without
+// regard to case, or "" if there is no such network.
+func networkName(n string) string {
+	for _, name := range networks {
+		if strings.EqualFold(n, name) {
+			return name
+		}
+	}
+	return ""
+}
+
+// run resolves did on pool, the network name, and prints its DID Document
+// to stdout. It returns the exit status of the command, with a message on
+// stderr if it is not 0.
+func run(ctx context.Context, pool *indyclient.Pool, name, did string, stdout, stderr io.Writer) int {
+	doc, _, err := indyclient.NewResolver(pool).Resolve(ctx, did)
+	if errors.Is(err, indyclient.ErrDIDNotFound) {
+		fmt.Fprintf(stderr, "resolve-did: %v is not on %v\n", did, name)
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "resolve-did: %v: %v\n", did, err)
+		return 1
+	}
+	e := json.NewEncoder(stdout)
+	e.SetIndent("", "  ")
+	if err := e.Encode(doc); err != nil {
+		fmt.Fprintf(stderr, "resolve-did: %v\n", err)
+		return 1
+	}
+	return 0
+}
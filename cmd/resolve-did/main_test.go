@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/indyclient"
+	"go.dedis.ch/indyclient/indyclienttest"
+)
+
+func TestRun(t *testing.T) {
+	n := indyclienttest.NewNetwork(4)
+	require.NoError(t, n.LoadTxns(indyclient.DomainLedger, strings.NewReader(
+		`{"txn":{"type":"1","data":{"dest":"V4SGRU86Z58d6TV7PBUe6f","verkey":"~CoRER63DVYnWZtK8uAzNbx"},"metadata":{}},"txnMetadata":{"seqNo":1}}`)))
+	pool, err := n.Pool()
+	require.NoError(t, err)
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, 0, run(context.Background(), pool, "StagingNet", "did:sov:V4SGRU86Z58d6TV7PBUe6f", &stdout, &stderr))
+	var doc indyclient.DIDDocument
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &doc))
+	require.Equal(t, "did:sov:V4SGRU86Z58d6TV7PBUe6f", doc.Id)
+
+	stdout.Reset()
+	require.Equal(t, 1, run(context.Background(), pool, "StagingNet", "did:sov:Th7MpTaRZVRYnPiabds81Y", &stdout, &stderr))
+	require.Empty(t, stdout.String())
+	require.Equal(t, "resolve-did: did:sov:Th7MpTaRZVRYnPiabds81Y is not on StagingNet\n", stderr.String())
+}
+
+func TestNetworkName(t *testing.T) {
+	require.Equal(t, "BuilderNet", networkName("buildernet"))
+	require.Equal(t, "MainNet", networkName("MainNet"))
+	require.Equal(t, "", networkName("sovrin-mainnet"))
+}
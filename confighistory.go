@@ -0,0 +1,134 @@
+package indyclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ConfigChange is a transaction of the config ledger. Change is its data
+// as decoded by Block.Decode: *TAATxn, *AML, *TAADisableTxn, *AuthRule,
+// *AuthRulesTxn, *PoolUpgrade, *NodeUpgrade, *PoolConfig or
+// *LedgersFreezeTxn, or nil for types this package has no model for.
+type ConfigChange struct {
+	SeqNo  int
+	Time   time.Time
+	Author string // DID which wrote the transaction
+	Change interface{}
+	Block  *Block
+}
+
+// ConfigHistory is the history of the config ledger, in ledger order. It
+// tells which agreements and rules governed the pool at any time.
+type ConfigHistory []ConfigChange
+
+// GetConfigHistory reads the whole config ledger and decodes its
+// transactions.
+func (p *Pool) GetConfigHistory(ctx context.Context) (ConfigHistory, error) {
+	blocks, errs := p.IterateTransactions(ctx, ConfigLedger, 1)
+	var h ConfigHistory
+	for b := range blocks {
+		c, err := newConfigChange(b)
+		if err != nil {
+			return nil, err
+		}
+		h = append(h, c)
+	}
+	if err := <-errs; err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func newConfigChange(b *Block) (ConfigChange, error) {
+	c := ConfigChange{SeqNo: b.TxnMetadata.SeqNo, Block: b}
+	if b.TxnMetadata.TxnTime != 0 {
+		c.Time = time.Unix(b.TxnMetadata.TxnTime, 0)
+	}
+	c.Author, _ = b.Txn.Metadata["from"].(string)
+	v, err := b.Decode()
+	if err != nil && !errors.Is(err, ErrUnknownTxnType) {
+		return c, fmt.Errorf("config transaction %v: %v", c.SeqNo, err)
+	}
+	c.Change = v
+	return c, nil
+}
+
+// until returns the changes of h written at or before t.
+func (h ConfigHistory) until(t time.Time) ConfigHistory {
+	for i, c := range h {
+		if c.Time.After(t) {
+			return h[:i]
+		}
+	}
+	return h
+}
+
+// TAAAt returns the latest transaction author agreement set at time t, or
+// nil if writes needed no agreement then. Its RetirementTs is that of the
+// agreement at t.
+func (h ConfigHistory) TAAAt(t time.Time) *TAATxn {
+	var latest *TAATxn
+	taas := make(map[string]*TAATxn)
+	for _, c := range h.until(t) {
+		switch v := c.Change.(type) {
+		case *TAATxn:
+			if taa, ok := taas[v.Version]; ok {
+				// Updates of an agreement only change its retirement.
+				taa.RetirementTs = v.RetirementTs
+				continue
+			}
+			taa := *v
+			taas[v.Version] = &taa
+			latest = &taa
+		case *TAADisableTxn:
+			latest = nil
+		}
+	}
+	if latest == nil {
+		return nil
+	}
+	taa := *latest
+	return &taa
+}
+
+// AMLAt returns the acceptance mechanisms list in force at time t, or nil
+// if none was set then.
+func (h ConfigHistory) AMLAt(t time.Time) *AML {
+	var latest *AML
+	for _, c := range h.until(t) {
+		if aml, ok := c.Change.(*AML); ok {
+			latest = aml
+		}
+	}
+	return latest
+}
+
+// AuthRuleAt returns the rule with the key of rule, whose constraint is
+// ignored, as last changed by AUTH_RULE or AUTH_RULES transactions at or
+// before time t, or nil if the rule was not changed by then, in which case
+// the default rule of indy-node applied.
+func (h ConfigHistory) AuthRuleAt(rule AuthRule, t time.Time) *AuthRule {
+	var latest *AuthRule
+	match := func(r *AuthRule) {
+		if r.AuthType == rule.AuthType && r.AuthAction == rule.AuthAction && r.Field == rule.Field &&
+			r.OldValue == rule.OldValue && r.NewValue == rule.NewValue {
+			latest = r
+		}
+	}
+	for _, c := range h.until(t) {
+		switch v := c.Change.(type) {
+		case *AuthRule:
+			match(v)
+		case *AuthRulesTxn:
+			for i := range v.Rules {
+				match(&v.Rules[i])
+			}
+		}
+	}
+	return latest
+}
@@ -0,0 +1,42 @@
+package indyclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool_GetConfigHistory(t *testing.T) {
+	v := ledgerValidator([]string{
+		`{"txn":{"type":"5","data":{"aml":{"for_session":"click"},"version":"1"},"metadata":{"from":"V4SGRU86Z58d6TV7PBUe6f"}},"txnMetadata":{"seqNo":1,"txnTime":1000}}`,
+		`{"txn":{"type":"4","data":{"text":"v1 text","version":"1","ratification_ts":900},"metadata":{"from":"V4SGRU86Z58d6TV7PBUe6f"}},"txnMetadata":{"seqNo":2,"txnTime":2000}}`,
+		`{"txn":{"type":"120","data":{"auth_type":"1","auth_action":"ADD","field":"role","old_value":"*","new_value":"101","constraint":{"constraint_id":"ROLE","role":"0","sig_count":1}},"metadata":{"from":"V4SGRU86Z58d6TV7PBUe6f"}},"txnMetadata":{"seqNo":3,"txnTime":3000}}`,
+		`{"txn":{"type":"4","data":{"text":"v2 text","version":"2","ratification_ts":3500},"metadata":{"from":"V4SGRU86Z58d6TV7PBUe6f"}},"txnMetadata":{"seqNo":4,"txnTime":4000}}`,
+		`{"txn":{"type":"4","data":{"version":"1","retirement_ts":4500},"metadata":{"from":"V4SGRU86Z58d6TV7PBUe6f"}},"txnMetadata":{"seqNo":5,"txnTime":5000}}`,
+		`{"txn":{"type":"8","data":{},"metadata":{"from":"V4SGRU86Z58d6TV7PBUe6f"}},"txnMetadata":{"seqNo":6,"txnTime":6000}}`,
+		`{"txn":{"type":"999","data":{},"metadata":{}},"txnMetadata":{"seqNo":7,"txnTime":7000}}`,
+	})
+	pool := testPool(t, fakeTransport{"Node1": v, "Node2": v, "Node3": v, "Node4": v})
+
+	h, err := pool.GetConfigHistory(context.Background())
+	require.NoError(t, err)
+	require.Len(t, h, 7)
+	require.Equal(t, 2, h[1].SeqNo)
+	require.Equal(t, time.Unix(2000, 0), h[1].Time)
+	require.Equal(t, "V4SGRU86Z58d6TV7PBUe6f", h[1].Author)
+	require.Nil(t, h[6].Change)
+
+	require.Nil(t, h.TAAAt(time.Unix(1500, 0)))
+	require.Equal(t, &TAATxn{Text: "v1 text", Version: "1", RatificationTs: 900}, h.TAAAt(time.Unix(3000, 0)))
+	require.Equal(t, "2", h.TAAAt(time.Unix(5000, 0)).Version)
+	require.Nil(t, h.TAAAt(time.Unix(6000, 0)))
+
+	require.Nil(t, h.AMLAt(time.Unix(999, 0)))
+	require.Equal(t, "1", h.AMLAt(time.Unix(1000, 0)).Version)
+
+	key := AuthRule{AuthType: "1", AuthAction: "ADD", Field: "role", OldValue: "*", NewValue: "101"}
+	require.Nil(t, h.AuthRuleAt(key, time.Unix(2999, 0)))
+	require.Equal(t, "0", h.AuthRuleAt(key, time.Unix(3000, 0)).Constraint.Role)
+}
@@ -0,0 +1,222 @@
+package indyclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// conn is a connection to a validator shared by concurrent requests. The
+// messages received are routed to the waiting requests by reqId, so that
+// replies may arrive in any order.
+type conn struct {
+	alias   string
+	address string
+	hook    func(Frame) // see WithFrameHook
+
+	tMu sync.Mutex // serializes use of t
+	t   Connection
+
+	mu      sync.Mutex // guards the fields below
+	pending map[seqNo]chan string
+	others  map[chan string]bool // waiters for messages without a reqId
+	err     error                // why the conn is unusable, if it is
+}
+
+// errConnClosed is returned to the requests waiting on a conn which was
+// closed, so that they resend their request on another one.
+var errConnClosed = errors.New("connection closed")
+
+// muxPoll bounds how long a request receives messages on behalf of all the
+// requests waiting on a conn before handing the connection to the next one.
+const muxPoll = 10 * time.Millisecond
+
+func newConn(t Connection, alias string) *conn {
+	return &conn{
+		alias:   alias,
+		t:       t,
+		pending: make(map[seqNo]chan string),
+		others:  make(map[chan string]bool),
+	}
+}
+
+// close closes the connection. Requests waiting on c fail with
+// errConnClosed.
+func (c *conn) close() {
+	c.tMu.Lock()
+	defer c.tMu.Unlock()
+	c.fail(errConnClosed)
+	if c.t != nil {
+		c.t.Close()
+		c.t = nil
+	}
+}
+
+// fail makes c unusable because of err, unless it already is.
+func (c *conn) fail(err error) {
+	c.mu.Lock()
+	if c.err == nil {
+		c.err = err
+	}
+	c.mu.Unlock()
+}
+
+func (c *conn) failure() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// load returns the number of requests waiting on c.
+func (c *conn) load() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.pending)
+}
+
+func (c *conn) send(m []byte) error {
+	c.tMu.Lock()
+	defer c.tMu.Unlock()
+	if err := c.failure(); err != nil {
+		return err
+	}
+	if err := c.t.Send(m); err != nil {
+		return err
+	}
+	c.capture(true, m)
+	return nil
+}
+
+// exchange sends the request m and waits for the validator to acknowledge
+// and answer it.
+func (c *conn) exchange(ctx context.Context, reqId seqNo, m []byte) (*Reply, error) {
+	// Room for the REQACK and the REPLY.
+	ch := make(chan string, 2)
+	c.mu.Lock()
+	c.pending[reqId] = ch
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, reqId)
+		c.mu.Unlock()
+	}()
+
+	if err := c.send(m); err != nil {
+		return nil, err
+	}
+	for {
+		frame, err := c.wait(ctx, ch)
+		if err != nil {
+			return nil, err
+		}
+		r, err := parseReply([]string{frame})
+		if err != nil {
+			return nil, err
+		}
+		if r.Op != "REQACK" {
+			return r, nil
+		}
+	}
+}
+
+// subscribe returns a channel receiving the messages of c which are not
+// replies to requests, such as the messages of the protocol between
+// validators. Messages are dropped if the channel is full.
+func (c *conn) subscribe() chan string {
+	ch := make(chan string, 16)
+	c.mu.Lock()
+	c.others[ch] = true
+	c.mu.Unlock()
+	return ch
+}
+
+func (c *conn) unsubscribe(ch chan string) {
+	c.mu.Lock()
+	delete(c.others, ch)
+	c.mu.Unlock()
+}
+
+// wait returns the next message routed to ch, receiving messages on behalf
+// of all the requests waiting on c in the meantime.
+func (c *conn) wait(ctx context.Context, ch chan string) (string, error) {
+	for {
+		select {
+		case frame := <-ch:
+			return frame, nil
+		default:
+		}
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		if err := c.receive(ch); err != nil {
+			return "", err
+		}
+	}
+}
+
+// receive waits up to muxPoll for a message and routes it.
+func (c *conn) receive(ch chan string) error {
+	c.tMu.Lock()
+	defer c.tMu.Unlock()
+	if len(ch) > 0 {
+		// Routed by another request while we waited for the connection.
+		return nil
+	}
+	if err := c.failure(); err != nil {
+		return err
+	}
+
+	m, err := c.t.Receive(muxPoll)
+	if err != nil {
+		c.fail(err)
+		return err
+	}
+	if m == nil {
+		return nil
+	}
+	c.capture(false, m)
+	return c.route(string(m))
+}
+
+// route hands frame to the request it answers, or to the subscribers if it
+// is not a reply. Replies to requests nobody waits for anymore, for example
+// because they timed out, are dropped.
+func (c *conn) route(frame string) error {
+	var msg struct {
+		Op     string `json:"op"`
+		ReqId  seqNo  `json:"reqId"`
+		Result struct {
+			ReqId seqNo `json:"reqId"`
+		} `json:"result"`
+	}
+	if err := decodeFrame(frame, &msg); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch msg.Op {
+	case "":
+		return fmt.Errorf("%w: missing op in %v", ErrMalformedReply, snippet(frame))
+	case "REQACK", "REQNACK", "REJECT":
+		deliver(c.pending[msg.ReqId], frame)
+	case "REPLY":
+		// The reqId of a REPLY is part of its result.
+		deliver(c.pending[msg.Result.ReqId], frame)
+	default:
+		for ch := range c.others {
+			deliver(ch, frame)
+		}
+	}
+	return nil
+}
+
+// deliver sends frame on ch unless ch is nil or full.
+func deliver(ch chan string, frame string) {
+	select {
+	case ch <- frame:
+	default:
+	}
+}
@@ -0,0 +1,36 @@
+package indyclient
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConn_Route(t *testing.T) {
+	c := newConn(nil, "Node1")
+	first := make(chan string, 2)
+	second := make(chan string, 2)
+	c.pending[1] = first
+	c.pending[2] = second
+	others := c.subscribe()
+
+	// Replies to interleaved requests reach their own request.
+	ack2 := `{"op":"REQACK","reqId":2}`
+	reply1 := `{"op":"REPLY","result":{"reqId":1,"data":null}}`
+	status := `{"op":"LEDGER_STATUS","ledgerId":0}`
+	require.NoError(t, c.route(ack2))
+	require.NoError(t, c.route(reply1))
+	require.NoError(t, c.route(status))
+	require.Equal(t, reply1, <-first)
+	require.Equal(t, ack2, <-second)
+	require.Equal(t, status, <-others)
+
+	// Replies nobody waits for are dropped.
+	require.NoError(t, c.route(`{"op":"REPLY","result":{"reqId":3}}`))
+	require.Len(t, first, 0)
+	require.Len(t, second, 0)
+
+	err := c.route(`{"result":{}}`)
+	require.True(t, errors.Is(err, ErrMalformedReply))
+}
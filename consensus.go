@@ -0,0 +1,147 @@
+package indyclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Consistency selects how much a read trusts individual validators.
+type Consistency int
+
+const (
+	// SingleNode reads are answered by a single validator. They are the
+	// cheapest and fastest, but a faulty or lagging validator can return
+	// wrong or stale data.
+	SingleNode Consistency = iota
+	// Consensus reads are sent to several validators in parallel and only
+	// succeed once f+1 of them, where f is the number of faulty validators
+	// the pool tolerates, returned the same result. At least one honest
+	// validator vouches for the result, at the price of more requests and
+	// the latency of the slowest of the f+1.
+	Consensus
+)
+
+// WithConsistency selects the consistency of the read. The default is
+// SingleNode, unless the Pool was created with WithReadQuorum.
+func WithConsistency(c Consistency) ReadOption {
+	return func(rc *readConfig) {
+		rc.consistency = c
+	}
+}
+
+// WithReadQuorum makes Consensus the default consistency of all the reads
+// of the Pool: they only return a result once f+1 validators agreed on it.
+// Single reads can still opt out with WithConsistency(SingleNode).
+func WithReadQuorum() Option {
+	return func(p *Pool) {
+		p.consistency = Consensus
+	}
+}
+
+// ErrNoConsensus is returned by Consensus reads when not enough validators
+// returned the same result.
+var ErrNoConsensus = errors.New("validators did not agree")
+
+// consensusRead sends the request m to the validators until f+1 of them
+// returned the same result.
+func (p *Pool) consensusRead(ctx context.Context, reqId seqNo, m []byte, cfg *readConfig, b *budget) (*Reply, error) {
+	vs := p.validators()
+	need := faulty(vs) + 1
+	votes := make(map[string]int)
+	var failed []string
+	var agreed *Reply
+
+	p.fanOut(ctx, vs, func(ctx context.Context, v Validator) (interface{}, error) {
+		if cfg.exclude[v.Alias] {
+			return nil, errors.New("excluded")
+		}
+		if !b.take() {
+			return nil, ErrBudgetExhausted
+		}
+		s, err := p.dial(ctx, v)
+		if err != nil {
+			b.failed(err, false)
+			return nil, err
+		}
+		c := p.newConn(s, v)
+		defer c.close()
+		r, err := p.exchangeWith(ctx, c, reqId, m)
+		if err != nil {
+			b.failed(err, false)
+			return nil, err
+		}
+		if !cfg.fresh(r) {
+			b.failed(ErrNotFresh, true)
+			return nil, ErrNotFresh
+		}
+		if cfg.verifyProof {
+			if err := p.verifyProof(r); err != nil {
+				b.failed(err, true)
+				return nil, err
+			}
+			r.Verified = true
+		}
+		return r, nil
+	}, func(v Validator, val interface{}, err error) bool {
+		if err == nil {
+			r := val.(*Reply)
+			var k string
+			k, err = consensusKey(r)
+			if err == nil {
+				votes[k]++
+				if votes[k] >= need {
+					agreed = r
+					return true
+				}
+				return false
+			}
+		}
+		failed = append(failed, fmt.Sprintf("%v (%v)", v.Alias, err))
+		return false
+	})
+	if agreed != nil {
+		return agreed, nil
+	}
+	if ctx.Err() != nil {
+		return nil, b.err()
+	}
+
+	sort.Strings(failed)
+	return nil, fmt.Errorf("%w: %v results, need %v agreeing; failed: %v",
+		ErrNoConsensus, len(votes), need, strings.Join(failed, ", "))
+}
+
+// consensusKey returns a string which is the same for replies from
+// different validators holding the same result. Parts of the result which
+// legitimately differ between validators, such as state proofs and audit
+// paths, are ignored.
+func consensusKey(r *Reply) (string, error) {
+	if err := checkReply(r); err != nil {
+		return "", err
+	}
+	var res map[string]json.RawMessage
+	if err := json.Unmarshal(r.Result, &res); err != nil {
+		return "", err
+	}
+	delete(res, "state_proof")
+
+	var data map[string]json.RawMessage
+	if json.Unmarshal(res["data"], &data) == nil && data != nil {
+		delete(data, "auditPath")
+		delete(data, "ledgerSize")
+		delete(data, "rootHash")
+		b, err := json.Marshal(data)
+		if err != nil {
+			return "", err
+		}
+		res["data"] = b
+	}
+
+	// Maps are marshaled with sorted keys.
+	b, err := json.Marshal(res)
+	return string(b), err
+}
@@ -0,0 +1,93 @@
+package indyclient
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsensusKey(t *testing.T) {
+	key := func(result string) string {
+		k, err := consensusKey(&Reply{Op: "REPLY", Result: []byte(result)})
+		require.NoError(t, err)
+		return k
+	}
+
+	a := key(`{"seqNo":5,"data":{"txn":{"type":"1"},"auditPath":["a"],"ledgerSize":10,"rootHash":"x"},"state_proof":{"root_hash":"1"}}`)
+	b := key(`{"data":{"auditPath":["b","c"],"ledgerSize":11,"rootHash":"y","txn":{"type":"1"}},"seqNo":5,"state_proof":{"root_hash":"2"}}`)
+	require.Equal(t, a, b)
+
+	c := key(`{"seqNo":5,"data":{"txn":{"type":"101"}}}`)
+	require.NotEqual(t, a, c)
+
+	require.Equal(t, key(`{"seqNo":5,"data":null}`), key(`{"data":null,"seqNo":5}`))
+
+	_, err := consensusKey(&Reply{Op: "REQNACK", Result: []byte(`{}`)})
+	require.Error(t, err)
+}
+
+// countingTransport is a fakeTransport recording the validators dialed and
+// the largest number of connections open at the same time.
+type countingTransport struct {
+	fakeTransport
+	mu            sync.Mutex
+	dialed        []string
+	open, maxOpen int
+}
+
+func (t *countingTransport) Dial(ctx context.Context, v Validator) (Connection, error) {
+	c, err := t.fakeTransport.Dial(ctx, v)
+	if err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.dialed = append(t.dialed, v.Alias)
+	t.open++
+	if t.open > t.maxOpen {
+		t.maxOpen = t.open
+	}
+	return &countedConn{Connection: c, t: t}, nil
+}
+
+type countedConn struct {
+	Connection
+	t *countingTransport
+}
+
+func (c *countedConn) Close() error {
+	c.t.mu.Lock()
+	c.t.open--
+	c.t.mu.Unlock()
+	return c.Connection.Close()
+}
+
+func TestPool_ConsensusRead(t *testing.T) {
+	honest := ledgerValidator(testLedger)
+	diverging := ledgerValidator([]string{testLedger[1], testLedger[0]})
+	ct := &countingTransport{fakeTransport: fakeTransport{
+		"Node1": diverging, "Node2": honest, "Node3": honest, "Node4": honest,
+	}}
+	g := testGenesis(t, "10.0.0.1:9702", "10.0.0.2:9702", "10.0.0.3:9702", "10.0.0.4:9702")
+	pool, err := NewPoolFromBytes(g, WithTransport(ct), WithReadQuorum(), WithMaxParallel(1))
+	require.NoError(t, err)
+
+	reply, err := pool.GetTransaction(context.Background(), DomainLedger, 1)
+	require.NoError(t, err)
+	b, _, err := blockFromReply(reply)
+	require.NoError(t, err)
+	require.Equal(t, "V4SGRU86Z58d6TV7PBUe6f", b.Txn.Data.Dest)
+
+	// One validator at a time, and none once f+1 = 2 agreed.
+	require.Equal(t, 1, ct.maxOpen)
+	require.Equal(t, []string{"Node1", "Node2", "Node3"}, ct.dialed)
+
+	// Without f+1 agreeing validators, the read fails.
+	delete(ct.fakeTransport, "Node2")
+	delete(ct.fakeTransport, "Node3")
+	_, err = pool.GetTransaction(context.Background(), DomainLedger, 1)
+	require.True(t, errors.Is(err, ErrNoConsensus), "%v", err)
+}
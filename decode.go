@@ -0,0 +1,135 @@
+package indyclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrNoData is returned by Reply.DecodeResult when the reply's data is null,
+// which is how validators report that the requested object does not exist.
+var ErrNoData = errors.New("reply holds no data")
+
+// DecodeResult decodes the data field of the reply's result into v.
+// Depending on the request type and the version of indy-node, data is either
+// a JSON object or a string holding the JSON encoding of the object; both
+// are accepted.
+func (r *Reply) DecodeResult(v interface{}) error {
+	var res struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(r.Result, &res); err != nil {
+		return err
+	}
+	return decodeData(res.Data, v)
+}
+
+// decodeData decodes a data field which may hold either JSON or a string
+// holding JSON.
+func decodeData(data json.RawMessage, v interface{}) error {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return ErrNoData
+	}
+	if data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		return decodeData(json.RawMessage(s), v)
+	}
+	return json.Unmarshal(data, v)
+}
+
+// GetTxnResult is the result of a GET_TXN request. Txn is nil if the ledger
+// does not contain the requested transaction. LedgerSize is 0 unless the
+// validator reported the size of the ledger.
+type GetTxnResult struct {
+	SeqNo      int // the requested seqNo
+	Txn        *Block
+	LedgerSize int
+}
+
+// GetTxnResult decodes the result of a reply to a GET_TXN request.
+func (r *Reply) GetTxnResult() (*GetTxnResult, error) {
+	if err := checkReply(r); err != nil {
+		return nil, err
+	}
+	var res struct {
+		SeqNo int `json:"seqNo"`
+	}
+	if err := json.Unmarshal(r.Result, &res); err != nil {
+		return nil, err
+	}
+	var data txnData
+	err := r.DecodeResult(&data)
+	if err == ErrNoData {
+		return &GetTxnResult{SeqNo: res.SeqNo}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &GetTxnResult{
+		SeqNo:      res.SeqNo,
+		Txn:        &data.Block,
+		LedgerSize: data.LedgerSize,
+	}, nil
+}
+
+// Decode decodes the result of the reply according to the type of the
+// request it answers. The concrete types returned are:
+//
+//	GET_TXN                      *GetTxnResult
+//	GET_NYM                      *Nym
+//	GET_ATTRIB                   *Attrib
+//	GET_SCHEMA                   *Schema
+//	GET_CLAIM_DEF                *CredentialDefinition
+//	GET_REVOC_REG_DEF            *RevocRegDef
+//	GET_REVOC_REG                *RevocReg
+//	GET_REVOC_REG_DELTA          *RevocRegDelta
+//	GET_AUTH_RULE                []AuthRule
+//	GET_FROZEN_LEDGERS           map[LedgerId]FrozenLedger
+//	VALIDATOR_INFO               *ValidatorReport
+//	GET_RICH_SCHEMA_OBJECT_BY_*  *RichSchemaObject
+//
+// Except for GET_TXN, ErrNoData is returned if the requested object does not
+// exist.
+func (r *Reply) Decode() (interface{}, error) {
+	if err := checkReply(r); err != nil {
+		return nil, err
+	}
+	var res struct {
+		Type protoId `json:"type"`
+	}
+	if err := json.Unmarshal(r.Result, &res); err != nil {
+		return nil, err
+	}
+	switch res.Type {
+	case idGetTxn:
+		return r.GetTxnResult()
+	case idGetNym:
+		return nymFromReply(r)
+	case idGetAttr:
+		return attribFromReply(r)
+	case idGetSchema:
+		return schemaFromReply(r)
+	case idGetClaimDef:
+		return credDefFromReply(r)
+	case idGetRevocRegDef:
+		return revocRegDefFromReply(r)
+	case idGetRevocReg:
+		return revocRegFromReply(r)
+	case idGetRevocRegDelta:
+		return revocRegDeltaFromReply(r)
+	case idGetAuthRule:
+		return authRulesFromReply(r)
+	case idGetFrozenLedgers:
+		return frozenLedgersFromReply(r)
+	case idValidatorInfo:
+		return validatorInfoFromReply(r)
+	case idGetRichSchemaById, idGetRichSchemaByMetadata:
+		return richSchemaFromReply(r)
+	}
+	return nil, fmt.Errorf("cannot decode result of type %v", res.Type)
+}
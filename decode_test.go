@@ -0,0 +1,61 @@
+package indyclient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReply_DecodeResult(t *testing.T) {
+	type nym struct {
+		Dest   string `json:"dest"`
+		Verkey string `json:"verkey"`
+	}
+	want := nym{Dest: "V4SGRU86Z58d6TV7PBUe6f", Verkey: "~CoRER63DVYnWZtK8uAzNbx"}
+
+	// Data as an object.
+	r := &Reply{Result: []byte(`{"type":"105","data":{"dest":"V4SGRU86Z58d6TV7PBUe6f","verkey":"~CoRER63DVYnWZtK8uAzNbx"}}`)}
+	var got nym
+	require.NoError(t, r.DecodeResult(&got))
+	require.Equal(t, want, got)
+
+	// Data as a string holding JSON.
+	r = &Reply{Result: []byte(`{"type":"105","data":"{\"dest\":\"V4SGRU86Z58d6TV7PBUe6f\",\"verkey\":\"~CoRER63DVYnWZtK8uAzNbx\"}"}`)}
+	got = nym{}
+	require.NoError(t, r.DecodeResult(&got))
+	require.Equal(t, want, got)
+
+	for _, res := range []string{`{"data":null}`, `{"seqNo":null}`, `{"data":"null"}`} {
+		r = &Reply{Result: []byte(res)}
+		require.Equal(t, ErrNoData, r.DecodeResult(&got), res)
+	}
+
+	r = &Reply{Result: []byte(`{"data":"{not json"}`)}
+	require.Error(t, r.DecodeResult(&got))
+}
+
+func TestReply_Decode(t *testing.T) {
+	r := &Reply{Op: "REPLY", Result: []byte(`{"type":"3","seqNo":7,"data":null}`)}
+	v, err := r.Decode()
+	require.NoError(t, err)
+	require.Equal(t, &GetTxnResult{SeqNo: 7}, v)
+
+	r = &Reply{Op: "REPLY", Result: []byte(`{"type":"3","seqNo":1,"data":{"ledgerSize":4,"txn":{"type":"1","data":{"dest":"V4SGRU86Z58d6TV7PBUe6f"}},"txnMetadata":{"seqNo":1}}}`)}
+	v, err = r.Decode()
+	require.NoError(t, err)
+	txn := v.(*GetTxnResult)
+	require.Equal(t, 4, txn.LedgerSize)
+	require.Equal(t, "V4SGRU86Z58d6TV7PBUe6f", txn.Txn.Txn.Data.Dest)
+
+	r = &Reply{Op: "REPLY", Result: []byte(`{"type":"105","data":"{\"dest\":\"V4SGRU86Z58d6TV7PBUe6f\",\"role\":\"0\"}","seqNo":3}`)}
+	v, err = r.Decode()
+	require.NoError(t, err)
+	require.Equal(t, "0", v.(*Nym).Role)
+
+	r = &Reply{Op: "REPLY", Result: []byte(`{"type":"1"}`)}
+	_, err = r.Decode()
+	require.Error(t, err)
+	r = &Reply{Op: "REQNACK", Result: []byte(`{"type":"105"}`)}
+	_, err = r.Decode()
+	require.Error(t, err)
+}
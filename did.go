@@ -0,0 +1,132 @@
+package indyclient
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// A Did is a did:sov or did:indy DID. Namespace is the namespace of
+// did:indy DIDs, such as sovrin or sovrin:staging, naming the network whose
+// ledger holds the DID.
+type Did struct {
+	Method    string
+	Namespace string
+	Id        string
+}
+
+// DidParse parses a did:sov:<id> or did:indy:<namespace>:<id> DID.
+func DidParse(didStr string) (*Did, error) {
+	u, err := url.Parse(didStr)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "did" {
+		return nil, errors.New("not a DID")
+	}
+	if u.Opaque == "" {
+		return nil, errors.New("no DID method found")
+	}
+	m := strings.SplitN(u.Opaque, ":", 2)
+	if m[0] != "sov" && m[0] != "indy" {
+		return nil, errors.New("not a sov or indy DID")
+	}
+	if len(m) < 2 || m[1] == "" {
+		return nil, errors.New("no ID found")
+	}
+	d := &Did{Method: m[0], Id: m[1]}
+	if d.Method == "indy" {
+		// The namespace may have a sub-namespace: the ID is what follows
+		// the last colon.
+		i := strings.LastIndex(d.Id, ":")
+		if i <= 0 || i == len(d.Id)-1 {
+			return nil, errors.New("did:indy needs a namespace and an ID")
+		}
+		d.Namespace, d.Id = d.Id[:i], d.Id[i+1:]
+	}
+	return d, nil
+}
+
+// String returns the DID in its canonical did:method:id or
+// did:indy:namespace:id form.
+func (d *Did) String() string {
+	if d.Namespace != "" {
+		return "did:" + d.Method + ":" + d.Namespace + ":" + d.Id
+	}
+	return "did:" + d.Method + ":" + d.Id
+}
+
+// MarshalJSON encodes the DID as a JSON string. It has a value receiver so
+// that Dids embedded by value in other structs are encoded the same way.
+func (d Did) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON decodes a DID from a JSON string.
+func (d *Did) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	parsed, err := DidParse(s)
+	if err != nil {
+		return err
+	}
+	*d = *parsed
+	return nil
+}
+
+// didId returns the ledger identifier of did, which may be given either as a
+// DID or as a bare identifier.
+func didId(did string) (string, error) {
+	if !strings.HasPrefix(did, "did:") {
+		return did, nil
+	}
+	d, err := DidParse(did)
+	if err != nil {
+		return "", err
+	}
+	return d.Id, nil
+}
+
+// A DidURL is a DID followed by an optional path, query and fragment, such
+// as did:sov:WRfXPg8dantKVubE3HX8pw?versionId=5#key-1.
+type DidURL struct {
+	Did      Did
+	Path     string // with its leading slash
+	Query    url.Values
+	Fragment string
+}
+
+// ParseDidURL parses a DID URL of a did:sov or did:indy DID.
+func ParseDidURL(s string) (*DidURL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "did" {
+		return nil, errors.New("not a DID")
+	}
+	did, path := u.Opaque, ""
+	if i := strings.Index(did, "/"); i >= 0 {
+		did, path = did[:i], did[i:]
+	}
+	d, err := DidParse("did:" + did)
+	if err != nil {
+		return nil, err
+	}
+	return &DidURL{Did: *d, Path: path, Query: u.Query(), Fragment: u.Fragment}, nil
+}
+
+// String returns the DID URL with its query parameters sorted by key.
+func (u *DidURL) String() string {
+	s := u.Did.String() + u.Path
+	if len(u.Query) > 0 {
+		s += "?" + u.Query.Encode()
+	}
+	if u.Fragment != "" {
+		s += "#" + u.Fragment
+	}
+	return s
+}
@@ -0,0 +1,73 @@
+package indyclient
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDid_RoundTrip(t *testing.T) {
+	for _, s := range []string{
+		"did:sov:V4SGRU86Z58d6TV7PBUe6f",
+		"did:sov:WRfXPg8dantKVubE3HX8pw",
+		"did:indy:sovrin:WRfXPg8dantKVubE3HX8pw",
+		"did:indy:sovrin:staging:WRfXPg8dantKVubE3HX8pw",
+	} {
+		d, err := DidParse(s)
+		require.NoError(t, err)
+		require.Equal(t, s, d.String())
+
+		d2, err := DidParse(d.String())
+		require.NoError(t, err)
+		require.Equal(t, d, d2)
+	}
+}
+
+func TestDidParse_Indy(t *testing.T) {
+	d, err := DidParse("did:indy:sovrin:staging:WRfXPg8dantKVubE3HX8pw")
+	require.NoError(t, err)
+	require.Equal(t, &Did{Method: "indy", Namespace: "sovrin:staging", Id: "WRfXPg8dantKVubE3HX8pw"}, d)
+
+	_, err = DidParse("did:indy:WRfXPg8dantKVubE3HX8pw")
+	require.Error(t, err)
+}
+
+func TestDid_JSON(t *testing.T) {
+	d, err := DidParse("did:sov:V4SGRU86Z58d6TV7PBUe6f")
+	require.NoError(t, err)
+
+	b, err := json.Marshal(d)
+	require.NoError(t, err)
+	require.Equal(t, `"did:sov:V4SGRU86Z58d6TV7PBUe6f"`, string(b))
+
+	// Dids held by value are encoded the same way.
+	b, err = json.Marshal(struct{ Id Did }{*d})
+	require.NoError(t, err)
+	require.Equal(t, `{"Id":"did:sov:V4SGRU86Z58d6TV7PBUe6f"}`, string(b))
+
+	var d2 Did
+	require.NoError(t, json.Unmarshal([]byte(`"did:sov:V4SGRU86Z58d6TV7PBUe6f"`), &d2))
+	require.Equal(t, *d, d2)
+
+	require.Error(t, json.Unmarshal([]byte(`"did:web:example.com"`), &d2))
+	require.Error(t, json.Unmarshal([]byte(`42`), &d2))
+}
+
+func TestParseDidURL(t *testing.T) {
+	u, err := ParseDidURL("did:sov:WRfXPg8dantKVubE3HX8pw?versionId=5#key-1")
+	require.NoError(t, err)
+	require.Equal(t, "WRfXPg8dantKVubE3HX8pw", u.Did.Id)
+	require.Equal(t, "5", u.Query.Get("versionId"))
+	require.Equal(t, "key-1", u.Fragment)
+	require.Equal(t, "did:sov:WRfXPg8dantKVubE3HX8pw?versionId=5#key-1", u.String())
+
+	u, err = ParseDidURL("did:indy:sovrin:WRfXPg8dantKVubE3HX8pw/path/to#frag")
+	require.NoError(t, err)
+	require.Equal(t, "sovrin", u.Did.Namespace)
+	require.Equal(t, "/path/to", u.Path)
+	require.Equal(t, "did:indy:sovrin:WRfXPg8dantKVubE3HX8pw/path/to#frag", u.String())
+
+	_, err = ParseDidURL("https://example.com")
+	require.Error(t, err)
+}
@@ -0,0 +1,126 @@
+package indyclient
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+
+	"github.com/mr-tron/base58"
+)
+
+// AddSignature signs r with signer, for requests which need the signatures
+// of several DIDs, such as a transaction by an author without the rights
+// to write it, endorsed by a DID which has them. The first signer is the
+// author of the request; Endorser must be set before it signs.
+//
+// The author signs the request and sends the output of
+// MarshalForEndorsement to the endorser, which reads it back with
+// ParseRequest, signs it in turn and submits it with SubmitMultiSigned.
+func (r *Request) AddSignature(signer Signer) error {
+	if r.identifier == "" {
+		id, err := randomReqId()
+		if err != nil {
+			return err
+		}
+		r.identifier, r.reqId = signer.Did(), id
+	}
+	m, err := json.Marshal(r.envelope())
+	if err != nil {
+		return err
+	}
+	msg, err := signingInput(m)
+	if err != nil {
+		return err
+	}
+	sig, err := signer.Sign(msg)
+	if err != nil {
+		return err
+	}
+	if r.signatures == nil {
+		r.signatures = make(map[string]string)
+	}
+	r.signatures[signer.Did()] = base58.Encode(sig)
+	return nil
+}
+
+// MarshalForEndorsement encodes r, once signed by its author with
+// AddSignature, in the JSON format of requests, for its endorser.
+func (r *Request) MarshalForEndorsement() ([]byte, error) {
+	if _, ok := r.signatures[r.identifier]; !ok {
+		return nil, errors.New("request is not signed by its author")
+	}
+	return json.Marshal(r.envelope())
+}
+
+// ParseRequest decodes a request encoded by MarshalForEndorsement.
+func ParseRequest(m []byte) (*Request, error) {
+	var env struct {
+		request
+		Operation json.RawMessage `json:"operation"`
+	}
+	if err := json.Unmarshal(m, &env); err != nil {
+		return nil, err
+	}
+	if env.Identifier == "" || len(env.Operation) == 0 {
+		return nil, errors.New("request has no identifier or operation")
+	}
+	r := &Request{
+		Operation:       env.Operation,
+		TAAAcceptance:   env.TAAAcceptance,
+		Endorser:        env.Endorser,
+		ProtocolVersion: env.ProtocolVersion,
+		identifier:      env.Identifier,
+		reqId:           env.ReqId,
+		signatures:      env.Signatures,
+	}
+	if env.Signature != "" {
+		if r.signatures == nil {
+			r.signatures = make(map[string]string)
+		}
+		r.signatures[env.Identifier] = env.Signature
+	}
+	return r, nil
+}
+
+// SubmitMultiSigned submits r, signed with AddSignature by its author and
+// its endorser, if any. It returns an error if the request is not
+// accepted.
+func (p *Pool) SubmitMultiSigned(ctx context.Context, r *Request) (*Reply, error) {
+	if r.identifier == "" {
+		return nil, errors.New("request is not signed")
+	}
+	return p.Submit(ctx, r)
+}
+
+// envelope returns the request r is sent as. Requests signed by their
+// author alone carry a single signature.
+func (r *Request) envelope() request {
+	env := request{
+		Operation:       r.Operation,
+		Identifier:      r.identifier,
+		ReqId:           r.reqId,
+		ProtocolVersion: r.ProtocolVersion,
+		TAAAcceptance:   r.TAAAcceptance,
+		Endorser:        r.Endorser,
+		Signatures:      r.signatures,
+	}
+	if env.ProtocolVersion == 0 && r.identifier != "" {
+		env.ProtocolVersion = defaultProtocolVersion
+	}
+	if sig, ok := r.signatures[r.identifier]; ok && len(r.signatures) == 1 && r.Endorser == "" {
+		env.Signature, env.Signatures = sig, nil
+	}
+	return env
+}
+
+// randomReqId returns a random reqId for requests signed outside of a Pool,
+// which cannot use its counter.
+func randomReqId() (seqNo, error) {
+	var b [4]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return 0, err
+	}
+	return seqNo(binary.LittleEndian.Uint32(b[:]) | 1<<31), nil
+}
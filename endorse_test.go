@@ -0,0 +1,84 @@
+package indyclient
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/mr-tron/base58"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequest_Endorsement(t *testing.T) {
+	author, err := SignerFromSeed([]byte("00000000000000000000000000000001"))
+	require.NoError(t, err)
+	endorser, err := SignerFromSeed([]byte("000000000000000000000000Trustee1"))
+	require.NoError(t, err)
+
+	req := &Request{
+		Operation: nymOp{Type: idNym, Dest: "WRfXPg8dantKVubE3HX8pw"},
+		Endorser:  endorser.Did(),
+	}
+	_, err = req.MarshalForEndorsement()
+	require.Error(t, err)
+	require.NoError(t, req.AddSignature(author))
+	m, err := req.MarshalForEndorsement()
+	require.NoError(t, err)
+
+	req, err = ParseRequest(m)
+	require.NoError(t, err)
+	require.NoError(t, req.AddSignature(endorser))
+
+	var submitted []byte
+	v := func(m []byte) [][]byte {
+		submitted = m
+		var r struct {
+			ReqId seqNo `json:"reqId"`
+		}
+		json.Unmarshal(m, &r)
+		return [][]byte{
+			[]byte(fmt.Sprintf(`{"op":"REQACK","reqId":%v}`, r.ReqId)),
+			[]byte(fmt.Sprintf(`{"op":"REPLY","result":{"type":"1","reqId":%v}}`, r.ReqId)),
+		}
+	}
+	pool := testPool(t, fakeTransport{"Node1": v, "Node2": v, "Node3": v, "Node4": v})
+	_, err = pool.SubmitMultiSigned(context.Background(), req)
+	require.NoError(t, err)
+
+	// Both signatures are over the same request, which names the author
+	// and the endorser.
+	var env struct {
+		Identifier string            `json:"identifier"`
+		Endorser   string            `json:"endorser"`
+		Signatures map[string]string `json:"signatures"`
+	}
+	require.NoError(t, json.Unmarshal(submitted, &env))
+	require.Equal(t, author.Did(), env.Identifier)
+	require.Equal(t, endorser.Did(), env.Endorser)
+	msg, err := signingInput(submitted)
+	require.NoError(t, err)
+	for _, seed := range []string{"00000000000000000000000000000001", "000000000000000000000000Trustee1"} {
+		_, verkey, did, err := KeypairFromSeed([]byte(seed))
+		require.NoError(t, err)
+		vk, err := base58.Decode(verkey)
+		require.NoError(t, err)
+		sig, err := base58.Decode(env.Signatures[did])
+		require.NoError(t, err)
+		require.True(t, ed25519.Verify(vk, msg, sig))
+	}
+}
+
+func TestPool_SubmitMultiSigned_Unsigned(t *testing.T) {
+	author, err := SignerFromSeed([]byte("00000000000000000000000000000001"))
+	require.NoError(t, err)
+	pool := testPool(t, fakeTransport{})
+
+	req := &Request{Operation: nymOp{Type: idNym, Dest: "WRfXPg8dantKVubE3HX8pw"}, Endorser: "V4SGRU86Z58d6TV7PBUe6f"}
+	_, err = pool.SubmitMultiSigned(context.Background(), req)
+	require.Error(t, err)
+	require.NoError(t, req.AddSignature(author))
+	_, err = pool.SubmitMultiSigned(context.Background(), req)
+	require.EqualError(t, err, "request is not signed by V4SGRU86Z58d6TV7PBUe6f")
+}
@@ -0,0 +1,67 @@
+package indyclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ServiceEndpoint is the agent endpoint published in the endpoint ATTRIB of a
+// DID.
+type ServiceEndpoint struct {
+	Endpoint    string
+	RoutingKeys []string
+	Types       []string
+}
+
+// ParseEndpoint extracts the service endpoint from the raw value of an
+// endpoint ATTRIB. Over time it has been stored in several shapes, all of
+// which are accepted:
+//
+//	{"endpoint":"https://agent.example.com"}
+//	{"endpoint":{"endpoint":"https://agent.example.com","routingKeys":[...]}}
+//	{"endpoint":{"ha":"10.0.0.1:8080"}}
+//
+// The value may also be given as a JSON string holding one of the above, as
+// GET_ATTRIB replies do.
+func ParseEndpoint(raw []byte) (*ServiceEndpoint, error) {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		raw = []byte(s)
+	}
+
+	var attr struct {
+		Endpoint json.RawMessage `json:"endpoint"`
+	}
+	if err := json.Unmarshal(raw, &attr); err != nil {
+		return nil, fmt.Errorf("invalid endpoint attribute: %v", err)
+	}
+	if len(attr.Endpoint) == 0 || string(attr.Endpoint) == "null" {
+		return nil, errors.New("attribute has no endpoint")
+	}
+
+	se := new(ServiceEndpoint)
+	if json.Unmarshal(attr.Endpoint, &se.Endpoint) == nil {
+		return se, nil
+	}
+
+	var nested struct {
+		Endpoint    string   `json:"endpoint"`
+		Ha          string   `json:"ha"`
+		RoutingKeys []string `json:"routingKeys"`
+		Types       []string `json:"types"`
+	}
+	if err := json.Unmarshal(attr.Endpoint, &nested); err != nil {
+		return nil, fmt.Errorf("invalid endpoint attribute: %v", err)
+	}
+	se.Endpoint = nested.Endpoint
+	if se.Endpoint == "" && nested.Ha != "" {
+		se.Endpoint = "http://" + nested.Ha
+	}
+	if se.Endpoint == "" {
+		return nil, errors.New("attribute has no endpoint")
+	}
+	se.RoutingKeys = nested.RoutingKeys
+	se.Types = nested.Types
+	return se, nil
+}
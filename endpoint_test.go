@@ -0,0 +1,38 @@
+package indyclient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEndpoint(t *testing.T) {
+	for _, tc := range []struct {
+		raw  string
+		want ServiceEndpoint
+	}{
+		{`{"endpoint":"https://agent.example.com"}`,
+			ServiceEndpoint{Endpoint: "https://agent.example.com"}},
+		{`{"endpoint":{"endpoint":"https://agent.example.com","routingKeys":["8Z6..."],"types":["endpoint","did-communication"]}}`,
+			ServiceEndpoint{Endpoint: "https://agent.example.com", RoutingKeys: []string{"8Z6..."}, Types: []string{"endpoint", "did-communication"}}},
+		{`{"endpoint":{"ha":"10.0.0.1:8080"}}`,
+			ServiceEndpoint{Endpoint: "http://10.0.0.1:8080"}},
+		{`"{\"endpoint\":{\"endpoint\":\"https://agent.example.com\"}}"`,
+			ServiceEndpoint{Endpoint: "https://agent.example.com"}},
+	} {
+		se, err := ParseEndpoint([]byte(tc.raw))
+		require.NoError(t, err, tc.raw)
+		require.Equal(t, tc.want, *se, tc.raw)
+	}
+
+	for _, raw := range []string{
+		`{}`,
+		`{"endpoint":null}`,
+		`{"endpoint":{"routingKeys":[]}}`,
+		`{"endpoint":42}`,
+		`not json`,
+	} {
+		_, err := ParseEndpoint([]byte(raw))
+		require.Error(t, err, raw)
+	}
+}
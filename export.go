@@ -0,0 +1,262 @@
+package indyclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// An ExportOption configures Export.
+type ExportOption func(*exportConfig)
+
+type exportConfig struct {
+	gzip       bool
+	verify     bool
+	batchSize  int
+	start, end int
+	workers    int
+	format     ExportFormat
+	progress   func(seqNo int)
+}
+
+// WithGzip compresses the output of Export with gzip.
+func WithGzip() ExportOption {
+	return func(c *exportConfig) {
+		c.gzip = true
+	}
+}
+
+// WithVerification makes Export check the audit path of every transaction
+// against the ledger root hash reported with it, and fail on the first
+// transaction which cannot be verified. The root hashes must all be of the
+// same ledger: when replies come with the root hash of a ledger of a new
+// size, for example because the ledger grew during the export, a validator
+// is asked for the consistency proofs linking it to the first one.
+func WithVerification() ExportOption {
+	return func(c *exportConfig) {
+		c.verify = true
+	}
+}
+
+// WithCatchup makes Export download the ledger with CatchupLedger, in
+// batches of batchSize transactions, instead of one GET_TXN request per
+// transaction. Every batch is verified against the ledger root hash, so
+// WithVerification is implied. The transactions are written without the
+// audit paths of GET_TXN replies.
+func WithCatchup(batchSize int) ExportOption {
+	return func(c *exportConfig) {
+		c.batchSize = batchSize
+	}
+}
+
+// WithRange makes Export write the transactions from seqNo start to end,
+// inclusive, instead of the whole ledger. An end of 0 stands for the end of
+// the ledger. With WithCatchup, the transactions before start are not
+// downloaded: the batches are verified against the root hash of the ledger
+// before start, from a consistency proof f+1 validators agree on.
+func WithRange(start, end int) ExportOption {
+	return func(c *exportConfig) {
+		c.start, c.end = start, end
+	}
+}
+
+// WithProgress makes Export call fn with the seqNo of every transaction it
+// has written, for example to record where to resume an interrupted
+// export with WithRange.
+func WithProgress(fn func(seqNo int)) ExportOption {
+	return func(c *exportConfig) {
+		c.progress = fn
+	}
+}
+
+// WithWorkers makes Export keep n GET_TXN requests in flight at the same
+// time, instead of waiting for each reply in turn. The transactions are
+// still written in order. Requests are spread over the connections of the
+// Pool, see WithConnections.
+func WithWorkers(n int) ExportOption {
+	return func(c *exportConfig) {
+		c.workers = n
+	}
+}
+
+// Export writes all transactions of ledger to w, as a JSON array holding the
+// data of the GET_TXN reply of each transaction in order, or in another
+// format selected with WithFormat. It stops at the
+// end of the ledger, or early with an error when ctx is done; the output is
+// a complete (gzip) stream in either case, so that a partial export can
+// still be read.
+func (p *Pool) Export(ctx context.Context, w io.Writer, ledger LedgerId, opts ...ExportOption) (err error) {
+	cfg := exportConfig{start: 1}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.start < 1 {
+		return ErrInvalidSeqNo
+	}
+
+	if cfg.gzip {
+		zw := gzip.NewWriter(w)
+		defer func() {
+			if cerr := zw.Close(); err == nil {
+				err = cerr
+			}
+		}()
+		w = zw
+	}
+
+	ew, err := newExportWriter(cfg.format, w, ledger)
+	if err != nil {
+		return err
+	}
+	if err := ew.begin(); err != nil {
+		return err
+	}
+	defer func() {
+		if werr := ew.end(); err == nil {
+			err = werr
+		}
+	}()
+
+	write := func(seqNo int, data []byte) error {
+		if err := ew.txn(seqNo, data); err != nil {
+			return err
+		}
+		if cfg.progress != nil {
+			cfg.progress(seqNo)
+		}
+		return nil
+	}
+
+	// pin holds the root hashes of the replies written so far.
+	pin := ledgerPin{ledger: ledger}
+	// writeReply writes the transaction of the GET_TXN reply r, or returns
+	// ErrNoData past the end of the ledger.
+	writeReply := func(seqNo int, r *Reply) error {
+		var data json.RawMessage
+		if err := r.DecodeResult(&data); err != nil {
+			return err
+		}
+		if cfg.verify {
+			if !r.Verified {
+				return fmt.Errorf("transaction %v: %w", seqNo, VerifyAuditPath(r))
+			}
+			if err := p.pinRoot(ctx, &pin, r.ledgerSize, r.rootHash); err != nil {
+				return fmt.Errorf("transaction %v: %w", seqNo, err)
+			}
+		}
+		return write(seqNo, data)
+	}
+
+	if cfg.batchSize > 0 {
+		return p.catchupLedger(ctx, ledger, cfg.start, cfg.end, cfg.batchSize, func(blocks []*Block, raws []json.RawMessage) error {
+			for i, raw := range raws {
+				if err := write(blocks[i].TxnMetadata.SeqNo, raw); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+
+	if cfg.workers > 1 {
+		// Fetch several windows of requests at a time, so that a slow
+		// reply holds back fewer of the others.
+		batch := 4 * cfg.workers
+		for from := cfg.start; cfg.end == 0 || from <= cfg.end; from += batch {
+			to := from + batch - 1
+			if cfg.end > 0 && to > cfg.end {
+				to = cfg.end
+			}
+			replies, _, err := p.fetchTxns(ctx, ledger, from, to, cfg.workers, nil)
+			if err != nil {
+				return err
+			}
+			for i, r := range replies {
+				if err := writeReply(from+i, r); err != nil {
+					return err
+				}
+			}
+			if len(replies) < to-from+1 {
+				return nil
+			}
+		}
+		return nil
+	}
+
+	for seqNo := cfg.start; cfg.end == 0 || seqNo <= cfg.end; seqNo++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		r, err := p.GetTransaction(ctx, ledger, seqNo)
+		if err != nil {
+			return err
+		}
+		if err := checkReply(r); err != nil {
+			return err
+		}
+		err = writeReply(seqNo, r)
+		if err == ErrNoData {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ledgerPin holds root hashes of a ledger by size, all checked to be those
+// of the same ledger.
+type ledgerPin struct {
+	ledger LedgerId
+	first  int // size of the first root hash, which the others are linked to
+	roots  map[int][]byte
+}
+
+// pinLinkAttempts bounds the number of times pinRoot asks for consistency
+// proofs while the ledger grows under it.
+const pinLinkAttempts = 3
+
+// pinRoot adds the root hash of ledger of the given size to pin. Unless pin
+// is empty or already holds it, the root hash is linked to the first one:
+// the current validator is asked for the consistency proofs from both to
+// its ledger, which must be the same.
+func (p *Pool) pinRoot(ctx context.Context, pin *ledgerPin, size int, root []byte) error {
+	if known, ok := pin.roots[size]; ok {
+		if !bytes.Equal(known, root) {
+			return fmt.Errorf("%w: two root hashes for size %v", ErrInconsistentLedger, size)
+		}
+		return nil
+	}
+	if pin.roots == nil {
+		pin.first, pin.roots = size, map[int][]byte{size: root}
+		return nil
+	}
+	c, err := p.connection(ctx, nil, p.newBudget())
+	if err != nil {
+		return err
+	}
+	for i := 0; i < pinLinkAttempts; i++ {
+		st, err := p.ledgerProof(ctx, c, pin.ledger, size, root)
+		if err != nil {
+			return err
+		}
+		first, err := p.ledgerProof(ctx, c, pin.ledger, pin.first, pin.roots[pin.first])
+		if err != nil {
+			return err
+		}
+		if st.size != first.size {
+			// The ledger grew between the proofs.
+			continue
+		}
+		if known, ok := pin.roots[st.size]; !bytes.Equal(st.root, first.root) || ok && !bytes.Equal(st.root, known) {
+			return fmt.Errorf("%w: ledger of size %v", ErrInconsistentLedger, size)
+		}
+		pin.roots[size], pin.roots[st.size] = root, st.root
+		return nil
+	}
+	return fmt.Errorf("ledger of size %v: ledger grew while checking its consistency", size)
+}
@@ -0,0 +1,212 @@
+package indyclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mr-tron/base58"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool_ExportRange(t *testing.T) {
+	ledger := numberedLedger(10)
+	v := ledgerValidator(ledger)
+	pool := testPool(t, fakeTransport{"Node1": v, "Node2": v, "Node3": v, "Node4": v})
+
+	var out bytes.Buffer
+	var written []int
+	err := pool.Export(context.Background(), &out, DomainLedger,
+		WithRange(4, 6), WithProgress(func(seqNo int) { written = append(written, seqNo) }))
+	require.NoError(t, err)
+	require.Equal(t, []int{4, 5, 6}, written)
+	var txns []Block
+	require.NoError(t, json.Unmarshal(out.Bytes(), &txns))
+	require.Len(t, txns, 3)
+	require.Equal(t, 4, txns[0].TxnMetadata.SeqNo)
+
+	out.Reset()
+	require.NoError(t, pool.Export(context.Background(), &out, DomainLedger, WithRange(9, 0)))
+	require.NoError(t, json.Unmarshal(out.Bytes(), &txns))
+	require.Len(t, txns, 2)
+	require.Equal(t, 10, txns[1].TxnMetadata.SeqNo)
+}
+
+func TestPool_ExportWorkers(t *testing.T) {
+	ledger := numberedLedger(50)
+	v := ledgerValidator(ledger)
+	pool := testPool(t, fakeTransport{"Node1": v, "Node2": v, "Node3": v, "Node4": v}, WithConnections(4))
+
+	var out bytes.Buffer
+	require.NoError(t, pool.Export(context.Background(), &out, DomainLedger, WithWorkers(4), WithRange(3, 0)))
+	var txns []Block
+	require.NoError(t, json.Unmarshal(out.Bytes(), &txns))
+	require.Len(t, txns, 48)
+	for i, b := range txns {
+		require.Equal(t, i+3, b.TxnMetadata.SeqNo)
+	}
+}
+
+func TestPool_ExportFormats(t *testing.T) {
+	ledger := []string{
+		`{"txn":{"type":"1","data":{"dest":"V4SGRU86Z58d6TV7PBUe6f"}},"txnMetadata":{"seqNo":1,"txnTime":1500000000}}`,
+		`{"txn":{"type":"100","data":{"dest":"Th7MpTaRZVRYnPiabds81Y","raw":"{\"name\":\"O'Brien\"}"}},"txnMetadata":{"seqNo":2}}`,
+	}
+	v := ledgerValidator(ledger)
+	pool := testPool(t, fakeTransport{"Node1": v, "Node2": v, "Node3": v, "Node4": v})
+	export := func(f ExportFormat) string {
+		var out bytes.Buffer
+		require.NoError(t, pool.Export(context.Background(), &out, DomainLedger, WithFormat(f)))
+		return out.String()
+	}
+
+	require.Equal(t, ledger[0]+"\n"+ledger[1]+"\n", export(FormatNDJSON))
+	require.Equal(t, "seqNo,type,time,dest\n1,1,2017-07-14T02:40:00Z,V4SGRU86Z58d6TV7PBUe6f\n2,100,,Th7MpTaRZVRYnPiabds81Y\n", export(FormatCSV))
+	sql := export(FormatSQL)
+	require.Contains(t, sql, "CREATE TABLE IF NOT EXISTS txns")
+	require.Contains(t, sql, `INSERT OR REPLACE INTO txns VALUES (1, 2, 100, NULL, 'Th7MpTaRZVRYnPiabds81Y', '{"txn":{"type":"100","data":{"dest":"Th7MpTaRZVRYnPiabds81Y","raw":"{\"name\":\"O''Brien\"}"}},"txnMetadata":{"seqNo":2}}');`)
+
+	f, err := ParseExportFormat("csv")
+	require.NoError(t, err)
+	require.Equal(t, FormatCSV, f)
+	_, err = ParseExportFormat("xml")
+	require.Error(t, err)
+}
+
+// auditedValidator serves the transactions returned by ledger, which may
+// grow between calls, with their audit paths, and answers LEDGER_STATUS
+// messages with consistency proofs.
+func auditedValidator(ledger func() []string) fakeValidator {
+	return func(m []byte) [][]byte {
+		txns := ledger()
+		leaves := make([][]byte, len(txns))
+		for i, txn := range txns {
+			leaves[i], _ = leafHash(json.RawMessage(txn))
+		}
+		encode := func(hashes [][]byte) []string {
+			enc := make([]string, len(hashes))
+			for i, h := range hashes {
+				enc[i] = base58.Encode(h)
+			}
+			return enc
+		}
+
+		var req struct {
+			Op        string   `json:"op"`
+			LedgerID  int      `json:"ledgerId"`
+			TxnSeqNo  int      `json:"txnSeqNo"`
+			ReqId     seqNo    `json:"reqId"`
+			Operation getTxnOp `json:"operation"`
+		}
+		if err := json.Unmarshal(m, &req); err != nil {
+			return nil
+		}
+		if req.Op == "LEDGER_STATUS" {
+			size := req.TxnSeqNo
+			if size >= len(txns) {
+				st, _ := json.Marshal(ledgerStatus{Op: "LEDGER_STATUS", LedgerID: req.LedgerID,
+					TxnSeqNo: len(txns), MerkleRoot: base58.Encode(mth(leaves))})
+				return [][]byte{st}
+			}
+			var hashes [][]byte
+			if size > 0 {
+				hashes = makeConsistencyProof(size, leaves, true)
+			}
+			cp, _ := json.Marshal(consistencyProof{Op: "CONSISTENCY_PROOF", LedgerID: req.LedgerID,
+				SeqNoStart: size, SeqNoEnd: len(txns), OldMerkleRoot: base58.Encode(mth(leaves[:size])),
+				NewMerkleRoot: base58.Encode(mth(leaves)), Hashes: encode(hashes)})
+			return [][]byte{cp}
+		}
+
+		data := "null"
+		if seqNo := req.Operation.Data; seqNo >= 1 && seqNo <= len(txns) {
+			path, _ := json.Marshal(encode(makeAuditPath(seqNo-1, leaves)))
+			data = strings.TrimSuffix(txns[seqNo-1], "}") + fmt.Sprintf(`,"auditPath":%s,"ledgerSize":%v,"rootHash":"%v"}`,
+				path, len(txns), base58.Encode(mth(leaves)))
+		}
+		return [][]byte{
+			[]byte(fmt.Sprintf(`{"op":"REQACK","reqId":%v}`, req.ReqId)),
+			[]byte(fmt.Sprintf(`{"op":"REPLY","result":{"type":"3","reqId":%v,"seqNo":%v,"data":%v}}`,
+				req.ReqId, req.Operation.Data, data)),
+		}
+	}
+}
+
+func TestPool_ExportPinnedRoot(t *testing.T) {
+	// The ledger grows by one transaction with every GET_TXN: the replies
+	// are verified against roots of ever larger ledgers.
+	var mu sync.Mutex
+	full := numberedLedger(20)
+	size, proofs := 5, 0
+	v := auditedValidator(func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return full[:size]
+	})
+	growing := func(m []byte) [][]byte {
+		out := v(m)
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case strings.Contains(string(m), `"LEDGER_STATUS"`):
+			proofs++
+		case size < len(full):
+			size++
+		}
+		return out
+	}
+	pool := testPool(t, fakeTransport{"Node1": growing, "Node2": growing, "Node3": growing, "Node4": growing})
+
+	var out bytes.Buffer
+	require.NoError(t, pool.Export(context.Background(), &out, DomainLedger, WithVerification()))
+	var txns []Block
+	require.NoError(t, json.Unmarshal(out.Bytes(), &txns))
+	require.Len(t, txns, 20)
+	require.NotZero(t, proofs)
+
+	mu.Lock()
+	size = 5
+	mu.Unlock()
+	out.Reset()
+	require.NoError(t, pool.Export(context.Background(), &out, DomainLedger, WithVerification(), WithWorkers(4)))
+	require.NoError(t, json.Unmarshal(out.Bytes(), &txns))
+	require.Len(t, txns, 20)
+}
+
+func TestPool_ExportFork(t *testing.T) {
+	honest := numberedLedger(6)
+	// fork has another first transaction, and one more transaction.
+	fork := append([]string{strings.Replace(honest[0], "dest1", "evil", 1)}, numberedLedger(7)[1:]...)
+	for _, ledger := range [][]string{fork[:6], fork} {
+		// The replies for the transactions after the third one, and the
+		// consistency proofs, come from the fork.
+		hv := auditedValidator(func() []string { return honest })
+		fv := auditedValidator(func() []string { return ledger })
+		v := func(m []byte) [][]byte {
+			var req struct {
+				Operation getTxnOp `json:"operation"`
+			}
+			json.Unmarshal(m, &req)
+			if req.Operation.Data >= 1 && req.Operation.Data <= 3 {
+				return hv(m)
+			}
+			return fv(m)
+		}
+		pool := testPool(t, fakeTransport{"Node1": v, "Node2": v, "Node3": v, "Node4": v})
+
+		var out bytes.Buffer
+		var written []int
+		err := pool.Export(context.Background(), &out, DomainLedger, WithVerification(),
+			WithProgress(func(seqNo int) { written = append(written, seqNo) }))
+		require.True(t, errors.Is(err, ErrInconsistentLedger), "%v", err)
+		require.Equal(t, []int{1, 2, 3}, written)
+
+		// Without verification, the fork goes unnoticed.
+		require.NoError(t, pool.Export(context.Background(), &out, DomainLedger))
+	}
+}
@@ -0,0 +1,212 @@
+package indyclient
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExportFormat is the output format of Export.
+type ExportFormat int
+
+const (
+	// FormatJSON is a JSON array of the data of the GET_TXN replies.
+	FormatJSON ExportFormat = iota
+	// FormatNDJSON is the data of one GET_TXN reply per line.
+	FormatNDJSON
+	// FormatCSV has a line per transaction with its seqNo, type, time in
+	// RFC 3339 format and dest, after a header line.
+	FormatCSV
+	// FormatSQL is SQL statements creating a txns table indexed by type,
+	// time and dest and filling it, for example with sqlite3 ledger.db.
+	FormatSQL
+	// FormatSQLite is a SQLite database holding a txns table indexed by
+	// type, time and dest, whose rowid is the seqNo. It is written directly,
+	// so that it can be opened by any tool reading SQLite files, but only
+	// to an io.WriteSeeker such as an os.File, without WithGzip.
+	FormatSQLite
+)
+
+var exportFormats = map[string]ExportFormat{
+	"json":   FormatJSON,
+	"ndjson": FormatNDJSON,
+	"csv":    FormatCSV,
+	"sql":    FormatSQL,
+	"sqlite": FormatSQLite,
+}
+
+// ParseExportFormat returns the ExportFormat called name: json, ndjson,
+// csv, sql or sqlite.
+func ParseExportFormat(name string) (ExportFormat, error) {
+	f, ok := exportFormats[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown export format %q", name)
+	}
+	return f, nil
+}
+
+// WithFormat selects the output format of Export. The default is
+// FormatJSON.
+func WithFormat(f ExportFormat) ExportOption {
+	return func(c *exportConfig) {
+		c.format = f
+	}
+}
+
+// exportWriter writes the transactions of Export in a format.
+type exportWriter interface {
+	begin() error
+	txn(seqNo int, data []byte) error
+	end() error
+}
+
+func newExportWriter(f ExportFormat, w io.Writer, ledger LedgerId) (exportWriter, error) {
+	switch f {
+	case FormatJSON:
+		return &jsonExportWriter{w: w}, nil
+	case FormatNDJSON:
+		return ndjsonExportWriter{w}, nil
+	case FormatCSV:
+		return csvExportWriter{csv.NewWriter(w)}, nil
+	case FormatSQL:
+		return sqlExportWriter{w, ledger}, nil
+	case FormatSQLite:
+		return newSQLiteExportWriter(w, ledger)
+	}
+	return nil, fmt.Errorf("unknown export format %v", f)
+}
+
+type jsonExportWriter struct {
+	w       io.Writer
+	started bool
+}
+
+func (e *jsonExportWriter) begin() error {
+	_, err := io.WriteString(e.w, "[")
+	return err
+}
+
+func (e *jsonExportWriter) txn(seqNo int, data []byte) error {
+	sep := ",\n"
+	if !e.started {
+		sep = "\n"
+		e.started = true
+	}
+	if _, err := io.WriteString(e.w, sep); err != nil {
+		return err
+	}
+	_, err := e.w.Write(data)
+	return err
+}
+
+func (e *jsonExportWriter) end() error {
+	_, err := io.WriteString(e.w, "\n]\n")
+	return err
+}
+
+type ndjsonExportWriter struct {
+	w io.Writer
+}
+
+func (e ndjsonExportWriter) begin() error { return nil }
+
+func (e ndjsonExportWriter) txn(seqNo int, data []byte) error {
+	_, err := e.w.Write(append(append([]byte(nil), data...), '\n'))
+	return err
+}
+
+func (e ndjsonExportWriter) end() error { return nil }
+
+// exportFields returns the type, time and dest of the transaction data.
+func exportFields(data []byte) (int, int64, string, error) {
+	var b Block
+	if err := json.Unmarshal(data, &b); err != nil {
+		return 0, 0, "", err
+	}
+	return int(b.Txn.Type), b.TxnMetadata.TxnTime, b.Txn.Data.Dest, nil
+}
+
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+func (e csvExportWriter) begin() error {
+	return e.w.Write([]string{"seqNo", "type", "time", "dest"})
+}
+
+func (e csvExportWriter) txn(seqNo int, data []byte) error {
+	typ, txnTime, dest, err := exportFields(data)
+	if err != nil {
+		return fmt.Errorf("transaction %v: %v", seqNo, err)
+	}
+	var t string
+	if txnTime != 0 {
+		t = time.Unix(txnTime, 0).UTC().Format(time.RFC3339)
+	}
+	return e.w.Write([]string{strconv.Itoa(seqNo), strconv.Itoa(typ), t, dest})
+}
+
+func (e csvExportWriter) end() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type sqlExportWriter struct {
+	w      io.Writer
+	ledger LedgerId
+}
+
+func (e sqlExportWriter) begin() error {
+	_, err := io.WriteString(e.w, `CREATE TABLE IF NOT EXISTS txns (
+	ledger INTEGER NOT NULL,
+	seqNo INTEGER NOT NULL,
+	type INTEGER NOT NULL,
+	txnTime INTEGER,
+	dest TEXT,
+	txn TEXT NOT NULL,
+	PRIMARY KEY (ledger, seqNo)
+);
+CREATE INDEX IF NOT EXISTS txns_type ON txns (ledger, type);
+CREATE INDEX IF NOT EXISTS txns_time ON txns (ledger, txnTime);
+CREATE INDEX IF NOT EXISTS txns_dest ON txns (dest);
+BEGIN;
+`)
+	return err
+}
+
+func (e sqlExportWriter) txn(seqNo int, data []byte) error {
+	typ, txnTime, dest, err := exportFields(data)
+	if err != nil {
+		return fmt.Errorf("transaction %v: %v", seqNo, err)
+	}
+	_, err = fmt.Fprintf(e.w, "INSERT OR REPLACE INTO txns VALUES (%d, %d, %d, %s, %s, %s);\n",
+		e.ledger, seqNo, typ, sqlNullInt(txnTime), sqlNullString(dest), sqlString(string(data)))
+	return err
+}
+
+func (e sqlExportWriter) end() error {
+	_, err := io.WriteString(e.w, "COMMIT;\n")
+	return err
+}
+
+func sqlString(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+func sqlNullString(s string) string {
+	if s == "" {
+		return "NULL"
+	}
+	return sqlString(s)
+}
+
+func sqlNullInt(i int64) string {
+	if i == 0 {
+		return "NULL"
+	}
+	return strconv.FormatInt(i, 10)
+}
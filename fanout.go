@@ -0,0 +1,83 @@
+package indyclient
+
+import "context"
+
+// validators returns the current Validators, which Refresh may replace
+// concurrently.
+func (p *Pool) validators() []Validator {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.Validators
+}
+
+// faulty returns f, the number of faulty validators a pool of vs tolerates:
+// a pool of n validators tolerates (n-1)/3 of them failing. Observers do
+// not take part in consensus and are not counted.
+func faulty(vs []Validator) int {
+	n := 0
+	for i := range vs {
+		if !vs[i].IsObserver() {
+			n++
+		}
+	}
+	return (n - 1) / 3
+}
+
+// parallelism returns the number of the validators vs which operations
+// fanning out to the pool talk to at the same time.
+func (p *Pool) parallelism(vs []Validator) int {
+	if p.maxParallel > 0 {
+		return p.maxParallel
+	}
+	return faulty(vs) + 1
+}
+
+// fanOut calls call for every validator of vs, a snapshot of Validators, at
+// most p.parallelism(vs) at a time, each with its own connection. The
+// results are passed to done as they arrive, from a single goroutine; once
+// done returns true, no further validators are called and the calls in
+// flight are canceled. fanOut returns when done returned true, all
+// validators answered, or ctx is done.
+func (p *Pool) fanOut(ctx context.Context, vs []Validator,
+	call func(context.Context, Validator) (interface{}, error),
+	done func(Validator, interface{}, error) bool) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		v   Validator
+		val interface{}
+		err error
+	}
+	// Buffered so that calls finishing after fanOut returned do not block.
+	results := make(chan result, len(vs))
+	sem := make(chan struct{}, p.parallelism(vs))
+
+	go func() {
+		for _, v := range vs {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			go func(v Validator) {
+				val, err := call(ctx, v)
+				results <- result{v, val, err}
+			}(v)
+		}
+	}()
+
+	for range vs {
+		select {
+		case r := <-results:
+			if done(r.v, r.val, r.err) {
+				return
+			}
+			// Only call the next validator once done has seen this
+			// result, so that none is called after done returned true.
+			<-sem
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package indyclient
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool_FanOut(t *testing.T) {
+	p := &Pool{}
+	for i := 0; i < 10; i++ {
+		p.Validators = append(p.Validators, Validator{Alias: fmt.Sprintf("Node%v", i)})
+	}
+	require.Equal(t, 3, faulty(p.Validators))
+	require.Equal(t, 4, p.parallelism(p.Validators))
+
+	var mu sync.Mutex
+	running, maxRunning, calls := 0, 0, 0
+	call := func(ctx context.Context, v Validator) (interface{}, error) {
+		mu.Lock()
+		running++
+		calls++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return v.Alias, nil
+	}
+
+	// All validators are called, never more than the bound at once.
+	p.maxParallel = 3
+	var got []string
+	p.fanOut(context.Background(), p.Validators, call, func(v Validator, val interface{}, err error) bool {
+		require.NoError(t, err)
+		require.Equal(t, v.Alias, val)
+		got = append(got, v.Alias)
+		return false
+	})
+	require.Len(t, got, 10)
+	require.Equal(t, 3, maxRunning)
+
+	// No further validators are called once done returns true.
+	calls = 0
+	p.maxParallel = 1
+	n := 0
+	p.fanOut(context.Background(), p.Validators, call, func(Validator, interface{}, error) bool {
+		n++
+		return n == 2
+	})
+	mu.Lock()
+	require.True(t, calls <= 3, "%v calls", calls)
+	mu.Unlock()
+}
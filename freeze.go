@@ -0,0 +1,66 @@
+package indyclient
+
+import (
+	"context"
+	"errors"
+)
+
+// LedgersFreezeTxn is a LEDGERS_FREEZE transaction, which freezes ledgers:
+// the validators stop accepting transactions for them and only keep their
+// last root hashes, so that plugin ledgers which are no longer used can be
+// retired. Trustees write it.
+type LedgersFreezeTxn struct {
+	LedgerIds []LedgerId `json:"ledgers_ids"`
+}
+
+type ledgersFreezeOp struct {
+	Type protoId `json:"type,string"`
+	LedgersFreezeTxn
+}
+
+type getFrozenLedgersOp struct {
+	Type protoId `json:"type,string"`
+}
+
+// FrozenLedger is the final state of a frozen ledger.
+type FrozenLedger struct {
+	LedgerRoot string `json:"ledger"` // Merkle root hash of the transactions
+	StateRoot  string `json:"state"`  // root hash of the state
+	SeqNo      int    `json:"seq_no"` // seqNo of the last transaction
+}
+
+// GetFrozenLedgers fetches the ledgers frozen by LEDGERS_FREEZE
+// transactions, with their final state. The map is empty if no ledger is
+// frozen.
+func (p *Pool) GetFrozenLedgers(ctx context.Context, opts ...ReadOption) (map[LedgerId]FrozenLedger, error) {
+	r, err := p.read(ctx, getFrozenLedgersOp{Type: idGetFrozenLedgers}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return frozenLedgersFromReply(r)
+}
+
+func frozenLedgersFromReply(r *Reply) (map[LedgerId]FrozenLedger, error) {
+	if err := checkReply(r); err != nil {
+		return nil, err
+	}
+	frozen := make(map[LedgerId]FrozenLedger)
+	if err := r.DecodeResult(&frozen); err != nil && err != ErrNoData {
+		return nil, err
+	}
+	return frozen, nil
+}
+
+// FreezeLedgers writes a LEDGERS_FREEZE transaction signed by signer, which
+// must be a trustee, freezing the given ledgers, and returns it once the
+// pool has ordered it. The ledgers frozen before stay frozen. Validators
+// refuse to freeze the pool, domain, config and audit ledgers.
+func (p *Pool) FreezeLedgers(ctx context.Context, signer Signer, ledgers ...LedgerId) (*Block, error) {
+	if len(ledgers) == 0 {
+		return nil, errors.New("no ledger to freeze")
+	}
+	return p.write(ctx, ledgersFreezeOp{
+		Type:             idLedgersFreeze,
+		LedgersFreezeTxn: LedgersFreezeTxn{LedgerIds: ledgers},
+	}, signer)
+}
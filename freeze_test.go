@@ -0,0 +1,34 @@
+package indyclient
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrozenLedgers(t *testing.T) {
+	r := &Reply{Op: "REPLY", Result: []byte(`{"type":"10","data":{"1001":{"ledger":"GKot5hBsd81kMupNCXHaqbhv3huEbxAFMLnpcX2hniwn","state":"DqQ7G4fgDHBGdXERzJGWpSWmNSTJhBR7UAxZBDdTxuTq","seq_no":3}}}`)}
+	v, err := r.Decode()
+	require.NoError(t, err)
+	require.Equal(t, map[LedgerId]FrozenLedger{1001: {
+		LedgerRoot: "GKot5hBsd81kMupNCXHaqbhv3huEbxAFMLnpcX2hniwn",
+		StateRoot:  "DqQ7G4fgDHBGdXERzJGWpSWmNSTJhBR7UAxZBDdTxuTq",
+		SeqNo:      3,
+	}}, v)
+
+	r = &Reply{Op: "REPLY", Result: []byte(`{"type":"10","data":null}`)}
+	frozen, err := frozenLedgersFromReply(r)
+	require.NoError(t, err)
+	require.Empty(t, frozen)
+
+	m, err := json.Marshal(ledgersFreezeOp{Type: idLedgersFreeze, LedgersFreezeTxn: LedgersFreezeTxn{LedgerIds: []LedgerId{1001, 1002}}})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"type":"9","ledgers_ids":[1001,1002]}`, string(m))
+
+	var b Block
+	require.NoError(t, json.Unmarshal([]byte(`{"txn":{"type":"9","data":{"ledgers_ids":[1001]},"metadata":{}},"txnMetadata":{"seqNo":5}}`), &b))
+	v, err = b.Decode()
+	require.NoError(t, err)
+	require.Equal(t, &LedgersFreezeTxn{LedgerIds: []LedgerId{1001}}, v)
+}
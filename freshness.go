@@ -0,0 +1,48 @@
+package indyclient
+
+import "time"
+
+// WithMaxStaleness rejects replies whose state proof is signed by the pool
+// for a state older than maxAge, which is the case of validators lagging
+// behind the pool. Like with WithMinFreshness, the next validator is asked
+// until a fresh reply arrives or wait has elapsed, in which case the read
+// fails with ErrNotFresh. Replies without a state proof, such as those to
+// GET_TXN, are not checked. The pool signs its state at least every few
+// minutes, so maxAge should not be much shorter than that.
+func WithMaxStaleness(maxAge, wait time.Duration) ReadOption {
+	return func(c *readConfig) {
+		c.maxStaleness = maxAge
+		c.freshnessWait = wait
+	}
+}
+
+// LedgerFreshness returns the time of the latest state of ledger signed by
+// the pool in the state proofs of the replies the Pool received. The
+// boolean is false if no reply carried a state proof for ledger yet.
+func (p *Pool) LedgerFreshness(ledger LedgerId) (time.Time, bool) {
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+	ts, ok := p.freshness[ledger]
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(ts, 0), true
+}
+
+// observeFreshness records the timestamp of the multi-signed state of r,
+// if it has one.
+func (p *Pool) observeFreshness(r *Reply) {
+	var res stateProofResult
+	if !r.decodeStateProof(&res) {
+		return
+	}
+	v := res.StateProof.MultiSignature.Value
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+	if p.freshness == nil {
+		p.freshness = make(map[LedgerId]int64)
+	}
+	if v.Timestamp > p.freshness[v.LedgerId] {
+		p.freshness[v.LedgerId] = v.Timestamp
+	}
+}
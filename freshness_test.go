@@ -0,0 +1,84 @@
+package indyclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// stateValidator is a fakeValidator answering every request with a reply
+// whose state of the config ledger is signed at ts.
+func stateValidator(ts int64) fakeValidator {
+	return func(m []byte) [][]byte {
+		var req struct {
+			ReqId seqNo `json:"reqId"`
+		}
+		json.Unmarshal(m, &req)
+		return [][]byte{[]byte(fmt.Sprintf(`{"op":"REPLY","result":{"type":"6","reqId":%v,"data":null,`+
+			`"state_proof":{"multi_signature":{"value":{"ledger_id":2,"timestamp":%v}}}}}`, req.ReqId, ts))}
+	}
+}
+
+func TestPool_MaxStaleness(t *testing.T) {
+	now := time.Now().Unix()
+	stale, fresh := stateValidator(now-3600), stateValidator(now)
+	pool := testPool(t, fakeTransport{"Node1": stale, "Node2": fresh, "Node3": stale, "Node4": stale})
+	ctx := context.Background()
+
+	_, ok := pool.LedgerFreshness(ConfigLedger)
+	require.False(t, ok)
+
+	r, err := pool.read(ctx, getTAAOp{Type: idGetTAA}, WithMaxStaleness(time.Minute, time.Second))
+	require.NoError(t, err)
+	require.Equal(t, "Node2", r.Node)
+	ts, ok := pool.LedgerFreshness(ConfigLedger)
+	require.True(t, ok)
+	require.Equal(t, now, ts.Unix())
+
+	pool = testPool(t, fakeTransport{"Node1": stale, "Node2": stale, "Node3": stale, "Node4": stale})
+	_, err = pool.read(ctx, getTAAOp{Type: idGetTAA}, WithMaxStaleness(time.Minute, 0))
+	require.Equal(t, ErrNotFresh, err)
+	ts, ok = pool.LedgerFreshness(ConfigLedger)
+	require.True(t, ok)
+	require.Equal(t, now-3600, ts.Unix())
+}
+
+func TestPool_MinFreshness(t *testing.T) {
+	now := time.Now()
+	stale, fresh := stateValidator(now.Unix()-3600), stateValidator(now.Unix())
+	ctx := context.Background()
+
+	// The lagging validator is skipped after a backoff.
+	pool := testPool(t, fakeTransport{"Node1": stale, "Node2": fresh, "Node3": fresh, "Node4": fresh},
+		WithBackoff(20*time.Millisecond, 20*time.Millisecond))
+	start := time.Now()
+	r, err := pool.read(ctx, getTAAOp{Type: idGetTAA}, WithMinFreshness(now, time.Second))
+	require.NoError(t, err)
+	require.Equal(t, "Node2", r.Node)
+	require.True(t, time.Since(start) >= 10*time.Millisecond)
+
+	// Stale validators are asked again until the wait is over, beyond the
+	// attempts of the budget.
+	var mu sync.Mutex
+	requests := 0
+	counting := func(m []byte) [][]byte {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		return stale(m)
+	}
+	pool = testPool(t, fakeTransport{"Node1": counting, "Node2": counting, "Node3": counting, "Node4": counting},
+		WithBackoff(5*time.Millisecond, 5*time.Millisecond), WithRetryBudget(2, time.Minute))
+	start = time.Now()
+	_, err = pool.read(ctx, getTAAOp{Type: idGetTAA}, WithMinFreshness(now, 200*time.Millisecond))
+	require.Equal(t, ErrNotFresh, err)
+	require.True(t, time.Since(start) >= 200*time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	require.True(t, requests > 2, "%v requests", requests)
+}
@@ -0,0 +1,65 @@
+//go:build ignore
+// +build ignore
+
+// gen_genesis.go downloads the genesis transactions of the built-in
+// networks of network.go and writes them to genesis_data.go. It is run by
+// go generate.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+)
+
+var urlRe = regexp.MustCompile(`\{url: "(https://[^"]+)"\}`)
+
+func main() {
+	src, err := ioutil.ReadFile("network.go")
+	if err != nil {
+		log.Fatal(err)
+	}
+	var urls []string
+	for _, m := range urlRe.FindAllSubmatch(src, -1) {
+		urls = append(urls, string(m[1]))
+	}
+	if len(urls) == 0 {
+		log.Fatal("no network URLs in network.go")
+	}
+	sort.Strings(urls)
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by gen_genesis.go; DO NOT EDIT.\n\npackage indyclient\n\nfunc init() {\n")
+	for _, url := range urls {
+		g, err := get(url)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Fprintf(&b, "\tembeddedGenesis[%q] = %q\n", url, g)
+	}
+	b.WriteString("}\n")
+	out, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile("genesis_data.go", out, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func get(url string) ([]byte, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %v: %v", url, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
@@ -0,0 +1,204 @@
+package indyclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mr-tron/base58"
+)
+
+// NewPoolFromBytes is like NewPool, for genesis transactions already in
+// memory. Use it to connect to private Indy networks, whose genesis is
+// usually distributed as a pool_transactions_genesis file.
+func NewPoolFromBytes(genesis []byte, opts ...Option) (*Pool, error) {
+	return NewPool(bytes.NewReader(genesis), opts...)
+}
+
+// WriteGenesis writes the pool ledger transactions the Pool knows, from its
+// genesis and the refreshes since, to w in the format of
+// pool_transactions_genesis files. Saving the genesis after Refresh lets
+// the next NewPool start with the current validators.
+func (p *Pool) WriteGenesis(w io.Writer) error {
+	p.mu.Lock()
+	txns := append([]*Block(nil), p.poolTxns...)
+	p.mu.Unlock()
+	for _, b := range txns {
+		line, err := json.Marshal(b)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(append(line, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarshalJSON encodes b in the layout of ledger transactions, as found in
+// genesis files and GET_TXN replies, so that decoded transactions can be
+// written back.
+func (b Block) MarshalJSON() ([]byte, error) {
+	data := b.Txn.Data.Raw
+	if data == nil {
+		var err error
+		data, err = json.Marshal(struct {
+			Data json.RawMessage `json:"data,omitempty"`
+			Dest string          `json:"dest,omitempty"`
+		}{b.Txn.Data.Data, b.Txn.Data.Dest})
+		if err != nil {
+			return nil, err
+		}
+	}
+	metadata := b.Txn.Metadata
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	reqSignature := b.ReqSignature
+	if reqSignature == nil {
+		reqSignature = json.RawMessage("{}")
+	}
+
+	type txn struct {
+		Data            json.RawMessage        `json:"data"`
+		Metadata        map[string]interface{} `json:"metadata"`
+		ProtocolVersion int                    `json:"protocolVersion,omitempty"`
+		Type            string                 `json:"type"`
+	}
+	type txnMetadata struct {
+		SeqNo   int    `json:"seqNo,omitempty"`
+		TxnId   string `json:"txnId,omitempty"`
+		TxnTime int64  `json:"txnTime,omitempty"`
+	}
+	return json.Marshal(struct {
+		ReqSignature json.RawMessage `json:"reqSignature"`
+		Txn          txn             `json:"txn"`
+		TxnMetadata  txnMetadata     `json:"txnMetadata"`
+		Ver          string          `json:"ver,omitempty"`
+	}{
+		ReqSignature: reqSignature,
+		Txn: txn{
+			Data:            data,
+			Metadata:        metadata,
+			ProtocolVersion: b.Txn.ProtocolVersion,
+			Type:            strconv.Itoa(int(b.Txn.Type)),
+		},
+		TxnMetadata: txnMetadata(b.TxnMetadata),
+		Ver:         b.Ver,
+	})
+}
+
+// validatorFromTxn checks the NODE transaction b and returns the validator
+// it describes.
+func validatorFromTxn(b *Block) (*Validator, error) {
+	var n TxnNode
+	err := decodeData(b.Txn.Data.Data, &n)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode TxnNode: %v", err)
+	}
+	if n.Alias == "" {
+		return nil, errors.New("node has no alias")
+	}
+
+	vk, err := base58.Decode(b.Txn.Data.Dest)
+	if err != nil {
+		return nil, fmt.Errorf("node %v: invalid verkey: %v", n.Alias, err)
+	}
+	if len(vk) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("node %v: verkey is not 32 bytes long", n.Alias)
+	}
+
+	if n.ClientIP == "" || strings.ContainsAny(n.ClientIP, " /") {
+		return nil, fmt.Errorf("node %v: invalid client_ip %q", n.Alias, n.ClientIP)
+	}
+	port, err := strconv.Atoi(string(n.ClientPort))
+	if err != nil || port < 1 || port > 65535 {
+		return nil, fmt.Errorf("node %v: invalid client_port %q", n.Alias, n.ClientPort)
+	}
+
+	v := &Validator{
+		Alias:     n.Alias,
+		VerKey:    b.Txn.Data.Dest,
+		Address:   net.JoinHostPort(n.ClientIP, string(n.ClientPort)),
+		Services:  n.Services,
+		BlsKey:    n.BlsKey,
+		BlsKeyPop: n.BlsKeyPop,
+	}
+	if n.NodeIP != "" {
+		v.NodeAddress = net.JoinHostPort(n.NodeIP, string(n.NodePort))
+	}
+	return v, nil
+}
+
+// ValidatorInfo describes a validator of the pool, as parsed from the
+// genesis transactions.
+type ValidatorInfo struct {
+	Alias         string   `json:"alias"`
+	ClientAddress string   `json:"clientAddress"`
+	NodeAddress   string   `json:"nodeAddress,omitempty"`
+	VerKey        string   `json:"verkey"`
+	BlsKey        string   `json:"blskey,omitempty"`
+	Services      []string `json:"services"`
+}
+
+// ValidatorTable returns everything the Pool knows about its validators,
+// for diagnostics. The result is a copy which the caller may modify.
+func (p *Pool) ValidatorTable() []ValidatorInfo {
+	vs := p.validators()
+	table := make([]ValidatorInfo, len(vs))
+	for i, v := range vs {
+		table[i] = ValidatorInfo{
+			Alias:         v.Alias,
+			ClientAddress: v.Address,
+			NodeAddress:   v.NodeAddress,
+			VerKey:        v.VerKey,
+			BlsKey:        v.BlsKey,
+			Services:      append([]string(nil), v.Services...),
+		}
+	}
+	return table
+}
+
+// CheckReachable dials the client port of every validator and returns an
+// error naming those which could not be reached within timeout, or before
+// ctx is done. It is meant
+// to catch genesis files pointing at wrong or firewalled addresses early; it
+// does not check that the validators speak the Indy protocol.
+func (p *Pool) CheckReachable(ctx context.Context, timeout time.Duration) error {
+	type result struct {
+		alias string
+		err   error
+	}
+	vs := p.validators()
+	results := make(chan result)
+	for _, v := range vs {
+		go func(v Validator) {
+			d := net.Dialer{Timeout: timeout}
+			c, err := d.DialContext(ctx, "tcp", v.Address)
+			if err == nil {
+				c.Close()
+			}
+			results <- result{v.Alias, err}
+		}(v)
+	}
+
+	var failed []string
+	for range vs {
+		r := <-results
+		if r.err != nil {
+			failed = append(failed, fmt.Sprintf("%v (%v)", r.alias, r.err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("unreachable validators: %v", strings.Join(failed, ", "))
+	}
+	return nil
+}
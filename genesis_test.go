@@ -0,0 +1,123 @@
+package indyclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testGenesis returns genesis transactions for a private network whose
+// validators listen on the given client addresses.
+func testGenesis(t *testing.T, addrs ...string) []byte {
+	var lines []string
+	for i, addr := range addrs {
+		host, port, err := net.SplitHostPort(addr)
+		require.NoError(t, err)
+		seed := fmt.Sprintf("%032d", i+1)
+		_, verkey, _, err := KeypairFromSeed([]byte(seed))
+		require.NoError(t, err)
+		lines = append(lines, fmt.Sprintf(`{"reqSignature":{},"txn":{"data":{"data":{"alias":"Node%v","client_ip":"%v","client_port":%v,"node_ip":"%v","node_port":9701,"services":["VALIDATOR"],"blskey":"bls%v"},"dest":"%v"},"metadata":{"from":"Th7MpTaRZVRYnPiabds81Y"},"type":"0"},"txnMetadata":{"seqNo":%v,"txnId":"%x"},"ver":"1"}`,
+			i+1, host, port, host, i+1, verkey, i+1, seed))
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+func TestNewPoolFromBytes(t *testing.T) {
+	g := testGenesis(t, "10.0.0.1:9702", "10.0.0.2:9702", "node3.example.com:9702", "[::1]:9702")
+	pool, err := NewPoolFromBytes(g)
+	require.NoError(t, err)
+	require.Len(t, pool.Validators, 4)
+	require.Equal(t, "Node1", pool.Validators[0].Alias)
+	require.Equal(t, "10.0.0.1:9702", pool.Validators[0].Address)
+	require.Equal(t, "node3.example.com:9702", pool.Validators[2].Address)
+	require.Equal(t, "[::1]:9702", pool.Validators[3].Address)
+
+	table := pool.ValidatorTable()
+	require.Len(t, table, 4)
+	require.Equal(t, ValidatorInfo{
+		Alias:         "Node1",
+		ClientAddress: "10.0.0.1:9702",
+		NodeAddress:   "10.0.0.1:9701",
+		VerKey:        pool.Validators[0].VerKey,
+		BlsKey:        "bls1",
+		Services:      []string{"VALIDATOR"},
+	}, table[0])
+	table[0].Services[0] = "changed"
+	require.Equal(t, "VALIDATOR", pool.Validators[0].Services[0])
+
+	// Ports given as strings are accepted too.
+	g = []byte(strings.Replace(string(g), `"client_port":9702`, `"client_port":"9702"`, 1))
+	pool, err = NewPoolFromBytes(g)
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.1:9702", pool.Validators[0].Address)
+}
+
+func TestNewPoolFromBytes_Malformed(t *testing.T) {
+	g := string(testGenesis(t, "10.0.0.1:9702"))
+	_, verkey, did, err := KeypairFromSeed([]byte(fmt.Sprintf("%032d", 1)))
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name, genesis, err string
+	}{
+		{"empty", "", "no validators"},
+		{"syntax", g + "\n{", "failed to decode genesis"},
+		{"verkey", strings.Replace(g, `"dest":"`, `"dest":"0`, 1), "invalid verkey"},
+		{"short verkey", strings.Replace(g, verkey, did, 1), "not 32 bytes"},
+		{"ip", strings.Replace(g, `"client_ip":"10.0.0.1"`, `"client_ip":""`, 1), "invalid client_ip"},
+		{"port", strings.Replace(g, `"client_port":9702`, `"client_port":0`, 1), "invalid client_port"},
+		{"alias", strings.Replace(g, `"alias":"Node1"`, `"alias":""`, 1), "no alias"},
+	} {
+		_, err := NewPoolFromBytes([]byte(tc.genesis))
+		require.Error(t, err, tc.name)
+		require.Contains(t, err.Error(), tc.err, tc.name)
+	}
+}
+
+func TestPool_CheckReachable(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	// Grab a free port and close it again to get an unreachable address.
+	dead, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	deadAddr := dead.Addr().String()
+	dead.Close()
+
+	pool, err := NewPoolFromBytes(testGenesis(t, l.Addr().String()))
+	require.NoError(t, err)
+	require.NoError(t, pool.CheckReachable(context.Background(), time.Second))
+
+	pool, err = NewPoolFromBytes(testGenesis(t, l.Addr().String(), deadAddr))
+	require.NoError(t, err)
+	err = pool.CheckReachable(context.Background(), time.Second)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Node2")
+	require.NotContains(t, err.Error(), "Node1")
+}
+
+func TestPool_WriteGenesis(t *testing.T) {
+	g := testGenesis(t, "10.0.0.1:9702", "10.0.0.2:9702")
+	pool, err := NewPoolFromBytes(g)
+	require.NoError(t, err)
+
+	var b bytes.Buffer
+	require.NoError(t, pool.WriteGenesis(&b))
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	require.Len(t, lines, 2)
+	for i, line := range strings.Split(string(g), "\n") {
+		require.JSONEq(t, line, lines[i])
+	}
+
+	again, err := NewPoolFromBytes(b.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, pool.Validators, again.Validators)
+	require.Equal(t, pool.poolSize, again.poolSize)
+}
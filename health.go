@@ -0,0 +1,124 @@
+package indyclient
+
+import (
+	"context"
+	"time"
+)
+
+// ValidatorHealth is the state of a validator as probed by Pool.Health.
+type ValidatorHealth struct {
+	Alias      string
+	Latency    time.Duration // time to answer a GET_TXN
+	LedgerSize int           // size of the domain ledger
+	RootHash   string        // Merkle root hash of the domain ledger, in base58
+	StateTime  time.Time     // time of the domain state signed by the pool, zero if unknown
+	InSync     bool          // whether the ledger agrees with that of the pool
+	Err        error         // why the validator could not be probed
+}
+
+// PoolHealth is the report of Pool.Health.
+type PoolHealth struct {
+	Validators []ValidatorHealth // in the order of Pool.Validators
+	// LedgerSize and RootHash are those of the domain ledger reported by
+	// f+1 validators, where f is the number of faulty validators the pool
+	// tolerates, or zero if not enough validators agree.
+	LedgerSize int
+	RootHash   string
+}
+
+// Health probes every validator of the pool, each over its own connection:
+// it asks for the size and Merkle root hash of the domain ledger, timing
+// the reply, and for a state proof telling the time of the validator's
+// latest domain state signed by the pool. Validators in sync report the
+// ledger which f+1 validators agree on. Unlike Ready, Health waits for all
+// validators to answer or fail, or ctx to be done.
+func (p *Pool) Health(ctx context.Context) *PoolHealth {
+	vs := p.validators()
+	index := make(map[string]int, len(vs))
+	h := &PoolHealth{Validators: make([]ValidatorHealth, len(vs))}
+	for i, v := range vs {
+		index[v.Alias] = i
+		h.Validators[i] = ValidatorHealth{Alias: v.Alias}
+	}
+	answered := make(map[string]bool, len(vs))
+
+	p.fanOut(ctx, vs, func(ctx context.Context, v Validator) (interface{}, error) {
+		return p.probeHealth(ctx, v)
+	}, func(v Validator, val interface{}, err error) bool {
+		answered[v.Alias] = true
+		if err == nil {
+			h.Validators[index[v.Alias]] = *val.(*ValidatorHealth)
+		} else {
+			h.Validators[index[v.Alias]].Err = err
+		}
+		return false
+	})
+	for i := range h.Validators {
+		if !answered[h.Validators[i].Alias] {
+			h.Validators[i].Err = ctx.Err()
+		}
+	}
+
+	type ledgerState struct {
+		size int
+		root string
+	}
+	need := faulty(vs) + 1
+	votes := make(map[ledgerState]int)
+	for _, vh := range h.Validators {
+		if vh.Err == nil && vh.RootHash != "" {
+			s := ledgerState{vh.LedgerSize, vh.RootHash}
+			votes[s]++
+			if votes[s] == need {
+				h.LedgerSize, h.RootHash = s.size, s.root
+			}
+		}
+	}
+	for i := range h.Validators {
+		vh := &h.Validators[i]
+		vh.InSync = h.RootHash != "" && vh.Err == nil &&
+			vh.LedgerSize == h.LedgerSize && vh.RootHash == h.RootHash
+	}
+	return h
+}
+
+// probeHealth returns the health of the single validator v.
+func (p *Pool) probeHealth(ctx context.Context, v Validator) (*ValidatorHealth, error) {
+	s, err := p.dial(ctx, v)
+	if err != nil {
+		return nil, err
+	}
+	c := p.newConn(s, v)
+	defer c.close()
+
+	vh := &ValidatorHealth{Alias: v.Alias}
+	reqId, m := p.getTxnRequest(DomainLedger, 1)
+	start := time.Now()
+	r, err := p.exchangeWith(ctx, c, reqId, m)
+	if err != nil {
+		return nil, err
+	}
+	vh.Latency = time.Since(start)
+	if err := checkReply(r); err != nil {
+		return nil, err
+	}
+	var proof struct {
+		LedgerSize int    `json:"ledgerSize"`
+		RootHash   string `json:"rootHash"`
+	}
+	if err := decodeData(resultData(r), &proof); err != nil && err != ErrNoData {
+		return nil, err
+	}
+	vh.LedgerSize, vh.RootHash = proof.LedgerSize, proof.RootHash
+
+	// The state proof of the absence of a NYM is signed like any other.
+	reqId, m = p.newRequest(getNymOp{Type: idGetNym, Dest: defaultIdent})
+	r, err = p.exchangeWith(ctx, c, reqId, m)
+	if err != nil {
+		return nil, err
+	}
+	if ts, ok := r.StateTimestamp(); ok {
+		vh.StateTime = time.Unix(ts, 0)
+	}
+	return vh, nil
+}
@@ -0,0 +1,62 @@
+package indyclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// healthValidator is a fakeValidator reporting a domain ledger of size with
+// root, and a domain state signed at ts.
+func healthValidator(size int, root string, ts int64) fakeValidator {
+	return func(m []byte) [][]byte {
+		var req struct {
+			ReqId     seqNo `json:"reqId"`
+			Operation struct {
+				Type protoId `json:"type,string"`
+			} `json:"operation"`
+		}
+		json.Unmarshal(m, &req)
+		if req.Operation.Type == idGetTxn {
+			return [][]byte{[]byte(fmt.Sprintf(`{"op":"REPLY","result":{"type":"3","reqId":%v,"seqNo":1,`+
+				`"data":{"txn":{"type":"1"},"txnMetadata":{"seqNo":1},"ledgerSize":%v,"rootHash":%q}}}`,
+				req.ReqId, size, root))}
+		}
+		return [][]byte{[]byte(fmt.Sprintf(`{"op":"REPLY","result":{"type":"105","reqId":%v,"data":null,`+
+			`"state_proof":{"multi_signature":{"value":{"ledger_id":1,"timestamp":%v}}}}}`, req.ReqId, ts))}
+	}
+}
+
+func TestPool_Health(t *testing.T) {
+	now := time.Now().Unix()
+	pool := testPool(t, fakeTransport{
+		"Node1": healthValidator(10, "root10", now),
+		"Node2": healthValidator(10, "root10", now),
+		"Node3": healthValidator(9, "root9", now-600),
+	})
+
+	h := pool.Health(context.Background())
+	require.Equal(t, 10, h.LedgerSize)
+	require.Equal(t, "root10", h.RootHash)
+	require.Len(t, h.Validators, 4)
+
+	require.Equal(t, "Node1", h.Validators[0].Alias)
+	require.NoError(t, h.Validators[0].Err)
+	require.True(t, h.Validators[0].InSync)
+	require.Equal(t, now, h.Validators[0].StateTime.Unix())
+
+	require.True(t, h.Validators[1].InSync)
+
+	require.NoError(t, h.Validators[2].Err)
+	require.False(t, h.Validators[2].InSync)
+	require.Equal(t, 9, h.Validators[2].LedgerSize)
+	require.Equal(t, now-600, h.Validators[2].StateTime.Unix())
+
+	require.Equal(t, "Node4", h.Validators[3].Alias)
+	require.Error(t, h.Validators[3].Err)
+	require.False(t, h.Validators[3].InSync)
+}
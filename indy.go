@@ -1,55 +1,124 @@
+// Package indyclient is a client for the ledgers of Hyperledger Indy pools,
+// talking to the validators directly, without libindy.
+//
+// Replies are trusted to come from the validator which sent them, unless
+// checked otherwise. Consensus reads (WithReadQuorum) only accept results
+// which f+1 validators agree on. WithStateProof also checks the state proof
+// of a reply and the BLS multi-signature of the pool over its root, with
+// the pairing used by the validators or the BLSVerifier given with
+// WithBLSVerifier.
 package indyclient
 
 import (
+	"context"
 	"crypto/ed25519"
-	"encoding/base64"
 	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"math/big"
-	"net"
-	"net/url"
-	"os"
 	"strings"
 	"sync"
+	"time"
 
-	"github.com/mr-tron/base58"
-	"github.com/pebbe/zmq4"
 	"golang.org/x/crypto/curve25519"
 )
 
+// A Pool is a client of an Indy validator pool. It is safe for concurrent
+// use: concurrent requests share the connection to the current validator
+// and their replies are matched to them by reqId.
 type Pool struct {
-	Validators    []Validator
-	s             *zmq4.Socket // the currently open socket
-	retryConn     int
-	nextValidator int
-	log           *log.Logger
+	Validators     []Validator
+	transport      Transport
+	conns          []*conn        // the open connections, shared by all requests
+	dialing        map[string]int // connection attempts under way, by alias
+	maxConns       int
+	backoff        backoff
+	connectTimeout time.Duration
+	replyTimeout   time.Duration
+	preferObserver bool
+	nextReqId      func() seqNo
+	protoVersion   int32 // protocolVersion of requests, accessed atomically
+	maxParallel    int
+	budgetAttempts int
+	budgetTime     time.Duration
+	retryTimeouts  bool
+	retryReqNack   bool
+	taaAcceptance  *TAAAcceptance
+	blsVerifier    BLSVerifier
+	cache          TxnCache
+	consistency    Consistency
+	nodes          []*nodeState // merged NODE transactions of the pool ledger
+	poolTxns       []*Block     // the pool ledger transactions applied
+	poolSize       int          // number of pool ledger transactions applied
+	nextValidator  int
+	log            Logger
+	metrics        Metrics
+	frameHook      func(Frame)
+	identifier     string        // of unsigned requests
+	done           chan struct{} // closed by Close
+	closeOnce      sync.Once
+	stats          map[string]*ValidatorStats
+	freshness      map[LedgerId]int64 // latest multi-signed state timestamps
+	statsMu        sync.Mutex         // guards stats and freshness
+	mu             sync.Mutex         // guards conns, dialing, the validators and the TAA acceptance
 }
 
 type Validator struct {
-	Alias   string
-	VerKey  string
-	Address string // client_ip:client_port
+	Alias       string
+	VerKey      string
+	Address     string   // client_ip:client_port
+	Services    []string // VALIDATOR for consensus nodes, nil if unknown
+	NodeAddress string   // node_ip:node_port, used between validators
+	BlsKey      string
+	BlsKeyPop   string
+}
+
+// IsObserver reports whether the node serves reads without taking part in
+// consensus, which is the case for nodes whose services are known and do not
+// include VALIDATOR.
+func (v *Validator) IsObserver() bool {
+	if v.Services == nil {
+		return false
+	}
+	for _, s := range v.Services {
+		if s == "VALIDATOR" {
+			return false
+		}
+	}
+	return true
 }
 
 type Block struct {
-	Txn         Txn
-	TxnMetadata TxnMetadata
-	Ver         int
+	Txn          Txn
+	TxnMetadata  TxnMetadata
+	Ver          string
+	ReqSignature json.RawMessage
 }
 
 type Txn struct {
-	Data     DataDest
-	Metadata map[string]string
-	Type     protoId
+	Data            DataDest
+	Metadata        map[string]interface{}
+	Type            protoId
+	ProtocolVersion int
 }
 
 type DataDest struct {
 	Data json.RawMessage
 	Dest string
+	// Raw is the complete data of the transaction. Most transaction types
+	// hold more fields than Data and Dest.
+	Raw json.RawMessage `json:"-"`
+}
+
+func (d *DataDest) UnmarshalJSON(b []byte) error {
+	type plain DataDest
+	if err := json.Unmarshal(b, (*plain)(d)); err != nil {
+		return err
+	}
+	d.Raw = append(json.RawMessage(nil), b...)
+	return nil
 }
 
 type TxnData struct {
@@ -58,69 +127,140 @@ type TxnData struct {
 }
 
 type TxnMetadata struct {
-	SeqNo int
-	TxnId string
+	SeqNo   int
+	TxnId   string
+	TxnTime int64
 }
 
 type protoId int
 
+// UnmarshalJSON accepts both the quoted form found in ledger transactions
+// ("type":"0") and a bare number.
+func (t *protoId) UnmarshalJSON(b []byte) error {
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return err
+	}
+	i, err := n.Int64()
+	if err != nil {
+		return err
+	}
+	*t = protoId(i)
+	return nil
+}
+
 // Constants from the indy-node specs.
 const (
-	idNode   protoId = 0
-	idGetTxn         = 3
+	idNode             protoId = 0
+	idNym              protoId = 1
+	idAttrib           protoId = 100
+	idPoolUpgrade      protoId = 109
+	idNodeUpgrade      protoId = 110
+	idPoolConfig       protoId = 111
+	idValidatorInfo    protoId = 119
+	idGetTxn                   = 3
+	idTAA              protoId = 4
+	idTAAAML           protoId = 5
+	idGetTAA           protoId = 6
+	idGetTAAAML        protoId = 7
+	idTAADisable       protoId = 8
+	idLedgersFreeze    protoId = 9
+	idGetFrozenLedgers protoId = 10
+	idGetAttr          protoId = 104
+	idGetNym           protoId = 105
+	idSchema           protoId = 101
+	idClaimDef         protoId = 102
+	idGetSchema        protoId = 107
+	idGetClaimDef      protoId = 108
+	idAuthRule         protoId = 120
+	idGetAuthRule      protoId = 121
+	idAuthRules        protoId = 122
+	idRevocRegDef      protoId = 113
+	idRevocRegEntry    protoId = 114
+	idGetRevocRegDef   protoId = 115
+	idGetRevocReg      protoId = 116
+	idGetRevocRegDelta protoId = 117
+
+	idJSONLDContext           protoId = 200
+	idRichSchema              protoId = 201
+	idRichSchemaEncoding      protoId = 202
+	idRichSchemaMapping       protoId = 203
+	idRichSchemaCredDef       protoId = 204
+	idRichSchemaPresDef       protoId = 205
+	idGetRichSchemaById       protoId = 300
+	idGetRichSchemaByMetadata protoId = 301
 )
 
+// LedgerId identifies a ledger of the pool. Plugins of indy-node add
+// ledgers of their own, which RegisterLedger names.
 type LedgerId int
 
 const (
 	PoolLedger   LedgerId = 0
-	DomainLedger LedgerId = 0
+	DomainLedger LedgerId = 1
+	ConfigLedger LedgerId = 2
+	AuditLedger  LedgerId = 3
 )
 
 type TxnNode struct {
 	Alias      string
-	ClientIP   string `json:"client_ip"`
-	ClientPort string `json:"client_port",string`
+	ClientIP   string      `json:"client_ip"`
+	ClientPort json.Number `json:"client_port"` // a number or a quoted number
+	NodeIP     string      `json:"node_ip"`
+	NodePort   json.Number `json:"node_port"`
+	Services   []string    `json:"services"`
+	BlsKey     string      `json:"blskey"`
+	BlsKeyPop  string      `json:"blskey_pop"`
 }
 
 // NewPool constructs a new Pool, which will follow the ledgers maintained by
-// the validators in the genesis transactions read from genesis.
-func NewPool(genesis io.Reader) (*Pool, error) {
+// the validators in the genesis transactions read from genesis. opts
+// configure the Pool; the options are the functions of this package
+// returning an Option.
+func NewPool(genesis io.Reader, opts ...Option) (*Pool, error) {
 	p := new(Pool)
-	p.retryConn = 3
-	p.log = log.New(os.Stderr, "", log.LstdFlags)
+	p.backoff = backoff{base: defaultBackoffBase, max: defaultBackoffMax}
+	p.transport = defaultTransport
+	p.maxConns = 1
+	p.log = nopLogger{}
+	p.metrics = nopMetrics{}
+	p.nextReqId = seqGetNext
+	p.identifier = defaultIdent
+	p.done = make(chan struct{})
+	p.protoVersion = defaultProtocolVersion
+	p.budgetAttempts = defaultBudgetAttempts
+	p.budgetTime = defaultBudgetTime
+	p.retryTimeouts = true
+	p.blsVerifier = indyCryptoVerifier{}
+	for _, opt := range opts {
+		opt(p)
+	}
 
 	dec := json.NewDecoder(genesis)
 	for {
 		var b Block
-		if err := dec.Decode(&b); err == io.EOF {
+		err := dec.Decode(&b)
+		if err == io.EOF {
 			break
 		}
-		switch b.Txn.Type {
-		case idNode:
-			var n TxnNode
-			err := json.Unmarshal(b.Txn.Data.Data, &n)
-			if err != nil {
-				log.Printf("failed to decode TxnNode: %v", err)
-				continue
-			}
-			p.Validators = append(p.Validators, Validator{
-				Alias:   n.Alias,
-				VerKey:  b.Txn.Data.Dest,
-				Address: net.JoinHostPort(n.ClientIP, n.ClientPort),
-			})
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode genesis: %v", err)
+		}
+		if err := p.applyPoolTxn(&b); err != nil {
+			return nil, fmt.Errorf("genesis transaction %v: %v", b.TxnMetadata.SeqNo, err)
 		}
 	}
+	vs, err := p.nodeValidators(false)
+	if err != nil {
+		return nil, fmt.Errorf("genesis %v", err)
+	}
+	if len(vs) == 0 {
+		return nil, errors.New("no validators found in genesis")
+	}
+	p.Validators = vs
 	return p, nil
 }
 
-type getTxn struct {
-	Operation       getTxnOp `json:"operation"`
-	Identifier      string   `json:"identifier"`
-	ReqId           seqNo    `json:"reqId"`
-	ProtocolVersion int      `json:"protocolVersion"`
-}
-
 type getTxnOp struct {
 	Type     protoId `json:"type,string"`
 	Data     int     `json:"data"`
@@ -131,158 +271,466 @@ type Reply struct {
 	Identifier string `json:"identifier"`
 	Op         string `json:"op"`
 	ReqId      seqNo  `json:"reqId"`
+	Reason     string `json:"reason,omitempty"` // why a request was refused
 	Result     json.RawMessage
+
+	// Verified reports whether the client checked a proof of the reply:
+	// the audit path of GET_TXN replies, to the root hash the validator
+	// sent, or the state proof and its multi-signature for reads using
+	// WithStateProof.
+	Verified bool `json:"-"`
+	// Node is the alias of the validator which sent the reply, and
+	// Address its client address.
+	Node    string `json:"-"`
+	Address string `json:"-"`
+
+	// ledgerSize and rootHash are those of the ledger which the audit path
+	// of a Verified GET_TXN reply leads to.
+	ledgerSize int
+	rootHash   []byte
 }
 
-type Did struct {
-	Method string
-	Id     string
+type stateProofResult struct {
+	StateProof *struct {
+		MultiSignature *struct {
+			Value struct {
+				Timestamp int64
+				LedgerId  LedgerId `json:"ledger_id"`
+			}
+		} `json:"multi_signature"`
+	} `json:"state_proof"`
 }
 
-func DidParse(didStr string) (*Did, error) {
-	u, err := url.Parse(didStr)
-	if err != nil {
-		return nil, err
+// StateTimestamp returns the timestamp, in seconds since the epoch, of the
+// state which the replying validator used to answer, as attested by the
+// multi-signature of its state proof. It tells how fresh the reply is even
+// without verifying the proof. The boolean is false if the reply carries no
+// state proof.
+func (r *Reply) StateTimestamp() (int64, bool) {
+	var res stateProofResult
+	if !r.decodeStateProof(&res) {
+		return 0, false
+	}
+	return res.StateProof.MultiSignature.Value.Timestamp, true
+}
+
+// decodeStateProof decodes the state proof of r into res, and reports
+// whether r has a multi-signed one.
+func (r *Reply) decodeStateProof(res *stateProofResult) bool {
+	if json.Unmarshal(r.Result, res) != nil {
+		return false
+	}
+	return res.StateProof != nil && res.StateProof.MultiSignature != nil
+}
+
+// timestamp returns the time, in seconds since the epoch, up to which the
+// replying validator's state is known to be current: the StateTimestamp if
+// there is one, or else the txnTime of the returned transaction.
+func (r *Reply) timestamp() (int64, bool) {
+	if ts, ok := r.StateTimestamp(); ok {
+		return ts, true
 	}
-	if u.Scheme != "did" {
-		return nil, errors.New("not a DID")
+	var res struct {
+		TxnTime int64
 	}
-	if u.Opaque == "" {
-		return nil, errors.New("no DID method found")
+	if json.Unmarshal(r.Result, &res) != nil {
+		return 0, false
 	}
-	m := strings.SplitN(u.Opaque, ":", 2)
-	if m[0] != "sov" {
-		return nil, errors.New("not a sov DID")
+	if res.TxnTime != 0 {
+		return res.TxnTime, true
 	}
-	if len(m) < 2 {
-		return nil, errors.New("no ID found")
+	var data struct {
+		TxnMetadata TxnMetadata
 	}
-	return &Did{
-		Method: "sov",
-		Id:     m[1],
-	}, nil
+	if r.DecodeResult(&data) == nil && data.TxnMetadata.TxnTime != 0 {
+		return data.TxnMetadata.TxnTime, true
+	}
+	return 0, false
 }
 
+// defaultIdent is the identifier of unsigned requests, unless set with
+// WithIdentifier.
 const defaultIdent = "Go1ndyC1ient1111111111"
 
-func (p *Pool) getConnection() (s *zmq4.Socket, err error) {
-	if p.s != nil {
-		return p.s, nil
+// ErrAllExcluded is returned when a request excludes every validator of the
+// Pool.
+var ErrAllExcluded = errors.New("all validators are excluded")
+
+// getConnection returns a connection to a validator whose alias is not in
+// exclude. Requests are spread over the open connections, and more are
+// opened as long as there are fewer than p.maxConns. New connections are
+// paid for from b, unless an open one can be used instead. At most one
+// connection attempt is made, without holding p.mu, so that other requests
+// keep using the open connections meanwhile.
+func (p *Pool) getConnection(ctx context.Context, exclude map[string]bool, b *budget) (*conn, error) {
+	p.mu.Lock()
+	best, v, err := p.pickConnection(exclude, b)
+	p.mu.Unlock()
+	if v == nil {
+		return best, err
 	}
 
-	for i := 0; i < p.retryConn; i++ {
-		s, err = p.newConnection()
-		if err == nil {
-			p.s = s
-			return s, nil
+	c, err := p.newConnection(ctx, *v)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.dialing[v.Alias]--; p.dialing[v.Alias] == 0 {
+		delete(p.dialing, v.Alias)
+	}
+	if err != nil {
+		if best != nil {
+			return best, nil
 		}
-		p.log.Print("failed connection, retrying:", err)
+		return nil, err
 	}
-
-	p.log.Print("failed all tries")
-	s = nil
-	return
+	if p.closed() {
+		c.close()
+		return nil, ErrClosed
+	}
+	p.conns = append(p.conns, c)
+	return c, nil
 }
 
-func (p *Pool) newConnection() (*zmq4.Socket, error) {
-	validator := p.Validators[p.nextValidator]
-	p.nextValidator = (p.nextValidator + 1) % len(p.Validators)
-
-	s, err := zmq4.NewSocket(zmq4.DEALER)
-	if err != nil {
-		return nil, err
+// pickConnection returns the open connection getConnection should use, or
+// the validator it should connect to, which is then marked as being dialed,
+// along with the open connection to fall back to if that fails. p.mu must be
+// held.
+func (p *Pool) pickConnection(exclude map[string]bool, b *budget) (*conn, *Validator, error) {
+	if p.closed() {
+		return nil, nil, ErrClosed
+	}
+	// Forget the connections which failed.
+	open := p.conns[:0]
+	for _, c := range p.conns {
+		if c.failure() == nil {
+			open = append(open, c)
+		} else {
+			c.close()
+		}
 	}
+	p.conns = open
 
-	pub, sec, err := zmq4.NewCurveKeypair()
-	if err != nil {
-		return nil, err
+	var best *conn
+	for _, c := range p.conns {
+		if !exclude[c.alias] && (best == nil || c.load() < best.load()) {
+			best = c
+		}
 	}
-	s.SetIdentity(base64.StdEncoding.EncodeToString([]byte(pub)))
-	err = s.SetCurvePublickey(pub)
-	if err != nil {
-		return nil, err
+	n := len(p.conns)
+	for _, d := range p.dialing {
+		n += d
 	}
-	err = s.SetCurveSecretkey(sec)
-	if err != nil {
-		return nil, err
+	full := n >= p.maxConns
+	if best != nil && (best.load() == 0 || full) {
+		return best, nil, nil
+	}
+	if best == nil && full && len(p.conns) > 0 {
+		// Make room for a validator which is not excluded.
+		p.conns[0].close()
+		p.conns = p.conns[1:]
 	}
 
-	vk, err := base58.Decode(validator.VerKey)
-	if err != nil {
-		return nil, err
+	skip := make(map[string]bool)
+	for a := range exclude {
+		skip[a] = true
 	}
-	srv := ed25519PublicKeyToCurve25519(ed25519.PublicKey(vk))
-	err = s.SetCurveServerkey(zmq4.Z85encode(string(srv)))
-	if err != nil {
-		return nil, err
+	for _, c := range p.conns {
+		skip[c.alias] = true
+	}
+	if best == nil && !b.take() {
+		return nil, nil, b.err()
+	}
+	// Prefer validators which other requests are not already connecting
+	// to, but do not wait for them.
+	dialing := make(map[string]bool)
+	for a := range skip {
+		dialing[a] = true
+	}
+	for a := range p.dialing {
+		dialing[a] = true
+	}
+	v, err := p.nextValidatorFor(dialing)
+	if err == ErrAllExcluded {
+		v, err = p.nextValidatorFor(skip)
+	}
+	if err != nil && best != nil {
+		return best, nil, nil
 	}
-
-	err = s.Connect("tcp://" + validator.Address)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return s, nil
+	if p.dialing == nil {
+		p.dialing = make(map[string]int)
+	}
+	p.dialing[v.Alias]++
+	return best, &v, nil
 }
 
-func (p *Pool) GetTransaction(ledger LedgerId, seqNo int) (*Reply, error) {
-	reqId := seqGetNext()
-	tx := getTxn{
-		Identifier: defaultIdent,
-		ReqId:      reqId,
-		Operation: getTxnOp{
-			Type:     idGetTxn,
-			Data:     seqNo,
-			LedgerID: int(ledger),
-		},
-		ProtocolVersion: 2,
+// nextValidatorFor returns the next validator in round-robin order which is
+// not in exclude. If the Pool prefers observers, they are picked over the
+// other validators whenever one is available.
+func (p *Pool) nextValidatorFor(exclude map[string]bool) (Validator, error) {
+	candidate := func(v *Validator) bool {
+		return !exclude[v.Alias]
+	}
+	if p.preferObserver {
+		for i := range p.Validators {
+			v := &p.Validators[(p.nextValidator+i)%len(p.Validators)]
+			if v.IsObserver() && candidate(v) {
+				candidate = func(v *Validator) bool {
+					return v.IsObserver() && !exclude[v.Alias]
+				}
+				break
+			}
+		}
+	}
+
+	for range p.Validators {
+		v := p.Validators[p.nextValidator]
+		p.nextValidator = (p.nextValidator + 1) % len(p.Validators)
+		if candidate(&v) {
+			return v, nil
+		}
 	}
-	m, _ := json.Marshal(tx)
+	return Validator{}, ErrAllExcluded
+}
 
-	s, err := p.getConnection()
+// newConnection connects to validator.
+func (p *Pool) newConnection(ctx context.Context, validator Validator) (*conn, error) {
+	s, err := p.dial(ctx, validator)
 	if err != nil {
+		p.record(validator.Alias, 0, err)
+		p.log.Log(LevelDebug, "dial failed", "node", validator.Alias, "address", validator.Address, "err", err)
 		return nil, err
 	}
+	p.log.Log(LevelDebug, "connected", "node", validator.Alias, "address", validator.Address)
+	return p.newConn(s, validator), nil
+}
 
-	_, err = s.SendMessageDontwait(m)
+// dial connects to validator with the Transport of the Pool. The CURVE
+// handshake is awaited for at most the connect timeout of the Pool.
+func (p *Pool) dial(ctx context.Context, validator Validator) (Connection, error) {
+	if p.closed() {
+		return nil, ErrClosed
+	}
+	if p.connectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.connectTimeout)
+		defer cancel()
+	}
+	s, err := p.transport.Dial(ctx, validator)
 	if err != nil {
+		p.metrics.DialFailed(validator.Alias, err)
 		return nil, err
 	}
+	p.metrics.Connected(validator.Alias)
+	return s, nil
+}
 
-	in, err := s.RecvMessage(0)
+// ErrInvalidSeqNo is returned for transaction sequence numbers below 1.
+var ErrInvalidSeqNo = errors.New("invalid seqNo: ledger sequence numbers start at 1")
+
+// GetTransaction fetches the transaction with sequence number seqNo from
+// ledger with a GET_TXN request. Sequence numbers are 1-based: the first
+// transaction of a ledger has seqNo 1. GetTxn also decodes the reply.
+// Transactions in the TxnCache of the Pool are returned without asking the
+// pool, with a Reply whose Node is empty.
+//
+// A seqNo past the end of the ledger is not an error: the validator answers
+// with a REPLY whose data is null, which GetTransaction returns as is. Only
+// GetTxn reports it, with ErrTxnNotFound.
+func (p *Pool) GetTransaction(ctx context.Context, ledger LedgerId, seqNo int, opts ...ReadOption) (*Reply, error) {
+	req, err := NewGetTxnRequest(ledger, seqNo)
 	if err != nil {
 		return nil, err
 	}
-	if len(in) != 1 {
-		return nil, errors.New("got wrong amount of input")
+	if p.cache != nil {
+		if r := p.cachedTxn(ledger, seqNo); r != nil {
+			return r, nil
+		}
 	}
-	var r = new(Reply)
-	err = json.Unmarshal([]byte(in[0]), r)
+	r, err := p.read(ctx, req.Operation, opts...)
 	if err != nil {
 		return nil, err
 	}
-	if r.ReqId != tx.ReqId {
-		return nil, errors.New("got answer to another request")
+	r.ledgerSize, r.rootHash, err = verifyAuditPath(r)
+	r.Verified = err == nil
+	if p.cache != nil {
+		p.cacheTxn(ledger, seqNo, r)
 	}
-	if r.Op != "REQACK" {
-		return nil, fmt.Errorf("unexpected reply op: %v", r.Op)
+	return r, nil
+}
+
+// getTxnRequest builds a GET_TXN request and returns its reqId and wire
+// encoding.
+func (p *Pool) getTxnRequest(ledger LedgerId, seqNo int) (seqNo, []byte) {
+	return p.newRequest(getTxnOp{
+		Type:     idGetTxn,
+		Data:     seqNo,
+		LedgerID: int(ledger),
+	})
+}
+
+// connection returns a connection to a validator not in exclude. Failed
+// connection attempts are retried after a backoff, until b is exhausted.
+func (p *Pool) connection(ctx context.Context, exclude map[string]bool, b *budget) (*conn, error) {
+	for attempt := 0; ; attempt++ {
+		c, err := p.getConnection(ctx, exclude, b)
+		if err == nil || err == ErrAllExcluded || err == ErrClosed || b.exhausted() {
+			return c, err
+		}
+		b.failed(err, false)
+		p.log.Log(LevelWarn, "connection failed, retrying", "attempt", attempt+1, "err", err)
+		if err := sleep(ctx, p.backoff.delay(attempt)); err != nil {
+			return nil, b.err()
+		}
+	}
+}
+
+// ErrReplyTimeout is returned when a validator did not answer a request
+// within the reply timeout of the Pool.
+var ErrReplyTimeout = errors.New("validator did not reply in time")
+
+// exchangeWith runs the exchange of the request m on c within the reply
+// timeout of the Pool, and records the alias and the address of the
+// validator as the Node and the Address of the reply.
+func (p *Pool) exchangeWith(ctx context.Context, c *conn, reqId seqNo, m []byte) (*Reply, error) {
+	ectx := ctx
+	if p.replyTimeout > 0 {
+		var cancel context.CancelFunc
+		ectx, cancel = context.WithTimeout(ctx, p.replyTimeout)
+		defer cancel()
 	}
-	in, err = s.RecvMessage(0)
+	start := time.Now()
+	p.metrics.RequestSent(c.alias)
+	r, err := c.exchange(ectx, reqId, m)
 	if err != nil {
+		if ectx.Err() != nil && ctx.Err() == nil {
+			err = fmt.Errorf("%w: %v after %v", ErrReplyTimeout, c.alias, p.replyTimeout)
+		}
+		if ctx.Err() == nil {
+			p.record(c.alias, 0, err)
+			p.metrics.RequestFailed(c.alias, err)
+			p.log.Log(LevelWarn, "request failed", "node", c.alias, "reqId", reqId, "err", err)
+		}
 		return nil, err
 	}
+	latency := time.Since(start)
+	p.record(c.alias, latency, nil)
+	p.metrics.ReplyReceived(c.alias, r.Op, latency)
+	p.observeFreshness(r)
+	p.log.Log(LevelDebug, "reply", "node", c.alias, "reqId", reqId, "op", r.Op, "latency", latency)
+	r.Node = c.alias
+	r.Address = c.address
+	return r, nil
+}
+
+// checkReply returns an error unless r is the REPLY to a successful request.
+// REQNACK and REJECT replies are returned as a *RequestError.
+func checkReply(r *Reply) error {
+	switch r.Op {
+	case "REPLY":
+		return nil
+	case "REQNACK", "REJECT":
+		return &RequestError{Op: r.Op, Node: r.Node, Reason: r.Reason}
+	}
+	return fmt.Errorf("%w: unexpected reply op %v", ErrMalformedReply, r.Op)
+}
+
+// ErrReqNack is matched by the errors of requests which a validator refused
+// with a REQNACK, because they are malformed or invalid, for example
+// because of a wrong signature or an unknown type.
+var ErrReqNack = errors.New("request not acknowledged")
+
+// ErrRejected is matched by the errors of requests which a validator
+// refused with a REJECT, because they are valid but not allowed, for
+// example because the sender lacks the required role.
+var ErrRejected = errors.New("request rejected")
+
+// A RequestError is returned when a validator refused a request with a
+// REQNACK or a REJECT. It matches ErrReqNack or ErrRejected with errors.Is.
+type RequestError struct {
+	Op     string // REQNACK or REJECT
+	Node   string // alias of the validator, if known
+	Reason string // as given by the validator
+}
+
+func (e *RequestError) Error() string {
+	if e.Node == "" {
+		return fmt.Sprintf("request refused with %v: %v", e.Op, e.Reason)
+	}
+	return fmt.Sprintf("request refused by %v with %v: %v", e.Node, e.Op, e.Reason)
+}
+
+// Is reports whether target is ErrReqNack or ErrRejected, matching e.Op.
+func (e *RequestError) Is(target error) bool {
+	switch e.Op {
+	case "REQNACK":
+		return target == ErrReqNack
+	case "REJECT":
+		return target == ErrRejected
+	}
+	return false
+}
+
+// ErrMalformedReply is returned when a validator's reply is not an Indy
+// reply, which usually means a protocol version mismatch or an endpoint
+// which is not an Indy node.
+var ErrMalformedReply = errors.New("malformed reply")
+
+// parseReply decodes the frames of a message received from a validator.
+func parseReply(in []string) (*Reply, error) {
 	if len(in) != 1 {
 		return nil, errors.New("got wrong amount of input")
 	}
-
-	r = new(Reply)
-	err = json.Unmarshal([]byte(in[0]), r)
+	r := new(Reply)
+	err := decodeFrame(in[0], r)
 	if err != nil {
 		return nil, err
 	}
-
+	if r.Op == "" {
+		return nil, fmt.Errorf("%w: missing op in %v", ErrMalformedReply, snippet(in[0]))
+	}
 	return r, nil
 }
 
+// decodeFrame decodes the first JSON value in frame into v, ignoring
+// anything after it: some transports append whitespace or other bytes to
+// the message.
+func decodeFrame(frame string, v interface{}) error {
+	return json.NewDecoder(strings.NewReader(frame)).Decode(v)
+}
+
+// snippet quotes the start of a message for use in error messages.
+func snippet(m string) string {
+	const max = 80
+	if len(m) > max {
+		return fmt.Sprintf("%q...", m[:max])
+	}
+	return fmt.Sprintf("%q", m)
+}
+
+// closeConnections closes all the open connections, so that the next
+// request goes to the next validator. p.mu must be held.
+func (p *Pool) closeConnections() {
+	for _, c := range p.conns {
+		c.close()
+	}
+	p.conns = nil
+}
+
+// dropConnection closes c and removes it from the open connections.
+func (p *Pool) dropConnection(c *conn) {
+	p.mu.Lock()
+	for i := range p.conns {
+		if p.conns[i] == c {
+			p.conns = append(p.conns[:i:i], p.conns[i+1:]...)
+			break
+		}
+	}
+	p.mu.Unlock()
+	c.close()
+}
+
 type seqNo uint32
 
 var seqNext seqNo = 1
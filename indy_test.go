@@ -0,0 +1,175 @@
+package indyclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReply_StateTimestamp(t *testing.T) {
+	r := &Reply{Result: []byte(`{"data":null,"state_proof":{"multi_signature":{"value":{"timestamp":1590000000}}}}`)}
+	ts, ok := r.StateTimestamp()
+	require.True(t, ok)
+	require.Equal(t, int64(1590000000), ts)
+
+	r = &Reply{Result: []byte(`{"data":null,"seqNo":null}`)}
+	_, ok = r.StateTimestamp()
+	require.False(t, ok)
+
+	r = &Reply{Result: []byte(`{"data":{"txnMetadata":{"seqNo":1,"txnTime":1500000000}}}`)}
+	_, ok = r.StateTimestamp()
+	require.False(t, ok)
+	ts, ok = r.timestamp()
+	require.True(t, ok)
+	require.Equal(t, int64(1500000000), ts)
+}
+
+func TestParseReply(t *testing.T) {
+	r, err := parseReply([]string{`{"op":"REQACK","reqId":7,"identifier":"Go1ndyC1ient1111111111"}`})
+	require.NoError(t, err)
+	require.Equal(t, "REQACK", r.Op)
+	require.Equal(t, seqNo(7), r.ReqId)
+
+	_, err = parseReply([]string{`{"reqId":7,"result":{"data":null}}`})
+	require.True(t, errors.Is(err, ErrMalformedReply))
+	require.Contains(t, err.Error(), "missing op")
+	require.Contains(t, err.Error(), `reqId`)
+
+	_, err = parseReply([]string{"a", "b"})
+	require.Error(t, err)
+}
+
+func TestPool_NextValidatorFor(t *testing.T) {
+	p := &Pool{Validators: []Validator{
+		{Alias: "Node1", Services: []string{"VALIDATOR"}},
+		{Alias: "Node2", Services: []string{"VALIDATOR"}},
+		{Alias: "Observer1", Services: []string{}},
+		{Alias: "Observer2", Services: []string{"OBSERVER"}},
+	}}
+
+	var got []string
+	for i := 0; i < 4; i++ {
+		v, err := p.nextValidatorFor(nil)
+		require.NoError(t, err)
+		got = append(got, v.Alias)
+	}
+	require.Equal(t, []string{"Node1", "Node2", "Observer1", "Observer2"}, got)
+
+	p.preferObserver = true
+	got = nil
+	for i := 0; i < 4; i++ {
+		v, err := p.nextValidatorFor(nil)
+		require.NoError(t, err)
+		got = append(got, v.Alias)
+	}
+	require.Equal(t, []string{"Observer1", "Observer2", "Observer1", "Observer2"}, got)
+
+	// Without available observers, validators are used.
+	v, err := p.nextValidatorFor(map[string]bool{"Observer1": true, "Observer2": true})
+	require.NoError(t, err)
+	require.False(t, v.IsObserver())
+
+	_, err = p.nextValidatorFor(map[string]bool{"Node1": true, "Node2": true, "Observer1": true, "Observer2": true})
+	require.Equal(t, ErrAllExcluded, err)
+}
+
+func TestPool_PreferObserversWrites(t *testing.T) {
+	answer := func(m []byte) [][]byte {
+		var req struct {
+			ReqId seqNo `json:"reqId"`
+		}
+		json.Unmarshal(m, &req)
+		return [][]byte{[]byte(fmt.Sprintf(`{"op":"REPLY","result":{"type":"1","reqId":%v,"txnMetadata":{"seqNo":1}}}`, req.ReqId))}
+	}
+	pool := testPool(t, fakeTransport{"Node1": answer, "Node2": answer, "Node3": answer, "Node4": answer}, WithPreferObservers())
+	pool.Validators[0].Services = []string{"OBSERVER"}
+	pool.Validators[1].Services = []string{"OBSERVER"}
+
+	r, err := pool.read(context.Background(), getTxnOp{Type: idGetTxn, Data: 1, LedgerID: int(DomainLedger)})
+	require.NoError(t, err)
+	require.Equal(t, "Node1", r.Node)
+
+	signer, err := SignerFromSeed([]byte("000000000000000000000000Trustee1"))
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		r, err = pool.SubmitSigned(context.Background(), Request{Operation: nymOp{Type: idNym, Dest: "WRfXPg8dantKVubE3HX8pw"}}, signer)
+		require.NoError(t, err)
+		require.Contains(t, []string{"Node3", "Node4"}, r.Node)
+	}
+}
+
+func TestParseReply_TrailingBytes(t *testing.T) {
+	for _, frame := range []string{
+		"{\"op\":\"REPLY\",\"reqId\":7,\"result\":{}}\n",
+		"{\"op\":\"REPLY\",\"reqId\":7,\"result\":{}}  \r\n\x00",
+		`{"op":"REPLY","reqId":7,"result":{}}{"op":"REQACK"}`,
+	} {
+		r, err := parseReply([]string{frame})
+		require.NoError(t, err, frame)
+		require.Equal(t, "REPLY", r.Op)
+	}
+
+	_, err := parseReply([]string{`{"op":"REPLY"`})
+	require.Error(t, err)
+}
+
+func TestPool_ReqIdGenerator(t *testing.T) {
+	var next uint32 = 100
+	p, err := NewPoolFromBytes(testGenesis(t, "10.0.0.1:9702"), WithReqIdGenerator(func() uint32 {
+		next++
+		return next
+	}))
+	require.NoError(t, err)
+
+	reqId, m := p.getTxnRequest(DomainLedger, 5)
+	require.Equal(t, seqNo(101), reqId)
+	require.Equal(t, `{"operation":{"type":"3","data":5,"ledgerId":1},"identifier":"Go1ndyC1ient1111111111","reqId":101,"protocolVersion":2}`, string(m))
+
+	reqId, _ = p.getTxnRequest(DomainLedger, 5)
+	require.Equal(t, seqNo(102), reqId)
+}
+
+func TestPool_GetTransaction_InvalidSeqNo(t *testing.T) {
+	p, err := NewPoolFromBytes(testGenesis(t, "10.0.0.1:9702"))
+	require.NoError(t, err)
+	for _, seqNo := range []int{0, -1} {
+		_, err := p.GetTransaction(context.Background(), DomainLedger, seqNo)
+		require.Equal(t, ErrInvalidSeqNo, err)
+	}
+}
+
+func TestCheckReply(t *testing.T) {
+	require.NoError(t, checkReply(&Reply{Op: "REPLY"}))
+
+	err := checkReply(&Reply{Op: "REQNACK", Node: "Node1", Reason: "invalid signature"})
+	require.True(t, errors.Is(err, ErrReqNack))
+	require.False(t, errors.Is(err, ErrRejected))
+	var re *RequestError
+	require.True(t, errors.As(err, &re))
+	require.Equal(t, "invalid signature", re.Reason)
+	require.Equal(t, "request refused by Node1 with REQNACK: invalid signature", err.Error())
+
+	err = checkReply(&Reply{Op: "REJECT", Reason: "UnauthorizedClientRequest"})
+	require.True(t, errors.Is(err, ErrRejected))
+
+	err = checkReply(&Reply{Op: "PONG"})
+	require.True(t, errors.Is(err, ErrMalformedReply))
+}
+
+func TestPool_ExcludeValidators(t *testing.T) {
+	v := ledgerValidator(testLedger)
+	pool := testPool(t, fakeTransport{"Node1": v, "Node2": v, "Node3": v, "Node4": v})
+
+	reply, err := pool.GetTransaction(context.Background(), DomainLedger, 2, ExcludeValidators("Node1"))
+	require.NoError(t, err)
+	require.NotEqual(t, "Node1", reply.Node)
+	require.Equal(t, "REPLY", reply.Op)
+
+	_, err = pool.GetTransaction(context.Background(), DomainLedger, 2,
+		ExcludeValidators("Node1", "Node2"), ExcludeValidators("Node3", "Node4"))
+	require.Equal(t, ErrAllExcluded, err)
+}
@@ -0,0 +1,159 @@
+//go:build docker
+// +build docker
+
+package indyclienttest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.dedis.ch/indyclient"
+)
+
+// Environment variables read by StartDocker.
+const (
+	// EnvGenesis names a genesis file of a running pool to attach to.
+	EnvGenesis = "INDYCLIENT_GENESIS"
+	// EnvContainer names a running container of the pool image to attach
+	// to.
+	EnvContainer = "INDYCLIENT_CONTAINER"
+	// EnvImage names the image to start, by default indy_pool.
+	EnvImage = "INDYCLIENT_POOL_IMAGE"
+)
+
+// defaultImage is the name under which the indy_pool image of the Indy SDK
+// (ci/indy-pool.dockerfile) is usually built. It runs four validators
+// listening on ports 9701 to 9708 of 127.0.0.1.
+const defaultImage = "indy_pool"
+
+// containerGenesis is where the pool image keeps its genesis file.
+const containerGenesis = "/var/lib/indy/sandbox/pool_transactions_genesis"
+
+// Docker is a local indy-node pool running in Docker.
+type Docker struct {
+	Pool        *indyclient.Pool
+	GenesisFile string // path of the genesis file of the pool
+
+	container string // started by StartDocker, empty if attached
+	dir       string
+}
+
+// StartDocker returns a Pool connected to a local indy-node pool running in
+// Docker, once it is ready. It attaches to the pool whose genesis file is
+// named by $INDYCLIENT_GENESIS, or to the container named by
+// $INDYCLIENT_CONTAINER, and otherwise starts a container of the image
+// $INDYCLIENT_POOL_IMAGE, indy_pool by default. Close stops the container
+// if StartDocker started it.
+//
+// This file is only built with the docker build tag:
+//
+//	go test -tags docker ./...
+func StartDocker(ctx context.Context, opts ...indyclient.Option) (*Docker, error) {
+	d := new(Docker)
+	err := d.start(ctx, opts)
+	if err != nil {
+		d.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *Docker) start(ctx context.Context, opts []indyclient.Option) error {
+	genesis := os.Getenv(EnvGenesis)
+	if genesis == "" {
+		dir, err := ioutil.TempDir("", "indyclienttest")
+		if err != nil {
+			return err
+		}
+		d.dir = dir
+
+		container := os.Getenv(EnvContainer)
+		if container == "" {
+			image := os.Getenv(EnvImage)
+			if image == "" {
+				image = defaultImage
+			}
+			out, err := docker(ctx, "run", "--detach", "--rm", "--publish", "9701-9708:9701-9708", image)
+			if err != nil {
+				return err
+			}
+			d.container = strings.TrimSpace(out)
+			container = d.container
+		}
+
+		// The genesis file appears once the container is set up.
+		var g string
+		for {
+			g, err = docker(ctx, "exec", container, "cat", containerGenesis)
+			if err == nil && strings.TrimSpace(g) != "" {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("no genesis file in container %v: %v", container, err)
+			case <-time.After(time.Second):
+			}
+		}
+		genesis = filepath.Join(dir, "pool_transactions_genesis")
+		if err := ioutil.WriteFile(genesis, []byte(g), 0644); err != nil {
+			return err
+		}
+	}
+	d.GenesisFile = genesis
+
+	f, err := os.Open(genesis)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	d.Pool, err = indyclient.NewPool(f, opts...)
+	if err != nil {
+		return err
+	}
+
+	// The validators take a while to start and connect to each other.
+	for {
+		err := d.Pool.Ready(ctx)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("pool did not get ready: %v", err)
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// Close stops the container started by StartDocker, if any.
+func (d *Docker) Close() error {
+	var err error
+	if d.container != "" {
+		_, err = docker(context.Background(), "rm", "--force", d.container)
+		d.container = ""
+	}
+	if d.dir != "" {
+		os.RemoveAll(d.dir)
+		d.dir = ""
+	}
+	return err
+}
+
+// docker runs the docker command with args and returns its output.
+func docker(ctx context.Context, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("docker %v: %v: %v", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,26 @@
+//go:build docker
+// +build docker
+
+package indyclienttest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/indyclient"
+	"go.dedis.ch/indyclient/indyclienttest"
+)
+
+func TestStartDocker(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	d, err := indyclienttest.StartDocker(ctx)
+	require.NoError(t, err)
+	defer d.Close()
+
+	reply, err := d.Pool.GetTransaction(ctx, indyclient.DomainLedger, 1)
+	require.NoError(t, err)
+	require.Equal(t, "REPLY", reply.Op)
+}
@@ -0,0 +1,29 @@
+package indyclienttest_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.dedis.ch/indyclient"
+	"go.dedis.ch/indyclient/indyclienttest"
+)
+
+func ExampleNetwork() {
+	n := indyclienttest.NewNetwork(4)
+	err := n.LoadTxns(indyclient.DomainLedger, strings.NewReader(domainTxns))
+	if err != nil {
+		panic(err)
+	}
+	pool, err := n.Pool()
+	if err != nil {
+		panic(err)
+	}
+
+	nym, err := pool.GetNym(context.Background(), "V4SGRU86Z58d6TV7PBUe6f")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(nym.Verkey, nym.Role)
+	// Output: ~CoRER63DVYnWZtK8uAzNbx 0
+}
@@ -0,0 +1,202 @@
+package indyclienttest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// The validators keep the Merkle tree of every ledger, as specified by RFC
+// 6962, to send the audit paths of GET_TXN replies and the consistency
+// proofs of catchup. Its leaves are the hashes of the transactions
+// serialized with MessagePack, with sorted keys.
+
+// leafHash returns the hash of txn as a leaf of the Merkle tree of its
+// ledger.
+func leafHash(txn json.RawMessage) ([]byte, error) {
+	d := json.NewDecoder(bytes.NewReader(txn))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	m, err := packValue(nil, v)
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.Sum256(append([]byte{0}, m...))
+	return h[:], nil
+}
+
+// packValue appends the MessagePack encoding of v, decoded from JSON with
+// json.Number numbers, to b.
+func packValue(b []byte, v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case nil:
+		return append(b, 0xc0), nil
+	case bool:
+		if v {
+			return append(b, 0xc3), nil
+		}
+		return append(b, 0xc2), nil
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return packInt(b, i), nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, err
+		}
+		var buf [8]byte
+		binary.BigEndian.PutUint64(buf[:], math.Float64bits(f))
+		return append(append(b, 0xcb), buf[:]...), nil
+	case string:
+		n := len(v)
+		switch {
+		case n < 32:
+			b = append(b, 0xa0|byte(n))
+		case n <= math.MaxUint8:
+			b = append(b, 0xd9, byte(n))
+		case n <= math.MaxUint16:
+			b = append(b, 0xda, byte(n>>8), byte(n))
+		default:
+			b = append(b, 0xdb, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+		}
+		return append(b, v...), nil
+	case []interface{}:
+		b = packHeader(b, len(v), 0x90, 0xdc)
+		for _, e := range v {
+			var err error
+			if b, err = packValue(b, e); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b = packHeader(b, len(v), 0x80, 0xde)
+		for _, k := range keys {
+			var err error
+			if b, err = packValue(b, k); err != nil {
+				return nil, err
+			}
+			if b, err = packValue(b, v[k]); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("cannot pack %T", v)
+}
+
+// packHeader appends the header of an array or map of n elements, given
+// its fix and 16 bit type bytes; the 32 bit type byte follows the latter.
+func packHeader(b []byte, n int, fix, t16 byte) []byte {
+	switch {
+	case n < 16:
+		return append(b, fix|byte(n))
+	case n <= math.MaxUint16:
+		return append(b, t16, byte(n>>8), byte(n))
+	}
+	return append(b, t16+1, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+}
+
+// packInt appends the shortest encoding of i to b.
+func packInt(b []byte, i int64) []byte {
+	var buf [8]byte
+	switch {
+	case i >= -32 && i < 128:
+		return append(b, byte(i))
+	case i >= 0 && i <= math.MaxUint8:
+		return append(b, 0xcc, byte(i))
+	case i >= 0 && i <= math.MaxUint16:
+		return append(b, 0xcd, byte(i>>8), byte(i))
+	case i >= 0 && i <= math.MaxUint32:
+		binary.BigEndian.PutUint32(buf[:], uint32(i))
+		return append(append(b, 0xce), buf[:4]...)
+	case i >= 0:
+		binary.BigEndian.PutUint64(buf[:], uint64(i))
+		return append(append(b, 0xcf), buf[:]...)
+	case i >= math.MinInt8:
+		return append(b, 0xd0, byte(i))
+	case i >= math.MinInt16:
+		return append(b, 0xd1, byte(i>>8), byte(i))
+	case i >= math.MinInt32:
+		binary.BigEndian.PutUint32(buf[:], uint32(i))
+		return append(append(b, 0xd2), buf[:4]...)
+	}
+	binary.BigEndian.PutUint64(buf[:], uint64(i))
+	return append(append(b, 0xd3), buf[:]...)
+}
+
+// treeHash returns the Merkle tree hash of leaves.
+func treeHash(leaves [][]byte) []byte {
+	switch len(leaves) {
+	case 0:
+		h := sha256.Sum256(nil)
+		return h[:]
+	case 1:
+		return leaves[0]
+	}
+	k := split(len(leaves))
+	return nodeHash(treeHash(leaves[:k]), treeHash(leaves[k:]))
+}
+
+// auditPath returns the audit path of the leaf at index in the tree of
+// leaves.
+func auditPath(index int, leaves [][]byte) [][]byte {
+	if len(leaves) <= 1 {
+		return nil
+	}
+	k := split(len(leaves))
+	if index < k {
+		return append(auditPath(index, leaves[:k]), treeHash(leaves[k:]))
+	}
+	return append(auditPath(index-k, leaves[k:]), treeHash(leaves[:k]))
+}
+
+// consistencyProof returns the proof that the tree of the first m leaves,
+// with 0 < m <= len(leaves), is a prefix of the tree of all of them.
+func consistencyProof(m int, leaves [][]byte) [][]byte {
+	return subproof(m, leaves, true)
+}
+
+func subproof(m int, leaves [][]byte, complete bool) [][]byte {
+	n := len(leaves)
+	if m == n {
+		if complete {
+			return nil
+		}
+		return [][]byte{treeHash(leaves)}
+	}
+	k := split(n)
+	if m <= k {
+		return append(subproof(m, leaves[:k], complete), treeHash(leaves[k:]))
+	}
+	return append(subproof(m-k, leaves[k:], false), treeHash(leaves[:k]))
+}
+
+// split returns the largest power of two smaller than n > 1.
+func split(n int) int {
+	k := 1
+	for k*2 < n {
+		k *= 2
+	}
+	return k
+}
+
+func nodeHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{1})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
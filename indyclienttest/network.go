@@ -0,0 +1,446 @@
+// Package indyclienttest provides fake Indy validators serving canned
+// transactions, to test code using indyclient without a running pool.
+//
+// The validators answer GET_TXN requests, with the audit paths of the
+// transactions, GET_NYM, including for past versions of NYMs, and
+// GET_ATTRIB requests for raw attributes, and refuse everything else. They
+// also answer the LEDGER_STATUS and CATCHUP_REQ messages of catchup. They can be reached either in memory, through the Transport of the
+// Network, or over the real ZMQ and CurveZMQ protocol on localhost once
+// Listen was called:
+//
+//	n := indyclienttest.NewNetwork(4)
+//	err := n.LoadTxns(indyclient.DomainLedger, domainTxns)
+//	pool, err := n.Pool()
+package indyclienttest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mr-tron/base58"
+	"go.dedis.ch/indyclient"
+)
+
+// A Network is a pool of fake validators serving the same ledgers.
+type Network struct {
+	Validators []*Validator
+
+	mu        sync.Mutex
+	ledgers   map[indyclient.LedgerId][]json.RawMessage
+	leaves    map[indyclient.LedgerId][][]byte // Merkle tree leaves of the ledgers
+	nyms      map[string][]*indyclient.Nym     // every version, oldest first
+	attribs   map[string]*attrib               // by dest and name
+	listeners []net.Listener
+}
+
+// Validator is a fake validator of a Network.
+type Validator struct {
+	Alias   string
+	Address string // client_ip:client_port
+	Key     ed25519.PrivateKey
+}
+
+// NewNetwork returns a Network of n validators, called Node1 to Node<n>,
+// with keys derived from fixed seeds.
+func NewNetwork(n int) *Network {
+	nw := &Network{
+		ledgers: make(map[indyclient.LedgerId][]json.RawMessage),
+		leaves:  make(map[indyclient.LedgerId][][]byte),
+		nyms:    make(map[string][]*indyclient.Nym),
+		attribs: make(map[string]*attrib),
+	}
+	for i := 1; i <= n; i++ {
+		seed := fmt.Sprintf("indyclienttest%018d", i)
+		nw.Validators = append(nw.Validators, &Validator{
+			Alias:   fmt.Sprintf("Node%v", i),
+			Address: fmt.Sprintf("127.0.0.1:%v", 9700+2*i),
+			Key:     ed25519.NewKeyFromSeed([]byte(seed)),
+		})
+	}
+	return nw
+}
+
+// Genesis returns the genesis transactions of the pool ledger of n, in the
+// format of pool_transactions_genesis files.
+func (n *Network) Genesis() []byte {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	var b bytes.Buffer
+	for i, v := range n.Validators {
+		host, port, _ := net.SplitHostPort(v.Address)
+		verkey := base58.Encode(v.Key.Public().(ed25519.PublicKey))
+		fmt.Fprintf(&b, `{"reqSignature":{},"txn":{"data":{"data":{"alias":%q,"client_ip":%q,"client_port":%v,"node_ip":%q,"node_port":%v,"services":["VALIDATOR"]},"dest":%q},"metadata":{},"type":"0"},"txnMetadata":{"seqNo":%v},"ver":"1"}`+"\n",
+			v.Alias, host, port, host, 9701+2*i, verkey, i+1)
+	}
+	return b.Bytes()
+}
+
+// LoadTxns appends the transactions read from r to ledger. r holds one JSON
+// transaction per line, like genesis files and ledger exports, in the order
+// of their seqNo. The NYM and ATTRIB transactions of the domain ledger are
+// served by GET_NYM and GET_ATTRIB.
+func (n *Network) LoadTxns(ledger indyclient.LedgerId, r io.Reader) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<24)
+	for s.Scan() {
+		line := bytes.TrimSpace(s.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var b indyclient.Block
+		if err := json.Unmarshal(line, &b); err != nil {
+			return fmt.Errorf("transaction %v: %v", len(n.ledgers[ledger])+1, err)
+		}
+		leaf, err := leafHash(line)
+		if err != nil {
+			return fmt.Errorf("transaction %v: %v", len(n.ledgers[ledger])+1, err)
+		}
+		n.ledgers[ledger] = append(n.ledgers[ledger], append(json.RawMessage(nil), line...))
+		n.leaves[ledger] = append(n.leaves[ledger], leaf)
+		seqNo := len(n.ledgers[ledger])
+		if ledger == indyclient.DomainLedger {
+			switch fmt.Sprint(b.Txn.Type) {
+			case "1":
+				n.applyNym(&b, seqNo)
+			case "100":
+				n.applyAttrib(&b, seqNo)
+			}
+		}
+	}
+	return s.Err()
+}
+
+// applyNym records the NYM transaction b. n.mu must be held.
+func (n *Network) applyNym(b *indyclient.Block, seqNo int) {
+	var data map[string]*string
+	json.Unmarshal(b.Txn.Data.Raw, &data)
+	dest := b.Txn.Data.Dest
+	nym := new(indyclient.Nym)
+	if versions := n.nyms[dest]; len(versions) > 0 {
+		*nym = *versions[len(versions)-1]
+	} else {
+		from, _ := b.Txn.Metadata["from"].(string)
+		*nym = indyclient.Nym{Dest: dest, Identifier: from}
+	}
+	n.nyms[dest] = append(n.nyms[dest], nym)
+	if v, ok := data["verkey"]; ok && v != nil {
+		nym.Verkey = *v
+	}
+	if r, ok := data["role"]; ok {
+		nym.Role = ""
+		if r != nil {
+			nym.Role = *r
+		}
+	}
+	nym.SeqNo = seqNo
+	nym.TxnTime = b.TxnMetadata.TxnTime
+}
+
+// attrib is the latest value of a raw attribute.
+type attrib struct {
+	value   json.RawMessage
+	seqNo   int
+	txnTime int64
+}
+
+// applyAttrib records the raw attributes set by the ATTRIB transaction b.
+// n.mu must be held.
+func (n *Network) applyAttrib(b *indyclient.Block, seqNo int) {
+	var data struct {
+		Raw string `json:"raw"`
+	}
+	json.Unmarshal(b.Txn.Data.Raw, &data)
+	var values map[string]json.RawMessage
+	if json.Unmarshal([]byte(data.Raw), &values) != nil {
+		return
+	}
+	for name, v := range values {
+		n.attribs[b.Txn.Data.Dest+"\x00"+name] = &attrib{value: v, seqNo: seqNo, txnTime: b.TxnMetadata.TxnTime}
+	}
+}
+
+// nymVersion returns the NYM of dest written by the transaction seqNo, or
+// current at timestamp, or the latest one if both are zero. n.mu must be
+// held.
+func (n *Network) nymVersion(dest string, seqNo int, timestamp int64) *indyclient.Nym {
+	var nym *indyclient.Nym
+	for _, v := range n.nyms[dest] {
+		switch {
+		case seqNo != 0:
+			if v.SeqNo == seqNo {
+				return v
+			}
+		case timestamp != 0 && v.TxnTime > timestamp:
+			return nym
+		default:
+			nym = v
+		}
+	}
+	if seqNo != 0 {
+		return nil
+	}
+	return nym
+}
+
+// Transport returns a Transport connecting to the validators of n in
+// memory. Validators are looked up by alias.
+func (n *Network) Transport() indyclient.Transport {
+	return memTransport{n}
+}
+
+// Pool returns a Pool connected to n through its Transport. opts are
+// applied after WithTransport, so that they can replace it.
+func (n *Network) Pool(opts ...indyclient.Option) (*indyclient.Pool, error) {
+	opts = append([]indyclient.Option{indyclient.WithTransport(n.Transport())}, opts...)
+	return indyclient.NewPoolFromBytes(n.Genesis(), opts...)
+}
+
+type memTransport struct {
+	n *Network
+}
+
+func (t memTransport) Dial(ctx context.Context, v indyclient.Validator) (indyclient.Connection, error) {
+	for _, fv := range t.n.Validators {
+		if fv.Alias == v.Alias {
+			return &memConn{n: t.n, in: make(chan []byte, 64)}, nil
+		}
+	}
+	return nil, fmt.Errorf("no validator %v", v.Alias)
+}
+
+type memConn struct {
+	n  *Network
+	in chan []byte
+}
+
+func (c *memConn) Send(m []byte) error {
+	for _, out := range c.n.handle(m) {
+		select {
+		case c.in <- out:
+		default:
+			return errors.New("too many pending replies")
+		}
+	}
+	return nil
+}
+
+func (c *memConn) Receive(timeout time.Duration) ([]byte, error) {
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	select {
+	case m := <-c.in:
+		return m, nil
+	case <-t.C:
+		return nil, nil
+	}
+}
+
+func (c *memConn) Close() error {
+	return nil
+}
+
+// request is the part of requests the validators look at.
+type request struct {
+	Identifier string `json:"identifier"`
+	ReqId      uint64 `json:"reqId"`
+	Operation  struct {
+		Type     json.RawMessage `json:"type"`
+		Data     json.RawMessage `json:"data"`
+		LedgerID int             `json:"ledgerId"`
+		Dest     string          `json:"dest"`
+		Raw      string          `json:"raw"`
+		SeqNo    int             `json:"seqNo"`
+		Time     int64           `json:"timestamp"`
+	} `json:"operation"`
+}
+
+// nodeMessage is the part of the messages of the protocol between
+// validators which the validators look at.
+type nodeMessage struct {
+	Op          string `json:"op"`
+	LedgerID    int    `json:"ledgerId"`
+	TxnSeqNo    int    `json:"txnSeqNo"`
+	SeqNoStart  int    `json:"seqNoStart"`
+	SeqNoEnd    int    `json:"seqNoEnd"`
+	CatchupTill int    `json:"catchupTill"`
+}
+
+// handle returns the messages a validator sends in response to m.
+func (n *Network) handle(m []byte) [][]byte {
+	var msg nodeMessage
+	if err := json.Unmarshal(m, &msg); err != nil {
+		return nil
+	}
+	switch msg.Op {
+	case "LEDGER_STATUS":
+		return n.ledgerStatus(&msg)
+	case "CATCHUP_REQ":
+		return n.catchup(&msg)
+	}
+
+	var req request
+	if err := json.Unmarshal(m, &req); err != nil {
+		return nil
+	}
+	nack := func(reason string) [][]byte {
+		out, _ := json.Marshal(map[string]interface{}{
+			"op":         "REQNACK",
+			"identifier": req.Identifier,
+			"reqId":      req.ReqId,
+			"reason":     reason,
+		})
+		return [][]byte{out}
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	result := map[string]interface{}{
+		"identifier": req.Identifier,
+		"reqId":      req.ReqId,
+	}
+	switch typ := strings.Trim(string(req.Operation.Type), `"`); typ {
+	case "3": // GET_TXN
+		var seqNo int
+		if err := json.Unmarshal(req.Operation.Data, &seqNo); err != nil {
+			return nack("invalid seqNo")
+		}
+		ledger := n.ledgers[indyclient.LedgerId(req.Operation.LedgerID)]
+		leaves := n.leaves[indyclient.LedgerId(req.Operation.LedgerID)]
+		result["type"] = typ
+		result["seqNo"] = seqNo
+		result["data"] = nil
+		if seqNo >= 1 && seqNo <= len(ledger) {
+			var data map[string]json.RawMessage
+			json.Unmarshal(ledger[seqNo-1], &data)
+			data["auditPath"], _ = json.Marshal(encodeHashes(auditPath(seqNo-1, leaves)))
+			data["ledgerSize"], _ = json.Marshal(len(ledger))
+			data["rootHash"], _ = json.Marshal(base58.Encode(treeHash(leaves)))
+			result["data"] = data
+		}
+	case "105": // GET_NYM
+		result["type"] = typ
+		result["dest"] = req.Operation.Dest
+		result["data"] = nil
+		if nym := n.nymVersion(req.Operation.Dest, req.Operation.SeqNo, req.Operation.Time); nym != nil {
+			data, _ := json.Marshal(nym)
+			result["data"] = string(data)
+			result["seqNo"] = nym.SeqNo
+			result["txnTime"] = nym.TxnTime
+		}
+	case "104": // GET_ATTRIB
+		if req.Operation.Raw == "" {
+			return nack("indyclienttest only serves raw attributes")
+		}
+		result["type"] = typ
+		result["dest"] = req.Operation.Dest
+		result["raw"] = req.Operation.Raw
+		result["data"] = nil
+		if a := n.attribs[req.Operation.Dest+"\x00"+req.Operation.Raw]; a != nil {
+			data, _ := json.Marshal(map[string]json.RawMessage{req.Operation.Raw: a.value})
+			result["data"] = string(data)
+			result["seqNo"] = a.seqNo
+			result["txnTime"] = a.txnTime
+		}
+	default:
+		return nack(fmt.Sprintf("indyclienttest does not serve requests of type %v", typ))
+	}
+
+	ack, _ := json.Marshal(map[string]interface{}{
+		"op":         "REQACK",
+		"identifier": req.Identifier,
+		"reqId":      req.ReqId,
+	})
+	reply, _ := json.Marshal(map[string]interface{}{
+		"op":     "REPLY",
+		"result": result,
+	})
+	return [][]byte{ack, reply}
+}
+
+// ledgerStatus answers the LEDGER_STATUS msg: with a consistency proof if
+// the ledger of the sender is behind, with the status of the ledger
+// otherwise.
+func (n *Network) ledgerStatus(msg *nodeMessage) [][]byte {
+	if msg.TxnSeqNo < 0 {
+		return nil
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	leaves := n.leaves[indyclient.LedgerId(msg.LedgerID)]
+	size := len(leaves)
+	var out []byte
+	if msg.TxnSeqNo < size {
+		var proof [][]byte
+		if msg.TxnSeqNo > 0 {
+			proof = consistencyProof(msg.TxnSeqNo, leaves)
+		}
+		out, _ = json.Marshal(map[string]interface{}{
+			"op":            "CONSISTENCY_PROOF",
+			"ledgerId":      msg.LedgerID,
+			"seqNoStart":    msg.TxnSeqNo,
+			"seqNoEnd":      size,
+			"viewNo":        0,
+			"ppSeqNo":       size,
+			"oldMerkleRoot": base58.Encode(treeHash(leaves[:msg.TxnSeqNo])),
+			"newMerkleRoot": base58.Encode(treeHash(leaves)),
+			"hashes":        encodeHashes(proof),
+		})
+	} else {
+		out, _ = json.Marshal(map[string]interface{}{
+			"op":              "LEDGER_STATUS",
+			"ledgerId":        msg.LedgerID,
+			"txnSeqNo":        size,
+			"viewNo":          0,
+			"ppSeqNo":         size,
+			"merkleRoot":      base58.Encode(treeHash(leaves)),
+			"protocolVersion": 2,
+		})
+	}
+	return [][]byte{out}
+}
+
+// catchup answers the CATCHUP_REQ msg with the requested transactions and
+// the consistency proof from the ledger ending with them to the ledger of
+// size catchupTill. Requests past the end of the ledger are ignored.
+func (n *Network) catchup(msg *nodeMessage) [][]byte {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	ledger := n.ledgers[indyclient.LedgerId(msg.LedgerID)]
+	leaves := n.leaves[indyclient.LedgerId(msg.LedgerID)]
+	if msg.SeqNoStart < 1 || msg.SeqNoEnd < msg.SeqNoStart ||
+		msg.CatchupTill < msg.SeqNoEnd || msg.CatchupTill > len(ledger) {
+		return nil
+	}
+	txns := make(map[string]json.RawMessage)
+	for seqNo := msg.SeqNoStart; seqNo <= msg.SeqNoEnd; seqNo++ {
+		txns[fmt.Sprint(seqNo)] = ledger[seqNo-1]
+	}
+	out, _ := json.Marshal(map[string]interface{}{
+		"op":        "CATCHUP_REP",
+		"ledgerId":  msg.LedgerID,
+		"txns":      txns,
+		"consProof": encodeHashes(consistencyProof(msg.SeqNoEnd, leaves[:msg.CatchupTill])),
+	})
+	return [][]byte{out}
+}
+
+// encodeHashes returns the base58 encoding of hashes.
+func encodeHashes(hashes [][]byte) []string {
+	enc := make([]string, len(hashes))
+	for i, h := range hashes {
+		enc[i] = base58.Encode(h)
+	}
+	return enc
+}
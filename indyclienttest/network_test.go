@@ -0,0 +1,76 @@
+package indyclienttest_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/indyclient"
+	"go.dedis.ch/indyclient/indyclienttest"
+)
+
+const domainTxns = `{"txn":{"type":"1","data":{"dest":"V4SGRU86Z58d6TV7PBUe6f","verkey":"~CoRER63DVYnWZtK8uAzNbx","role":"0"},"metadata":{}},"txnMetadata":{"seqNo":1}}
+{"txn":{"type":"1","data":{"dest":"Th7MpTaRZVRYnPiabds81Y","verkey":"~7TYfekw4GUagBnBVCqPjiC","role":"2"},"metadata":{"from":"V4SGRU86Z58d6TV7PBUe6f"}},"txnMetadata":{"seqNo":2,"txnTime":1500000000}}
+{"txn":{"type":"1","data":{"dest":"Th7MpTaRZVRYnPiabds81Y","role":null},"metadata":{"from":"V4SGRU86Z58d6TV7PBUe6f"}},"txnMetadata":{"seqNo":3,"txnTime":1600000000}}
+`
+
+func testNetwork(t *testing.T) *indyclienttest.Network {
+	n := indyclienttest.NewNetwork(4)
+	require.NoError(t, n.LoadTxns(indyclient.DomainLedger, strings.NewReader(domainTxns)))
+	return n
+}
+
+func checkPool(t *testing.T, pool *indyclient.Pool) {
+	ctx := context.Background()
+	r, err := pool.GetTransaction(ctx, indyclient.DomainLedger, 2)
+	require.NoError(t, err)
+	res, err := r.GetTxnResult()
+	require.NoError(t, err)
+	require.Equal(t, "Th7MpTaRZVRYnPiabds81Y", res.Txn.Txn.Data.Dest)
+	require.Equal(t, 3, res.LedgerSize)
+	require.True(t, r.Verified)
+
+	r, err = pool.GetTransaction(ctx, indyclient.DomainLedger, 4)
+	require.NoError(t, err)
+	res, err = r.GetTxnResult()
+	require.NoError(t, err)
+	require.Nil(t, res.Txn)
+
+	nym, err := pool.GetNym(ctx, "did:sov:Th7MpTaRZVRYnPiabds81Y")
+	require.NoError(t, err)
+	require.Equal(t, indyclient.Nym{
+		Dest:       "Th7MpTaRZVRYnPiabds81Y",
+		Identifier: "V4SGRU86Z58d6TV7PBUe6f",
+		Verkey:     "~7TYfekw4GUagBnBVCqPjiC",
+		SeqNo:      3,
+		TxnTime:    1600000000,
+	}, *nym)
+
+	_, err = pool.GetNym(ctx, "LnXR1rPnncTPZvRdmJKhJQ")
+	require.Equal(t, indyclient.ErrNoData, err)
+
+	_, err = pool.GetSchema(ctx, "V4SGRU86Z58d6TV7PBUe6f", "degree", "1.0")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "REQNACK")
+}
+
+func TestNetwork_Transport(t *testing.T) {
+	pool, err := testNetwork(t).Pool()
+	require.NoError(t, err)
+	require.Len(t, pool.Validators, 4)
+	checkPool(t, pool)
+}
+
+func TestNetwork_Listen(t *testing.T) {
+	n := testNetwork(t)
+	require.NoError(t, n.Listen())
+	defer n.Close()
+
+	pool, err := indyclient.NewPoolFromBytes(n.Genesis(),
+		indyclient.WithTransport(indyclient.ZMTPTransport{}),
+		indyclient.WithConnectTimeout(5*time.Second))
+	require.NoError(t, err)
+	checkPool(t, pool)
+}
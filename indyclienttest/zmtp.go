@@ -0,0 +1,272 @@
+package indyclienttest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/nacl/box"
+)
+
+// Listen makes every validator of n listen on a random port of localhost
+// and speak ZMTP 3.0 with CurveZMQ, the protocol of indy-node, like a ZMQ
+// ROUTER socket. The addresses of the validators and the genesis
+// transactions are updated accordingly, so that Pools created afterwards
+// use the real protocol unless they are given the in-memory Transport.
+func (n *Network) Listen() error {
+	for _, v := range n.Validators {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			n.Close()
+			return err
+		}
+		n.mu.Lock()
+		n.listeners = append(n.listeners, l)
+		v.Address = l.Addr().String()
+		n.mu.Unlock()
+		go n.serve(l, curveSecret(v))
+	}
+	return nil
+}
+
+// Close stops the listeners started by Listen.
+func (n *Network) Close() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, l := range n.listeners {
+		l.Close()
+	}
+	n.listeners = nil
+	return nil
+}
+
+// curveSecret returns the Curve25519 secret key matching the Ed25519 key of
+// v, which is how indy-node derives its CurveZMQ key.
+func curveSecret(v *Validator) *[32]byte {
+	h := sha512.Sum512(v.Key.Seed())
+	var sec [32]byte
+	copy(sec[:], h[:32])
+	sec[0] &= 248
+	sec[31] &= 127
+	sec[31] |= 64
+	return &sec
+}
+
+func (n *Network) serve(l net.Listener, sec *[32]byte) {
+	for {
+		nc, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer nc.Close()
+			c, err := curveAccept(nc, sec)
+			if err != nil {
+				return
+			}
+			for {
+				m, err := c.receive()
+				if err != nil {
+					return
+				}
+				for _, out := range n.handle(m) {
+					if err := c.send(out); err != nil {
+						return
+					}
+				}
+			}
+		}()
+	}
+}
+
+// ZMTP frame flags.
+const (
+	flagLong    = 0x02
+	flagCommand = 0x04
+)
+
+// serverConn is the server side of a CurveZMQ connection.
+type serverConn struct {
+	nc        net.Conn
+	shared    [32]byte
+	sendNonce uint64
+}
+
+// curveAccept runs the ZMTP greeting and the server side of the CurveZMQ
+// handshake on nc. sec is the permanent secret key of the server.
+func curveAccept(nc net.Conn, sec *[32]byte) (*serverConn, error) {
+	var pub [32]byte
+	curve25519.ScalarBaseMult(&pub, sec)
+
+	g := make([]byte, 64)
+	if _, err := io.ReadFull(nc, g); err != nil {
+		return nil, err
+	}
+	if g[0] != 0xff || g[10] < 3 || string(bytes.TrimRight(g[12:32], "\x00")) != "CURVE" {
+		return nil, errors.New("not a ZMTP 3 CURVE client")
+	}
+	g = make([]byte, 64)
+	g[0], g[9], g[10], g[11], g[32] = 0xff, 0x7f, 3, 0, 1
+	copy(g[12:], "CURVE")
+	if _, err := nc.Write(g); err != nil {
+		return nil, err
+	}
+
+	hello, err := readCommand(nc, "HELLO")
+	if err != nil {
+		return nil, err
+	}
+	if len(hello) != 194 {
+		return nil, errors.New("malformed HELLO")
+	}
+	var clientT [32]byte
+	copy(clientT[:], hello[74:106])
+	nonce := curveNonce("CurveZMQHELLO---", 0)
+	copy(nonce[16:], hello[106:114])
+	if _, ok := box.Open(nil, hello[114:], &nonce, &clientT, sec); !ok {
+		return nil, errors.New("cannot open HELLO box")
+	}
+
+	tpub, tsec, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	cookie := make([]byte, 96)
+	if _, err := rand.Read(cookie); err != nil {
+		return nil, err
+	}
+	copy(nonce[:], "WELCOME-")
+	if _, err := rand.Read(nonce[8:]); err != nil {
+		return nil, err
+	}
+	welcome := append([]byte("\x07WELCOME"), nonce[8:]...)
+	welcome = box.Seal(welcome, append(tpub[:], cookie...), &nonce, &clientT, sec)
+	if err := writeFrame(nc, flagCommand, welcome); err != nil {
+		return nil, err
+	}
+
+	initiate, err := readCommand(nc, "INITIATE")
+	if err != nil {
+		return nil, err
+	}
+	if len(initiate) < 104+box.Overhead+128 || !bytes.Equal(initiate[:96], cookie) {
+		return nil, errors.New("malformed INITIATE")
+	}
+	c := &serverConn{nc: nc}
+	box.Precompute(&c.shared, &clientT, tsec)
+	copy(nonce[:], "CurveZMQINITIATE")
+	copy(nonce[16:], initiate[96:104])
+	plain, ok := box.OpenAfterPrecomputation(nil, initiate[104:], &nonce, &c.shared)
+	if !ok {
+		return nil, errors.New("cannot open INITIATE box")
+	}
+	var client [32]byte
+	copy(client[:], plain[:32])
+	copy(nonce[:], "VOUCH---")
+	copy(nonce[8:], plain[32:48])
+	vouch, ok := box.Open(nil, plain[48:128], &nonce, &client, tsec)
+	if !ok || !bytes.Equal(vouch, append(clientT[:], pub[:]...)) {
+		return nil, errors.New("invalid vouch")
+	}
+
+	ready := []byte{0x0b}
+	ready = append(ready, "Socket-Type\x00\x00\x00\x06ROUTER"...)
+	if err := c.sendCommand("READY", "CurveZMQREADY---", ready); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *serverConn) send(m []byte) error {
+	return c.sendCommand("MESSAGE", "CurveZMQMESSAGES", append([]byte{0}, m...))
+}
+
+// sendCommand sends the command name holding a box of plain.
+func (c *serverConn) sendCommand(name, prefix string, plain []byte) error {
+	c.sendNonce++
+	nonce := curveNonce(prefix, c.sendNonce)
+	body := append([]byte{byte(len(name))}, name...)
+	body = append(body, nonce[16:]...)
+	body = box.SealAfterPrecomputation(body, plain, &nonce, &c.shared)
+	return writeFrame(c.nc, flagCommand, body)
+}
+
+// receive returns the next message of the client, skipping commands.
+func (c *serverConn) receive() ([]byte, error) {
+	for {
+		_, body, err := readFrame(c.nc)
+		if err != nil {
+			return nil, err
+		}
+		if len(body) < 16+box.Overhead+1 || string(body[:8]) != "\x07MESSAGE" {
+			return nil, errors.New("expected MESSAGE")
+		}
+		nonce := curveNonce("CurveZMQMESSAGEC", 0)
+		copy(nonce[16:], body[8:16])
+		plain, ok := box.OpenAfterPrecomputation(nil, body[16:], &nonce, &c.shared)
+		if !ok {
+			return nil, errors.New("cannot open MESSAGE box")
+		}
+		if plain[0] == 0 {
+			return plain[1:], nil
+		}
+	}
+}
+
+func curveNonce(prefix string, n uint64) [24]byte {
+	var nonce [24]byte
+	copy(nonce[:], prefix)
+	binary.BigEndian.PutUint64(nonce[16:], n)
+	return nonce
+}
+
+func writeFrame(w io.Writer, flags byte, body []byte) error {
+	var hdr []byte
+	if len(body) > 255 {
+		hdr = make([]byte, 9)
+		hdr[0] = flags | flagLong
+		binary.BigEndian.PutUint64(hdr[1:], uint64(len(body)))
+	} else {
+		hdr = []byte{flags, byte(len(body))}
+	}
+	_, err := w.Write(append(hdr, body...))
+	return err
+}
+
+func readFrame(r io.Reader) (byte, []byte, error) {
+	var hdr [9]byte
+	if _, err := io.ReadFull(r, hdr[:2]); err != nil {
+		return 0, nil, err
+	}
+	size := uint64(hdr[1])
+	if hdr[0]&flagLong != 0 {
+		if _, err := io.ReadFull(r, hdr[2:]); err != nil {
+			return 0, nil, err
+		}
+		size = binary.BigEndian.Uint64(hdr[1:])
+	}
+	if size > 1<<28 {
+		return 0, nil, fmt.Errorf("frame of %v bytes is too large", size)
+	}
+	body := make([]byte, size)
+	_, err := io.ReadFull(r, body)
+	return hdr[0], body, err
+}
+
+// readCommand reads the command name and returns its data.
+func readCommand(r io.Reader, name string) ([]byte, error) {
+	flags, body, err := readFrame(r)
+	if err != nil {
+		return nil, err
+	}
+	if flags&flagCommand == 0 || len(body) < 1+len(name) || int(body[0]) != len(name) || string(body[1:1+len(name)]) != name {
+		return nil, fmt.Errorf("expected %v command", name)
+	}
+	return body[1+len(name):], nil
+}
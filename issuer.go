@@ -0,0 +1,88 @@
+package indyclient
+
+import (
+	"context"
+	"fmt"
+)
+
+// GetIssuerObjects scans the domain ledger for the SCHEMA and CLAIM_DEF
+// transactions written by issuerDid and returns their ids, which can be
+// passed to GetSchema and GetCredDef. Indy keeps no index of transactions
+// by author, so this reads the whole ledger, one transaction at a time; use
+// ctx to cancel it.
+func (p *Pool) GetIssuerObjects(ctx context.Context, issuerDid string) (schemas []string, credDefs []string, err error) {
+	issuerDid, err = didId(issuerDid)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for seqNo := 1; ; seqNo++ {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		b, _, err := p.getBlock(ctx, DomainLedger, seqNo)
+		if err != nil {
+			return nil, nil, err
+		}
+		if b == nil {
+			return schemas, credDefs, nil
+		}
+		if from, _ := b.Txn.Metadata["from"].(string); from != issuerDid {
+			continue
+		}
+
+		id, err := issuerObjectId(b)
+		if err != nil {
+			return nil, nil, fmt.Errorf("transaction %v: %v", seqNo, err)
+		}
+		switch b.Txn.Type {
+		case idSchema:
+			schemas = append(schemas, id)
+		case idClaimDef:
+			credDefs = append(credDefs, id)
+		}
+	}
+}
+
+// issuerObjectId returns the id of the schema or credential definition
+// written by the transaction b, or "" for other transactions.
+func issuerObjectId(b *Block) (string, error) {
+	from, _ := b.Txn.Metadata["from"].(string)
+	switch b.Txn.Type {
+	case idSchema:
+		var s struct {
+			Name    string
+			Version string
+		}
+		if err := decodeData(b.Txn.Data.Data, &s); err != nil {
+			return "", err
+		}
+		return schemaId(from, s.Name, s.Version), nil
+	case idClaimDef:
+		var cd struct {
+			Ref           int
+			SignatureType string `json:"signature_type"`
+			Tag           string
+		}
+		if err := decodeData(b.Txn.Data.Raw, &cd); err != nil {
+			return "", err
+		}
+		return credDefId(from, cd.SignatureType, cd.Ref, cd.Tag), nil
+	}
+	return "", nil
+}
+
+// schemaId returns the id of the schema written by did, in the legacy Indy
+// format did:2:name:version.
+func schemaId(did, name, version string) string {
+	return fmt.Sprintf("%v:2:%v:%v", did, name, version)
+}
+
+// credDefId returns the id of the credential definition written by did, in
+// the legacy Indy format did:3:signatureType:schemaSeqNo:tag.
+func credDefId(did, signatureType string, schemaSeqNo int, tag string) string {
+	if signatureType == "" {
+		signatureType = "CL"
+	}
+	return fmt.Sprintf("%v:3:%v:%v:%v", did, signatureType, schemaSeqNo, tag)
+}
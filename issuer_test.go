@@ -0,0 +1,83 @@
+package indyclient
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssuerObjectId(t *testing.T) {
+	for _, tc := range []struct {
+		txn, id string
+	}{
+		{`{"txn":{"data":{"data":{"attr_names":["name","age"],"name":"degree","version":"1.0"}},"metadata":{"from":"V4SGRU86Z58d6TV7PBUe6f","reqId":1524},"type":"101"},"txnMetadata":{"seqNo":10,"txnTime":1513945121},"ver":"1"}`,
+			"V4SGRU86Z58d6TV7PBUe6f:2:degree:1.0"},
+		{`{"txn":{"data":{"data":{"primary":{"n":"1"}},"ref":10,"signature_type":"CL","tag":"tag1"},"metadata":{"from":"V4SGRU86Z58d6TV7PBUe6f","reqId":1525},"type":"102"},"txnMetadata":{"seqNo":11},"ver":"1"}`,
+			"V4SGRU86Z58d6TV7PBUe6f:3:CL:10:tag1"},
+		{`{"txn":{"data":{"dest":"WRfXPg8dantKVubE3HX8pw","verkey":"~9L2f"},"metadata":{"from":"V4SGRU86Z58d6TV7PBUe6f"},"type":"1"},"txnMetadata":{"seqNo":12},"ver":"1"}`,
+			""},
+	} {
+		var b Block
+		require.NoError(t, json.Unmarshal([]byte(tc.txn), &b))
+		id, err := issuerObjectId(&b)
+		require.NoError(t, err)
+		require.Equal(t, tc.id, id)
+	}
+}
+
+func TestSchemaFromReply(t *testing.T) {
+	r := &Reply{Result: []byte(`{"type":"107","dest":"V4SGRU86Z58d6TV7PBUe6f","data":{"name":"degree","version":"1.0","attr_names":["name","age"]},"seqNo":10,"txnTime":1500000000}`)}
+	s, err := schemaFromReply(r)
+	require.NoError(t, err)
+	require.Equal(t, "V4SGRU86Z58d6TV7PBUe6f:2:degree:1.0", s.Id)
+	require.Equal(t, []string{"name", "age"}, s.AttrNames)
+
+	r = &Reply{Result: []byte(`{"type":"107","dest":"V4SGRU86Z58d6TV7PBUe6f","data":{"name":"degree","version":"1.0"},"seqNo":null}`)}
+	_, err = schemaFromReply(r)
+	require.Equal(t, ErrNoData, err)
+}
+
+func TestPool_GetSchemaById(t *testing.T) {
+	v := ledgerValidator([]string{
+		`{"txn":{"data":{"data":{"attr_names":["name","age"],"name":"degree","version":"1.0"}},"metadata":{"from":"V4SGRU86Z58d6TV7PBUe6f"},"type":"101"},"txnMetadata":{"seqNo":1,"txnTime":1513945121},"ver":"1"}`,
+		testLedger[1],
+	})
+	pool := testPool(t, fakeTransport{"Node1": v, "Node2": v, "Node3": v, "Node4": v})
+	ctx := context.Background()
+
+	s, err := pool.GetSchemaById(ctx, "1")
+	require.NoError(t, err)
+	require.Equal(t, &Schema{
+		Id:        "V4SGRU86Z58d6TV7PBUe6f:2:degree:1.0",
+		IssuerDid: "V4SGRU86Z58d6TV7PBUe6f",
+		Name:      "degree",
+		Version:   "1.0",
+		AttrNames: []string{"name", "age"},
+		SeqNo:     1,
+		TxnTime:   1513945121,
+	}, s)
+
+	_, err = pool.GetSchemaBySeqNo(ctx, 2)
+	require.Error(t, err)
+	_, err = pool.GetSchemaBySeqNo(ctx, 3)
+	require.Equal(t, ErrNoData, err)
+	_, err = pool.GetSchemaById(ctx, "V4SGRU86Z58d6TV7PBUe6f:3:CL:10:default")
+	require.Error(t, err)
+}
+
+func TestCredDef(t *testing.T) {
+	id := "V4SGRU86Z58d6TV7PBUe6f:3:CL:10:default"
+	op, err := parseCredDefId(id)
+	require.NoError(t, err)
+	require.Equal(t, getClaimDefOp{Type: idGetClaimDef, Origin: "V4SGRU86Z58d6TV7PBUe6f", Ref: 10, SignatureType: "CL", Tag: "default"}, op)
+	_, err = parseCredDefId("V4SGRU86Z58d6TV7PBUe6f:2:degree:1.0")
+	require.Error(t, err)
+
+	r := &Reply{Result: []byte(`{"type":"108","origin":"V4SGRU86Z58d6TV7PBUe6f","ref":10,"signature_type":"CL","tag":"default","data":{"primary":{"n":"1"}},"seqNo":11,"txnTime":1500000000}`)}
+	cd, err := credDefFromReply(r)
+	require.NoError(t, err)
+	require.Equal(t, id, cd.Id)
+	require.JSONEq(t, `{"primary":{"n":"1"}}`, string(cd.Value))
+}
@@ -0,0 +1,224 @@
+package indyclient
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/mr-tron/base58"
+)
+
+// DidFromVerkey derives the self-certifying DID of a base58 encoded verkey:
+// the base58 encoding of the verkey's first 16 bytes.
+func DidFromVerkey(verkey string) (string, error) {
+	vk, err := base58.Decode(verkey)
+	if err != nil {
+		return "", err
+	}
+	if len(vk) != ed25519.PublicKeySize {
+		return "", errors.New("verkey is not 32 bytes long")
+	}
+	return base58.Encode(vk[:16]), nil
+}
+
+// KeypairFromSeed derives an Ed25519 key pair from a 32 byte seed, the way
+// Indy wallets do. It returns the private key, the base58 encoded verkey
+// and the DID derived from it.
+func KeypairFromSeed(seed []byte) (ed25519.PrivateKey, string, string, error) {
+	if len(seed) != ed25519.SeedSize {
+		return nil, "", "", errors.New("seed is not 32 bytes long")
+	}
+	sk := ed25519.NewKeyFromSeed(seed)
+	verkey := base58.Encode(sk.Public().(ed25519.PublicKey))
+	did, err := DidFromVerkey(verkey)
+	if err != nil {
+		return nil, "", "", err
+	}
+	return sk, verkey, did, nil
+}
+
+// GenerateSeed returns a random 32 byte seed for KeypairFromSeed.
+func GenerateSeed() ([]byte, error) {
+	seed := make([]byte, ed25519.SeedSize)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
+// ParseSeed decodes a seed in one of the forms indy-cli and the Indy SDK
+// accept: 32 characters used as is, 64 hex digits, or base64 ending in
+// "=". Surrounding whitespace is ignored.
+func ParseSeed(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	var seed []byte
+	var err error
+	switch {
+	case strings.HasSuffix(s, "="):
+		seed, err = base64.StdEncoding.DecodeString(s)
+	case len(s) == 2*ed25519.SeedSize:
+		seed, err = hex.DecodeString(s)
+	default:
+		seed = []byte(s)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid seed: %v", err)
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, errors.New("seed is not 32 bytes long")
+	}
+	return seed, nil
+}
+
+// SeedFromEnv parses the seed held by the environment variable name, such
+// as the SEED variable of indy-node test setups.
+func SeedFromEnv(name string) ([]byte, error) {
+	s, ok := os.LookupEnv(name)
+	if !ok {
+		return nil, fmt.Errorf("$%v is not set", name)
+	}
+	return ParseSeed(s)
+}
+
+// SeedFromFile parses the seed held by the file at path.
+func SeedFromFile(path string) ([]byte, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSeed(string(b))
+}
+
+// ReadDidImport reads the DIDs of an indy-cli "did import" file, a JSON
+// object such as {"version":1,"dids":[{"did":"...","seed":"..."}]}, and
+// returns a Signer for each of them. A DID which is not given is derived
+// from the verkey of its seed.
+func ReadDidImport(r io.Reader) ([]Signer, error) {
+	var f struct {
+		Version int `json:"version"`
+		Dids    []struct {
+			Did  string `json:"did"`
+			Seed string `json:"seed"`
+		} `json:"dids"`
+	}
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return nil, err
+	}
+	if f.Version != 1 {
+		return nil, fmt.Errorf("unsupported did import version %v", f.Version)
+	}
+	signers := make([]Signer, len(f.Dids))
+	for i, d := range f.Dids {
+		seed, err := ParseSeed(d.Seed)
+		if err != nil {
+			return nil, fmt.Errorf("did %v: %v", i, err)
+		}
+		sk, _, did, err := KeypairFromSeed(seed)
+		if err != nil {
+			return nil, err
+		}
+		if d.Did != "" {
+			did = d.Did
+		}
+		signers[i], err = NewSigner(did, sk)
+		if err != nil {
+			return nil, fmt.Errorf("did %v: %v", i, err)
+		}
+	}
+	return signers, nil
+}
+
+// ErrVerkeyMismatch is returned by CheckVerkey for verkeys from which the
+// DID was not derived.
+var ErrVerkeyMismatch = errors.New("verkey does not match DID")
+
+// ExpandVerkey returns the full verkey of did, given as a DID or a bare
+// identifier, from its verkey as stored on the ledger, which may be
+// abbreviated to "~" followed by the base58 encoding of the last 16 bytes
+// of the key, the first 16 being the DID. Full verkeys are returned as is.
+func ExpandVerkey(did, verkey string) (string, error) {
+	if !strings.HasPrefix(verkey, "~") {
+		return verkey, nil
+	}
+	id, err := didBytes(did)
+	if err != nil {
+		return "", err
+	}
+	rest, err := base58.Decode(verkey[1:])
+	if err != nil {
+		return "", fmt.Errorf("invalid verkey %v: %v", verkey, err)
+	}
+	if len(rest) != ed25519.PublicKeySize-16 {
+		return "", fmt.Errorf("abbreviated verkey %v is not 16 bytes long", verkey)
+	}
+	return base58.Encode(append(id, rest...)), nil
+}
+
+// AbbreviateVerkey returns the abbreviated form of the verkey of did if the
+// DID was derived from it, and the full verkey otherwise, such as after a
+// key rotation.
+func AbbreviateVerkey(did, verkey string) (string, error) {
+	verkey, err := ExpandVerkey(did, verkey)
+	if err != nil {
+		return "", err
+	}
+	if CheckVerkey(did, verkey) == ErrVerkeyMismatch {
+		return verkey, nil
+	}
+	vk, err := base58.Decode(verkey)
+	if err != nil {
+		return "", err
+	}
+	return "~" + base58.Encode(vk[16:]), nil
+}
+
+// CheckVerkey checks that did, given as a DID or a bare identifier, was
+// derived from verkey, which may be abbreviated. It returns
+// ErrVerkeyMismatch if it was not, which is legitimate for DIDs whose key
+// was rotated or which were not self-certifying in the first place.
+func CheckVerkey(did, verkey string) error {
+	id, err := didBytes(did)
+	if err != nil {
+		return err
+	}
+	verkey, err = ExpandVerkey(did, verkey)
+	if err != nil {
+		return err
+	}
+	vk, err := base58.Decode(verkey)
+	if err != nil {
+		return fmt.Errorf("invalid verkey %v: %v", verkey, err)
+	}
+	if len(vk) != ed25519.PublicKeySize {
+		return fmt.Errorf("verkey %v is not 32 bytes long", verkey)
+	}
+	if !bytes.Equal(vk[:16], id) {
+		return ErrVerkeyMismatch
+	}
+	return nil
+}
+
+// didBytes decodes the 16 bytes of the identifier of did.
+func didBytes(did string) ([]byte, error) {
+	id, err := didId(did)
+	if err != nil {
+		return nil, err
+	}
+	b, err := base58.Decode(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DID %v: %v", did, err)
+	}
+	if len(b) != 16 {
+		return nil, fmt.Errorf("DID %v is not 16 bytes long", did)
+	}
+	return b, nil
+}
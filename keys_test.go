@@ -0,0 +1,107 @@
+package indyclient
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeypairFromSeed(t *testing.T) {
+	_, verkey, did, err := KeypairFromSeed([]byte("000000000000000000000000Trustee1"))
+	require.NoError(t, err)
+	require.Equal(t, "GJ1SzoWzavQYfNL9XkaJdrQejfztN4XqdsiV4ct3LXKL", verkey)
+	require.Equal(t, "V4SGRU86Z58d6TV7PBUe6f", did)
+
+	_, _, _, err = KeypairFromSeed([]byte("too short"))
+	require.Error(t, err)
+}
+
+func TestDidFromVerkey(t *testing.T) {
+	did, err := DidFromVerkey("GJ1SzoWzavQYfNL9XkaJdrQejfztN4XqdsiV4ct3LXKL")
+	require.NoError(t, err)
+	require.Equal(t, "V4SGRU86Z58d6TV7PBUe6f", did)
+
+	_, err = DidFromVerkey("V4SGRU86Z58d6TV7PBUe6f")
+	require.Error(t, err)
+	_, err = DidFromVerkey("not base58!")
+	require.Error(t, err)
+}
+
+func TestExpandVerkey(t *testing.T) {
+	const did, verkey = "V4SGRU86Z58d6TV7PBUe6f", "GJ1SzoWzavQYfNL9XkaJdrQejfztN4XqdsiV4ct3LXKL"
+	abbr, err := AbbreviateVerkey(did, verkey)
+	require.NoError(t, err)
+	require.True(t, abbr[0] == '~')
+
+	full, err := ExpandVerkey("did:sov:"+did, abbr)
+	require.NoError(t, err)
+	require.Equal(t, verkey, full)
+	n := Nym{Dest: did, Verkey: abbr}
+	full, err = n.FullVerkey()
+	require.NoError(t, err)
+	require.Equal(t, verkey, full)
+
+	full, err = ExpandVerkey(did, verkey)
+	require.NoError(t, err)
+	require.Equal(t, verkey, full)
+	_, err = ExpandVerkey(did, "~V4SGRU86Z58d6TV7PBUe6f"+"1")
+	require.Error(t, err)
+}
+
+func TestCheckVerkey(t *testing.T) {
+	const did, verkey = "V4SGRU86Z58d6TV7PBUe6f", "GJ1SzoWzavQYfNL9XkaJdrQejfztN4XqdsiV4ct3LXKL"
+	require.NoError(t, CheckVerkey(did, verkey))
+	abbr, err := AbbreviateVerkey(did, verkey)
+	require.NoError(t, err)
+	require.NoError(t, CheckVerkey(did, abbr))
+
+	_, other, _, err := KeypairFromSeed([]byte("00000000000000000000000000000001"))
+	require.NoError(t, err)
+	require.Equal(t, ErrVerkeyMismatch, CheckVerkey(did, other))
+	abbr, err = AbbreviateVerkey(did, other)
+	require.NoError(t, err)
+	require.Equal(t, other, abbr)
+
+	require.Error(t, CheckVerkey("short", verkey))
+}
+
+func TestParseSeed(t *testing.T) {
+	const trustee = "000000000000000000000000Trustee1"
+	for _, s := range []string{
+		trustee,
+		" " + trustee + "\n",
+		hex.EncodeToString([]byte(trustee)),
+		base64.StdEncoding.EncodeToString([]byte(trustee)),
+	} {
+		seed, err := ParseSeed(s)
+		require.NoError(t, err)
+		require.Equal(t, trustee, string(seed))
+	}
+
+	_, err := ParseSeed("too short")
+	require.Error(t, err)
+	_, err = ParseSeed("not base64=")
+	require.Error(t, err)
+
+	seed, err := GenerateSeed()
+	require.NoError(t, err)
+	_, _, _, err = KeypairFromSeed(seed)
+	require.NoError(t, err)
+}
+
+func TestReadDidImport(t *testing.T) {
+	signers, err := ReadDidImport(strings.NewReader(`{"version":1,"dids":[
+		{"seed":"000000000000000000000000Trustee1"},
+		{"did":"did:sov:WRfXPg8dantKVubE3HX8pw","seed":"000000000000000000000000Steward1"}
+	]}`))
+	require.NoError(t, err)
+	require.Len(t, signers, 2)
+	require.Equal(t, "V4SGRU86Z58d6TV7PBUe6f", signers[0].Did())
+	require.Equal(t, "WRfXPg8dantKVubE3HX8pw", signers[1].Did())
+
+	_, err = ReadDidImport(strings.NewReader(`{"version":2,"dids":[]}`))
+	require.Error(t, err)
+}
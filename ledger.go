@@ -0,0 +1,291 @@
+package indyclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// txnData is the data of a GET_TXN reply.
+type txnData struct {
+	Block
+	LedgerSize int `json:"ledgerSize"`
+}
+
+// UnmarshalJSON decodes d around Block.UnmarshalJSON, which would
+// otherwise be promoted and skip LedgerSize.
+func (d *txnData) UnmarshalJSON(m []byte) error {
+	if err := json.Unmarshal(m, &d.Block); err != nil {
+		return err
+	}
+	var size struct {
+		LedgerSize int `json:"ledgerSize"`
+	}
+	if err := json.Unmarshal(m, &size); err != nil {
+		return err
+	}
+	d.LedgerSize = size.LedgerSize
+	return nil
+}
+
+// ErrTxnNotFound is returned by GetTxn when the ledger does not contain the
+// requested transaction, usually because it is past the end of the ledger.
+var ErrTxnNotFound = errors.New("transaction not found")
+
+// GetTxn fetches the transaction with sequence number seqNo from ledger like
+// GetTransaction, and decodes the reply. It returns an error matching
+// ErrTxnNotFound if the ledger does not contain seqNo. The LedgerSize of the
+// result tells where the ledger ends, if the validator reported it.
+func (p *Pool) GetTxn(ctx context.Context, ledger LedgerId, seqNo int, opts ...ReadOption) (*GetTxnResult, error) {
+	r, err := p.GetTransaction(ctx, ledger, seqNo, opts...)
+	if err != nil {
+		return nil, err
+	}
+	res, err := r.GetTxnResult()
+	if err != nil {
+		return nil, err
+	}
+	if res.Txn == nil {
+		return nil, fmt.Errorf("%w: seqNo %v of ledger %v", ErrTxnNotFound, seqNo, ledger)
+	}
+	return res, nil
+}
+
+// getTxnWindow is the number of GET_TXN requests GetTransactions keeps in
+// flight at the same time.
+const getTxnWindow = 16
+
+// GetTransactions fetches the transactions with sequence numbers from to to,
+// inclusive, from ledger. It keeps several GET_TXN requests in flight on the
+// connections of the Pool instead of waiting for each reply in turn, and
+// returns the transactions in order. Fewer transactions are returned if the
+// ledger ends before to.
+func (p *Pool) GetTransactions(ctx context.Context, ledger LedgerId, from, to int, opts ...ReadOption) ([]*Block, error) {
+	_, blocks, err := p.fetchTxns(ctx, ledger, from, to, getTxnWindow, opts)
+	return blocks, err
+}
+
+// fetchTxns is GetTransactions with window requests in flight, also
+// returning the replies.
+func (p *Pool) fetchTxns(ctx context.Context, ledger LedgerId, from, to, window int, opts []ReadOption) ([]*Reply, []*Block, error) {
+	if from < 1 {
+		return nil, nil, ErrInvalidSeqNo
+	}
+	if to < from {
+		return nil, nil, nil
+	}
+	rctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	replies := make([]*Reply, to-from+1)
+	blocks := make([]*Block, to-from+1)
+	var (
+		mu       sync.Mutex
+		firstErr error
+		end      = len(blocks) // index of the first missing transaction
+		wg       sync.WaitGroup
+	)
+	sem := make(chan struct{}, window)
+	for i := range blocks {
+		select {
+		case sem <- struct{}{}:
+		case <-rctx.Done():
+		}
+		mu.Lock()
+		stop := firstErr != nil || i >= end || rctx.Err() != nil
+		mu.Unlock()
+		if stop {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			r, err := p.GetTransaction(rctx, ledger, from+i, opts...)
+			var b *Block
+			if err == nil {
+				b, _, err = blockFromReply(r)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil:
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+			case b == nil:
+				if i < end {
+					end = i
+				}
+			default:
+				replies[i], blocks[i] = r, b
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	return replies[:end], blocks[:end], nil
+}
+
+// IterateTransactions sends the transactions of ledger on the returned Block
+// channel in order, starting with seqNo start, until the end of the ledger.
+// Transactions are fetched lazily, a window at a time with GetTransactions,
+// so that the next ones are on their way while the current ones are
+// processed.
+//
+// Iterating stops at the end of the ledger, when ctx is done, or when a
+// request fails; in the latter case the error is sent on the error channel
+// first. Both channels are closed when iterating stops.
+func (p *Pool) IterateTransactions(ctx context.Context, ledger LedgerId, start int) (<-chan *Block, <-chan error) {
+	blocks := make(chan *Block, getTxnWindow)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(blocks)
+
+		for from := start; ; from += getTxnWindow {
+			batch, err := p.GetTransactions(ctx, ledger, from, from+getTxnWindow-1)
+			if err != nil {
+				if ctx.Err() == nil {
+					errs <- err
+				}
+				return
+			}
+			for _, b := range batch {
+				select {
+				case blocks <- b:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if len(batch) < getTxnWindow {
+				return
+			}
+		}
+	}()
+
+	return blocks, errs
+}
+
+// getBlock fetches a single transaction from the ledger. It returns a nil
+// Block if the ledger does not contain seqNo, together with the ledger size
+// if the validator reported it, or 0 otherwise.
+func (p *Pool) getBlock(ctx context.Context, ledger LedgerId, seqNo int) (*Block, int, error) {
+	r, err := p.GetTransaction(ctx, ledger, seqNo)
+	if err != nil {
+		return nil, 0, err
+	}
+	return blockFromReply(r)
+}
+
+// blockFromReply decodes the reply to a GET_TXN request like getBlock.
+func blockFromReply(r *Reply) (*Block, int, error) {
+	res, err := r.GetTxnResult()
+	if err != nil {
+		return nil, 0, err
+	}
+	return res.Txn, res.LedgerSize, nil
+}
+
+// ledgerSize returns the number of transactions currently in the ledger,
+// which is also the seqNo of its last transaction.
+func (p *Pool) ledgerSize(ctx context.Context, ledger LedgerId) (int, error) {
+	return findLedgerSize(func(seqNo int) (*Block, int, error) {
+		return p.getBlock(ctx, ledger, seqNo)
+	})
+}
+
+// findLedgerSize determines the size of a ledger using get, which behaves
+// like getBlock. Validators which do not report the size in GET_TXN replies
+// are probed with a binary search.
+func findLedgerSize(get func(seqNo int) (*Block, int, error)) (int, error) {
+	b, size, err := get(1)
+	if err != nil || b == nil || size > 0 {
+		return size, err
+	}
+
+	// lo is known to exist, hi is the first candidate not yet checked.
+	lo, hi := 1, 2
+	for {
+		b, _, err = get(hi)
+		if err != nil {
+			return 0, err
+		}
+		if b == nil {
+			break
+		}
+		lo, hi = hi, hi*2
+	}
+	// Invariant: lo exists, hi does not.
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		b, _, err = get(mid)
+		if err != nil {
+			return 0, err
+		}
+		if b == nil {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return lo, nil
+}
+
+// FindTxnByTime returns the last transaction of ledger written at or before
+// t, found with a binary search over the txnTime of the transactions, in
+// O(log n) GET_TXN requests. Transactions without a txnTime, such as those
+// of the genesis, are older than any t. It returns an error matching
+// ErrTxnNotFound if the ledger holds no such transaction.
+func (p *Pool) FindTxnByTime(ctx context.Context, ledger LedgerId, t time.Time) (*Block, error) {
+	size, err := p.ledgerSize(ctx, ledger)
+	if err != nil {
+		return nil, err
+	}
+	b, err := findTxnByTime(func(seqNo int) (*Block, error) {
+		b, _, err := p.getBlock(ctx, ledger, seqNo)
+		if err == nil && b == nil {
+			err = fmt.Errorf("%w: seqNo %v of ledger %v", ErrTxnNotFound, seqNo, ledger)
+		}
+		return b, err
+	}, size, t.Unix())
+	if err == nil && b == nil {
+		err = fmt.Errorf("%w: ledger %v has no transaction before %v", ErrTxnNotFound, ledger, t)
+	}
+	return b, err
+}
+
+// findTxnByTime returns the last of the size transactions, fetched with
+// get, whose txnTime is at most t, or nil if there is none.
+func findTxnByTime(get func(seqNo int) (*Block, error), size int, t int64) (*Block, error) {
+	// Invariant: lo is at most t, or 0, hi is after t, or size+1.
+	var found *Block
+	lo, hi := 0, size+1
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		b, err := get(mid)
+		if err != nil {
+			return nil, err
+		}
+		if b.TxnMetadata.TxnTime <= t {
+			lo, found = mid, b
+		} else {
+			hi = mid
+		}
+	}
+	return found, nil
+}
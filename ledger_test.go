@@ -0,0 +1,96 @@
+package indyclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool_GetTxn(t *testing.T) {
+	pool := testPool(t, fakeTransport{"Node1": ledgerValidator(testLedger)})
+
+	res, err := pool.GetTxn(context.Background(), DomainLedger, 2)
+	require.NoError(t, err)
+	require.Equal(t, 2, res.SeqNo)
+	require.Equal(t, "Th7MpTaRZVRYnPiabds81Y", res.Txn.Txn.Data.Dest)
+
+	_, err = pool.GetTxn(context.Background(), DomainLedger, 3)
+	require.True(t, errors.Is(err, ErrTxnNotFound))
+
+	// GetTransaction returns the reply without data as is.
+	r, err := pool.GetTransaction(context.Background(), DomainLedger, 3)
+	require.NoError(t, err)
+	res, err = r.GetTxnResult()
+	require.NoError(t, err)
+	require.Nil(t, res.Txn)
+}
+
+// numberedLedger returns a ledger of n NYM transactions for dest1 to dest<n>.
+func numberedLedger(n int) []string {
+	var ledger []string
+	for i := 1; i <= n; i++ {
+		ledger = append(ledger, fmt.Sprintf(`{"txn":{"type":"1","data":{"dest":"dest%v"}},"txnMetadata":{"seqNo":%v}}`, i, i))
+	}
+	return ledger
+}
+
+func TestPool_GetTransactions(t *testing.T) {
+	pool := testPool(t, fakeTransport{"Node1": ledgerValidator(numberedLedger(40))})
+
+	blocks, err := pool.GetTransactions(context.Background(), DomainLedger, 5, 30)
+	require.NoError(t, err)
+	require.Len(t, blocks, 26)
+	for i, b := range blocks {
+		require.Equal(t, 5+i, b.TxnMetadata.SeqNo)
+	}
+
+	blocks, err = pool.GetTransactions(context.Background(), DomainLedger, 35, 60)
+	require.NoError(t, err)
+	require.Len(t, blocks, 6)
+	require.Equal(t, "dest40", blocks[5].Txn.Data.Dest)
+}
+
+func TestPool_IterateTransactions(t *testing.T) {
+	pool := testPool(t, fakeTransport{"Node1": ledgerValidator(numberedLedger(40))})
+
+	blocks, errs := pool.IterateTransactions(context.Background(), DomainLedger, 3)
+	seqNo := 3
+	for b := range blocks {
+		require.Equal(t, seqNo, b.TxnMetadata.SeqNo)
+		seqNo++
+	}
+	require.NoError(t, <-errs)
+	require.Equal(t, 41, seqNo)
+}
+
+func TestPool_FindTxnByTime(t *testing.T) {
+	ledger := numberedLedger(3)
+	for i := 4; i <= 40; i++ {
+		// Two transactions per second from seqNo 4.
+		ledger = append(ledger, fmt.Sprintf(`{"txn":{"type":"1","data":{"dest":"dest%v"}},"txnMetadata":{"seqNo":%v,"txnTime":%v}}`, i, i, 1000+i/2))
+	}
+	pool := testPool(t, fakeTransport{"Node1": ledgerValidator(ledger)})
+
+	for _, tc := range []struct {
+		time  int64
+		seqNo int
+	}{
+		{999, 3},
+		{1002, 5},
+		{1010, 21},
+		{1020, 40},
+		{5000, 40},
+	} {
+		b, err := pool.FindTxnByTime(context.Background(), DomainLedger, time.Unix(tc.time, 0))
+		require.NoError(t, err)
+		require.Equal(t, tc.seqNo, b.TxnMetadata.SeqNo, tc.time)
+	}
+
+	pool = testPool(t, fakeTransport{"Node1": ledgerValidator(ledger[3:4])})
+	_, err := pool.FindTxnByTime(context.Background(), DomainLedger, time.Unix(1001, 0))
+	require.True(t, errors.Is(err, ErrTxnNotFound))
+}
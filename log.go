@@ -0,0 +1,83 @@
+package indyclient
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// A Level is the severity of a log message.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	}
+	return fmt.Sprintf("LEVEL(%d)", int(l))
+}
+
+// A Logger receives the log messages of a Pool. keyvals alternate keys and
+// values, such as "node" and the alias of a validator, or "reqId" and the
+// reqId of a request. A Logger must be safe for concurrent use.
+type Logger interface {
+	Log(level Level, msg string, keyvals ...interface{})
+}
+
+// WithLogger makes the Pool log to l. By default, a Pool does not log.
+func WithLogger(l Logger) Option {
+	return func(p *Pool) {
+		if l == nil {
+			l = nopLogger{}
+		}
+		p.log = l
+	}
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Log(Level, string, ...interface{}) {}
+
+// NewStdLogger returns a Logger writing the messages of at least level min
+// to l, one line per message with the fields as key=value pairs.
+func NewStdLogger(l *log.Logger, min Level) Logger {
+	return stdLogger{l, min}
+}
+
+type stdLogger struct {
+	l   *log.Logger
+	min Level
+}
+
+func (s stdLogger) Log(level Level, msg string, keyvals ...interface{}) {
+	if level < s.min {
+		return
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%v %v", level, msg)
+	for i := 0; i < len(keyvals); i += 2 {
+		var v interface{} = "MISSING"
+		if i+1 < len(keyvals) {
+			v = keyvals[i+1]
+		}
+		val := fmt.Sprint(v)
+		if strings.ContainsAny(val, " \"=") {
+			val = fmt.Sprintf("%q", val)
+		}
+		fmt.Fprintf(&b, " %v=%v", keyvals[i], val)
+	}
+	s.l.Print(b.String())
+}
@@ -0,0 +1,37 @@
+package indyclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStdLogger(t *testing.T) {
+	var b bytes.Buffer
+	l := NewStdLogger(log.New(&b, "", 0), LevelInfo)
+	l.Log(LevelDebug, "hidden")
+	l.Log(LevelWarn, "request failed", "node", "Node1", "reqId", 42, "err", errors.New("timed out"))
+	require.Equal(t, "WARN request failed node=Node1 reqId=42 err=\"timed out\"\n", b.String())
+}
+
+type recordingLogger struct {
+	msgs []string
+}
+
+func (l *recordingLogger) Log(level Level, msg string, keyvals ...interface{}) {
+	l.msgs = append(l.msgs, msg)
+}
+
+func TestWithLogger(t *testing.T) {
+	// Only Node2 accepts connections, so dialing Node1 fails first.
+	l := new(recordingLogger)
+	pool := testPool(t, fakeTransport{"Node2": ledgerValidator(testLedger)}, WithLogger(l))
+	_, err := pool.GetTransaction(context.Background(), DomainLedger, 1)
+	require.NoError(t, err)
+	require.Contains(t, l.msgs, "dial failed")
+	require.Contains(t, l.msgs, "reply")
+}
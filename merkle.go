@@ -0,0 +1,128 @@
+package indyclient
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+)
+
+// leafHash returns the hash of the ledger transaction txn as a leaf of the
+// ledger's Merkle tree: the hash of its sorted MessagePack serialization.
+func leafHash(txn json.RawMessage) ([]byte, error) {
+	var v interface{}
+	if err := unmarshalNumbers(txn, &v); err != nil {
+		return nil, err
+	}
+	m, err := msgpackEncode(nil, v)
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.Sum256(append([]byte{0}, m...))
+	return h[:], nil
+}
+
+// merkleTree is a compact Merkle tree, as specified by RFC 6962, which
+// only keeps the roots of its perfect subtrees.
+type merkleTree struct {
+	size  int
+	roots [][]byte // roots of the perfect subtrees, largest first
+}
+
+// append adds a leaf with the given hash to the tree.
+func (t *merkleTree) append(leaf []byte) {
+	t.roots = append(t.roots, leaf)
+	// Merge the subtrees of equal size, which correspond to the trailing
+	// ones of the size.
+	for n := t.size; n&1 == 1; n >>= 1 {
+		k := len(t.roots)
+		t.roots = append(t.roots[:k-2], nodeHash(t.roots[k-2], t.roots[k-1]))
+	}
+	t.size++
+}
+
+// root returns the root hash of the tree.
+func (t *merkleTree) root() []byte {
+	if len(t.roots) == 0 {
+		h := sha256.Sum256(nil)
+		return h[:]
+	}
+	r := t.roots[len(t.roots)-1]
+	for i := len(t.roots) - 2; i >= 0; i-- {
+		r = nodeHash(t.roots[i], r)
+	}
+	return r
+}
+
+// verifyConsistency checks the proof that the tree of size second with root
+// secondRoot extends the tree of size first with root firstRoot, as
+// specified by RFC 9162.
+func verifyConsistency(first, second int, firstRoot, secondRoot []byte, proof [][]byte) bool {
+	switch {
+	case first > second:
+		return false
+	case first == second:
+		return len(proof) == 0 && bytes.Equal(firstRoot, secondRoot)
+	case first == 0:
+		return len(proof) == 0
+	}
+	if first&(first-1) == 0 {
+		proof = append([][]byte{firstRoot}, proof...)
+	}
+	if len(proof) == 0 {
+		return false
+	}
+	fn, sn := first-1, second-1
+	for fn&1 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+	fr, sr := proof[0], proof[0]
+	for _, c := range proof[1:] {
+		if sn == 0 {
+			return false
+		}
+		if fn&1 == 1 || fn == sn {
+			fr = nodeHash(c, fr)
+			sr = nodeHash(c, sr)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			sr = nodeHash(sr, c)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	return sn == 0 && bytes.Equal(fr, firstRoot) && bytes.Equal(sr, secondRoot)
+}
+
+// consistencyPrefix returns the compact tree of size first with root
+// firstRoot, which must be the first tree of the consistency proof proof
+// to the tree of size second, verified with verifyConsistency. The leaves
+// following the first ones can then be appended to it.
+func consistencyPrefix(first, second int, firstRoot []byte, proof [][]byte) merkleTree {
+	if first&(first-1) == 0 {
+		return merkleTree{size: first, roots: [][]byte{firstRoot}}
+	}
+	// The nodes which verifyConsistency hashes into the first root are the
+	// roots of the perfect subtrees of the first tree, smallest first.
+	fn, sn := first-1, second-1
+	for fn&1 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+	roots := [][]byte{proof[0]}
+	for _, c := range proof[1:] {
+		if fn&1 == 1 || fn == sn {
+			roots = append([][]byte{c}, roots...)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	return merkleTree{size: first, roots: roots}
+}
@@ -0,0 +1,92 @@
+package indyclient
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// mth computes the Merkle tree hash of leaves as defined by RFC 6962.
+func mth(leaves [][]byte) []byte {
+	switch len(leaves) {
+	case 0:
+		h := sha256.Sum256(nil)
+		return h[:]
+	case 1:
+		return leaves[0]
+	}
+	k := 1
+	for k*2 < len(leaves) {
+		k *= 2
+	}
+	return nodeHash(mth(leaves[:k]), mth(leaves[k:]))
+}
+
+// makeConsistencyProof returns the RFC 6962 proof that the tree of the first m
+// leaves is a prefix of the tree of all leaves.
+func makeConsistencyProof(m int, leaves [][]byte, complete bool) [][]byte {
+	n := len(leaves)
+	if m == n {
+		if complete {
+			return nil
+		}
+		return [][]byte{mth(leaves)}
+	}
+	k := 1
+	for k*2 < n {
+		k *= 2
+	}
+	if m <= k {
+		return append(makeConsistencyProof(m, leaves[:k], complete), mth(leaves[k:]))
+	}
+	return append(makeConsistencyProof(m-k, leaves[k:], false), mth(leaves[:k]))
+}
+
+// makeAuditPath returns the RFC 6962 audit path of the leaf at index in the
+// tree of leaves.
+func makeAuditPath(index int, leaves [][]byte) [][]byte {
+	n := len(leaves)
+	if n <= 1 {
+		return nil
+	}
+	k := 1
+	for k*2 < n {
+		k *= 2
+	}
+	if index < k {
+		return append(makeAuditPath(index, leaves[:k]), mth(leaves[k:]))
+	}
+	return append(makeAuditPath(index-k, leaves[k:]), mth(leaves[:k]))
+}
+
+func TestMerkleTree(t *testing.T) {
+	var leaves [][]byte
+	var tree merkleTree
+	require.Equal(t, mth(nil), tree.root())
+	for i := 0; i < 20; i++ {
+		h := sha256.Sum256([]byte{byte(i)})
+		leaves = append(leaves, h[:])
+		tree.append(h[:])
+		require.Equal(t, mth(leaves), tree.root(), "size %v", i+1)
+	}
+
+	for n := 1; n <= len(leaves); n++ {
+		for i := 0; i < n; i++ {
+			require.True(t, verifyInclusion(leaves[i], i, n, makeAuditPath(i, leaves[:n]), mth(leaves[:n])), "%v in %v", i, n)
+		}
+		for m := 1; m <= n; m++ {
+			proof := makeConsistencyProof(m, leaves[:n], true)
+			require.True(t, verifyConsistency(m, n, mth(leaves[:m]), mth(leaves[:n]), proof), "%v -> %v", m, n)
+			if m < n {
+				var prefix merkleTree
+				for _, l := range leaves[:m] {
+					prefix.append(l)
+				}
+				require.Equal(t, prefix, consistencyPrefix(m, n, mth(leaves[:m]), proof), "%v -> %v", m, n)
+				require.False(t, verifyConsistency(m, n, mth(leaves[1:m+1]), mth(leaves[:n]), proof), "%v -> %v", m, n)
+				require.False(t, verifyConsistency(m, n, mth(leaves[:m]), mth(leaves[:n]), proof[1:]), "%v -> %v", m, n)
+			}
+		}
+	}
+}
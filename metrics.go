@@ -0,0 +1,47 @@
+package indyclient
+
+import "time"
+
+// Metrics receives the events of a Pool, to feed counters and histograms
+// such as those of Prometheus. node is the alias of the validator involved.
+// Every connection opened after the first one to a validator is a
+// reconnect. A Metrics must be safe for concurrent use.
+//
+// For example, with github.com/prometheus/client_golang:
+//
+//	func (m *promMetrics) ReplyReceived(node, op string, latency time.Duration) {
+//		m.replies.WithLabelValues(node, op).Inc()
+//		m.latency.WithLabelValues(node).Observe(latency.Seconds())
+//	}
+type Metrics interface {
+	// Connected is called when a connection to node is opened.
+	Connected(node string)
+	// DialFailed is called when connecting to node failed.
+	DialFailed(node string, err error)
+	// RequestSent is called when a request is sent to node.
+	RequestSent(node string)
+	// ReplyReceived is called when node answered a request, with op
+	// REPLY, REQNACK or REJECT, after latency.
+	ReplyReceived(node, op string, latency time.Duration)
+	// RequestFailed is called when node did not answer a request. err
+	// wraps ErrReplyTimeout if it did not answer in time.
+	RequestFailed(node string, err error)
+}
+
+// WithMetrics makes the Pool report its events to m.
+func WithMetrics(m Metrics) Option {
+	return func(p *Pool) {
+		if m == nil {
+			m = nopMetrics{}
+		}
+		p.metrics = m
+	}
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) Connected(string)                            {}
+func (nopMetrics) DialFailed(string, error)                    {}
+func (nopMetrics) RequestSent(string)                          {}
+func (nopMetrics) ReplyReceived(string, string, time.Duration) {}
+func (nopMetrics) RequestFailed(string, error)                 {}
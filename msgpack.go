@@ -0,0 +1,127 @@
+package indyclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// msgpackEncode appends the MessagePack encoding of v, a value decoded from
+// JSON with json.Number numbers, to b. Map keys are sorted, which is how the
+// validators serialize transactions for the ledger's Merkle tree.
+func msgpackEncode(b []byte, v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case nil:
+		return append(b, 0xc0), nil
+	case bool:
+		if v {
+			return append(b, 0xc3), nil
+		}
+		return append(b, 0xc2), nil
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return msgpackInt(b, i), nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, 0xcb)
+		return appendUint64(b, math.Float64bits(f)), nil
+	case string:
+		n := len(v)
+		switch {
+		case n < 32:
+			b = append(b, 0xa0|byte(n))
+		case n <= math.MaxUint8:
+			b = append(b, 0xd9, byte(n))
+		case n <= math.MaxUint16:
+			b = append(b, 0xda)
+			b = appendUint16(b, uint16(n))
+		default:
+			b = append(b, 0xdb)
+			b = appendUint32(b, uint32(n))
+		}
+		return append(b, v...), nil
+	case []interface{}:
+		b = msgpackHeader(b, len(v), 0x90, 0xdc)
+		for _, e := range v {
+			var err error
+			if b, err = msgpackEncode(b, e); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b = msgpackHeader(b, len(v), 0x80, 0xde)
+		for _, k := range keys {
+			var err error
+			if b, err = msgpackEncode(b, k); err != nil {
+				return nil, err
+			}
+			if b, err = msgpackEncode(b, v[k]); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("cannot encode %T", v)
+}
+
+// msgpackHeader appends the header of an array or map of n elements, given
+// the fix and 16 bit type bytes; the 32 bit type follows the latter.
+func msgpackHeader(b []byte, n int, fix, t16 byte) []byte {
+	switch {
+	case n < 16:
+		return append(b, fix|byte(n))
+	case n <= math.MaxUint16:
+		b = append(b, t16)
+		return appendUint16(b, uint16(n))
+	default:
+		b = append(b, t16+1)
+		return appendUint32(b, uint32(n))
+	}
+}
+
+// msgpackInt appends the shortest encoding of i.
+func msgpackInt(b []byte, i int64) []byte {
+	switch {
+	case i >= 0 && i < 128:
+		return append(b, byte(i))
+	case i >= -32 && i < 0:
+		return append(b, byte(i))
+	case i >= 0 && i <= math.MaxUint8:
+		return append(b, 0xcc, byte(i))
+	case i >= 0 && i <= math.MaxUint16:
+		return appendUint16(append(b, 0xcd), uint16(i))
+	case i >= 0 && i <= math.MaxUint32:
+		return appendUint32(append(b, 0xce), uint32(i))
+	case i >= 0:
+		return appendUint64(append(b, 0xcf), uint64(i))
+	case i >= math.MinInt8:
+		return append(b, 0xd0, byte(i))
+	case i >= math.MinInt16:
+		return appendUint16(append(b, 0xd1), uint16(i))
+	case i >= math.MinInt32:
+		return appendUint32(append(b, 0xd2), uint32(i))
+	}
+	return appendUint64(append(b, 0xd3), uint64(i))
+}
+
+func appendUint16(b []byte, v uint16) []byte {
+	return append(b, byte(v>>8), byte(v))
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	return appendUint32(appendUint32(b, uint32(v>>32)), uint32(v))
+}
@@ -0,0 +1,287 @@
+package indyclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// knownNetwork is where the genesis transactions of a named network come
+// from: either an https URL, whose download must match sha256sum if it is
+// not empty, or transactions registered with RegisterGenesis.
+type knownNetwork struct {
+	url       string
+	sha256sum string
+	genesis   []byte
+}
+
+var (
+	networksMu sync.Mutex
+	networks   = map[string]knownNetwork{
+		"sovrin-mainnet":    {url: "https://raw.githubusercontent.com/sovrin-foundation/sovrin/stable/sovrin/pool_transactions_live_genesis"},
+		"sovrin-stagingnet": {url: "https://raw.githubusercontent.com/sovrin-foundation/sovrin/stable/sovrin/pool_transactions_sandbox_genesis"},
+		"sovrin-buildernet": {url: "https://raw.githubusercontent.com/sovrin-foundation/sovrin/stable/sovrin/pool_transactions_builder_genesis"},
+		"indicio-mainnet":   {url: "https://raw.githubusercontent.com/Indicio-tech/indicio-network/main/genesis_files/pool_transactions_mainnet_genesis"},
+		"indicio-testnet":   {url: "https://raw.githubusercontent.com/Indicio-tech/indicio-network/main/genesis_files/pool_transactions_testnet_genesis"},
+		"indicio-demonet":   {url: "https://raw.githubusercontent.com/Indicio-tech/indicio-network/main/genesis_files/pool_transactions_demonet_genesis"},
+		"bcovrin-test":      {url: "https://test.bcovrin.vonx.io/genesis"},
+		"bcovrin-dev":       {url: "https://dev.bcovrin.vonx.io/genesis"},
+		"idunion-testnet":   {url: "https://raw.githubusercontent.com/IDunion/IDunion_TestNet_Genesis/master/pool_transactions_genesis"},
+	}
+)
+
+// ErrUnknownNetwork is returned for names of networks which are neither
+// built in nor registered.
+var ErrUnknownNetwork = errors.New("unknown network")
+
+// RegisterNetwork makes the network name known to KnownNetwork, with its
+// genesis transactions published at genesisURL, which must be an https URL
+// unless the network is pinned with PinGenesis. It replaces any network of
+// the same name.
+func RegisterNetwork(name, genesisURL string) {
+	networksMu.Lock()
+	defer networksMu.Unlock()
+	networks[name] = knownNetwork{url: genesisURL}
+}
+
+// PinGenesis makes KnownNetwork only accept genesis transactions of the
+// network name whose hex SHA-256 hash is sha256sum, as NewPoolFromURL does,
+// instead of trusting whatever its URL serves. The pin must be updated when
+// the operator of the network publishes new genesis transactions.
+func PinGenesis(name, sha256sum string) error {
+	networksMu.Lock()
+	defer networksMu.Unlock()
+	n, ok := networks[name]
+	if !ok {
+		return fmt.Errorf("%w: %v", ErrUnknownNetwork, name)
+	}
+	if n.url == "" {
+		return fmt.Errorf("genesis of %v is registered, not downloaded", name)
+	}
+	n.sha256sum = sha256sum
+	networks[name] = n
+	return nil
+}
+
+// RegisterGenesis makes the network name known to KnownNetwork, with the
+// given genesis transactions. It replaces any network of the same name.
+func RegisterGenesis(name string, genesis []byte) {
+	networksMu.Lock()
+	defer networksMu.Unlock()
+	networks[name] = knownNetwork{genesis: append([]byte(nil), genesis...)}
+}
+
+// KnownNetworks returns the sorted names of the networks known to
+// KnownNetwork. The built-in networks are sovrin-mainnet,
+// sovrin-stagingnet, sovrin-buildernet, indicio-mainnet, indicio-testnet,
+// indicio-demonet, bcovrin-test, bcovrin-dev and idunion-testnet.
+func KnownNetworks() []string {
+	networksMu.Lock()
+	defer networksMu.Unlock()
+	names := make([]string, 0, len(networks))
+	for name := range networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// KnownNetwork returns a Pool for the network name, one of KnownNetworks.
+// The genesis transactions of the built-in networks are downloaded over
+// https from where their operators publish them, so that they are never
+// outdated. As the validator keys they list are what the Pool trusts,
+// applications which cannot trust the publisher should pin them with
+// PinGenesis.
+func KnownNetwork(ctx context.Context, name string, opts ...Option) (*Pool, error) {
+	g, err := knownGenesis(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return NewPoolFromBytes(g, opts...)
+}
+
+// SovrinPool returns the genesis transactions of the Sovrin network name,
+// MainNet, StagingNet or BuilderNet, for NewPool. They are downloaded on
+// the first Read, whose error NewPool reports.
+func SovrinPool(name string) io.Reader {
+	return &lazyReader{get: func() ([]byte, error) {
+		return knownGenesis(context.Background(), "sovrin-"+strings.ToLower(name))
+	}}
+}
+
+// knownGenesis returns the genesis transactions of the network name.
+func knownGenesis(ctx context.Context, name string) ([]byte, error) {
+	networksMu.Lock()
+	n, ok := networks[name]
+	networksMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownNetwork, name)
+	}
+	if n.genesis != nil {
+		return n.genesis, nil
+	}
+	if n.sha256sum == "" && !strings.HasPrefix(n.url, "https://") {
+		return nil, fmt.Errorf("%w: genesis of %v: %v", ErrInsecureGenesis, name, n.url)
+	}
+	g, err := cachedGenesis(ctx, n.url, n.sha256sum)
+	if err != nil {
+		return nil, fmt.Errorf("genesis of %v: %w", name, err)
+	}
+	return g, nil
+}
+
+// ErrGenesisChecksum is returned by NewPoolFromURL and KnownNetwork when
+// the genesis transactions do not match the pinned hash.
+var ErrGenesisChecksum = errors.New("genesis does not match its checksum")
+
+// ErrInsecureGenesis is returned for genesis transactions which would be
+// downloaded without https nor a pinned hash, so that anyone on the path
+// could replace the validators the Pool trusts.
+var ErrInsecureGenesis = errors.New("insecure genesis URL")
+
+// GenesisCacheDir is where NewPoolFromURL and KnownNetwork keep the genesis
+// files they download. It defaults to indyclient/genesis in the user cache
+// directory; caching is disabled if it is empty.
+var GenesisCacheDir = defaultGenesisCacheDir()
+
+func defaultGenesisCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "indyclient", "genesis")
+}
+
+// NewPoolFromURL returns a Pool for the genesis transactions published at
+// url, such as the raw GitHub URL of a pool_transactions_genesis file. If
+// sha256sum, the hex SHA-256 hash of the file, is not empty, the download
+// must match it, and a cached copy matching it is used without downloading
+// it again. The file is cached in GenesisCacheDir, so that a Pool can still
+// be created when the download fails, as it can for the URLs of the
+// built-in networks embedded by go generate. Without sha256sum, url must be
+// an https URL.
+func NewPoolFromURL(ctx context.Context, url, sha256sum string, opts ...Option) (*Pool, error) {
+	if sha256sum == "" && !strings.HasPrefix(url, "https://") {
+		return nil, fmt.Errorf("%w: %v", ErrInsecureGenesis, url)
+	}
+	g, err := cachedGenesis(ctx, url, sha256sum)
+	if err != nil {
+		return nil, err
+	}
+	return NewPoolFromBytes(g, opts...)
+}
+
+// cachedGenesis returns the genesis transactions at url, downloading them
+// unless the cache holds a copy matching sha256sum.
+func cachedGenesis(ctx context.Context, url, sha256sum string) ([]byte, error) {
+	sha256sum = strings.ToLower(sha256sum)
+	matches := func(g []byte) bool {
+		h := sha256.Sum256(g)
+		return sha256sum == "" || hex.EncodeToString(h[:]) == sha256sum
+	}
+
+	var cache string
+	if GenesisCacheDir != "" {
+		h := sha256.Sum256([]byte(url))
+		cache = filepath.Join(GenesisCacheDir, hex.EncodeToString(h[:16]))
+	}
+	var cached []byte
+	if cache != "" {
+		cached, _ = ioutil.ReadFile(cache)
+		if cached != nil && sha256sum != "" && matches(cached) {
+			return cached, nil
+		}
+	}
+
+	g, err := fetchGenesis(ctx, url)
+	if err != nil {
+		if cached != nil && matches(cached) {
+			return cached, nil
+		}
+		if e, ok := embeddedGenesis[url]; ok && matches([]byte(e)) {
+			return []byte(e), nil
+		}
+		return nil, err
+	}
+	if !matches(g) {
+		return nil, fmt.Errorf("%w: %v", ErrGenesisChecksum, url)
+	}
+	if cache != "" {
+		// The cache is an optimization: failing to write it is fine.
+		if os.MkdirAll(GenesisCacheDir, 0755) == nil {
+			ioutil.WriteFile(cache, g, 0644)
+		}
+	}
+	return g, nil
+}
+
+//go:generate go run gen_genesis.go
+
+// embeddedGenesis maps the URLs of the built-in networks to copies of their
+// genesis transactions, which genesis_data.go sets when generated from the
+// networks above. cachedGenesis falls back to them when the download fails
+// and nothing is cached, so that Pools of the built-in networks can be
+// created offline, whose validators Refresh then brings up to date.
+var embeddedGenesis = map[string]string{}
+
+// maxGenesisSize bounds the size of downloaded genesis files.
+const maxGenesisSize = 16 << 20
+
+// genesisClient downloads genesis files. Tests replace it with the client of
+// their TLS server.
+var genesisClient = http.DefaultClient
+
+// fetchGenesis downloads the genesis transactions at url.
+func fetchGenesis(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := genesisClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %v: %v", url, resp.Status)
+	}
+	g, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxGenesisSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(g) > maxGenesisSize {
+		return nil, fmt.Errorf("GET %v: genesis larger than %v bytes", url, maxGenesisSize)
+	}
+	return g, nil
+}
+
+// lazyReader reads the bytes returned by get, which is called on the first
+// Read.
+type lazyReader struct {
+	get func() ([]byte, error)
+	r   io.Reader
+	err error
+}
+
+func (l *lazyReader) Read(b []byte) (int, error) {
+	if l.r == nil && l.err == nil {
+		var g []byte
+		g, l.err = l.get()
+		l.r = bytes.NewReader(g)
+	}
+	if l.err != nil {
+		return 0, l.err
+	}
+	return l.r.Read(b)
+}
@@ -0,0 +1,126 @@
+package indyclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKnownNetwork(t *testing.T) {
+	g := testGenesis(t, "10.0.0.1:9702", "10.0.0.2:9702")
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(g)
+	}))
+	defer srv.Close()
+	defer func(old *http.Client) { genesisClient = old }(genesisClient)
+	genesisClient = srv.Client()
+	defer func(old string) { GenesisCacheDir = old }(GenesisCacheDir)
+	GenesisCacheDir = ""
+
+	RegisterNetwork("test-url", srv.URL)
+	RegisterGenesis("test-bytes", g)
+	require.Contains(t, KnownNetworks(), "sovrin-buildernet")
+	require.Contains(t, KnownNetworks(), "test-url")
+
+	for _, name := range []string{"test-url", "test-bytes"} {
+		pool, err := KnownNetwork(context.Background(), name)
+		require.NoError(t, err)
+		require.Len(t, pool.Validators, 2)
+	}
+
+	_, err := KnownNetwork(context.Background(), "nonet")
+	require.True(t, errors.Is(err, ErrUnknownNetwork))
+	_, err = NewPool(SovrinPool("NoNet"))
+	require.Error(t, err)
+
+	// Pinned networks only accept the genesis matching their hash.
+	h := sha256.Sum256(g)
+	require.NoError(t, PinGenesis("test-url", "00"+hex.EncodeToString(h[1:])))
+	_, err = KnownNetwork(context.Background(), "test-url")
+	require.True(t, errors.Is(err, ErrGenesisChecksum), "%v", err)
+	require.NoError(t, PinGenesis("test-url", hex.EncodeToString(h[:])))
+	_, err = KnownNetwork(context.Background(), "test-url")
+	require.NoError(t, err)
+	require.Error(t, PinGenesis("test-bytes", hex.EncodeToString(h[:])))
+
+	// Networks served over http must be pinned.
+	plain := httptest.NewServer(srv.Config.Handler)
+	defer plain.Close()
+	RegisterNetwork("test-http", plain.URL)
+	_, err = KnownNetwork(context.Background(), "test-http")
+	require.True(t, errors.Is(err, ErrInsecureGenesis), "%v", err)
+	require.NoError(t, PinGenesis("test-http", hex.EncodeToString(h[:])))
+	_, err = KnownNetwork(context.Background(), "test-http")
+	require.NoError(t, err)
+
+	for _, name := range KnownNetworks() {
+		if !strings.HasPrefix(name, "test-") {
+			require.True(t, strings.HasPrefix(networks[name].url, "https://"), name)
+		}
+	}
+}
+
+func TestNewPoolFromURL(t *testing.T) {
+	dir, err := ioutil.TempDir("", "indyclient")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(old string) { GenesisCacheDir = old }(GenesisCacheDir)
+	GenesisCacheDir = dir
+
+	g := testGenesis(t, "10.0.0.1:9702", "10.0.0.2:9702")
+	h := sha256.Sum256(g)
+	sum := hex.EncodeToString(h[:])
+	var down int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&down) != 0 {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(g)
+	}))
+	defer srv.Close()
+	defer func(old *http.Client) { genesisClient = old }(genesisClient)
+	genesisClient = srv.Client()
+
+	_, err = NewPoolFromURL(context.Background(), srv.URL, "00"+sum[2:])
+	require.True(t, errors.Is(err, ErrGenesisChecksum))
+
+	pool, err := NewPoolFromURL(context.Background(), srv.URL, sum)
+	require.NoError(t, err)
+	require.Len(t, pool.Validators, 2)
+
+	// The cached copy is used once the server is down.
+	atomic.StoreInt32(&down, 1)
+	_, err = NewPoolFromURL(context.Background(), srv.URL, "")
+	require.NoError(t, err)
+	_, err = NewPoolFromURL(context.Background(), srv.URL+"/other", "")
+	require.Error(t, err)
+
+	// So are the embedded genesis transactions of the built-in networks.
+	embeddedGenesis[srv.URL+"/embedded"] = string(g)
+	defer delete(embeddedGenesis, srv.URL+"/embedded")
+	pool, err = NewPoolFromURL(context.Background(), srv.URL+"/embedded", "")
+	require.NoError(t, err)
+	require.Len(t, pool.Validators, 2)
+	_, err = NewPoolFromURL(context.Background(), srv.URL+"/embedded", "00"+sum[2:])
+	require.Error(t, err)
+
+	// Over http, only a pinned genesis is accepted.
+	atomic.StoreInt32(&down, 0)
+	plain := httptest.NewServer(srv.Config.Handler)
+	defer plain.Close()
+	_, err = NewPoolFromURL(context.Background(), plain.URL, "")
+	require.True(t, errors.Is(err, ErrInsecureGenesis), "%v", err)
+	_, err = NewPoolFromURL(context.Background(), plain.URL, sum)
+	require.NoError(t, err)
+}
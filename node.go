@@ -0,0 +1,119 @@
+package indyclient
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/mr-tron/base58"
+)
+
+// NodeData is the data of a NODE transaction. Only Alias is required when
+// updating a node: the fields left empty are not changed. A nil Services
+// leaves the services unchanged, while an empty non-nil Services demotes the
+// node from validator.
+type NodeData struct {
+	Alias      string
+	ClientIP   string
+	ClientPort int
+	NodeIP     string
+	NodePort   int
+	Services   []string
+	BlsKey     string
+	BlsKeyPop  string // proof of possession of BlsKey, required with it
+}
+
+// MarshalJSON encodes d as in NODE transactions.
+func (d NodeData) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Alias      string    `json:"alias"`
+		ClientIP   string    `json:"client_ip,omitempty"`
+		ClientPort int       `json:"client_port,omitempty"`
+		NodeIP     string    `json:"node_ip,omitempty"`
+		NodePort   int       `json:"node_port,omitempty"`
+		Services   *[]string `json:"services,omitempty"`
+		BlsKey     string    `json:"blskey,omitempty"`
+		BlsKeyPop  string    `json:"blskey_pop,omitempty"`
+	}{
+		Alias:      d.Alias,
+		ClientIP:   d.ClientIP,
+		ClientPort: d.ClientPort,
+		NodeIP:     d.NodeIP,
+		NodePort:   d.NodePort,
+		Services:   servicesField(d.Services),
+		BlsKey:     d.BlsKey,
+		BlsKeyPop:  d.BlsKeyPop,
+	})
+}
+
+func servicesField(s []string) *[]string {
+	if s == nil {
+		return nil
+	}
+	return &s
+}
+
+// validate checks d the way validators do, to fail before submission.
+func (d *NodeData) validate() error {
+	if d.Alias == "" {
+		return errors.New("node has no alias")
+	}
+	for _, ip := range []struct{ name, ip string }{{"client_ip", d.ClientIP}, {"node_ip", d.NodeIP}} {
+		if ip.ip != "" && net.ParseIP(ip.ip) == nil {
+			return fmt.Errorf("node %v: invalid %v %q", d.Alias, ip.name, ip.ip)
+		}
+	}
+	for _, port := range []struct {
+		name string
+		port int
+	}{{"client_port", d.ClientPort}, {"node_port", d.NodePort}} {
+		if port.port < 0 || port.port > 65535 {
+			return fmt.Errorf("node %v: invalid %v %v", d.Alias, port.name, port.port)
+		}
+	}
+	if (d.ClientIP == "") != (d.ClientPort == 0) || (d.NodeIP == "") != (d.NodePort == 0) {
+		return fmt.Errorf("node %v: addresses need both an ip and a port", d.Alias)
+	}
+	if d.ClientIP != "" && d.ClientIP == d.NodeIP && d.ClientPort == d.NodePort {
+		return fmt.Errorf("node %v: client and node addresses must differ", d.Alias)
+	}
+	for _, s := range d.Services {
+		if s != "VALIDATOR" {
+			return fmt.Errorf("node %v: unknown service %q", d.Alias, s)
+		}
+	}
+	if d.BlsKey != "" && d.BlsKeyPop == "" {
+		return fmt.Errorf("node %v: blskey needs a proof of possession", d.Alias)
+	}
+	return nil
+}
+
+type nodeOp struct {
+	Type protoId  `json:"type,string"`
+	Dest string   `json:"dest"`
+	Data NodeData `json:"data"`
+}
+
+// WriteNode writes a NODE transaction, with which stewards add their
+// validator to the pool or update its addresses, services and BLS keys.
+// nodeVerkey is the base58 encoded verkey of the node, which identifies it.
+func (p *Pool) WriteNode(ctx context.Context, signer Signer, nodeVerkey string, data NodeData) (*Block, error) {
+	vk, err := base58.Decode(nodeVerkey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid node verkey: %v", err)
+	}
+	if len(vk) != ed25519.PublicKeySize {
+		return nil, errors.New("node verkey is not 32 bytes long")
+	}
+	if err := data.validate(); err != nil {
+		return nil, err
+	}
+	return p.write(ctx, nodeOp{
+		Type: idNode,
+		Dest: nodeVerkey,
+		Data: data,
+	}, signer)
+}
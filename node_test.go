@@ -0,0 +1,43 @@
+package indyclient
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeData(t *testing.T) {
+	d := NodeData{
+		Alias:      "Node5",
+		ClientIP:   "10.0.0.5",
+		ClientPort: 9702,
+		NodeIP:     "10.0.0.5",
+		NodePort:   9701,
+		Services:   []string{"VALIDATOR"},
+		BlsKey:     "bls5",
+		BlsKeyPop:  "pop5",
+	}
+	require.NoError(t, d.validate())
+	m, err := json.Marshal(d)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"alias":"Node5","client_ip":"10.0.0.5","client_port":9702,"node_ip":"10.0.0.5","node_port":9701,"services":["VALIDATOR"],"blskey":"bls5","blskey_pop":"pop5"}`, string(m))
+
+	demote := NodeData{Alias: "Node5", Services: []string{}}
+	require.NoError(t, demote.validate())
+	m, err = json.Marshal(demote)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"alias":"Node5","services":[]}`, string(m))
+
+	for _, bad := range []NodeData{
+		{},
+		{Alias: "Node5", ClientIP: "node5.example.com", ClientPort: 9702},
+		{Alias: "Node5", ClientIP: "10.0.0.5"},
+		{Alias: "Node5", NodeIP: "10.0.0.5", NodePort: 70000},
+		{Alias: "Node5", Services: []string{"OBSERVER"}},
+		{Alias: "Node5", BlsKey: "bls5"},
+		{Alias: "Node5", ClientIP: "10.0.0.5", ClientPort: 9701, NodeIP: "10.0.0.5", NodePort: 9701},
+	} {
+		require.Error(t, bad.validate(), "%+v", bad)
+	}
+}
@@ -0,0 +1,230 @@
+package indyclient
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mr-tron/base58"
+)
+
+type getNymOp struct {
+	Type      protoId `json:"type,string"`
+	Dest      string  `json:"dest"`
+	SeqNo     int     `json:"seqNo,omitempty"`     // NYM as written by this transaction
+	Timestamp int64   `json:"timestamp,omitempty"` // NYM as of this time
+}
+
+type nymOp struct {
+	Type   protoId `json:"type,string"`
+	Dest   string  `json:"dest"`
+	Verkey string  `json:"verkey,omitempty"`
+	Role   string  `json:"role,omitempty"`
+	Alias  string  `json:"alias,omitempty"`
+}
+
+// Nym is the ledger entry of a DID, as returned by GET_NYM.
+type Nym struct {
+	Dest       string `json:"dest"`
+	Identifier string `json:"identifier"` // the DID which wrote the NYM
+	Verkey     string `json:"verkey"`     // possibly abbreviated, see Indy docs
+	Role       string `json:"role"`       // role code, "" for none
+	SeqNo      int    `json:"seqNo"`
+	TxnTime    int64  `json:"txnTime"`
+}
+
+// FullVerkey returns the verkey of n, expanded if it is abbreviated.
+func (n *Nym) FullVerkey() (string, error) {
+	return ExpandVerkey(n.Dest, n.Verkey)
+}
+
+// GetNym fetches the NYM of did, given as a DID or a bare identifier, with
+// a GET_NYM request. It returns ErrNoData if the DID is not on the ledger.
+func (p *Pool) GetNym(ctx context.Context, did string, opts ...ReadOption) (*Nym, error) {
+	return p.getNym(ctx, did, 0, 0, opts)
+}
+
+// GetNymAt fetches the NYM of did as it was at time t: its verkey and role
+// then. It returns ErrNoData if the DID did not exist yet.
+func (p *Pool) GetNymAt(ctx context.Context, did string, t time.Time, opts ...ReadOption) (*Nym, error) {
+	return p.nymVersion(ctx, did, 0, t.Unix(), opts)
+}
+
+// GetNymVersion fetches the NYM of did as written by the transaction seqNo
+// of the domain ledger. It returns ErrNoData if that transaction is not a
+// NYM of did.
+func (p *Pool) GetNymVersion(ctx context.Context, did string, seqNo int, opts ...ReadOption) (*Nym, error) {
+	if seqNo < 1 {
+		return nil, ErrInvalidSeqNo
+	}
+	return p.nymVersion(ctx, did, seqNo, 0, opts)
+}
+
+// nymVersion is getNym for a past version of the NYM of did. Validators
+// older than indy-node 1.13 ignore the seqNo and timestamp of GET_NYM and
+// answer with the current NYM; if that is not the version asked for, the
+// version is rebuilt from the history of the domain ledger instead.
+func (p *Pool) nymVersion(ctx context.Context, did string, seqNo int, timestamp int64, opts []ReadOption) (*Nym, error) {
+	nym, err := p.getNym(ctx, did, seqNo, timestamp, opts)
+	if err != nil {
+		// NYMs are never deleted: a DID missing now never existed.
+		return nil, err
+	}
+	switch {
+	case seqNo != 0 && nym.SeqNo > seqNo:
+	case timestamp != 0 && nym.TxnTime > timestamp:
+	case seqNo != 0 && nym.SeqNo != seqNo:
+		return nil, ErrNoData
+	default:
+		return nym, nil
+	}
+	id, err := didId(did)
+	if err != nil {
+		return nil, err
+	}
+	return p.nymFromHistory(ctx, id, func(b *Block) bool {
+		if seqNo != 0 {
+			return b.TxnMetadata.SeqNo > seqNo
+		}
+		return b.TxnMetadata.TxnTime > timestamp
+	}, seqNo)
+}
+
+// nymFromHistory rebuilds the NYM of id by applying the NYM transactions
+// of the domain ledger for id until done returns true. If seqNo is not
+// zero, the NYM must have been written by that transaction.
+func (p *Pool) nymFromHistory(ctx context.Context, id string, done func(b *Block) bool, seqNo int) (*Nym, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	blocks, errs := p.IterateTransactions(ctx, DomainLedger, 1)
+	var nym *Nym
+	for b := range blocks {
+		if done(b) {
+			break
+		}
+		if b.Txn.Type != idNym {
+			continue
+		}
+		n, err := decodeNymTxn(b.Txn.Data.Raw)
+		if err != nil {
+			return nil, err
+		}
+		if n.Dest != id {
+			continue
+		}
+		if nym == nil {
+			from, _ := b.Txn.Metadata["from"].(string)
+			nym = &Nym{Dest: id, Identifier: from}
+		}
+		if n.Verkey != "" {
+			nym.Verkey = n.Verkey
+		}
+		if n.Role != nil {
+			nym.Role = *n.Role
+		}
+		nym.SeqNo, nym.TxnTime = b.TxnMetadata.SeqNo, b.TxnMetadata.TxnTime
+	}
+	cancel()
+	if err := <-errs; err != nil {
+		return nil, err
+	}
+	if nym == nil || (seqNo != 0 && nym.SeqNo != seqNo) {
+		return nil, ErrNoData
+	}
+	return nym, nil
+}
+
+// getNym is GetNym for the version of the NYM written by the transaction
+// seqNo, or current at timestamp, if either is not zero.
+func (p *Pool) getNym(ctx context.Context, did string, seqNo int, timestamp int64, opts []ReadOption) (*Nym, error) {
+	id, err := didId(did)
+	if err != nil {
+		return nil, err
+	}
+	r, err := p.read(ctx, getNymOp{
+		Type:      idGetNym,
+		Dest:      id,
+		SeqNo:     seqNo,
+		Timestamp: timestamp,
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkReply(r); err != nil {
+		return nil, err
+	}
+	return nymFromReply(r)
+}
+
+func nymFromReply(r *Reply) (*Nym, error) {
+	nym := new(Nym)
+	if err := r.DecodeResult(nym); err != nil {
+		return nil, err
+	}
+	return nym, nil
+}
+
+// WriteNym writes a NYM transaction signed by signer, which creates
+// targetDid with the given verkey, role code and alias, or updates them if
+// it exists. Empty verkey, role and alias are left out of the request: use
+// RemoveRole to remove a role. It returns the transaction once the pool has
+// ordered it.
+func (p *Pool) WriteNym(ctx context.Context, signer Signer, targetDid, verkey, role, alias string) (*Block, error) {
+	req, err := NewNymRequest(targetDid, verkey, role, alias)
+	if err != nil {
+		return nil, err
+	}
+	return p.write(ctx, req.Operation, signer)
+}
+
+// ErrRotationUnconfirmed is returned by RotateVerkey when the NYM read back
+// after the rotation does not hold the new verkey.
+var ErrRotationUnconfirmed = errors.New("verkey rotation not confirmed")
+
+// rotateConfirmWait bounds how long RotateVerkey waits for a validator
+// which has applied the rotation.
+const rotateConfirmWait = 10 * time.Second
+
+// RotateVerkey replaces the verkey of did with newVerkey, which may be
+// abbreviated, with a NYM transaction signed by signer, which must hold the
+// current key of the owner of did. It then reads the NYM back from a
+// validator which has applied the transaction and returns it, or an error
+// matching ErrRotationUnconfirmed if it does not hold newVerkey. From then
+// on, requests of did must be signed with the key of newVerkey. If did
+// already has newVerkey, nothing is written.
+func (p *Pool) RotateVerkey(ctx context.Context, signer Signer, did, newVerkey string) (*Nym, error) {
+	id, err := didId(did)
+	if err != nil {
+		return nil, err
+	}
+	full, err := ExpandVerkey(id, newVerkey)
+	if err != nil {
+		return nil, err
+	}
+	if vk, err := base58.Decode(full); err != nil || len(vk) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid verkey %v", newVerkey)
+	}
+
+	current, err := p.GetNym(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the NYM of %v: %w", id, err)
+	}
+	if vk, err := current.FullVerkey(); err == nil && vk == full {
+		return current, nil
+	}
+
+	b, err := p.WriteNym(ctx, signer, id, newVerkey, "", "")
+	if err != nil {
+		return nil, err
+	}
+	nym, err := p.GetNym(ctx, id, WithMinFreshness(time.Unix(b.TxnMetadata.TxnTime, 0), rotateConfirmWait))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRotationUnconfirmed, err)
+	}
+	if vk, err := nym.FullVerkey(); err != nil || vk != full {
+		return nil, fmt.Errorf("%w: %v has verkey %v", ErrRotationUnconfirmed, id, nym.Verkey)
+	}
+	return nym, nil
+}
@@ -0,0 +1,120 @@
+package indyclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool_GetNymAt(t *testing.T) {
+	ledger := []string{
+		`{"txn":{"type":"1","data":{"dest":"V4SGRU86Z58d6TV7PBUe6f","verkey":"~key1","role":"101"},"metadata":{"from":"Th7MpTaRZVRYnPiabds81Y"}},"txnMetadata":{"seqNo":1,"txnTime":1000}}`,
+		`{"txn":{"type":"1","data":{"dest":"Th7MpTaRZVRYnPiabds81Y","verkey":"~other"},"metadata":{}},"txnMetadata":{"seqNo":2,"txnTime":2000}}`,
+		`{"txn":{"type":"1","data":{"dest":"V4SGRU86Z58d6TV7PBUe6f","verkey":"~key2"},"metadata":{"from":"V4SGRU86Z58d6TV7PBUe6f"}},"txnMetadata":{"seqNo":3,"txnTime":3000}}`,
+		`{"txn":{"type":"1","data":{"dest":"V4SGRU86Z58d6TV7PBUe6f","role":null},"metadata":{"from":"Th7MpTaRZVRYnPiabds81Y"}},"txnMetadata":{"seqNo":4,"txnTime":4000}}`,
+	}
+	txns := ledgerValidator(ledger)
+	// A validator older than indy-node 1.13, which answers GET_NYM with the
+	// current NYM whatever the version asked for.
+	v := func(m []byte) [][]byte {
+		var req struct {
+			ReqId     seqNo
+			Operation struct {
+				Type protoId `json:"type,string"`
+				Dest string
+			}
+		}
+		require.NoError(t, json.Unmarshal(m, &req))
+		if req.Operation.Type != idGetNym {
+			return txns(m)
+		}
+		data := "null"
+		if req.Operation.Dest == "V4SGRU86Z58d6TV7PBUe6f" {
+			data = `{"dest":"V4SGRU86Z58d6TV7PBUe6f","identifier":"Th7MpTaRZVRYnPiabds81Y","verkey":"~key2","role":null,"seqNo":4,"txnTime":4000}`
+		}
+		return [][]byte{[]byte(fmt.Sprintf(`{"op":"REPLY","result":{"type":"105","reqId":%v,"dest":"%v","seqNo":4,"txnTime":4000,"data":%v}}`,
+			req.ReqId, req.Operation.Dest, data))}
+	}
+	pool := testPool(t, fakeTransport{"Node1": v, "Node2": v, "Node3": v, "Node4": v})
+	ctx := context.Background()
+
+	nym, err := pool.GetNymAt(ctx, "did:sov:V4SGRU86Z58d6TV7PBUe6f", time.Unix(2500, 0))
+	require.NoError(t, err)
+	require.Equal(t, &Nym{Dest: "V4SGRU86Z58d6TV7PBUe6f", Identifier: "Th7MpTaRZVRYnPiabds81Y", Verkey: "~key1", Role: "101", SeqNo: 1, TxnTime: 1000}, nym)
+
+	nym, err = pool.GetNymVersion(ctx, "V4SGRU86Z58d6TV7PBUe6f", 3)
+	require.NoError(t, err)
+	require.Equal(t, "~key2", nym.Verkey)
+	require.Equal(t, "101", nym.Role)
+
+	nym, err = pool.GetNymAt(ctx, "V4SGRU86Z58d6TV7PBUe6f", time.Unix(5000, 0))
+	require.NoError(t, err)
+	require.Equal(t, 4, nym.SeqNo)
+	require.Equal(t, "", nym.Role)
+
+	_, err = pool.GetNymAt(ctx, "V4SGRU86Z58d6TV7PBUe6f", time.Unix(500, 0))
+	require.Equal(t, ErrNoData, err)
+	_, err = pool.GetNymVersion(ctx, "V4SGRU86Z58d6TV7PBUe6f", 2)
+	require.Equal(t, ErrNoData, err)
+	_, err = pool.GetNymAt(ctx, "QuCBjYx4CbGCiMcoqQg1y", time.Unix(5000, 0))
+	require.Equal(t, ErrNoData, err)
+}
+
+func TestPool_RotateVerkey(t *testing.T) {
+	signer, err := SignerFromSeed([]byte("000000000000000000000000Trustee1"))
+	require.NoError(t, err)
+	did := signer.Did()
+	_, verkey, _, err := KeypairFromSeed([]byte("000000000000000000000000Trustee2"))
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	current, txnTime, apply := "~oldkeyoldkeyoldkeyoldke", int64(1000), true
+	v := func(m []byte) [][]byte {
+		var req struct {
+			ReqId     seqNo
+			Operation nymOp
+		}
+		require.NoError(t, json.Unmarshal(m, &req))
+		mu.Lock()
+		defer mu.Unlock()
+		switch req.Operation.Type {
+		case idNym:
+			txnTime++
+			if apply {
+				current = req.Operation.Verkey
+			}
+			return [][]byte{[]byte(fmt.Sprintf(`{"op":"REPLY","result":{"reqId":%v,"txn":{"type":"1","data":{"dest":"%v","verkey":"%v"}},"txnMetadata":{"seqNo":5,"txnTime":%v}}}`,
+				req.ReqId, did, req.Operation.Verkey, txnTime))}
+		case idGetNym:
+			return [][]byte{[]byte(fmt.Sprintf(`{"op":"REPLY","result":{"type":"105","reqId":%v,"seqNo":5,"txnTime":%v,"data":{"dest":"%v","verkey":"%v","seqNo":5,"txnTime":%v}}}`,
+				req.ReqId, txnTime, did, current, txnTime))}
+		}
+		return nil
+	}
+	pool := testPool(t, fakeTransport{"Node1": v, "Node2": v, "Node3": v, "Node4": v})
+	ctx := context.Background()
+
+	nym, err := pool.RotateVerkey(ctx, signer, did, verkey)
+	require.NoError(t, err)
+	require.Equal(t, verkey, nym.Verkey)
+	require.Equal(t, int64(1001), nym.TxnTime)
+
+	// Rotating to the current key writes nothing.
+	_, err = pool.RotateVerkey(ctx, signer, did, verkey)
+	require.NoError(t, err)
+	require.Equal(t, int64(1001), txnTime)
+
+	apply = false
+	_, err = pool.RotateVerkey(ctx, signer, did, "~"+verkey[:22])
+	require.Error(t, err)
+	_, otherVerkey, _, err := KeypairFromSeed([]byte("000000000000000000000000Trustee3"))
+	require.NoError(t, err)
+	_, err = pool.RotateVerkey(ctx, signer, did, otherVerkey)
+	require.True(t, errors.Is(err, ErrRotationUnconfirmed))
+}
@@ -0,0 +1,190 @@
+package indyclient
+
+import (
+	"errors"
+	"time"
+)
+
+// An Option configures a Pool constructed by NewPool.
+type Option func(*Pool)
+
+// WithConnectTimeout bounds how long connecting to a single validator,
+// including the CURVE handshake, may take. A validator which does not
+// complete the handshake in time is abandoned and the next one is tried.
+// By default connecting is not bounded.
+func WithConnectTimeout(d time.Duration) Option {
+	return func(p *Pool) {
+		p.connectTimeout = d
+	}
+}
+
+// WithReplyTimeout bounds how long the Pool waits for a validator to
+// answer a request. A validator which does not reply in time is abandoned
+// and the request is resent to the next validator in round-robin order,
+// within the retry budget. Reply.Node tells which validator finally
+// answered. By default, the Pool waits until the retry budget runs out.
+func WithReplyTimeout(d time.Duration) Option {
+	return func(p *Pool) {
+		p.replyTimeout = d
+	}
+}
+
+// WithTimeout bounds the time a request may take, across all the
+// connection attempts, retries and validators it involves, as
+// WithRetryBudget does without changing the number of attempts. The default
+// is 30 seconds.
+func WithTimeout(d time.Duration) Option {
+	return func(p *Pool) {
+		p.budgetTime = d
+	}
+}
+
+// WithConnections makes the Pool keep connections to up to n validators
+// open at the same time and spread the requests over them. The default is a
+// single connection.
+func WithConnections(n int) Option {
+	return func(p *Pool) {
+		if n > 0 {
+			p.maxConns = n
+		}
+	}
+}
+
+// WithPreferObservers makes the Pool send reads to observer nodes, which
+// serve reads without taking part in consensus, whenever the genesis lists
+// any. This takes read load off the validators. If no observer is
+// available, validators are used as usual. Writes always go to validators.
+func WithPreferObservers() Option {
+	return func(p *Pool) {
+		p.preferObserver = true
+	}
+}
+
+// WithReqIdGenerator replaces the generator of the reqId of requests, which
+// by default is a process-wide counter. It is meant for tests which need
+// deterministic requests. The generator must not return the same reqId
+// twice for requests in flight at the same time.
+func WithReqIdGenerator(next func() uint32) Option {
+	return func(p *Pool) {
+		p.nextReqId = func() seqNo {
+			return seqNo(next())
+		}
+	}
+}
+
+// WithMaxParallel bounds the number of validators which operations fanning
+// out to the whole pool, such as Consensus reads, Ready and Health, talk to
+// at the same time. Consensus reads stop dialing validators once f+1 of
+// them agreed. It defaults to f+1, where f is the number of faulty
+// validators the pool tolerates, which is the number of agreeing answers
+// those operations need.
+func WithMaxParallel(n int) Option {
+	return func(p *Pool) {
+		p.maxParallel = n
+	}
+}
+
+// WithRetryBudget bounds the effort spent on a single request, across all
+// the connection attempts, retries and validators it involves, to at most
+// attempts connections and d of wall-clock time. Once the budget is
+// exhausted, the request fails with the most telling error seen so far. The
+// default is 10 attempts and 30 seconds. See also WithRetryPolicy.
+func WithRetryBudget(attempts int, d time.Duration) Option {
+	return func(p *Pool) {
+		p.budgetAttempts = attempts
+		p.budgetTime = d
+	}
+}
+
+// A ReadOption configures a single read request.
+type ReadOption func(*readConfig)
+
+type readConfig struct {
+	minFreshness  time.Time
+	maxStaleness  time.Duration
+	freshnessWait time.Duration
+	exclude       map[string]bool
+	consistency   Consistency
+	verifyProof   bool
+	identifier    string
+	observers     bool // whether observer nodes may answer, false for writes
+	protoVersion  int  // protocolVersion of the request, that of the Pool if 0
+}
+
+// ErrNotFresh is returned by reads using WithMinFreshness when no validator
+// answered with sufficiently fresh data in time.
+var ErrNotFresh = errors.New("no sufficiently fresh reply")
+
+// WithMinFreshness requires the state of the answering validator to be at
+// least as recent as t, for example the txnTime of a write which should be
+// visible to the read. Validators lagging behind are skipped in favour of
+// the next one, after a backoff as set by WithBackoff, until a fresh reply
+// arrives or wait has elapsed, in which case the read fails with
+// ErrNotFresh. Stale replies do not count against the attempts of the
+// retry budget, only against its time.
+func WithMinFreshness(t time.Time, wait time.Duration) ReadOption {
+	return func(c *readConfig) {
+		c.minFreshness = t
+		c.freshnessWait = wait
+	}
+}
+
+// fresh reports whether r satisfies the freshness requirement of c.
+func (c *readConfig) fresh(r *Reply) bool {
+	if c.maxStaleness > 0 {
+		ts, ok := r.StateTimestamp()
+		if ok && ts < time.Now().Add(-c.maxStaleness).Unix() {
+			return false
+		}
+	}
+	if c.minFreshness.IsZero() {
+		return true
+	}
+	ts, ok := r.timestamp()
+	return ok && ts >= c.minFreshness.Unix()
+}
+
+// ExcludeValidators makes the read skip the validators with the given
+// aliases, for example because they were found to serve stale or divergent
+// data. The exclusion only applies to this request. If all validators are
+// excluded, the read fails with ErrAllExcluded.
+func ExcludeValidators(aliases ...string) ReadOption {
+	return func(c *readConfig) {
+		if c.exclude == nil {
+			c.exclude = make(map[string]bool)
+		}
+		for _, a := range aliases {
+			c.exclude[a] = true
+		}
+	}
+}
+
+// WithStateProof makes the read only accept replies whose state proof
+// passes VerifyStateProof and VerifyMultiSignature, asking the next
+// validator otherwise. If the BLSVerifier of the Pool was disabled, the read
+// fails with ErrNoBLSVerifier instead of trusting the root hash of the proof.
+func WithStateProof() ReadOption {
+	return func(c *readConfig) {
+		c.verifyProof = true
+	}
+}
+
+// WithIdentifier sets the identifier of the unsigned requests of the Pool,
+// such as reads, which networks may use to log or rate-limit clients. It is
+// usually the DID of the client. Signed requests always carry the DID of
+// their signer. The default is Go1ndyC1ient1111111111.
+func WithIdentifier(did string) Option {
+	return func(p *Pool) {
+		if did != "" {
+			p.identifier = did
+		}
+	}
+}
+
+// WithRequestIdentifier sets the identifier of an unsigned request,
+// overriding that of the Pool.
+func WithRequestIdentifier(did string) ReadOption {
+	return func(c *readConfig) {
+		c.identifier = did
+	}
+}
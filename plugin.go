@@ -0,0 +1,101 @@
+package indyclient
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// plugins records the ledgers and transaction types of indy-node plugins.
+var plugins = struct {
+	sync.RWMutex
+	ledgers  map[LedgerId]string
+	txnTypes map[protoId]TxnDecoder
+}{
+	ledgers: map[LedgerId]string{
+		PoolLedger:   "pool",
+		DomainLedger: "domain",
+		ConfigLedger: "config",
+		AuditLedger:  "audit",
+	},
+	txnTypes: make(map[protoId]TxnDecoder),
+}
+
+// RegisterLedger names the ledger id of a plugin, such as the token ledger
+// 1001 of Sovrin, for LedgerId.String and ParseLedger. It panics if the id
+// or the name is taken.
+func RegisterLedger(id LedgerId, name string) {
+	plugins.Lock()
+	defer plugins.Unlock()
+	for l, n := range plugins.ledgers {
+		if l == id || n == name {
+			panic(fmt.Sprintf("indyclient: ledger %d already registered as %v", int(l), n))
+		}
+	}
+	plugins.ledgers[id] = name
+}
+
+// unregisterLedger forgets the name of the plugin ledger id.
+func unregisterLedger(id LedgerId) {
+	plugins.Lock()
+	defer plugins.Unlock()
+	delete(plugins.ledgers, id)
+}
+
+// String returns the name of l: pool, domain, config, audit or the name of
+// a registered plugin ledger, or else its number.
+func (l LedgerId) String() string {
+	plugins.RLock()
+	defer plugins.RUnlock()
+	if name, ok := plugins.ledgers[l]; ok {
+		return name
+	}
+	return strconv.Itoa(int(l))
+}
+
+// ParseLedger returns the ledger with the given name, as returned by
+// LedgerId.String, or number.
+func ParseLedger(s string) (LedgerId, error) {
+	plugins.RLock()
+	defer plugins.RUnlock()
+	for l, name := range plugins.ledgers {
+		if name == s {
+			return l, nil
+		}
+	}
+	id, err := strconv.Atoi(s)
+	if err != nil || id < 0 {
+		return 0, fmt.Errorf("unknown ledger %q", s)
+	}
+	return LedgerId(id), nil
+}
+
+// TxnDecoder decodes the data of a transaction of a plugin.
+type TxnDecoder func(b *Block) (interface{}, error)
+
+// RegisterTxnType makes Block.Decode decode the transactions of type typ,
+// which a plugin writes, with decode. It panics if typ is registered
+// already. Types which Block.Decode knows are not overridden.
+func RegisterTxnType(typ int, decode TxnDecoder) {
+	plugins.Lock()
+	defer plugins.Unlock()
+	if _, ok := plugins.txnTypes[protoId(typ)]; ok {
+		panic(fmt.Sprintf("indyclient: transaction type %v already registered", typ))
+	}
+	plugins.txnTypes[protoId(typ)] = decode
+}
+
+// unregisterTxnType forgets the TxnDecoder of typ.
+func unregisterTxnType(typ int) {
+	plugins.Lock()
+	defer plugins.Unlock()
+	delete(plugins.txnTypes, protoId(typ))
+}
+
+// pluginDecoder returns the TxnDecoder registered for typ, if any.
+func pluginDecoder(typ protoId) (TxnDecoder, bool) {
+	plugins.RLock()
+	defer plugins.RUnlock()
+	decode, ok := plugins.txnTypes[typ]
+	return decode, ok
+}
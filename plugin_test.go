@@ -0,0 +1,58 @@
+package indyclient
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterLedger(t *testing.T) {
+	RegisterLedger(1001, "sovtoken")
+	defer unregisterLedger(1001)
+	require.Panics(t, func() { RegisterLedger(1001, "token") })
+	require.Panics(t, func() { RegisterLedger(1002, "domain") })
+
+	for _, tc := range []struct {
+		name string
+		id   LedgerId
+	}{
+		{"pool", PoolLedger},
+		{"domain", DomainLedger},
+		{"config", ConfigLedger},
+		{"audit", AuditLedger},
+		{"sovtoken", 1001},
+		{"1002", 1002},
+	} {
+		id, err := ParseLedger(tc.name)
+		require.NoError(t, err)
+		require.Equal(t, tc.id, id)
+		require.Equal(t, tc.name, id.String())
+	}
+	_, err := ParseLedger("tokens")
+	require.Error(t, err)
+}
+
+func TestRegisterTxnType(t *testing.T) {
+	type xfer struct {
+		Outputs []struct {
+			Address string
+			Amount  int
+		}
+	}
+	var b Block
+	require.NoError(t, json.Unmarshal([]byte(`{"txn":{"type":"10001","data":{"outputs":[{"address":"2jS4PHWQJKcawRxdW6GVsjnZBa1ecGdCssn7KhWYJZGTXgL7Es","amount":10}]},"metadata":{}},"txnMetadata":{"seqNo":1}}`), &b))
+	_, err := b.Decode()
+	require.True(t, errors.Is(err, ErrUnknownTxnType))
+
+	RegisterTxnType(10001, func(b *Block) (interface{}, error) {
+		x := new(xfer)
+		return x, json.Unmarshal(b.Txn.Data.Raw, x)
+	})
+	defer unregisterTxnType(10001)
+	require.Panics(t, func() { RegisterTxnType(10001, nil) })
+	v, err := b.Decode()
+	require.NoError(t, err)
+	require.Equal(t, 10, v.(*xfer).Outputs[0].Amount)
+}
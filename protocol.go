@@ -0,0 +1,121 @@
+package indyclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync/atomic"
+)
+
+// defaultProtocolVersion is the protocolVersion of requests unless
+// configured otherwise: that of indy-node 1.4 and later.
+const defaultProtocolVersion = 2
+
+// WithProtocolVersion sets the protocolVersion of the requests of the Pool.
+// The default is 2; networks still running indy-node 1.3 or older need 1.
+// DetectProtocolVersion finds the version a pool accepts.
+func WithProtocolVersion(v int) Option {
+	return func(p *Pool) {
+		p.protoVersion = int32(v)
+	}
+}
+
+// protocolVersion returns the protocolVersion of the requests of p.
+func (p *Pool) protocolVersion() int {
+	return int(atomic.LoadInt32(&p.protoVersion))
+}
+
+// DetectProtocolVersion probes the pool with a read using the protocol
+// version of the Pool and, if the validator refuses the version, with the
+// other one. It makes the Pool use the version which was accepted, and
+// returns it. The probes always go to the pool, bypassing the TxnCache, and
+// other requests keep using the current version until one is accepted.
+// Transactions of protocol version 1 networks, in the flat layout of
+// indy-node 1.3, are decoded like current ones.
+func (p *Pool) DetectProtocolVersion(ctx context.Context) (int, error) {
+	v := p.protocolVersion()
+	for _, try := range []int{v, 3 - v} {
+		r, err := p.read(ctx, getTxnOp{Type: idGetTxn, Data: 1, LedgerID: int(PoolLedger)}, withProtocolVersion(try))
+		if err == nil {
+			err = checkReply(r)
+		}
+		if err == nil {
+			atomic.StoreInt32(&p.protoVersion, int32(try))
+			return try, nil
+		}
+		var rerr *RequestError
+		if !errors.As(err, &rerr) || !strings.Contains(strings.ToLower(rerr.Reason), "protocol version") {
+			return 0, err
+		}
+	}
+	return 0, errors.New("pool accepts neither protocol version 1 nor 2")
+}
+
+// withProtocolVersion makes a read use the protocol version v instead of
+// that of the Pool.
+func withProtocolVersion(v int) ReadOption {
+	return func(c *readConfig) {
+		c.protoVersion = v
+	}
+}
+
+// UnmarshalJSON decodes transactions in the layout of ledger transactions,
+// and in the flat layout of indy-node 1.3 and older, as found in the
+// genesis files and GET_TXN replies of protocol version 1 networks.
+func (b *Block) UnmarshalJSON(m []byte) error {
+	type plain Block
+	var probe struct {
+		Txn  json.RawMessage `json:"txn"`
+		Type json.RawMessage `json:"type"`
+	}
+	if err := json.Unmarshal(m, &probe); err != nil {
+		return err
+	}
+	if probe.Txn != nil || probe.Type == nil {
+		return json.Unmarshal(m, (*plain)(b))
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(m, &fields); err != nil {
+		return err
+	}
+	var legacy struct {
+		Type       json.RawMessage `json:"type"`
+		SeqNo      int             `json:"seqNo"`
+		TxnTime    int64           `json:"txnTime"`
+		TxnId      string          `json:"txnId"`
+		Identifier string          `json:"identifier"`
+		ReqId      json.RawMessage `json:"reqId"`
+	}
+	if err := json.Unmarshal(m, &legacy); err != nil {
+		return err
+	}
+	metadata := map[string]interface{}{}
+	if legacy.Identifier != "" {
+		metadata["from"] = legacy.Identifier
+	}
+	if legacy.ReqId != nil {
+		metadata["reqId"] = legacy.ReqId
+	}
+	// Everything but the metadata is the data of the transaction.
+	for _, k := range []string{"type", "seqNo", "txnTime", "txnId", "identifier", "reqId", "signature", "signatures", "auditPath", "rootHash"} {
+		delete(fields, k)
+	}
+	layout, err := json.Marshal(map[string]interface{}{
+		"txn": map[string]interface{}{
+			"type":     legacy.Type,
+			"data":     fields,
+			"metadata": metadata,
+		},
+		"txnMetadata": map[string]interface{}{
+			"seqNo":   legacy.SeqNo,
+			"txnTime": legacy.TxnTime,
+			"txnId":   legacy.TxnId,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(layout, (*plain)(b))
+}
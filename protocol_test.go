@@ -0,0 +1,92 @@
+package indyclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlock_UnmarshalLegacy(t *testing.T) {
+	var b Block
+	require.NoError(t, json.Unmarshal([]byte(`{"dest":"WRfXPg8dantKVubE3HX8pw","verkey":"~abc","role":"0","identifier":"V4SGRU86Z58d6TV7PBUe6f","reqId":42,"signature":"sig","seqNo":7,"txnTime":1500000000,"type":"1"}`), &b))
+	require.Equal(t, idNym, b.Txn.Type)
+	require.Equal(t, "WRfXPg8dantKVubE3HX8pw", b.Txn.Data.Dest)
+	require.Equal(t, TxnMetadata{SeqNo: 7, TxnTime: 1500000000}, b.TxnMetadata)
+	require.Equal(t, "V4SGRU86Z58d6TV7PBUe6f", b.Txn.Metadata["from"])
+	v, err := b.Decode()
+	require.NoError(t, err)
+	require.Equal(t, "~abc", v.(*NymTxn).Verkey)
+
+	// Genesis files of indy-node 1.3 use the same layout.
+	_, verkey, _, err := KeypairFromSeed([]byte("00000000000000000000000000000001"))
+	require.NoError(t, err)
+	p, err := NewPoolFromBytes([]byte(fmt.Sprintf(`{"data":{"alias":"Node1","client_ip":"10.0.0.1","client_port":9702,"node_ip":"10.0.0.1","node_port":9701,"services":["VALIDATOR"]},"dest":"%v","identifier":"V4SGRU86Z58d6TV7PBUe6f","txnId":"fea82e10","type":"0"}`, verkey)))
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.1:9702", p.Validators[0].Address)
+}
+
+func TestPool_DetectProtocolVersion(t *testing.T) {
+	var versions []int
+	v := func(m []byte) [][]byte {
+		var req struct {
+			ReqId           seqNo `json:"reqId"`
+			ProtocolVersion int   `json:"protocolVersion"`
+		}
+		json.Unmarshal(m, &req)
+		versions = append(versions, req.ProtocolVersion)
+		if req.ProtocolVersion != 1 {
+			return [][]byte{[]byte(fmt.Sprintf(`{"op":"REQNACK","reqId":%v,"reason":"Unknown protocol version value %v"}`, req.ReqId, req.ProtocolVersion))}
+		}
+		return [][]byte{[]byte(fmt.Sprintf(`{"op":"REPLY","result":{"type":"3","reqId":%v,"seqNo":1,"data":{"dest":"WRfXPg8dantKVubE3HX8pw","seqNo":1,"type":"1"}}}`, req.ReqId))}
+	}
+	pool := testPool(t, fakeTransport{"Node1": v, "Node2": v, "Node3": v, "Node4": v})
+
+	version, err := pool.DetectProtocolVersion(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, version)
+	require.Equal(t, []int{2, 1}, versions)
+
+	res, err := pool.GetTxn(context.Background(), PoolLedger, 1)
+	require.NoError(t, err)
+	require.Equal(t, "WRfXPg8dantKVubE3HX8pw", res.Txn.Txn.Data.Dest)
+	require.Equal(t, 1, versions[2])
+
+	pool = testPool(t, fakeTransport{}, WithProtocolVersion(1))
+	_, m := pool.getTxnRequest(PoolLedger, 1)
+	require.Contains(t, string(m), `"protocolVersion":1`)
+}
+
+func TestPool_DetectProtocolVersionProbes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "indyclient")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	cache := &DirCache{Dir: dir}
+	require.NoError(t, cache.Put(PoolLedger, 1, []byte(`{"txn":{"type":"0","data":{},"metadata":{}},"txnMetadata":{"seqNo":1}}`)))
+
+	// The cache does not answer the probes, and the Pool keeps its version
+	// while they are in flight.
+	var pool *Pool
+	var versions []int
+	v := func(m []byte) [][]byte {
+		var req struct {
+			ReqId seqNo `json:"reqId"`
+		}
+		json.Unmarshal(m, &req)
+		versions = append(versions, pool.protocolVersion())
+		return [][]byte{[]byte(fmt.Sprintf(`{"op":"REQNACK","reqId":%v,"reason":"Unknown protocol version value"}`, req.ReqId))}
+	}
+	pool = testPool(t, fakeTransport{"Node1": v, "Node2": v, "Node3": v, "Node4": v}, WithCache(cache))
+
+	_, err = pool.DetectProtocolVersion(context.Background())
+	require.Error(t, err)
+	require.NotEmpty(t, versions)
+	for _, version := range versions {
+		require.Equal(t, 2, version)
+	}
+	require.Equal(t, 2, pool.protocolVersion())
+}
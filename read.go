@@ -0,0 +1,176 @@
+package indyclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// request is the envelope of the requests sent to validators.
+type request struct {
+	Operation       interface{}       `json:"operation"`
+	Identifier      string            `json:"identifier"`
+	ReqId           seqNo             `json:"reqId"`
+	ProtocolVersion int               `json:"protocolVersion"`
+	TAAAcceptance   *TAAAcceptance    `json:"taaAcceptance,omitempty"`
+	Endorser        string            `json:"endorser,omitempty"`
+	Signature       string            `json:"signature,omitempty"`
+	Signatures      map[string]string `json:"signatures,omitempty"`
+}
+
+// newRequest wraps the operation op into a request from the identifier of
+// the Pool and returns its reqId and wire encoding.
+func (p *Pool) newRequest(op interface{}) (seqNo, []byte) {
+	return p.newRequestFrom(p.identifier, p.protocolVersion(), op)
+}
+
+// newRequestFrom is newRequest with the identifier ident and the protocol
+// version version.
+func (p *Pool) newRequestFrom(ident string, version int, op interface{}) (seqNo, []byte) {
+	req := request{
+		Operation:       op,
+		Identifier:      ident,
+		ReqId:           p.nextReqId(),
+		ProtocolVersion: version,
+	}
+	m, _ := json.Marshal(req)
+	return req.ReqId, m
+}
+
+// read sends a read request with the operation op and returns the reply.
+// All typed reads go through read, which applies the ReadOptions, the retry
+// budget and the failover between validators.
+func (p *Pool) read(ctx context.Context, op interface{}, opts ...ReadOption) (*Reply, error) {
+	cfg := readConfig{consistency: p.consistency, observers: true}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	ident := p.identifier
+	if cfg.identifier != "" {
+		ident = cfg.identifier
+	}
+	version := cfg.protoVersion
+	if version == 0 {
+		version = p.protocolVersion()
+	}
+	reqId, m := p.newRequestFrom(ident, version, op)
+	return p.submit(ctx, reqId, m, &cfg)
+}
+
+// submit sends the encoded request m according to cfg and returns the
+// reply.
+func (p *Pool) submit(ctx context.Context, reqId seqNo, m []byte, cfg *readConfig) (*Reply, error) {
+	if cfg.verifyProof && p.blsVerifier == nil {
+		return nil, ErrNoBLSVerifier
+	}
+	b := p.newBudget()
+	ctx, cancel := b.context(ctx)
+	defer cancel()
+	if cfg.consistency == Consensus {
+		return p.consensusRead(ctx, reqId, m, cfg, b)
+	}
+
+	exclude := cfg.exclude
+	if !cfg.observers {
+		exclude = p.excludeObservers(exclude)
+	}
+	deadline := time.Now().Add(cfg.freshnessWait)
+	for nacks, stale := 0, 0; ; {
+		c, err := p.connection(ctx, exclude, b)
+		if err != nil {
+			if ctx.Err() != nil {
+				b.failed(err, false)
+				return nil, b.err()
+			}
+			return nil, err
+		}
+		r, err := p.exchangeWith(ctx, c, reqId, m)
+		if errors.Is(err, ErrReplyTimeout) && !p.retryTimeouts {
+			return nil, err
+		}
+		if errors.Is(err, ErrReplyTimeout) || (err != nil && c.failure() != nil) {
+			// The validator is unresponsive or the connection died:
+			// resend the request on another connection.
+			b.failed(err, false)
+			p.dropConnection(c)
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				b.failed(err, false)
+				return nil, b.err()
+			}
+			return nil, err
+		}
+		if r.Op == "REQNACK" && p.retryReqNack && !b.exhausted() {
+			// Ask the next validator, which may be more up to date.
+			b.failed(checkReply(r), true)
+			p.dropConnection(c)
+			p.log.Log(LevelInfo, "request refused, retrying", "node", c.alias, "reqId", reqId, "reason", r.Reason)
+			if err := sleep(ctx, p.backoff.delay(nacks)); err != nil {
+				return nil, b.err()
+			}
+			nacks++
+			continue
+		}
+		if cfg.verifyProof {
+			if err := p.verifyProof(r); err != nil {
+				// Ask the next validator.
+				b.failed(err, true)
+				p.dropConnection(c)
+				continue
+			}
+			r.Verified = true
+		}
+		if cfg.fresh(r) {
+			return r, nil
+		}
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			return nil, ErrNotFresh
+		}
+		// Ask the next validator after a backoff, which gives the
+		// lagging ones time to catch up. The wait is bounded by the
+		// deadline, not the attempts.
+		b.failed(ErrNotFresh, true)
+		b.refund()
+		p.dropConnection(c)
+		if d := p.backoff.delay(stale); d < wait {
+			wait = d
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return nil, b.err()
+		}
+		stale++
+	}
+}
+
+// excludeObservers returns exclude with the aliases of the observer nodes
+// added, so that requests which need a validator, such as writes, are not
+// sent to observers.
+func (p *Pool) excludeObservers(exclude map[string]bool) map[string]bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	all := make(map[string]bool, len(exclude))
+	for a := range exclude {
+		all[a] = true
+	}
+	for i := range p.Validators {
+		if p.Validators[i].IsObserver() {
+			all[p.Validators[i].Alias] = true
+		}
+	}
+	return all
+}
+
+// verifyProof checks the state proof of r and its multi-signature. Without
+// the multi-signature, the state proof only shows that r matches the root
+// hash the validator chose to send along.
+func (p *Pool) verifyProof(r *Reply) error {
+	if err := VerifyStateProof(r); err != nil {
+		return err
+	}
+	return p.VerifyMultiSignature(r)
+}
@@ -0,0 +1,75 @@
+package indyclient
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Ready checks that the pool is usable: at least f+1 validators, where f is
+// the number of faulty validators the pool tolerates, must be reachable and
+// agree on the size of the pool ledger. It is stronger than a single
+// successful request and is meant for readiness probes of services relying
+// on the pool. The returned error names the validators which failed or
+// diverged.
+func (p *Pool) Ready(ctx context.Context) error {
+	vs := p.validators()
+	need := faulty(vs) + 1
+	sizes := make(map[int][]string)
+	var failed []string
+	agreed := false
+
+	p.fanOut(ctx, vs, func(ctx context.Context, v Validator) (interface{}, error) {
+		return p.validatorLedgerSize(ctx, v, PoolLedger)
+	}, func(v Validator, size interface{}, err error) bool {
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%v (%v)", v.Alias, err))
+			return false
+		}
+		s := size.(int)
+		sizes[s] = append(sizes[s], v.Alias)
+		agreed = len(sizes[s]) >= need
+		return agreed
+	})
+	if agreed {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("pool not ready: fewer than %v validators agree on the pool ledger", need)
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		msg += "; failed: " + strings.Join(failed, ", ")
+	}
+	if len(sizes) > 0 {
+		var diverged []string
+		for s, aliases := range sizes {
+			diverged = append(diverged, fmt.Sprintf("%v at size %v", strings.Join(aliases, ", "), s))
+		}
+		sort.Strings(diverged)
+		msg += "; reported: " + strings.Join(diverged, "; ")
+	}
+	return fmt.Errorf("%v", msg)
+}
+
+// validatorLedgerSize asks the single validator v for the size of ledger.
+func (p *Pool) validatorLedgerSize(ctx context.Context, v Validator, ledger LedgerId) (int, error) {
+	s, err := p.dial(ctx, v)
+	if err != nil {
+		return 0, err
+	}
+	c := p.newConn(s, v)
+	defer c.close()
+
+	return findLedgerSize(func(seqNo int) (*Block, int, error) {
+		reqId, m := p.getTxnRequest(ledger, seqNo)
+		r, err := p.exchangeWith(ctx, c, reqId, m)
+		if err != nil {
+			return nil, 0, err
+		}
+		return blockFromReply(r)
+	})
+}
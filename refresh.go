@@ -0,0 +1,140 @@
+package indyclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// nodeState is the state of a node, merged from the NODE transactions
+// about it: the first one lists all its fields, later ones only the changed
+// ones.
+type nodeState struct {
+	dest  string // the node's verkey, which identifies it
+	data  map[string]json.RawMessage
+	seqNo int // of the last transaction
+}
+
+// applyPoolTxn applies the pool ledger transaction b to the node states.
+func (p *Pool) applyPoolTxn(b *Block) error {
+	if b.TxnMetadata.SeqNo > p.poolSize {
+		p.poolSize = b.TxnMetadata.SeqNo
+	} else if b.TxnMetadata.SeqNo == 0 {
+		p.poolSize++
+	}
+	p.poolTxns = append(p.poolTxns, b)
+	if b.Txn.Type != idNode {
+		return nil
+	}
+
+	var data map[string]json.RawMessage
+	if err := decodeData(b.Txn.Data.Data, &data); err != nil {
+		return fmt.Errorf("failed to decode TxnNode: %v", err)
+	}
+	var n *nodeState
+	for _, s := range p.nodes {
+		if s.dest == b.Txn.Data.Dest {
+			n = s
+			break
+		}
+	}
+	if n == nil {
+		n = &nodeState{dest: b.Txn.Data.Dest, data: make(map[string]json.RawMessage)}
+		p.nodes = append(p.nodes, n)
+	}
+	for k, v := range data {
+		n.data[k] = v
+	}
+	n.seqNo = b.TxnMetadata.SeqNo
+	return nil
+}
+
+// nodeValidators returns the validators described by the node states,
+// leaving out the nodes demoted by emptying their services. If lenient,
+// nodes with invalid data are logged and left out, otherwise they are an
+// error.
+func (p *Pool) nodeValidators(lenient bool) ([]Validator, error) {
+	var vs []Validator
+	for _, n := range p.nodes {
+		data, err := json.Marshal(n.data)
+		if err != nil {
+			return nil, err
+		}
+		v, err := validatorFromTxn(&Block{Txn: Txn{
+			Type: idNode,
+			Data: DataDest{Data: data, Dest: n.dest},
+		}})
+		if err != nil {
+			if lenient {
+				p.log.Log(LevelWarn, "ignoring malformed node", "seqNo", n.seqNo, "err", err)
+				continue
+			}
+			return nil, fmt.Errorf("transaction %v: %v", n.seqNo, err)
+		}
+		if v.Services != nil && len(v.Services) == 0 {
+			continue // demoted
+		}
+		vs = append(vs, *v)
+	}
+	return vs, nil
+}
+
+// Refresh replays the pool ledger from the end of the genesis transactions,
+// or of the previous refresh, and updates Validators with the nodes added
+// and the addresses, keys and services changed since. Nodes demoted by
+// emptying their services are removed, while those left with only the
+// OBSERVER service are kept as observers. Genesis files of long-lived
+// networks are often outdated, so Refresh is best called right after
+// NewPool.
+func (p *Pool) Refresh(ctx context.Context) error {
+	p.mu.Lock()
+	from := p.poolSize + 1
+	p.mu.Unlock()
+
+	var blocks []*Block
+	for seqNo := from; ; seqNo++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		b, _, err := p.getBlock(ctx, PoolLedger, seqNo)
+		if err != nil {
+			return err
+		}
+		if b == nil {
+			break
+		}
+		blocks = append(blocks, b)
+	}
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.applyPoolTxns(blocks)
+}
+
+// applyPoolTxns applies new pool ledger transactions and updates the
+// validators. p.mu must be held.
+func (p *Pool) applyPoolTxns(blocks []*Block) error {
+	for _, b := range blocks {
+		if b.TxnMetadata.SeqNo != 0 && b.TxnMetadata.SeqNo <= p.poolSize {
+			continue // already applied by a concurrent refresh
+		}
+		if err := p.applyPoolTxn(b); err != nil {
+			p.log.Log(LevelWarn, "ignoring pool transaction", "seqNo", b.TxnMetadata.SeqNo, "err", err)
+		}
+	}
+	vs, err := p.nodeValidators(true)
+	if err != nil {
+		return err
+	}
+	if len(vs) == 0 {
+		return fmt.Errorf("no validators left after pool transaction %v", p.poolSize)
+	}
+	// The open connections may be to nodes which changed.
+	p.closeConnections()
+	p.Validators = vs
+	p.nextValidator %= len(vs)
+	return nil
+}
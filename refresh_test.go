@@ -0,0 +1,77 @@
+package indyclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool_ApplyPoolTxns(t *testing.T) {
+	pool, err := NewPoolFromBytes(testGenesis(t, "10.0.0.1:9702", "10.0.0.2:9702"))
+	require.NoError(t, err)
+	require.Equal(t, 2, pool.poolSize)
+	_, verkey3, _, err := KeypairFromSeed([]byte(fmt.Sprintf("%032d", 3)))
+	require.NoError(t, err)
+
+	block := func(seqNo int, dest, data string) *Block {
+		var b Block
+		require.NoError(t, json.Unmarshal([]byte(fmt.Sprintf(`{"txn":{"type":"0","data":{"dest":"%v","data":%v}},"txnMetadata":{"seqNo":%v}}`, dest, data, seqNo)), &b))
+		return &b
+	}
+	require.NoError(t, pool.applyPoolTxns([]*Block{
+		// Node1 moves.
+		block(3, pool.Validators[0].VerKey, `{"alias":"Node1","client_ip":"10.0.1.1","client_port":9712}`),
+		// Node3 joins.
+		block(4, verkey3, `{"alias":"Node3","client_ip":"10.0.0.3","client_port":9702,"node_ip":"10.0.0.3","node_port":9701,"services":["VALIDATOR"]}`),
+		// Node2 is demoted.
+		block(5, pool.Validators[1].VerKey, `{"alias":"Node2","services":[]}`),
+		// Invalid transactions are skipped.
+		block(6, "invalid", `{"alias":"Node4"}`),
+	}))
+
+	require.Equal(t, 6, pool.poolSize)
+	require.Len(t, pool.Validators, 2)
+	require.Equal(t, "10.0.1.1:9712", pool.Validators[0].Address)
+	require.Equal(t, "10.0.0.1:9701", pool.Validators[0].NodeAddress)
+	require.Equal(t, "Node3", pool.Validators[1].Alias)
+	require.Equal(t, 0, faulty(pool.Validators))
+
+	// Node3 becomes an observer.
+	require.NoError(t, pool.applyPoolTxns([]*Block{
+		block(7, verkey3, `{"alias":"Node3","services":["OBSERVER"]}`),
+	}))
+	require.Len(t, pool.Validators, 2)
+	require.True(t, pool.Validators[1].IsObserver())
+
+	// Transactions which were already applied are ignored.
+	require.NoError(t, pool.applyPoolTxns([]*Block{
+		block(4, verkey3, `{"alias":"Node3","client_ip":"10.9.9.9"}`),
+	}))
+	require.Equal(t, "10.0.0.3:9702", pool.Validators[1].Address)
+}
+
+func TestPool_RefreshConcurrent(t *testing.T) {
+	v := ledgerValidator(testLedger)
+	pool := testPool(t, fakeTransport{"Node1": v, "Node2": v, "Node3": v, "Node4": v})
+	verkey := pool.Validators[0].VerKey
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			var b Block
+			require.NoError(t, json.Unmarshal([]byte(fmt.Sprintf(`{"txn":{"type":"0","data":{"dest":"%v","data":{"alias":"Node1","client_port":%v}}},"txnMetadata":{"seqNo":%v}}`, verkey, 9702+i, 5+i)), &b))
+			pool.mu.Lock()
+			require.NoError(t, pool.applyPoolTxns([]*Block{&b}))
+			pool.mu.Unlock()
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		require.Len(t, pool.ValidatorTable(), 4)
+		pool.Health(context.Background())
+	}
+	<-done
+}
@@ -0,0 +1,141 @@
+package indyclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// The New*Request functions build the requests behind the typed reads and
+// writes of Pool, so that they can be inspected, signed and serialized
+// before being submitted with Pool.Submit, possibly by another process.
+
+// NewGetTxnRequest returns a GET_TXN request for the transaction seqNo of
+// ledger.
+func NewGetTxnRequest(ledger LedgerId, seqNo int) (*Request, error) {
+	if seqNo < 1 {
+		return nil, ErrInvalidSeqNo
+	}
+	return &Request{Operation: getTxnOp{
+		Type:     idGetTxn,
+		Data:     seqNo,
+		LedgerID: int(ledger),
+	}}, nil
+}
+
+// NewGetNymRequest returns a GET_NYM request for did, given as a DID or a
+// bare identifier.
+func NewGetNymRequest(did string) (*Request, error) {
+	id, err := didId(did)
+	if err != nil {
+		return nil, err
+	}
+	return &Request{Operation: getNymOp{Type: idGetNym, Dest: id}}, nil
+}
+
+// NewNymRequest returns the NYM request written by Pool.WriteNym.
+func NewNymRequest(targetDid, verkey, role, alias string) (*Request, error) {
+	id, err := didId(targetDid)
+	if err != nil {
+		return nil, err
+	}
+	return &Request{Operation: nymOp{
+		Type:   idNym,
+		Dest:   id,
+		Verkey: verkey,
+		Role:   role,
+		Alias:  alias,
+	}}, nil
+}
+
+// NewAttribRequest returns the ATTRIB request written by Pool.WriteAttrib.
+func NewAttribRequest(did, name string, value interface{}) (*Request, error) {
+	id, err := didId(did)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(map[string]interface{}{name: value})
+	if err != nil {
+		return nil, err
+	}
+	return &Request{Operation: attribOp{Type: idAttrib, Dest: id, Raw: string(raw)}}, nil
+}
+
+// NewSchemaRequest returns the SCHEMA request written by Pool.WriteSchema.
+func NewSchemaRequest(name, version string, attrNames []string) *Request {
+	return &Request{Operation: schemaOp{
+		Type: idSchema,
+		Data: schemaData{Name: name, Version: version, AttrNames: attrNames},
+	}}
+}
+
+// NewCredDefRequest returns the CLAIM_DEF request written by
+// Pool.WriteCredDef.
+func NewCredDefRequest(schemaSeqNo int, tag string, value json.RawMessage) (*Request, error) {
+	if schemaSeqNo < 1 {
+		return nil, ErrInvalidSeqNo
+	}
+	return &Request{Operation: claimDefOp{
+		Type:          idClaimDef,
+		Ref:           schemaSeqNo,
+		SignatureType: "CL",
+		Tag:           tag,
+		Data:          value,
+	}}, nil
+}
+
+// Identifier returns the identifier of the author of r, which is empty
+// until r is signed.
+func (r *Request) Identifier() string {
+	return r.identifier
+}
+
+// MarshalJSON encodes r in the JSON format of requests. Requests which are
+// not signed yet have no identifier and reqId.
+func (r *Request) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.envelope())
+}
+
+// Submit sends r and returns the reply. Requests which are not signed are
+// sent on behalf of the identifier of the Pool, or that set with
+// WithRequestIdentifier, as reads; signed requests must carry the
+// signatures of their author and of their endorser, if any. It returns an
+// error if the request is not accepted.
+func (p *Pool) Submit(ctx context.Context, r *Request, opts ...ReadOption) (*Reply, error) {
+	cfg := readConfig{consistency: p.consistency}
+	env := r.envelope()
+	if r.identifier == "" {
+		cfg.observers = true
+		env.Identifier, env.ReqId = p.identifier, p.nextReqId()
+		if env.ProtocolVersion == 0 {
+			env.ProtocolVersion = p.protocolVersion()
+		}
+	} else {
+		for _, did := range []string{r.identifier, r.Endorser} {
+			if _, ok := r.signatures[did]; did != "" && !ok {
+				return nil, fmt.Errorf("request is not signed by %v", did)
+			}
+		}
+		// Writes are answered once ordered: there is nothing to compare.
+		cfg.consistency = SingleNode
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if r.identifier == "" && cfg.identifier != "" {
+		env.Identifier = cfg.identifier
+	}
+
+	m, err := json.Marshal(env)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := p.submit(ctx, env.ReqId, m, &cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkReply(reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
@@ -0,0 +1,83 @@
+package indyclient
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool_Submit(t *testing.T) {
+	v := ledgerValidator(testLedger)
+	pool := testPool(t, fakeTransport{"Node1": v, "Node2": v, "Node3": v, "Node4": v})
+
+	req, err := NewGetTxnRequest(DomainLedger, 2)
+	require.NoError(t, err)
+	m, err := json.Marshal(req)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"operation":{"type":"3","data":2,"ledgerId":1},"identifier":"","reqId":0,"protocolVersion":0}`, string(m))
+
+	reply, err := pool.Submit(context.Background(), req)
+	require.NoError(t, err)
+	b, _, err := blockFromReply(reply)
+	require.NoError(t, err)
+	require.Equal(t, "Th7MpTaRZVRYnPiabds81Y", b.Txn.Data.Dest)
+
+	_, err = NewGetTxnRequest(DomainLedger, 0)
+	require.Equal(t, ErrInvalidSeqNo, err)
+}
+
+func TestPool_Identifier(t *testing.T) {
+	var mu sync.Mutex
+	var idents []string
+	v := ledgerValidator(testLedger)
+	record := func(m []byte) [][]byte {
+		var req struct{ Identifier string }
+		require.NoError(t, json.Unmarshal(m, &req))
+		mu.Lock()
+		idents = append(idents, req.Identifier)
+		mu.Unlock()
+		return v(m)
+	}
+	pool := testPool(t, fakeTransport{"Node1": record, "Node2": record, "Node3": record, "Node4": record},
+		WithIdentifier("V4SGRU86Z58d6TV7PBUe6f"))
+
+	_, err := pool.GetTransaction(context.Background(), DomainLedger, 1)
+	require.NoError(t, err)
+	_, err = pool.GetTransaction(context.Background(), DomainLedger, 2, WithRequestIdentifier("Th7MpTaRZVRYnPiabds81Y"))
+	require.NoError(t, err)
+	req, err := NewGetTxnRequest(DomainLedger, 2)
+	require.NoError(t, err)
+	_, err = pool.Submit(context.Background(), req, WithRequestIdentifier("Th7MpTaRZVRYnPiabds81Y"))
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []string{"V4SGRU86Z58d6TV7PBUe6f", "Th7MpTaRZVRYnPiabds81Y", "Th7MpTaRZVRYnPiabds81Y"}, idents)
+}
+
+func TestRequest_Sign(t *testing.T) {
+	signer, err := SignerFromSeed([]byte("000000000000000000000000Trustee1"))
+	require.NoError(t, err)
+	req, err := NewNymRequest("did:sov:WRfXPg8dantKVubE3HX8pw", "", "101", "")
+	require.NoError(t, err)
+	require.NoError(t, req.AddSignature(signer))
+	require.Equal(t, signer.Did(), req.Identifier())
+
+	// A request signed by its author alone has a single signature.
+	m, err := json.Marshal(req)
+	require.NoError(t, err)
+	var env map[string]interface{}
+	require.NoError(t, json.Unmarshal(m, &env))
+	require.NotEmpty(t, env["signature"])
+	require.Nil(t, env["signatures"])
+	require.Equal(t, map[string]interface{}{"type": "1", "dest": "WRfXPg8dantKVubE3HX8pw", "role": "101"}, env["operation"])
+
+	parsed, err := ParseRequest(m)
+	require.NoError(t, err)
+	m2, err := json.Marshal(parsed)
+	require.NoError(t, err)
+	require.JSONEq(t, string(m), string(m2))
+}
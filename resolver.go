@@ -0,0 +1,299 @@
+package indyclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mr-tron/base58"
+)
+
+// DIDDocument is a W3C DID Document, as produced by a Resolver.
+type DIDDocument struct {
+	Context            []string             `json:"@context"`
+	Id                 string               `json:"id"`
+	VerificationMethod []VerificationMethod `json:"verificationMethod,omitempty"`
+	Authentication     []string             `json:"authentication,omitempty"`
+	AssertionMethod    []string             `json:"assertionMethod,omitempty"`
+	KeyAgreement       []string             `json:"keyAgreement,omitempty"`
+	Service            []Service            `json:"service,omitempty"`
+}
+
+// VerificationMethod is a public key of a DID Document.
+type VerificationMethod struct {
+	Id              string `json:"id"`
+	Type            string `json:"type"`
+	Controller      string `json:"controller"`
+	PublicKeyBase58 string `json:"publicKeyBase58"`
+}
+
+// Service is a service endpoint of a DID Document.
+type Service struct {
+	Id              string   `json:"id"`
+	Type            string   `json:"type"`
+	ServiceEndpoint string   `json:"serviceEndpoint"`
+	RecipientKeys   []string `json:"recipientKeys,omitempty"`
+	RoutingKeys     []string `json:"routingKeys,omitempty"`
+	Accept          []string `json:"accept,omitempty"`
+	Priority        *int     `json:"priority,omitempty"`
+}
+
+// DocumentMetadata describes the ledger entries a DID Document was built
+// from. TxnTime is the time of the latest of them. A DID whose NYM has no
+// verkey is deactivated.
+type DocumentMetadata struct {
+	NymSeqNo    int   `json:"nymSeqNo,omitempty"`
+	AttribSeqNo int   `json:"attribSeqNo,omitempty"`
+	TxnTime     int64 `json:"txnTime,omitempty"`
+	Deactivated bool  `json:"deactivated,omitempty"`
+}
+
+// ErrDIDNotFound is returned by Resolve for DIDs which are not on the
+// ledger.
+var ErrDIDNotFound = errors.New("DID not found")
+
+// ErrUnknownNamespace is returned by Resolve for did:indy DIDs of a
+// namespace the Resolver has no Pool for.
+var ErrUnknownNamespace = errors.New("unknown did:indy namespace")
+
+// A Resolver resolves did:sov and did:indy DIDs into DID Documents,
+// following their method specifications: the verkey of the NYM of the DID
+// becomes its authentication key, and its endpoint ATTRIB its services.
+// did:indy DIDs are resolved on the Pool of their namespace.
+type Resolver struct {
+	pool       *Pool
+	mu         sync.Mutex
+	namespaces map[string]*Pool
+}
+
+// NewResolver returns a Resolver reading did:sov DIDs from pool, which may
+// be nil if only did:indy DIDs are resolved.
+func NewResolver(pool *Pool) *Resolver {
+	return &Resolver{pool: pool, namespaces: make(map[string]*Pool)}
+}
+
+// AddNamespace makes r resolve the did:indy DIDs of namespace, such as
+// sovrin or sovrin:staging, on pool.
+func (r *Resolver) AddNamespace(namespace string, pool *Pool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.namespaces[namespace] = pool
+}
+
+// poolFor returns the Pool holding d.
+func (r *Resolver) poolFor(d *Did) (*Pool, error) {
+	if d.Method == "sov" {
+		if r.pool == nil {
+			return nil, errors.New("resolver has no pool for did:sov")
+		}
+		return r.pool, nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	pool, ok := r.namespaces[d.Namespace]
+	if !ok {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownNamespace, d.Namespace)
+	}
+	return pool, nil
+}
+
+// Resolve returns the DID Document of did, given as a DID or a bare
+// identifier of a did:sov DID, and its metadata. opts apply to the reads of
+// the ledger.
+func (r *Resolver) Resolve(ctx context.Context, did string, opts ...ReadOption) (*DIDDocument, *DocumentMetadata, error) {
+	d := &Did{Method: "sov", Id: did}
+	if strings.HasPrefix(did, "did:") {
+		var err error
+		d, err = DidParse(did)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return r.resolve(ctx, d, 0, 0, opts)
+}
+
+// resolve is Resolve for the version of the NYM of d written by the
+// transaction seqNo, or current at timestamp, if either is not zero.
+func (r *Resolver) resolve(ctx context.Context, d *Did, seqNo int, timestamp int64, opts []ReadOption) (*DIDDocument, *DocumentMetadata, error) {
+	pool, err := r.poolFor(d)
+	if err != nil {
+		return nil, nil, err
+	}
+	id := d.Id
+	nym, err := pool.nymVersion(ctx, id, seqNo, timestamp, opts)
+	if err == ErrNoData {
+		return nil, nil, fmt.Errorf("%w: %v", ErrDIDNotFound, d)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	meta := &DocumentMetadata{NymSeqNo: nym.SeqNo, TxnTime: nym.TxnTime}
+
+	var endpoint *ServiceEndpoint
+	attr, err := pool.GetAttrib(ctx, id, "endpoint", opts...)
+	switch {
+	case err == ErrNoData:
+	case err != nil:
+		return nil, nil, err
+	default:
+		raw, err := json.Marshal(map[string]json.RawMessage{"endpoint": attr.Value})
+		if err != nil {
+			return nil, nil, err
+		}
+		endpoint, err = ParseEndpoint(raw)
+		if err != nil {
+			return nil, nil, err
+		}
+		meta.AttribSeqNo = attr.SeqNo
+		if attr.TxnTime > meta.TxnTime {
+			meta.TxnTime = attr.TxnTime
+		}
+	}
+
+	doc, err := didDocument(d, nym.Verkey, endpoint)
+	if err != nil {
+		return nil, nil, err
+	}
+	meta.Deactivated = nym.Verkey == ""
+	return doc, meta, nil
+}
+
+// ErrDIDURLNotFound is returned by Dereference for DID URLs whose fragment
+// matches nothing in the DID Document.
+var ErrDIDURLNotFound = errors.New("DID URL not found")
+
+// Dereference returns the resource identified by the DID URL didURL: the
+// DID Document of its DID if it has no fragment, or else the
+// *VerificationMethod or *Service of the document whose id it is. The
+// versionId query parameter selects the NYM written by the transaction of
+// that seqNo, and versionTime, an RFC 3339 time, the NYM current at that
+// time. The services are always built from the current endpoint. DID URL
+// paths are not supported.
+func (r *Resolver) Dereference(ctx context.Context, didURL string, opts ...ReadOption) (interface{}, *DocumentMetadata, error) {
+	u, err := ParseDidURL(didURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	if u.Path != "" {
+		return nil, nil, fmt.Errorf("unsupported DID URL path %v", u.Path)
+	}
+	var seqNo int
+	if v := u.Query.Get("versionId"); v != "" {
+		seqNo, err = strconv.Atoi(v)
+		if err != nil || seqNo < 1 {
+			return nil, nil, fmt.Errorf("invalid versionId %q", v)
+		}
+	}
+	var timestamp int64
+	if v := u.Query.Get("versionTime"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid versionTime %q", v)
+		}
+		timestamp = t.Unix()
+	}
+
+	doc, meta, err := r.resolve(ctx, &u.Did, seqNo, timestamp, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if u.Fragment == "" {
+		return doc, meta, nil
+	}
+	id := doc.Id + "#" + u.Fragment
+	for i := range doc.VerificationMethod {
+		if doc.VerificationMethod[i].Id == id {
+			return &doc.VerificationMethod[i], meta, nil
+		}
+	}
+	for i := range doc.Service {
+		if doc.Service[i].Id == id {
+			return &doc.Service[i], meta, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("%w: %v", ErrDIDURLNotFound, didURL)
+}
+
+// didDocument builds the DID Document of d from its verkey and endpoint,
+// which may be nil. did:sov documents have an X25519 key agreement key
+// derived from the verkey, did:indy ones only have the verkey.
+func didDocument(d *Did, verkey string, endpoint *ServiceEndpoint) (*DIDDocument, error) {
+	did := d.String()
+	doc := &DIDDocument{
+		Context: []string{"https://www.w3.org/ns/did/v1"},
+		Id:      did,
+	}
+	if verkey == "" {
+		return doc, nil
+	}
+	verkey, err := ExpandVerkey(d.Id, verkey)
+	if err != nil {
+		return nil, err
+	}
+	vk, err := base58.Decode(verkey)
+	if err != nil || len(vk) != 32 {
+		return nil, fmt.Errorf("invalid verkey %v", verkey)
+	}
+	doc.Context = append(doc.Context, "https://w3id.org/security/suites/ed25519-2018/v1")
+	key, recipientKey := did+"#verkey", did+"#verkey"
+	if d.Method == "sov" {
+		key, recipientKey = did+"#key-1", did+"#key-agreement-1"
+	}
+	doc.VerificationMethod = []VerificationMethod{{
+		Id:              key,
+		Type:            "Ed25519VerificationKey2018",
+		Controller:      did,
+		PublicKeyBase58: verkey,
+	}}
+	doc.Authentication = []string{key}
+	doc.AssertionMethod = []string{key}
+	if d.Method == "sov" {
+		doc.Context = append(doc.Context, "https://w3id.org/security/suites/x25519-2019/v1")
+		doc.VerificationMethod = append(doc.VerificationMethod, VerificationMethod{
+			Id:              recipientKey,
+			Type:            "X25519KeyAgreementKey2019",
+			Controller:      did,
+			PublicKeyBase58: base58.Encode(ed25519PublicKeyToCurve25519(vk)),
+		})
+		doc.KeyAgreement = []string{recipientKey}
+	}
+
+	if endpoint == nil {
+		return doc, nil
+	}
+	// An endpoint without types is both a plain endpoint and a DIDComm v1
+	// one.
+	types := endpoint.Types
+	if len(types) == 0 {
+		types = []string{"endpoint", "did-communication"}
+	}
+	for _, t := range types {
+		s := Service{
+			Id:              did + "#" + t,
+			Type:            t,
+			ServiceEndpoint: endpoint.Endpoint,
+		}
+		switch t {
+		case "endpoint":
+		case "did-communication":
+			priority := 0
+			s.RecipientKeys = []string{recipientKey}
+			s.RoutingKeys = endpoint.RoutingKeys
+			s.Accept = []string{"didcomm/aip2;env=rfc19"}
+			s.Priority = &priority
+		case "DIDComm":
+			s.Id = did + "#didcomm-1"
+			s.RoutingKeys = endpoint.RoutingKeys
+			s.Accept = []string{"didcomm/v2"}
+		default:
+			continue
+		}
+		doc.Service = append(doc.Service, s)
+	}
+	return doc, nil
+}
@@ -0,0 +1,101 @@
+package indyclient_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mr-tron/base58"
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/indyclient"
+	"go.dedis.ch/indyclient/indyclienttest"
+)
+
+func TestResolver(t *testing.T) {
+	sk, verkey, did, err := indyclient.KeypairFromSeed([]byte("00000000000000000000000000000001"))
+	require.NoError(t, err)
+	abbreviated := "~" + base58.Encode(sk.Public().(ed25519.PublicKey)[16:])
+	n := indyclienttest.NewNetwork(4)
+	require.NoError(t, n.LoadTxns(indyclient.DomainLedger, strings.NewReader(fmt.Sprintf(
+		`{"txn":{"type":"1","data":{"dest":"%v","verkey":"%v"}},"txnMetadata":{"seqNo":1,"txnTime":1500000000}}
+{"txn":{"type":"100","data":{"dest":"%v","raw":"{\"endpoint\":{\"endpoint\":\"https://agent.example.com\"}}"}},"txnMetadata":{"seqNo":2,"txnTime":1600000000}}
+`, did, abbreviated, did))))
+	pool, err := n.Pool()
+	require.NoError(t, err)
+	r := indyclient.NewResolver(pool)
+
+	doc, meta, err := r.Resolve(context.Background(), "did:sov:"+did)
+	require.NoError(t, err)
+	require.Equal(t, "did:sov:"+did, doc.Id)
+	require.Equal(t, verkey, doc.VerificationMethod[0].PublicKeyBase58)
+	require.Equal(t, []string{"did:sov:" + did + "#key-1"}, doc.Authentication)
+	require.Len(t, doc.Service, 2)
+	require.Equal(t, "https://agent.example.com", doc.Service[1].ServiceEndpoint)
+	require.Equal(t, "did-communication", doc.Service[1].Type)
+	require.Equal(t, &indyclient.DocumentMetadata{NymSeqNo: 1, AttribSeqNo: 2, TxnTime: 1600000000}, meta)
+
+	_, _, err = r.Resolve(context.Background(), "did:sov:V4SGRU86Z58d6TV7PBUe6f")
+	require.True(t, errors.Is(err, indyclient.ErrDIDNotFound))
+}
+
+func TestResolver_Indy(t *testing.T) {
+	_, verkey, did, err := indyclient.KeypairFromSeed([]byte("00000000000000000000000000000002"))
+	require.NoError(t, err)
+	n := indyclienttest.NewNetwork(4)
+	require.NoError(t, n.LoadTxns(indyclient.DomainLedger, strings.NewReader(fmt.Sprintf(
+		`{"txn":{"type":"1","data":{"dest":"%v","verkey":"%v"}},"txnMetadata":{"seqNo":1}}`, did, verkey))))
+	pool, err := n.Pool()
+	require.NoError(t, err)
+	r := indyclient.NewResolver(nil)
+	r.AddNamespace("test:net", pool)
+
+	doc, _, err := r.Resolve(context.Background(), "did:indy:test:net:"+did)
+	require.NoError(t, err)
+	require.Equal(t, "did:indy:test:net:"+did, doc.Id)
+	require.Equal(t, "did:indy:test:net:"+did+"#verkey", doc.VerificationMethod[0].Id)
+
+	_, _, err = r.Resolve(context.Background(), "did:indy:other:"+did)
+	require.True(t, errors.Is(err, indyclient.ErrUnknownNamespace))
+	_, _, err = r.Resolve(context.Background(), "did:sov:"+did)
+	require.Error(t, err)
+}
+
+func TestResolver_Dereference(t *testing.T) {
+	_, verkey1, did, err := indyclient.KeypairFromSeed([]byte("00000000000000000000000000000003"))
+	require.NoError(t, err)
+	_, verkey2, _, err := indyclient.KeypairFromSeed([]byte("00000000000000000000000000000004"))
+	require.NoError(t, err)
+	n := indyclienttest.NewNetwork(4)
+	require.NoError(t, n.LoadTxns(indyclient.DomainLedger, strings.NewReader(fmt.Sprintf(
+		`{"txn":{"type":"1","data":{"dest":"%v","verkey":"%v"}},"txnMetadata":{"seqNo":1,"txnTime":1500000000}}
+{"txn":{"type":"1","data":{"dest":"%v","verkey":"%v"}},"txnMetadata":{"seqNo":2,"txnTime":1600000000}}
+`, did, verkey1, did, verkey2))))
+	pool, err := n.Pool()
+	require.NoError(t, err)
+	r := indyclient.NewResolver(pool)
+	ctx := context.Background()
+
+	res, meta, err := r.Dereference(ctx, "did:sov:"+did+"#key-1")
+	require.NoError(t, err)
+	require.Equal(t, verkey2, res.(*indyclient.VerificationMethod).PublicKeyBase58)
+	require.Equal(t, 2, meta.NymSeqNo)
+
+	res, meta, err = r.Dereference(ctx, "did:sov:"+did+"?versionId=1#key-1")
+	require.NoError(t, err)
+	require.Equal(t, verkey1, res.(*indyclient.VerificationMethod).PublicKeyBase58)
+	require.Equal(t, 1, meta.NymSeqNo)
+
+	res, _, err = r.Dereference(ctx, "did:sov:"+did+"?versionTime=2019-01-01T00:00:00Z")
+	require.NoError(t, err)
+	require.Equal(t, verkey1, res.(*indyclient.DIDDocument).VerificationMethod[0].PublicKeyBase58)
+
+	_, _, err = r.Dereference(ctx, "did:sov:"+did+"?versionTime=2000-01-01T00:00:00Z")
+	require.True(t, errors.Is(err, indyclient.ErrDIDNotFound))
+	_, _, err = r.Dereference(ctx, "did:sov:"+did+"#key-9")
+	require.True(t, errors.Is(err, indyclient.ErrDIDURLNotFound))
+	_, _, err = r.Dereference(ctx, "did:sov:"+did+"/path")
+	require.Error(t, err)
+}
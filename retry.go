@@ -0,0 +1,65 @@
+package indyclient
+
+import "time"
+
+// A RetryPolicy decides how hard the Pool tries to get a request answered.
+// It applies to every request sent to a single validator at a time, reads
+// and writes alike.
+//
+// A request sent again is the same message, with the same reqId and, for
+// writes, the same signature. Validators recognize it by its digest, so
+// that a write resent after a timeout is ordered at most once, and the
+// validator which ordered it answers with the transaction already written.
+// Writes must thus be retried by the Pool rather than signed again by the
+// application, which would make a new, distinct request.
+type RetryPolicy struct {
+	// Attempts bounds the number of connections a request may use, and
+	// Timeout the time it may take, as WithRetryBudget. Zero keeps the
+	// defaults of 10 attempts and 30 seconds.
+	Attempts int
+	Timeout  time.Duration
+	// BackoffBase and BackoffMax set the delays between attempts, as
+	// WithBackoff.
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	// RetryTimeouts makes the Pool resend requests which a validator did
+	// not answer within the reply timeout to the next validator. Otherwise
+	// the request fails with ErrReplyTimeout.
+	RetryTimeouts bool
+	// RetryReqNack makes the Pool resend requests which a validator
+	// refused with a REQNACK to the next validator, after a backoff. A
+	// REQNACK may come from a validator lagging behind the pool, for
+	// example one which does not know the DID of the signer yet. Requests
+	// refused with a REJECT are never resent.
+	RetryReqNack bool
+}
+
+// DefaultRetryPolicy returns the policy of Pools constructed without
+// WithRetryPolicy.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		Attempts:      defaultBudgetAttempts,
+		Timeout:       defaultBudgetTime,
+		BackoffBase:   defaultBackoffBase,
+		BackoffMax:    defaultBackoffMax,
+		RetryTimeouts: true,
+	}
+}
+
+// WithRetryPolicy makes the Pool retry requests according to r. It
+// replaces WithRetryBudget and WithBackoff.
+func WithRetryPolicy(r RetryPolicy) Option {
+	return func(p *Pool) {
+		p.budgetAttempts = defaultBudgetAttempts
+		if r.Attempts != 0 {
+			p.budgetAttempts = r.Attempts
+		}
+		p.budgetTime = defaultBudgetTime
+		if r.Timeout != 0 {
+			p.budgetTime = r.Timeout
+		}
+		p.backoff = backoff{base: r.BackoffBase, max: r.BackoffMax}
+		p.retryTimeouts = r.RetryTimeouts
+		p.retryReqNack = r.RetryReqNack
+	}
+}
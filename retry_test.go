@@ -0,0 +1,62 @@
+package indyclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool_RetryPolicy(t *testing.T) {
+	var mu sync.Mutex
+	var reqIds []seqNo
+	nack := func(m []byte) [][]byte {
+		var req struct{ ReqId seqNo }
+		require.NoError(t, json.Unmarshal(m, &req))
+		mu.Lock()
+		reqIds = append(reqIds, req.ReqId)
+		mu.Unlock()
+		return [][]byte{[]byte(fmt.Sprintf(`{"op":"REQNACK","reqId":%v,"reason":"unknown DID"}`, req.ReqId))}
+	}
+	silent := func(m []byte) [][]byte { return nil }
+	v := ledgerValidator(testLedger)
+
+	policy := DefaultRetryPolicy()
+	policy.BackoffBase = time.Millisecond
+	policy.BackoffMax = time.Millisecond
+	pool := testPool(t, fakeTransport{"Node1": nack, "Node2": v}, WithRetryPolicy(policy))
+	reply, err := pool.GetTransaction(context.Background(), DomainLedger, 1)
+	require.NoError(t, err)
+	require.True(t, errors.Is(checkReply(reply), ErrReqNack))
+
+	policy.RetryReqNack = true
+	pool = testPool(t, fakeTransport{"Node1": nack, "Node2": nack, "Node3": v}, WithRetryPolicy(policy))
+	reply, err = pool.GetTransaction(context.Background(), DomainLedger, 1)
+	require.NoError(t, err)
+	require.Equal(t, "Node3", reply.Node)
+	// The request was resent as is.
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, reqIds, 3)
+	require.Equal(t, reqIds[1], reqIds[2])
+
+	policy.RetryTimeouts = false
+	pool = testPool(t, fakeTransport{"Node1": silent, "Node2": v}, WithRetryPolicy(policy), WithReplyTimeout(20*time.Millisecond))
+	_, err = pool.GetTransaction(context.Background(), DomainLedger, 1)
+	require.True(t, errors.Is(err, ErrReplyTimeout))
+}
+
+func TestWithRetryPolicy_Defaults(t *testing.T) {
+	pool := testPool(t, fakeTransport{}, WithRetryPolicy(RetryPolicy{RetryTimeouts: true}))
+	require.Equal(t, defaultBudgetAttempts, pool.budgetAttempts)
+	require.Equal(t, defaultBudgetTime, pool.budgetTime)
+
+	pool = testPool(t, fakeTransport{}, WithRetryPolicy(RetryPolicy{Attempts: 3, Timeout: time.Second}))
+	require.Equal(t, 3, pool.budgetAttempts)
+	require.Equal(t, time.Second, pool.budgetTime)
+}
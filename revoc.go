@@ -0,0 +1,364 @@
+package indyclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+type getRevocRegDefOp struct {
+	Type protoId `json:"type,string"`
+	Id   string  `json:"id"`
+}
+
+type getRevocRegOp struct {
+	Type          protoId `json:"type,string"`
+	RevocRegDefId string  `json:"revocRegDefId"`
+	Timestamp     int64   `json:"timestamp"`
+}
+
+type getRevocRegDeltaOp struct {
+	Type          protoId `json:"type,string"`
+	RevocRegDefId string  `json:"revocRegDefId"`
+	From          int64   `json:"from,omitempty"`
+	To            int64   `json:"to"`
+}
+
+type revocRegDefOp struct {
+	Type         protoId         `json:"type,string"`
+	Id           string          `json:"id"`
+	RevocDefType string          `json:"revocDefType"`
+	Tag          string          `json:"tag"`
+	CredDefId    string          `json:"credDefId"`
+	Value        json.RawMessage `json:"value"`
+}
+
+type revocRegEntryOp struct {
+	Type          protoId       `json:"type,string"`
+	RevocRegDefId string        `json:"revocRegDefId"`
+	RevocDefType  string        `json:"revocDefType"`
+	Value         RevocRegEntry `json:"value"`
+}
+
+// RevocRegDef is the definition of a revocation registry, as returned by
+// GET_REVOC_REG_DEF. Value holds the whole value of the definition,
+// including the public keys; its most used fields are copied out.
+type RevocRegDef struct {
+	Id            string
+	Type          string // revocDefType, usually CL_ACCUM
+	Tag           string
+	CredDefId     string
+	IssuanceType  string
+	MaxCredNum    int
+	TailsHash     string
+	TailsLocation string
+	Value         json.RawMessage
+	SeqNo         int
+	TxnTime       int64
+}
+
+// RevocReg is the state of a revocation registry at some time, as returned
+// by GET_REVOC_REG.
+type RevocReg struct {
+	RevocRegDefId string
+	Accum         string // the accumulator value
+	SeqNo         int
+	TxnTime       int64 // when this state was written
+}
+
+// RevocRegDelta is the change of a revocation registry between two times,
+// as returned by GET_REVOC_REG_DELTA. AccumFrom and TxnTimeFrom are empty if
+// the delta starts at the creation of the registry.
+type RevocRegDelta struct {
+	RevocRegDefId string
+	AccumFrom     string
+	TxnTimeFrom   int64
+	AccumTo       string
+	TxnTimeTo     int64
+	Issued        []int
+	Revoked       []int
+}
+
+// revocEntry is an accumulator state in the data of GET_REVOC_REG and
+// GET_REVOC_REG_DELTA replies.
+type revocEntry struct {
+	RevocRegDefId string `json:"revocRegDefId"`
+	SeqNo         int    `json:"seqNo"`
+	TxnTime       int64  `json:"txnTime"`
+	Value         struct {
+		Accum string `json:"accum"`
+	} `json:"value"`
+}
+
+// GetRevocRegDef fetches the revocation registry definition id, in either
+// format of ParseRevocRegId. It returns ErrNoData if there is no such
+// definition.
+func (p *Pool) GetRevocRegDef(ctx context.Context, id string, opts ...ReadOption) (*RevocRegDef, error) {
+	id, err := legacyRevocRegId(id)
+	if err != nil {
+		return nil, err
+	}
+	r, err := p.read(ctx, getRevocRegDefOp{Type: idGetRevocRegDef, Id: id}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkReply(r); err != nil {
+		return nil, err
+	}
+	return revocRegDefFromReply(r)
+}
+
+func revocRegDefFromReply(r *Reply) (*RevocRegDef, error) {
+	var res struct {
+		SeqNo   int   `json:"seqNo"`
+		TxnTime int64 `json:"txnTime"`
+	}
+	if err := json.Unmarshal(r.Result, &res); err != nil {
+		return nil, err
+	}
+	var data struct {
+		Id           string          `json:"id"`
+		RevocDefType string          `json:"revocDefType"`
+		Tag          string          `json:"tag"`
+		CredDefId    string          `json:"credDefId"`
+		Value        json.RawMessage `json:"value"`
+	}
+	if err := r.DecodeResult(&data); err != nil {
+		return nil, err
+	}
+	var value struct {
+		IssuanceType  string `json:"issuanceType"`
+		MaxCredNum    int    `json:"maxCredNum"`
+		TailsHash     string `json:"tailsHash"`
+		TailsLocation string `json:"tailsLocation"`
+	}
+	if len(data.Value) > 0 {
+		if err := json.Unmarshal(data.Value, &value); err != nil {
+			return nil, err
+		}
+	}
+	return &RevocRegDef{
+		Id:            data.Id,
+		Type:          data.RevocDefType,
+		Tag:           data.Tag,
+		CredDefId:     data.CredDefId,
+		IssuanceType:  value.IssuanceType,
+		MaxCredNum:    value.MaxCredNum,
+		TailsHash:     value.TailsHash,
+		TailsLocation: value.TailsLocation,
+		Value:         data.Value,
+		SeqNo:         res.SeqNo,
+		TxnTime:       res.TxnTime,
+	}, nil
+}
+
+// GetRevocReg fetches the state of the revocation registry defined by
+// revocRegDefId as it was at timestamp, in seconds since the epoch. It
+// returns ErrNoData if the registry did not exist then.
+func (p *Pool) GetRevocReg(ctx context.Context, revocRegDefId string, timestamp int64, opts ...ReadOption) (*RevocReg, error) {
+	revocRegDefId, err := legacyRevocRegId(revocRegDefId)
+	if err != nil {
+		return nil, err
+	}
+	r, err := p.read(ctx, getRevocRegOp{
+		Type:          idGetRevocReg,
+		RevocRegDefId: revocRegDefId,
+		Timestamp:     timestamp,
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkReply(r); err != nil {
+		return nil, err
+	}
+	return revocRegFromReply(r)
+}
+
+func revocRegFromReply(r *Reply) (*RevocReg, error) {
+	var res struct {
+		SeqNo   int   `json:"seqNo"`
+		TxnTime int64 `json:"txnTime"`
+	}
+	if err := json.Unmarshal(r.Result, &res); err != nil {
+		return nil, err
+	}
+	var data revocEntry
+	if err := r.DecodeResult(&data); err != nil {
+		return nil, err
+	}
+	// Depending on the version of indy-node, the position of the entry is
+	// in the data or next to it.
+	if data.SeqNo == 0 {
+		data.SeqNo, data.TxnTime = res.SeqNo, res.TxnTime
+	}
+	return &RevocReg{
+		RevocRegDefId: data.RevocRegDefId,
+		Accum:         data.Value.Accum,
+		SeqNo:         data.SeqNo,
+		TxnTime:       data.TxnTime,
+	}, nil
+}
+
+// GetRevocRegDelta fetches the credentials issued and revoked in the
+// revocation registry defined by revocRegDefId between the timestamps from
+// and to, in seconds since the epoch. A zero from asks for the delta since
+// the creation of the registry. It returns ErrNoData if the registry did
+// not exist at to.
+func (p *Pool) GetRevocRegDelta(ctx context.Context, revocRegDefId string, from, to int64, opts ...ReadOption) (*RevocRegDelta, error) {
+	revocRegDefId, err := legacyRevocRegId(revocRegDefId)
+	if err != nil {
+		return nil, err
+	}
+	r, err := p.read(ctx, getRevocRegDeltaOp{
+		Type:          idGetRevocRegDelta,
+		RevocRegDefId: revocRegDefId,
+		From:          from,
+		To:            to,
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkReply(r); err != nil {
+		return nil, err
+	}
+	return revocRegDeltaFromReply(r)
+}
+
+func revocRegDeltaFromReply(r *Reply) (*RevocRegDelta, error) {
+	var data struct {
+		RevocRegDefId string `json:"revocRegDefId"`
+		Value         struct {
+			AccumFrom *revocEntry `json:"accum_from"`
+			AccumTo   *revocEntry `json:"accum_to"`
+			Issued    []int       `json:"issued"`
+			Revoked   []int       `json:"revoked"`
+		} `json:"value"`
+	}
+	if err := r.DecodeResult(&data); err != nil {
+		return nil, err
+	}
+	if data.Value.AccumTo == nil {
+		return nil, ErrNoData
+	}
+	d := &RevocRegDelta{
+		RevocRegDefId: data.RevocRegDefId,
+		AccumTo:       data.Value.AccumTo.Value.Accum,
+		TxnTimeTo:     data.Value.AccumTo.TxnTime,
+		Issued:        data.Value.Issued,
+		Revoked:       data.Value.Revoked,
+	}
+	if from := data.Value.AccumFrom; from != nil {
+		d.AccumFrom = from.Value.Accum
+		d.TxnTimeFrom = from.TxnTime
+	}
+	return d, nil
+}
+
+// revocRegDefId returns the id of a CL_ACCUM revocation registry of
+// credDefId written by did, in the format did:4:credDefId:CL_ACCUM:tag.
+func revocRegDefId(did, credDefId, tag string) string {
+	return fmt.Sprintf("%v:4:%v:CL_ACCUM:%v", did, credDefId, tag)
+}
+
+// WriteRevocRegDef writes the definition of a CL_ACCUM revocation registry
+// for the credential definition credDefId, on behalf of signer, and returns
+// it once the pool has ordered it. value holds the registry parameters,
+// tails and public keys, as generated by an anoncreds library.
+func (p *Pool) WriteRevocRegDef(ctx context.Context, signer Signer, credDefId, tag string, value json.RawMessage) (*RevocRegDef, error) {
+	id := revocRegDefId(signer.Did(), credDefId, tag)
+	b, err := p.write(ctx, revocRegDefOp{
+		Type:         idRevocRegDef,
+		Id:           id,
+		RevocDefType: "CL_ACCUM",
+		Tag:          tag,
+		CredDefId:    credDefId,
+		Value:        value,
+	}, signer)
+	if err != nil {
+		return nil, err
+	}
+	var v struct {
+		IssuanceType  string `json:"issuanceType"`
+		MaxCredNum    int    `json:"maxCredNum"`
+		TailsHash     string `json:"tailsHash"`
+		TailsLocation string `json:"tailsLocation"`
+	}
+	if err := json.Unmarshal(value, &v); err != nil {
+		return nil, err
+	}
+	return &RevocRegDef{
+		Id:            id,
+		Type:          "CL_ACCUM",
+		Tag:           tag,
+		CredDefId:     credDefId,
+		IssuanceType:  v.IssuanceType,
+		MaxCredNum:    v.MaxCredNum,
+		TailsHash:     v.TailsHash,
+		TailsLocation: v.TailsLocation,
+		Value:         value,
+		SeqNo:         b.TxnMetadata.SeqNo,
+		TxnTime:       b.TxnMetadata.TxnTime,
+	}, nil
+}
+
+// RevocRegEntry is an update of a revocation registry. The ledger stores
+// updates as deltas: the credential indices issued and revoked since the
+// previous entry, whose accumulator is PrevAccum. PrevAccum is empty for
+// the first entry of a registry.
+type RevocRegEntry struct {
+	Accum     string `json:"accum"`
+	PrevAccum string `json:"prevAccum,omitempty"`
+	Issued    []int  `json:"issued,omitempty"`
+	Revoked   []int  `json:"revoked,omitempty"`
+}
+
+// NewRevocRegEntry returns the entry updating a registry from the full
+// state prevAccum, with the credential indices prevRevoked revoked, to the
+// state accum with revoked revoked.
+func NewRevocRegEntry(prevAccum string, prevRevoked []int, accum string, revoked []int) RevocRegEntry {
+	return RevocRegEntry{
+		Accum:     accum,
+		PrevAccum: prevAccum,
+		Issued:    difference(prevRevoked, revoked),
+		Revoked:   difference(revoked, prevRevoked),
+	}
+}
+
+// difference returns the sorted elements of a which are not in b.
+func difference(a, b []int) []int {
+	in := make(map[int]bool, len(b))
+	for _, i := range b {
+		in[i] = true
+	}
+	var d []int
+	for _, i := range a {
+		if !in[i] {
+			d = append(d, i)
+			in[i] = true
+		}
+	}
+	sort.Ints(d)
+	return d
+}
+
+// WriteRevocRegEntry writes the update e of the CL_ACCUM revocation registry
+// revocRegDefId, on behalf of signer, and returns the new state of the
+// registry once the pool has ordered it.
+func (p *Pool) WriteRevocRegEntry(ctx context.Context, signer Signer, revocRegDefId string, e RevocRegEntry) (*RevocReg, error) {
+	b, err := p.write(ctx, revocRegEntryOp{
+		Type:          idRevocRegEntry,
+		RevocRegDefId: revocRegDefId,
+		RevocDefType:  "CL_ACCUM",
+		Value:         e,
+	}, signer)
+	if err != nil {
+		return nil, err
+	}
+	return &RevocReg{
+		RevocRegDefId: revocRegDefId,
+		Accum:         e.Accum,
+		SeqNo:         b.TxnMetadata.SeqNo,
+		TxnTime:       b.TxnMetadata.TxnTime,
+	}, nil
+}
@@ -0,0 +1,46 @@
+package indyclient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevocFromReply(t *testing.T) {
+	defId := "V4SGRU86Z58d6TV7PBUe6f:4:V4SGRU86Z58d6TV7PBUe6f:3:CL:10:default:CL_ACCUM:tag1"
+
+	r := &Reply{Op: "REPLY", Result: []byte(`{"type":"115","seqNo":20,"txnTime":1500000000,"data":{"id":"` + defId + `","revocDefType":"CL_ACCUM","tag":"tag1","credDefId":"V4SGRU86Z58d6TV7PBUe6f:3:CL:10:default","value":{"issuanceType":"ISSUANCE_BY_DEFAULT","maxCredNum":100,"tailsHash":"th","tailsLocation":"http://tails","publicKeys":{"accumKey":{"z":"1"}}}}}`)}
+	v, err := r.Decode()
+	require.NoError(t, err)
+	def := v.(*RevocRegDef)
+	require.Equal(t, defId, def.Id)
+	require.Equal(t, 100, def.MaxCredNum)
+	require.Equal(t, "http://tails", def.TailsLocation)
+	require.Equal(t, 20, def.SeqNo)
+
+	r = &Reply{Op: "REPLY", Result: []byte(`{"type":"116","seqNo":21,"txnTime":1500000100,"data":{"revocRegDefId":"` + defId + `","revocDefType":"CL_ACCUM","value":{"accum":"21 1"}}}`)}
+	v, err = r.Decode()
+	require.NoError(t, err)
+	require.Equal(t, &RevocReg{RevocRegDefId: defId, Accum: "21 1", SeqNo: 21, TxnTime: 1500000100}, v)
+
+	r = &Reply{Op: "REPLY", Result: []byte(`{"type":"117","data":{"revocRegDefId":"` + defId + `","value":{"accum_to":{"seqNo":23,"txnTime":1500000300,"value":{"accum":"21 3"}},"accum_from":{"seqNo":21,"txnTime":1500000100,"value":{"accum":"21 1"}},"issued":[],"revoked":[4,7]}}}`)}
+	v, err = r.Decode()
+	require.NoError(t, err)
+	d := v.(*RevocRegDelta)
+	require.Equal(t, "21 1", d.AccumFrom)
+	require.Equal(t, int64(1500000300), d.TxnTimeTo)
+	require.Equal(t, []int{4, 7}, d.Revoked)
+
+	r = &Reply{Op: "REPLY", Result: []byte(`{"type":"116","data":null}`)}
+	_, err = r.Decode()
+	require.Equal(t, ErrNoData, err)
+}
+
+func TestNewRevocRegEntry(t *testing.T) {
+	e := NewRevocRegEntry("21 1", []int{1, 4}, "21 2", []int{7, 1, 9})
+	require.Equal(t, RevocRegEntry{Accum: "21 2", PrevAccum: "21 1", Issued: []int{4}, Revoked: []int{7, 9}}, e)
+
+	e = NewRevocRegEntry("", nil, "21 1", nil)
+	require.Nil(t, e.Issued)
+	require.Nil(t, e.Revoked)
+}
@@ -0,0 +1,162 @@
+package indyclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// RichSchemaType is the kind of a rich schema object, as defined by Aries
+// RFC 0281: JSON-LD contexts, schemas, encodings, mappings, credential
+// definitions and presentation definitions.
+type RichSchemaType string
+
+const (
+	RichSchemaContext  RichSchemaType = "ctx"
+	RichSchemaSchema   RichSchemaType = "sch"
+	RichSchemaEncoding RichSchemaType = "enc"
+	RichSchemaMapping  RichSchemaType = "map"
+	RichSchemaCredDef  RichSchemaType = "cdf"
+	RichSchemaPresDef  RichSchemaType = "pdf"
+)
+
+// txnType returns the type of the transactions writing objects of type t.
+func (t RichSchemaType) txnType() (protoId, error) {
+	switch t {
+	case RichSchemaContext:
+		return idJSONLDContext, nil
+	case RichSchemaSchema:
+		return idRichSchema, nil
+	case RichSchemaEncoding:
+		return idRichSchemaEncoding, nil
+	case RichSchemaMapping:
+		return idRichSchemaMapping, nil
+	case RichSchemaCredDef:
+		return idRichSchemaCredDef, nil
+	case RichSchemaPresDef:
+		return idRichSchemaPresDef, nil
+	}
+	return 0, fmt.Errorf("unknown rich schema type %q", string(t))
+}
+
+// RichSchemaTxn is a rich schema transaction, such as JSON_LD_CONTEXT or
+// RICH_SCHEMA, which writes a rich schema object. Content is the JSON-LD
+// document of the object; its @id is Id, a DID of the form did:sov:...
+// Objects are immutable: an object with the same id, or the same type,
+// name and version, cannot be written again.
+type RichSchemaTxn struct {
+	Id      string         `json:"id"`
+	Type    RichSchemaType `json:"rsType"`
+	Name    string         `json:"rsName"`
+	Version string         `json:"rsVersion"`
+	Content string         `json:"content"`
+	Ver     string         `json:"ver"` // version of the transaction format
+}
+
+// DecodeContent decodes the JSON-LD document of the object into v.
+func (t *RichSchemaTxn) DecodeContent(v interface{}) error {
+	return json.Unmarshal([]byte(t.Content), v)
+}
+
+// RichSchemaObject is a rich schema object, as returned by
+// GET_RICH_SCHEMA_OBJECT_BY_ID and GET_RICH_SCHEMA_OBJECT_BY_METADATA.
+type RichSchemaObject struct {
+	RichSchemaTxn
+	From     string `json:"from"` // DID which wrote the object
+	Endorser string `json:"endorser,omitempty"`
+	SeqNo    int    `json:"-"`
+	TxnTime  int64  `json:"-"`
+}
+
+type richSchemaOp struct {
+	Type protoId `json:"type,string"`
+	RichSchemaTxn
+}
+
+type getRichSchemaByIdOp struct {
+	Type protoId `json:"type,string"`
+	Id   string  `json:"id"`
+}
+
+type getRichSchemaByMetadataOp struct {
+	Type    protoId        `json:"type,string"`
+	RSType  RichSchemaType `json:"rsType"`
+	Name    string         `json:"rsName"`
+	Version string         `json:"rsVersion"`
+}
+
+// GetRichSchemaObject fetches the rich schema object with the given id. It
+// returns ErrNoData if there is no such object.
+func (p *Pool) GetRichSchemaObject(ctx context.Context, id string, opts ...ReadOption) (*RichSchemaObject, error) {
+	r, err := p.read(ctx, getRichSchemaByIdOp{Type: idGetRichSchemaById, Id: id}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return richSchemaFromReply(r)
+}
+
+// GetRichSchemaObjectByMetadata fetches the rich schema object of type typ
+// with the given name and version. It returns ErrNoData if there is no such
+// object.
+func (p *Pool) GetRichSchemaObjectByMetadata(ctx context.Context, typ RichSchemaType, name, version string, opts ...ReadOption) (*RichSchemaObject, error) {
+	r, err := p.read(ctx, getRichSchemaByMetadataOp{
+		Type:    idGetRichSchemaByMetadata,
+		RSType:  typ,
+		Name:    name,
+		Version: version,
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return richSchemaFromReply(r)
+}
+
+func richSchemaFromReply(r *Reply) (*RichSchemaObject, error) {
+	if err := checkReply(r); err != nil {
+		return nil, err
+	}
+	var res struct {
+		SeqNo   int
+		TxnTime int64
+	}
+	if err := json.Unmarshal(r.Result, &res); err != nil {
+		return nil, err
+	}
+	o := new(RichSchemaObject)
+	if err := r.DecodeResult(o); err != nil {
+		return nil, err
+	}
+	o.SeqNo, o.TxnTime = res.SeqNo, res.TxnTime
+	return o, nil
+}
+
+// WriteRichSchemaObject writes the rich schema object t on behalf of
+// signer and returns it once the pool has ordered it. Ver defaults to 1.
+// Rich schema transactions are disabled on most networks; validators
+// refuse them with a REQNACK there.
+func (p *Pool) WriteRichSchemaObject(ctx context.Context, signer Signer, t RichSchemaTxn) (*RichSchemaObject, error) {
+	typ, err := t.Type.txnType()
+	if err != nil {
+		return nil, err
+	}
+	if t.Id == "" {
+		return nil, errors.New("rich schema object without id")
+	}
+	if !json.Valid([]byte(t.Content)) {
+		return nil, fmt.Errorf("content of %v is not JSON", t.Id)
+	}
+	if t.Ver == "" {
+		t.Ver = "1"
+	}
+	b, err := p.write(ctx, richSchemaOp{Type: typ, RichSchemaTxn: t}, signer)
+	if err != nil {
+		return nil, err
+	}
+	return &RichSchemaObject{
+		RichSchemaTxn: t,
+		From:          signer.Did(),
+		SeqNo:         b.TxnMetadata.SeqNo,
+		TxnTime:       b.TxnMetadata.TxnTime,
+	}, nil
+}
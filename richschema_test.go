@@ -0,0 +1,47 @@
+package indyclient
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRichSchema(t *testing.T) {
+	content := `{"@id":"did:sov:8a9F6Z1v5HjaHLnBdpZyt6","@type":"rdfs:Class","name":"Driver license"}`
+	r := &Reply{Op: "REPLY", Result: []byte(`{"type":"300","seqNo":7,"txnTime":1600000000,"data":{"id":"did:sov:8a9F6Z1v5HjaHLnBdpZyt6","rsType":"sch","rsName":"License","rsVersion":"1.0","content":` +
+		string(mustMarshal(t, content)) + `,"from":"V4SGRU86Z58d6TV7PBUe6f","ver":"1"}}`)}
+	v, err := r.Decode()
+	require.NoError(t, err)
+	o := v.(*RichSchemaObject)
+	require.Equal(t, RichSchemaSchema, o.Type)
+	require.Equal(t, "License", o.Name)
+	require.Equal(t, "V4SGRU86Z58d6TV7PBUe6f", o.From)
+	require.Equal(t, 7, o.SeqNo)
+	require.Equal(t, int64(1600000000), o.TxnTime)
+	var doc struct {
+		Name string `json:"name"`
+	}
+	require.NoError(t, o.DecodeContent(&doc))
+	require.Equal(t, "Driver license", doc.Name)
+
+	_, err = richSchemaFromReply(&Reply{Op: "REPLY", Result: []byte(`{"type":"300","data":null}`)})
+	require.Equal(t, ErrNoData, err)
+
+	m, err := json.Marshal(richSchemaOp{Type: idRichSchema, RichSchemaTxn: o.RichSchemaTxn})
+	require.NoError(t, err)
+	var b Block
+	require.NoError(t, json.Unmarshal([]byte(`{"txn":{"type":"201","data":`+string(m)+`,"metadata":{}},"txnMetadata":{"seqNo":7}}`), &b))
+	v, err = b.Decode()
+	require.NoError(t, err)
+	require.Equal(t, &o.RichSchemaTxn, v)
+
+	_, err = RichSchemaType("xyz").txnType()
+	require.Error(t, err)
+}
+
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	m, err := json.Marshal(v)
+	require.NoError(t, err)
+	return m
+}
@@ -0,0 +1,81 @@
+package indyclient
+
+import "errors"
+
+var errRLP = errors.New("invalid RLP encoding")
+
+// rlpItem is a decoded RLP item: either a string or a list of items.
+type rlpItem struct {
+	str    []byte
+	list   []rlpItem
+	isList bool
+	raw    []byte // the encoding of the item
+}
+
+// rlpDecode decodes the single RLP item b.
+func rlpDecode(b []byte) (rlpItem, error) {
+	it, rest, err := rlpNext(b)
+	if err != nil {
+		return rlpItem{}, err
+	}
+	if len(rest) != 0 {
+		return rlpItem{}, errRLP
+	}
+	return it, nil
+}
+
+// rlpNext decodes the item at the start of b and returns it with the
+// remaining bytes.
+func rlpNext(b []byte) (rlpItem, []byte, error) {
+	if len(b) == 0 {
+		return rlpItem{}, nil, errRLP
+	}
+	var it rlpItem
+	var hdr, n int
+	switch p := int(b[0]); {
+	case p < 0x80:
+		it.str = b[:1]
+		it.raw = b[:1]
+		return it, b[1:], nil
+	case p <= 0xb7:
+		hdr, n = 1, p-0x80
+	case p < 0xc0:
+		hdr, n = rlpLength(b, p-0xb7)
+	case p <= 0xf7:
+		hdr, n = 1, p-0xc0
+		it.isList = true
+	default:
+		hdr, n = rlpLength(b, p-0xf7)
+		it.isList = true
+	}
+	if hdr == 0 || n < 0 || len(b)-hdr < n {
+		return rlpItem{}, nil, errRLP
+	}
+	it.raw = b[:hdr+n]
+	payload := b[hdr : hdr+n]
+	if !it.isList {
+		it.str = payload
+		return it, b[hdr+n:], nil
+	}
+	for len(payload) > 0 {
+		e, rest, err := rlpNext(payload)
+		if err != nil {
+			return rlpItem{}, nil, err
+		}
+		it.list = append(it.list, e)
+		payload = rest
+	}
+	return it, b[hdr+n:], nil
+}
+
+// rlpLength decodes the long form length of size bytes following the prefix
+// of b. It returns a zero header length if b is too short.
+func rlpLength(b []byte, size int) (hdr, n int) {
+	if size > 4 || len(b) < 1+size {
+		return 0, 0
+	}
+	for _, c := range b[1 : 1+size] {
+		n = n<<8 | int(c)
+	}
+	return 1 + size, n
+}
@@ -0,0 +1,92 @@
+package indyclient
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Role codes of NYMs, as found in Nym.Role and NymTxn.Role. DIDs without a
+// role are identity owners, which may only write about themselves.
+const (
+	RoleTrustee        = "0"
+	RoleSteward        = "2"
+	RoleEndorser       = "101"
+	RoleNetworkMonitor = "201"
+)
+
+var roleNames = map[string]string{
+	RoleTrustee:        "TRUSTEE",
+	RoleSteward:        "STEWARD",
+	RoleEndorser:       "ENDORSER",
+	RoleNetworkMonitor: "NETWORK_MONITOR",
+}
+
+// RoleName returns the name of the role code, such as ENDORSER for 101, ""
+// for no role, or the code itself if it is unknown.
+func RoleName(code string) string {
+	if name, ok := roleNames[code]; ok {
+		return name
+	}
+	return code
+}
+
+// ParseRole returns the code of the role named s, in any case, such as 101
+// for ENDORSER or its former name TRUST_ANCHOR. Numeric codes are returned
+// as is.
+func ParseRole(s string) (string, error) {
+	name := strings.ToUpper(s)
+	if name == "TRUST_ANCHOR" {
+		return RoleEndorser, nil
+	}
+	for code, n := range roleNames {
+		if n == name {
+			return code, nil
+		}
+	}
+	if _, err := strconv.Atoi(s); err == nil {
+		return s, nil
+	}
+	return "", fmt.Errorf("unknown role %q", s)
+}
+
+// roleOp is a NYM which only changes the role of its target. Unlike nymOp,
+// it sends a missing role as null, which is how NYMs remove a role.
+type roleOp struct {
+	Type protoId `json:"type,string"`
+	Dest string  `json:"dest"`
+	Role *string `json:"role"`
+}
+
+// NewRoleRequest returns the NYM request written by Pool.SetRole.
+func NewRoleRequest(did, role string) (*Request, error) {
+	id, err := didId(did)
+	if err != nil {
+		return nil, err
+	}
+	op := roleOp{Type: idNym, Dest: id}
+	if role != "" {
+		op.Role = &role
+	}
+	return &Request{Operation: op}, nil
+}
+
+// SetRole gives did the role code, such as RoleEndorser, with a NYM
+// transaction signed by signer, and returns it once the pool has ordered
+// it. An empty role removes the role of did, as RemoveRole. The auth rules
+// of the pool tell who may grant or remove which role; by default trustees
+// manage all roles and stewards grant the endorser role.
+func (p *Pool) SetRole(ctx context.Context, signer Signer, did, role string) (*Block, error) {
+	req, err := NewRoleRequest(did, role)
+	if err != nil {
+		return nil, err
+	}
+	return p.write(ctx, req.Operation, signer)
+}
+
+// RemoveRole removes the role of did, which becomes an identity owner,
+// with a NYM transaction signed by signer, whose role is null.
+func (p *Pool) RemoveRole(ctx context.Context, signer Signer, did string) (*Block, error) {
+	return p.SetRole(ctx, signer, did, "")
+}
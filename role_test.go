@@ -0,0 +1,50 @@
+package indyclient
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRole(t *testing.T) {
+	for _, tc := range []struct{ name, code string }{
+		{"TRUSTEE", RoleTrustee},
+		{"steward", RoleSteward},
+		{"ENDORSER", RoleEndorser},
+		{"TRUST_ANCHOR", RoleEndorser},
+		{"NETWORK_MONITOR", RoleNetworkMonitor},
+		{"101", RoleEndorser},
+	} {
+		code, err := ParseRole(tc.name)
+		require.NoError(t, err)
+		require.Equal(t, tc.code, code)
+	}
+	_, err := ParseRole("OWNER")
+	require.Error(t, err)
+
+	require.Equal(t, "ENDORSER", RoleName(RoleEndorser))
+	require.Equal(t, "", RoleName(""))
+	require.Equal(t, "7", RoleName("7"))
+}
+
+func TestNewRoleRequest(t *testing.T) {
+	req, err := NewRoleRequest("did:sov:V4SGRU86Z58d6TV7PBUe6f", RoleSteward)
+	require.NoError(t, err)
+	m, err := json.Marshal(req.Operation)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"type":"1","dest":"V4SGRU86Z58d6TV7PBUe6f","role":"2"}`, string(m))
+
+	req, err = NewRoleRequest("V4SGRU86Z58d6TV7PBUe6f", "")
+	require.NoError(t, err)
+	m, err = json.Marshal(req.Operation)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"type":"1","dest":"V4SGRU86Z58d6TV7PBUe6f","role":null}`, string(m))
+
+	// The transaction written reads back as removing the role.
+	var b Block
+	require.NoError(t, json.Unmarshal([]byte(`{"txn":{"type":"1","data":`+string(m)+`,"metadata":{}},"txnMetadata":{"seqNo":3}}`), &b))
+	v, err := b.Decode()
+	require.NoError(t, err)
+	require.Equal(t, "", *v.(*NymTxn).Role)
+}
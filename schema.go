@@ -0,0 +1,291 @@
+package indyclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+type getSchemaOp struct {
+	Type protoId       `json:"type,string"`
+	Dest string        `json:"dest"`
+	Data schemaKeyData `json:"data"`
+}
+
+type schemaKeyData struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type getClaimDefOp struct {
+	Type          protoId `json:"type,string"`
+	Origin        string  `json:"origin"`
+	Ref           int     `json:"ref"`
+	SignatureType string  `json:"signature_type"`
+	Tag           string  `json:"tag"`
+}
+
+type schemaOp struct {
+	Type protoId    `json:"type,string"`
+	Data schemaData `json:"data"`
+}
+
+type schemaData struct {
+	Name      string   `json:"name"`
+	Version   string   `json:"version"`
+	AttrNames []string `json:"attr_names"`
+}
+
+type claimDefOp struct {
+	Type          protoId         `json:"type,string"`
+	Ref           int             `json:"ref"`
+	SignatureType string          `json:"signature_type"`
+	Tag           string          `json:"tag"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// Schema is an anoncreds schema, as returned by GET_SCHEMA.
+type Schema struct {
+	Id        string
+	IssuerDid string
+	Name      string
+	Version   string
+	AttrNames []string
+	SeqNo     int
+	TxnTime   int64
+}
+
+// CredentialDefinition is an anoncreds credential definition, as returned by
+// GET_CLAIM_DEF. Value holds the public keys of the issuer, with the
+// primary and the optional revocation key.
+type CredentialDefinition struct {
+	Id            string
+	IssuerDid     string
+	SchemaSeqNo   int
+	SignatureType string
+	Tag           string
+	Value         json.RawMessage
+	SeqNo         int
+	TxnTime       int64
+}
+
+// GetSchema fetches the schema name, version written by issuerDid. It
+// returns ErrNoData if there is no such schema.
+func (p *Pool) GetSchema(ctx context.Context, issuerDid, name, version string, opts ...ReadOption) (*Schema, error) {
+	id, err := didId(issuerDid)
+	if err != nil {
+		return nil, err
+	}
+	r, err := p.read(ctx, getSchemaOp{
+		Type: idGetSchema,
+		Dest: id,
+		Data: schemaKeyData{Name: name, Version: version},
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkReply(r); err != nil {
+		return nil, err
+	}
+	return schemaFromReply(r)
+}
+
+// GetSchemaById fetches the schema with the given id, in either format of
+// ParseSchemaId, or given as the seqNo of its transaction, as in the ids of
+// credential definitions. It returns ErrNoData if there is no such
+// schema.
+func (p *Pool) GetSchemaById(ctx context.Context, id string, opts ...ReadOption) (*Schema, error) {
+	if seqNo, err := strconv.Atoi(id); err == nil {
+		return p.GetSchemaBySeqNo(ctx, seqNo, opts...)
+	}
+	s, err := ParseSchemaId(id)
+	if err != nil {
+		return nil, err
+	}
+	return p.GetSchema(ctx, s.Did, s.Name, s.Version, opts...)
+}
+
+// GetSchemaBySeqNo fetches the schema written by the transaction seqNo of
+// the domain ledger, which credential definitions refer to. It returns
+// ErrNoData if there is no such transaction, and an error if it is not a
+// schema.
+func (p *Pool) GetSchemaBySeqNo(ctx context.Context, seqNo int, opts ...ReadOption) (*Schema, error) {
+	r, err := p.GetTransaction(ctx, DomainLedger, seqNo, opts...)
+	if err != nil {
+		return nil, err
+	}
+	b, _, err := blockFromReply(r)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, ErrNoData
+	}
+	return schemaFromBlock(b)
+}
+
+// schemaFromBlock returns the schema written by the transaction b.
+func schemaFromBlock(b *Block) (*Schema, error) {
+	if b.Txn.Type != idSchema {
+		return nil, fmt.Errorf("transaction %v is not a schema", b.TxnMetadata.SeqNo)
+	}
+	var data schemaData
+	if err := decodeData(b.Txn.Data.Data, &data); err != nil {
+		return nil, err
+	}
+	from, _ := b.Txn.Metadata["from"].(string)
+	return &Schema{
+		Id:        schemaId(from, data.Name, data.Version),
+		IssuerDid: from,
+		Name:      data.Name,
+		Version:   data.Version,
+		AttrNames: data.AttrNames,
+		SeqNo:     b.TxnMetadata.SeqNo,
+		TxnTime:   b.TxnMetadata.TxnTime,
+	}, nil
+}
+
+func schemaFromReply(r *Reply) (*Schema, error) {
+	var res struct {
+		Dest    string
+		Data    json.RawMessage
+		SeqNo   int
+		TxnTime int64
+	}
+	if err := json.Unmarshal(r.Result, &res); err != nil {
+		return nil, err
+	}
+	var data struct {
+		Name      string
+		Version   string
+		AttrNames []string `json:"attr_names"`
+	}
+	if err := decodeData(res.Data, &data); err != nil {
+		return nil, err
+	}
+	// Validators echo the schema name and version back even if the schema
+	// does not exist, without seqNo.
+	if res.SeqNo == 0 {
+		return nil, ErrNoData
+	}
+	return &Schema{
+		Id:        schemaId(res.Dest, data.Name, data.Version),
+		IssuerDid: res.Dest,
+		Name:      data.Name,
+		Version:   data.Version,
+		AttrNames: data.AttrNames,
+		SeqNo:     res.SeqNo,
+		TxnTime:   res.TxnTime,
+	}, nil
+}
+
+// GetCredDef fetches the credential definition with the given id, in either
+// format of ParseCredDefId. It returns ErrNoData if there is no such
+// credential definition.
+func (p *Pool) GetCredDef(ctx context.Context, id string, opts ...ReadOption) (*CredentialDefinition, error) {
+	op, err := parseCredDefId(id)
+	if err != nil {
+		return nil, err
+	}
+	r, err := p.read(ctx, op, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkReply(r); err != nil {
+		return nil, err
+	}
+	return credDefFromReply(r)
+}
+
+// parseCredDefId returns the GET_CLAIM_DEF operation fetching the
+// credential definition id.
+func parseCredDefId(id string) (getClaimDefOp, error) {
+	c, err := ParseCredDefId(id)
+	if err != nil {
+		return getClaimDefOp{}, err
+	}
+	return getClaimDefOp{
+		Type:          idGetClaimDef,
+		Origin:        c.Did,
+		Ref:           c.SchemaSeqNo,
+		SignatureType: c.SignatureType,
+		Tag:           c.Tag,
+	}, nil
+}
+
+func credDefFromReply(r *Reply) (*CredentialDefinition, error) {
+	var res struct {
+		Origin        string
+		Ref           int
+		SignatureType string `json:"signature_type"`
+		Tag           string
+		Data          json.RawMessage
+		SeqNo         int
+		TxnTime       int64
+	}
+	if err := json.Unmarshal(r.Result, &res); err != nil {
+		return nil, err
+	}
+	var value json.RawMessage
+	if err := decodeData(res.Data, &value); err != nil {
+		return nil, err
+	}
+	if res.SeqNo == 0 {
+		return nil, ErrNoData
+	}
+	return &CredentialDefinition{
+		Id:            credDefId(res.Origin, res.SignatureType, res.Ref, res.Tag),
+		IssuerDid:     res.Origin,
+		SchemaSeqNo:   res.Ref,
+		SignatureType: res.SignatureType,
+		Tag:           res.Tag,
+		Value:         value,
+		SeqNo:         res.SeqNo,
+		TxnTime:       res.TxnTime,
+	}, nil
+}
+
+// WriteSchema writes the schema name, version with the given attributes on
+// behalf of signer and returns it once the pool has ordered it. Its id is
+// signer's DID:2:name:version.
+func (p *Pool) WriteSchema(ctx context.Context, signer Signer, name, version string, attrNames []string) (*Schema, error) {
+	b, err := p.write(ctx, NewSchemaRequest(name, version, attrNames).Operation, signer)
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{
+		Id:        schemaId(signer.Did(), name, version),
+		IssuerDid: signer.Did(),
+		Name:      name,
+		Version:   version,
+		AttrNames: attrNames,
+		SeqNo:     b.TxnMetadata.SeqNo,
+		TxnTime:   b.TxnMetadata.TxnTime,
+	}, nil
+}
+
+// WriteCredDef writes a CL credential definition for the schema written at
+// schemaSeqNo, on behalf of signer, and returns it once the pool has
+// ordered it. value holds the issuer's public keys, as generated by an
+// anoncreds library. Its id is signer's DID:3:CL:schemaSeqNo:tag.
+func (p *Pool) WriteCredDef(ctx context.Context, signer Signer, schemaSeqNo int, tag string, value json.RawMessage) (*CredentialDefinition, error) {
+	req, err := NewCredDefRequest(schemaSeqNo, tag, value)
+	if err != nil {
+		return nil, err
+	}
+	b, err := p.write(ctx, req.Operation, signer)
+	if err != nil {
+		return nil, err
+	}
+	return &CredentialDefinition{
+		Id:            credDefId(signer.Did(), "CL", schemaSeqNo, tag),
+		IssuerDid:     signer.Did(),
+		SchemaSeqNo:   schemaSeqNo,
+		SignatureType: "CL",
+		Tag:           tag,
+		Value:         value,
+		SeqNo:         b.TxnMetadata.SeqNo,
+		TxnTime:       b.TxnMetadata.TxnTime,
+	}, nil
+}
@@ -0,0 +1,226 @@
+package indyclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/mr-tron/base58"
+)
+
+// Signer signs requests on behalf of a DID.
+type Signer interface {
+	// Did returns the identifier of the DID, which is sent as the
+	// identifier of the signed requests.
+	Did() string
+	// Sign returns the Ed25519 signature of msg.
+	Sign(msg []byte) ([]byte, error)
+}
+
+type keySigner struct {
+	did string
+	key ed25519.PrivateKey
+}
+
+// NewSigner returns a Signer signing with key on behalf of did, given as a
+// DID or a bare identifier.
+func NewSigner(did string, key ed25519.PrivateKey) (Signer, error) {
+	id, err := didId(did)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, errors.New("invalid Ed25519 private key")
+	}
+	return &keySigner{did: id, key: key}, nil
+}
+
+// SignerFromSeed returns a Signer for the key derived from a 32 byte seed,
+// on behalf of the DID derived from its verkey; see KeypairFromSeed.
+func SignerFromSeed(seed []byte) (Signer, error) {
+	sk, _, did, err := KeypairFromSeed(seed)
+	if err != nil {
+		return nil, err
+	}
+	return &keySigner{did: did, key: sk}, nil
+}
+
+func (s *keySigner) Did() string {
+	return s.did
+}
+
+func (s *keySigner) Sign(msg []byte) ([]byte, error) {
+	return ed25519.Sign(s.key, msg), nil
+}
+
+// Request is a request to be signed and submitted to the ledger.
+type Request struct {
+	// Operation is encoded to JSON as the operation of the request.
+	Operation interface{}
+	// TAAAcceptance is the acceptance of the transaction author agreement
+	// required by the pool for writes, if any.
+	TAAAcceptance *TAAAcceptance
+	// Endorser is the identifier of the DID endorsing the request, if any;
+	// see AddSignature.
+	Endorser string
+	// ProtocolVersion is the protocolVersion of the request. If it is 0,
+	// requests signed with AddSignature use protocol version 2, and the
+	// others that of the Pool submitting them.
+	ProtocolVersion int
+
+	// The author, reqId and signatures of a request signed with
+	// AddSignature.
+	identifier string
+	reqId      seqNo
+	signatures map[string]string
+}
+
+// SubmitSigned signs req with signer and submits it. Signed requests are
+// mostly writes, which the validators only answer once the transaction is
+// ordered. It returns an error if the request is not accepted.
+func (p *Pool) SubmitSigned(ctx context.Context, req Request, signer Signer) (*Reply, error) {
+	return p.submitSigned(ctx, req, signer)
+}
+
+func (p *Pool) submitSigned(ctx context.Context, req Request, signer Signer) (*Reply, error) {
+	reqId, m, err := p.signedRequest(req, signer)
+	if err != nil {
+		return nil, err
+	}
+	r, err := p.submit(ctx, reqId, m, &readConfig{})
+	if err != nil {
+		return nil, err
+	}
+	if err := checkReply(r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// signedRequest wraps req into a request signed by signer and returns its
+// reqId and wire encoding.
+func (p *Pool) signedRequest(req Request, signer Signer) (seqNo, []byte, error) {
+	env := request{
+		Operation:       req.Operation,
+		Identifier:      signer.Did(),
+		ReqId:           p.nextReqId(),
+		ProtocolVersion: req.ProtocolVersion,
+		TAAAcceptance:   req.TAAAcceptance,
+		Endorser:        req.Endorser,
+	}
+	if env.ProtocolVersion == 0 {
+		env.ProtocolVersion = p.protocolVersion()
+	}
+	if err := signRequest(&env, signer); err != nil {
+		return 0, nil, err
+	}
+	m, err := json.Marshal(env)
+	return env.ReqId, m, err
+}
+
+// signRequest sets the signature of req.
+func signRequest(req *request, signer Signer) error {
+	m, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	msg, err := signingInput(m)
+	if err != nil {
+		return err
+	}
+	sig, err := signer.Sign(msg)
+	if err != nil {
+		return err
+	}
+	req.Signature = base58.Encode(sig)
+	return nil
+}
+
+// signingInput returns the serialization of the JSON request m which is
+// signed, as defined by indy-plenum: the keys of objects are sorted and
+// joined as key:value with |, arrays are joined with , and the signatures
+// are left out. The values of ATTRIB raw, hash and enc are replaced by
+// their SHA-256.
+func signingInput(m []byte) ([]byte, error) {
+	d := json.NewDecoder(bytes.NewReader(m))
+	d.UseNumber()
+	var req map[string]interface{}
+	if err := d.Decode(&req); err != nil {
+		return nil, err
+	}
+	var typ string
+	if op, ok := req["operation"].(map[string]interface{}); ok {
+		typ, _ = op["type"].(string)
+	}
+	var b strings.Builder
+	serializeSigning(&b, req, true, typ == "100" || typ == "104")
+	return []byte(b.String()), nil
+}
+
+func serializeSigning(b *strings.Builder, v interface{}, top, attrib bool) {
+	switch v := v.(type) {
+	case nil:
+	case bool:
+		if v {
+			b.WriteString("True")
+		} else {
+			b.WriteString("False")
+		}
+	case json.Number:
+		b.WriteString(v.String())
+	case string:
+		b.WriteString(v)
+	case []interface{}:
+		for i, e := range v {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			serializeSigning(b, e, false, attrib)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			if top && (k == "signature" || k == "signatures" || k == "fees") {
+				continue
+			}
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for i, k := range keys {
+			if i > 0 {
+				b.WriteByte('|')
+			}
+			b.WriteString(k)
+			b.WriteByte(':')
+			e := v[k]
+			if s, ok := e.(string); ok && attrib && (k == "raw" || k == "hash" || k == "enc") {
+				h := sha256.Sum256([]byte(s))
+				e = hex.EncodeToString(h[:])
+			}
+			serializeSigning(b, e, false, attrib)
+		}
+	}
+}
+
+// write signs and submits the write operation op, with the TAA acceptance
+// set by AcceptTAA, and returns the transaction written to the ledger.
+func (p *Pool) write(ctx context.Context, op interface{}, signer Signer) (*Block, error) {
+	p.mu.Lock()
+	taa := p.taaAcceptance
+	p.mu.Unlock()
+	r, err := p.submitSigned(ctx, Request{Operation: op, TAAAcceptance: taa}, signer)
+	if err != nil {
+		return nil, err
+	}
+	b := new(Block)
+	if err := json.Unmarshal(r.Result, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
@@ -0,0 +1,68 @@
+package indyclient
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"testing"
+
+	"github.com/mr-tron/base58"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigningInput(t *testing.T) {
+	for _, tc := range []struct{ req, want string }{
+		{`{"name":"John Doe","age":43,"operation":{"dest":54},"phones":["1234567","2345678",{"rust":5,"age":1},3]}`,
+			"age:43|name:John Doe|operation:dest:54|phones:1234567,2345678,age:1|rust:5,3"},
+		{`{"name":"John Doe","age":43,"operation":{"hash":"cool hash","dest":54},"fees":"fees1","signature":"sign1","signatures":"sign-m"}`,
+			"age:43|name:John Doe|operation:dest:54|hash:cool hash"},
+		{`{"name":"John Doe","age":43,"operation":{"type":"100","hash":"cool hash","dest":54},"fees":"fees1","signature":"sign1","signatures":"sign-m"}`,
+			"age:43|name:John Doe|operation:dest:54|hash:46aa0c92129b33ee72ee1478d2ae62fa6e756869dedc6c858af3214a6fcf1904|type:100"},
+		{`{"operation":{"flag":true,"none":null}}`, "operation:flag:True|none:"},
+	} {
+		got, err := signingInput([]byte(tc.req))
+		require.NoError(t, err)
+		require.Equal(t, tc.want, string(got))
+	}
+}
+
+func TestSignRequest(t *testing.T) {
+	signer, err := SignerFromSeed([]byte("000000000000000000000000Trustee1"))
+	require.NoError(t, err)
+	require.Equal(t, "V4SGRU86Z58d6TV7PBUe6f", signer.Did())
+
+	req := request{
+		Operation:       getNymOp{Type: idGetNym, Dest: "V4SGRU86Z58d6TV7PBUe6f"},
+		Identifier:      signer.Did(),
+		ReqId:           1,
+		ProtocolVersion: 2,
+	}
+	require.NoError(t, signRequest(&req, signer))
+
+	sig, err := base58.Decode(req.Signature)
+	require.NoError(t, err)
+	_, verkey, _, err := KeypairFromSeed([]byte("000000000000000000000000Trustee1"))
+	require.NoError(t, err)
+	vk, err := base58.Decode(verkey)
+	require.NoError(t, err)
+	msg := "identifier:V4SGRU86Z58d6TV7PBUe6f|operation:dest:V4SGRU86Z58d6TV7PBUe6f|type:105|protocolVersion:2|reqId:1"
+	require.True(t, ed25519.Verify(vk, []byte(msg), sig))
+}
+
+func TestPool_SignedRequestProtocolVersion(t *testing.T) {
+	signer, err := SignerFromSeed([]byte("000000000000000000000000Trustee1"))
+	require.NoError(t, err)
+	pool := testPool(t, fakeTransport{}, WithProtocolVersion(1))
+
+	for _, tc := range []struct{ version, want int }{{0, 1}, {2, 2}} {
+		_, m, err := pool.signedRequest(Request{
+			Operation:       getNymOp{Type: idGetNym, Dest: signer.Did()},
+			ProtocolVersion: tc.version,
+		}, signer)
+		require.NoError(t, err)
+		var req struct {
+			ProtocolVersion int `json:"protocolVersion"`
+		}
+		require.NoError(t, json.Unmarshal(m, &req))
+		require.Equal(t, tc.want, req.ProtocolVersion)
+	}
+}
@@ -1,20 +1,26 @@
 package indyclient
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 )
 
-// This unit test unfortunately only works when you are online, and when the BuilderNet is responding.
-// It could be converted to be self-contained by learning how to start up a local indy-node cluster.
+// This test only works when you are online, and when the BuilderNet is responding.
+// The tests of transport_test.go run the same requests against in-memory validators.
+// indyclienttest.StartDocker runs them against a local indy-node pool with
+// go test -tags docker ./indyclienttest.
 
 func Test_SovrinBuilderNet(t *testing.T) {
+	if testing.Short() {
+		t.Skip("needs the BuilderNet")
+	}
 	pool, err := NewPool(SovrinPool("BuilderNet"))
 	require.NoError(t, err)
 	require.Equal(t, len(pool.Validators), 4)
 
-	reply, err := pool.GetTransaction(DomainLedger, 1)
+	reply, err := pool.GetTransaction(context.Background(), DomainLedger, 1)
 	require.NoError(t, err)
 	require.NotNil(t, reply)
 	require.Equal(t, reply.Op, "REPLY")
@@ -0,0 +1,459 @@
+package indyclient
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// sqliteExportWriter writes the transactions of Export as a SQLite 3
+// database file, in the format documented at
+// https://www.sqlite.org/fileformat.html, without depending on a SQLite
+// library. The transactions come in order, so the leaves of the txns table,
+// whose rowid is the seqNo, are written as they fill; the interior pages
+// and the indexes, whose keys are kept in memory, are written at the end,
+// and the first page, which points to all of them, last.
+type sqliteExportWriter struct {
+	w      io.WriteSeeker
+	ledger LedgerId
+
+	start  int64  // offset of the database in w
+	pages  uint32 // number of pages written
+	leaf   *sqlitePage
+	leaves []sqliteChild // full leaves of the table, and their last seqNo
+	last   int64         // last seqNo written
+
+	types, times, dests []sqliteIndexEntry
+}
+
+const (
+	sqlitePageSize = 4096
+
+	// The types of b-tree pages.
+	sqliteIndexInterior = 0x02
+	sqliteTableInterior = 0x05
+	sqliteIndexLeaf     = 0x0a
+	sqliteTableLeaf     = 0x0d
+)
+
+// sqliteSchema is the SQL of the table and its indexes, as recorded in
+// sqlite_master.
+var sqliteSchema = [...]struct{ typ, name, sql string }{
+	{"table", "txns", `CREATE TABLE txns (
+	seqNo INTEGER PRIMARY KEY,
+	ledger INTEGER NOT NULL,
+	type INTEGER NOT NULL,
+	txnTime INTEGER,
+	dest TEXT,
+	txn TEXT NOT NULL
+)`},
+	{"index", "txns_type", "CREATE INDEX txns_type ON txns (type)"},
+	{"index", "txns_time", "CREATE INDEX txns_time ON txns (txnTime)"},
+	{"index", "txns_dest", "CREATE INDEX txns_dest ON txns (dest)"},
+}
+
+func newSQLiteExportWriter(w io.Writer, ledger LedgerId) (*sqliteExportWriter, error) {
+	ws, ok := w.(io.WriteSeeker)
+	if !ok {
+		return nil, errors.New("FormatSQLite needs to write to a file")
+	}
+	return &sqliteExportWriter{w: ws, ledger: ledger, leaf: newSQLitePage(sqliteTableLeaf)}, nil
+}
+
+func (e *sqliteExportWriter) begin() error {
+	var err error
+	if e.start, err = e.w.Seek(0, io.SeekCurrent); err != nil {
+		return err
+	}
+	// The first page is written again at the end.
+	_, err = e.writePage(make([]byte, sqlitePageSize))
+	return err
+}
+
+func (e *sqliteExportWriter) txn(seqNo int, data []byte) error {
+	typ, txnTime, dest, err := exportFields(data)
+	if err != nil {
+		return fmt.Errorf("transaction %v: %v", seqNo, err)
+	}
+	rowid := int64(seqNo)
+	if rowid <= e.last {
+		return fmt.Errorf("transaction %v written after %v", seqNo, e.last)
+	}
+
+	var t, d interface{}
+	if txnTime != 0 {
+		t = txnTime
+	}
+	if dest != "" {
+		d = dest
+	}
+	// seqNo, an alias of the rowid, is stored as NULL.
+	payload := sqliteRecord(nil, nil, int64(e.ledger), int64(typ), t, d, string(data))
+	cell := sqliteVarint(nil, uint64(len(payload)))
+	cell = sqliteVarint(cell, uint64(rowid))
+	if cell, err = e.appendPayload(cell, payload, sqlitePageSize-35); err != nil {
+		return err
+	}
+	if !e.leaf.fits(cell) {
+		if err := e.flushLeaf(); err != nil {
+			return err
+		}
+	}
+	e.leaf.add(cell)
+	e.last = rowid
+
+	e.types = append(e.types, sqliteIndexEntry{int64(typ), rowid})
+	e.times = append(e.times, sqliteIndexEntry{t, rowid})
+	e.dests = append(e.dests, sqliteIndexEntry{d, rowid})
+	return nil
+}
+
+// flushLeaf writes the current leaf of the table and starts a new one.
+func (e *sqliteExportWriter) flushLeaf() error {
+	n, err := e.writePage(e.leaf.bytes())
+	if err != nil {
+		return err
+	}
+	e.leaves = append(e.leaves, sqliteChild{n, sqliteVarint(nil, uint64(e.last))})
+	e.leaf = newSQLitePage(sqliteTableLeaf)
+	return nil
+}
+
+func (e *sqliteExportWriter) end() error {
+	last, err := e.writePage(e.leaf.bytes())
+	if err != nil {
+		return err
+	}
+	var roots [len(sqliteSchema)]uint32
+	if roots[0], err = e.writeInterior(sqliteTableInterior, e.leaves, last); err != nil {
+		return err
+	}
+	for i, entries := range [][]sqliteIndexEntry{e.types, e.times, e.dests} {
+		if roots[i+1], err = e.writeIndex(entries); err != nil {
+			return err
+		}
+	}
+
+	// The first page holds the header of the file and sqlite_master.
+	master := newSQLitePage(sqliteTableLeaf)
+	master.offset = 100
+	for i, s := range sqliteSchema {
+		payload := sqliteRecord(nil, s.typ, s.name, "txns", int64(roots[i]), s.sql)
+		cell := sqliteVarint(nil, uint64(len(payload)))
+		cell = sqliteVarint(cell, uint64(i+1))
+		master.add(append(cell, payload...))
+	}
+	page := master.bytes()
+	copy(page, "SQLite format 3\x00")
+	binary.BigEndian.PutUint16(page[16:], sqlitePageSize)
+	page[18], page[19] = 1, 1                 // rollback journal
+	page[21], page[22], page[23] = 64, 32, 32 // payload fractions
+	binary.BigEndian.PutUint32(page[24:], 1)  // change counter
+	binary.BigEndian.PutUint32(page[28:], e.pages)
+	binary.BigEndian.PutUint32(page[40:], 1) // schema cookie
+	binary.BigEndian.PutUint32(page[44:], 4) // schema format
+	binary.BigEndian.PutUint32(page[56:], 1) // UTF-8
+	binary.BigEndian.PutUint32(page[92:], 1) // valid for change counter 1
+	binary.BigEndian.PutUint32(page[96:], 3031001)
+
+	if _, err := e.w.Seek(e.start, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := e.w.Write(page); err != nil {
+		return err
+	}
+	_, err = e.w.Seek(0, io.SeekEnd)
+	return err
+}
+
+// writePage writes the next page and returns its number.
+func (e *sqliteExportWriter) writePage(page []byte) (uint32, error) {
+	if _, err := e.w.Write(page); err != nil {
+		return 0, err
+	}
+	e.pages++
+	return e.pages, nil
+}
+
+// appendPayload appends payload to cell, spilling what does not fit in a
+// page with at most maxLocal bytes of payload per cell to overflow pages.
+func (e *sqliteExportWriter) appendPayload(cell, payload []byte, maxLocal int) ([]byte, error) {
+	const usable = sqlitePageSize
+	local := len(payload)
+	if local > maxLocal {
+		min := (usable-12)*32/255 - 23
+		local = min + (len(payload)-min)%(usable-4)
+		if local > maxLocal {
+			local = min
+		}
+	}
+	cell = append(cell, payload[:local]...)
+	rest := payload[local:]
+	if len(rest) == 0 {
+		return cell, nil
+	}
+	// Every overflow page points to the next one, which is written right
+	// after it.
+	first := e.pages + 1
+	for len(rest) > 0 {
+		page := make([]byte, sqlitePageSize)
+		n := copy(page[4:], rest)
+		rest = rest[n:]
+		if len(rest) > 0 {
+			binary.BigEndian.PutUint32(page, e.pages+2)
+		}
+		if _, err := e.writePage(page); err != nil {
+			return nil, err
+		}
+	}
+	var ptr [4]byte
+	binary.BigEndian.PutUint32(ptr[:], first)
+	return append(cell, ptr[:]...), nil
+}
+
+// writeIndex writes an index b-tree holding entries, and returns its root
+// page.
+func (e *sqliteExportWriter) writeIndex(entries []sqliteIndexEntry) (uint32, error) {
+	// The entries are in rowid order, which a stable sort keeps for equal
+	// values.
+	sort.SliceStable(entries, func(i, j int) bool {
+		return sqliteLess(entries[i].value, entries[j].value)
+	})
+	maxLocal := (sqlitePageSize-12)*64/255 - 23
+	var items []sqliteChild
+	leaf := newSQLitePage(sqliteIndexLeaf)
+	var cells [][]byte
+	for _, en := range entries {
+		payload := sqliteRecord(nil, en.value, en.rowid)
+		cell, err := e.appendPayload(sqliteVarint(nil, uint64(len(payload))), payload, maxLocal)
+		if err != nil {
+			return 0, err
+		}
+		cells = append(cells, cell)
+	}
+	// Unlike those of tables, the interior cells of indexes hold entries
+	// which are not in the leaves: the first which does not fit in a leaf
+	// goes to the level above, unless it is the last, which would leave the
+	// next leaf empty.
+	for i := 0; i < len(cells); i++ {
+		if leaf.fits(cells[i]) {
+			leaf.add(cells[i])
+			continue
+		}
+		if i == len(cells)-1 {
+			leaf.removeLast()
+			i--
+		}
+		n, err := e.writePage(leaf.bytes())
+		if err != nil {
+			return 0, err
+		}
+		items = append(items, sqliteChild{n, cells[i]})
+		leaf = newSQLitePage(sqliteIndexLeaf)
+	}
+	last, err := e.writePage(leaf.bytes())
+	if err != nil {
+		return 0, err
+	}
+	return e.writeInterior(sqliteIndexInterior, items, last)
+}
+
+// writeInterior writes the interior pages of a b-tree of type typ above the
+// children, each with the key separating it from the next, and the last
+// child. It returns the root page.
+func (e *sqliteExportWriter) writeInterior(typ byte, children []sqliteChild, last uint32) (uint32, error) {
+	for len(children) > 0 {
+		var parents []sqliteChild
+		page := newSQLitePage(typ)
+		for i := 0; i < len(children); i++ {
+			cell := make([]byte, 4, 4+len(children[i].key))
+			binary.BigEndian.PutUint32(cell, children[i].page)
+			cell = append(cell, children[i].key...)
+			if page.fits(cell) {
+				page.add(cell)
+				continue
+			}
+			// The child which does not fit becomes the rightmost of the
+			// page, and its key goes to the level above.
+			if i == len(children)-1 {
+				page.removeLast()
+				i--
+			}
+			page.right = children[i].page
+			n, err := e.writePage(page.bytes())
+			if err != nil {
+				return 0, err
+			}
+			parents = append(parents, sqliteChild{n, children[i].key})
+			page = newSQLitePage(typ)
+		}
+		page.right = last
+		var err error
+		if last, err = e.writePage(page.bytes()); err != nil {
+			return 0, err
+		}
+		children = parents
+	}
+	return last, nil
+}
+
+// sqliteChild is a child page of an interior b-tree page, and the key
+// following it: the last rowid of the child in table b-trees, and an entry
+// in index b-trees.
+type sqliteChild struct {
+	page uint32
+	key  []byte
+}
+
+// sqliteIndexEntry is an entry of an index on one column, whose value is
+// nil, an int64 or a string.
+type sqliteIndexEntry struct {
+	value interface{}
+	rowid int64
+}
+
+// sqliteLess orders the values of a column like SQLite: NULL first, then
+// numbers, then text in byte order.
+func sqliteLess(a, b interface{}) bool {
+	rank := func(v interface{}) int {
+		switch v.(type) {
+		case nil:
+			return 0
+		case int64:
+			return 1
+		}
+		return 2
+	}
+	if ra, rb := rank(a), rank(b); ra != rb {
+		return ra < rb
+	}
+	switch a := a.(type) {
+	case int64:
+		return a < b.(int64)
+	case string:
+		return a < b.(string)
+	}
+	return false
+}
+
+// sqlitePage is a b-tree page being filled with cells.
+type sqlitePage struct {
+	typ    byte
+	offset int // of the b-tree header, after that of the file in the first page
+	cells  [][]byte
+	size   int    // of the cells
+	right  uint32 // rightmost child of interior pages
+}
+
+func newSQLitePage(typ byte) *sqlitePage {
+	return &sqlitePage{typ: typ}
+}
+
+func (p *sqlitePage) headerSize() int {
+	if p.typ == sqliteTableLeaf || p.typ == sqliteIndexLeaf {
+		return 8
+	}
+	return 12
+}
+
+// fits tells whether cell fits in p. Every page holds at least one cell.
+func (p *sqlitePage) fits(cell []byte) bool {
+	return len(p.cells) == 0 || p.offset+p.headerSize()+2*(len(p.cells)+1)+p.size+len(cell) <= sqlitePageSize
+}
+
+func (p *sqlitePage) add(cell []byte) {
+	p.cells = append(p.cells, cell)
+	p.size += len(cell)
+}
+
+func (p *sqlitePage) removeLast() {
+	p.size -= len(p.cells[len(p.cells)-1])
+	p.cells = p.cells[:len(p.cells)-1]
+}
+
+func (p *sqlitePage) bytes() []byte {
+	page := make([]byte, sqlitePageSize)
+	h := page[p.offset:]
+	h[0] = p.typ
+	binary.BigEndian.PutUint16(h[3:], uint16(len(p.cells)))
+	ptrs := h[p.headerSize():]
+	end := sqlitePageSize
+	for i, c := range p.cells {
+		end -= len(c)
+		copy(page[end:], c)
+		binary.BigEndian.PutUint16(ptrs[2*i:], uint16(end))
+	}
+	// A content area starting at 65536 is written as 0, which a page of
+	// 4096 bytes never needs.
+	binary.BigEndian.PutUint16(h[5:], uint16(end))
+	if p.headerSize() == 12 {
+		binary.BigEndian.PutUint32(h[8:], p.right)
+	}
+	return page
+}
+
+// sqliteRecord appends the record of values, each nil, an int64 or a
+// string, to b.
+func sqliteRecord(b []byte, values ...interface{}) []byte {
+	var header, body []byte
+	for _, v := range values {
+		switch v := v.(type) {
+		case nil:
+			header = append(header, 0)
+		case int64:
+			typ, n := sqliteIntType(v)
+			header = append(header, typ)
+			var buf [8]byte
+			binary.BigEndian.PutUint64(buf[:], uint64(v))
+			body = append(body, buf[8-n:]...)
+		case string:
+			header = sqliteVarint(header, uint64(2*len(v)+13))
+			body = append(body, v...)
+		}
+	}
+	// The size of the header includes its own varint.
+	n := len(header) + 1
+	if len(sqliteVarint(nil, uint64(n))) > 1 {
+		n++
+	}
+	b = sqliteVarint(b, uint64(n))
+	b = append(b, header...)
+	return append(b, body...)
+}
+
+// sqliteIntType returns the serial type of the smallest encoding of i, and
+// its size.
+func sqliteIntType(i int64) (byte, int) {
+	switch {
+	case i == 0:
+		return 8, 0
+	case i == 1:
+		return 9, 0
+	case -1<<7 <= i && i < 1<<7:
+		return 1, 1
+	case -1<<15 <= i && i < 1<<15:
+		return 2, 2
+	case -1<<23 <= i && i < 1<<23:
+		return 3, 3
+	case -1<<31 <= i && i < 1<<31:
+		return 4, 4
+	case -1<<47 <= i && i < 1<<47:
+		return 5, 6
+	}
+	return 6, 8
+}
+
+// sqliteVarint appends the SQLite varint encoding of v, big endian in 7 bit
+// groups, to b. v must be less than 2⁵⁶, as sizes and seqNos are.
+func sqliteVarint(b []byte, v uint64) []byte {
+	var buf [8]byte
+	i := len(buf) - 1
+	buf[i] = byte(v & 0x7f)
+	for v >>= 7; v > 0; v >>= 7 {
+		i--
+		buf[i] = byte(v&0x7f) | 0x80
+	}
+	return append(b, buf[i:]...)
+}
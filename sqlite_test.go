@@ -0,0 +1,145 @@
+package indyclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// sqliteUvarint decodes the SQLite varint at the start of b, of less than 9
+// bytes, and returns it with its size.
+func sqliteUvarint(b []byte) (uint64, int) {
+	var v uint64
+	for i, c := range b {
+		v = v<<7 | uint64(c&0x7f)
+		if c&0x80 == 0 {
+			return v, i + 1
+		}
+	}
+	panic("truncated varint")
+}
+
+// sqliteValues decodes a record holding integers and text.
+func sqliteValues(t *testing.T, rec []byte) []interface{} {
+	size, n := sqliteUvarint(rec)
+	header, body := rec[n:size], rec[size:]
+	var values []interface{}
+	for len(header) > 0 {
+		typ, n := sqliteUvarint(header)
+		header = header[n:]
+		switch {
+		case typ == 0:
+			values = append(values, nil)
+		case typ == 8 || typ == 9:
+			values = append(values, int64(typ-8))
+		case typ <= 6:
+			n := []int{0, 1, 2, 3, 4, 6, 8}[typ]
+			var buf [8]byte
+			if body[0]&0x80 != 0 {
+				copy(buf[:], bytes.Repeat([]byte{0xff}, 8))
+			}
+			copy(buf[8-n:], body[:n])
+			values = append(values, int64(binary.BigEndian.Uint64(buf[:])))
+			body = body[n:]
+		case typ >= 13 && typ%2 == 1:
+			n := int(typ-13) / 2
+			values = append(values, string(body[:n]))
+			body = body[n:]
+		default:
+			t.Fatalf("serial type %v", typ)
+		}
+	}
+	return values
+}
+
+// sqliteCells calls fn with the payload, without overflow, of the cells of
+// the leaves of the b-tree rooted at page, in order.
+func sqliteCells(t *testing.T, db []byte, page uint32, fn func(cell []byte)) {
+	p := db[sqlitePageSize*int(page-1) : sqlitePageSize*int(page)]
+	h := p
+	if page == 1 {
+		h = p[100:]
+	}
+	typ := h[0]
+	n := int(binary.BigEndian.Uint16(h[3:]))
+	ptrs := h[8:]
+	if typ == sqliteTableInterior || typ == sqliteIndexInterior {
+		ptrs = h[12:]
+	}
+	for i := 0; i < n; i++ {
+		cell := p[binary.BigEndian.Uint16(ptrs[2*i:]):]
+		switch typ {
+		case sqliteTableInterior:
+			sqliteCells(t, db, binary.BigEndian.Uint32(cell), fn)
+		case sqliteIndexInterior:
+			sqliteCells(t, db, binary.BigEndian.Uint32(cell), fn)
+			fn(cell[4:])
+		default:
+			fn(cell)
+		}
+	}
+	if typ == sqliteTableInterior || typ == sqliteIndexInterior {
+		sqliteCells(t, db, binary.BigEndian.Uint32(h[8:]), fn)
+	}
+}
+
+func TestPool_ExportSQLite(t *testing.T) {
+	ledger := numberedLedger(1000)
+	v := ledgerValidator(ledger)
+	pool := testPool(t, fakeTransport{"Node1": v, "Node2": v, "Node3": v, "Node4": v}, WithConnections(4))
+
+	f, err := ioutil.TempFile("", "indyclient")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	require.NoError(t, pool.Export(context.Background(), f, DomainLedger, WithFormat(FormatSQLite), WithWorkers(4)))
+	require.NoError(t, f.Close())
+
+	db, err := ioutil.ReadFile(f.Name())
+	require.NoError(t, err)
+	require.Equal(t, "SQLite format 3\x00", string(db[:16]))
+	require.Equal(t, len(db)/sqlitePageSize, int(binary.BigEndian.Uint32(db[28:])))
+	require.Zero(t, len(db)%sqlitePageSize)
+
+	roots := make(map[string]uint32)
+	sqliteCells(t, db, 1, func(cell []byte) {
+		_, n := sqliteUvarint(cell)
+		_, m := sqliteUvarint(cell[n:])
+		values := sqliteValues(t, cell[n+m:])
+		roots[values[1].(string)] = uint32(values[3].(int64))
+	})
+	require.Len(t, roots, 4)
+
+	// The rows of the table are in seqNo order.
+	var seqNo uint64
+	sqliteCells(t, db, roots["txns"], func(cell []byte) {
+		size, n := sqliteUvarint(cell)
+		rowid, m := sqliteUvarint(cell[n:])
+		seqNo++
+		require.Equal(t, seqNo, rowid)
+		values := sqliteValues(t, cell[n+m:n+m+int(size)])
+		require.Equal(t, []interface{}{nil, int64(DomainLedger), int64(1), nil, fmt.Sprintf("dest%v", rowid), ledger[rowid-1]}, values)
+	})
+	require.EqualValues(t, 1000, seqNo)
+
+	// Those of the index on dest are sorted.
+	var dests []string
+	sqliteCells(t, db, roots["txns_dest"], func(cell []byte) {
+		_, n := sqliteUvarint(cell)
+		values := sqliteValues(t, cell[n:])
+		dests = append(dests, values[0].(string))
+	})
+	require.Len(t, dests, 1000)
+	for i := 1; i < len(dests); i++ {
+		require.True(t, dests[i-1] < dests[i])
+	}
+
+	// The database cannot be written to a stream.
+	var out bytes.Buffer
+	require.Error(t, pool.Export(context.Background(), &out, DomainLedger, WithFormat(FormatSQLite)))
+}
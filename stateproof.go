@@ -0,0 +1,339 @@
+package indyclient
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mr-tron/base58"
+	"golang.org/x/crypto/sha3"
+)
+
+// ErrNoStateProof is returned by VerifyStateProof for replies without a
+// state proof, either because the request type has none or because the
+// validator did not send it.
+var ErrNoStateProof = errors.New("reply has no state proof")
+
+// ErrInvalidStateProof is returned by VerifyStateProof when the state proof
+// does not prove the reply.
+var ErrInvalidStateProof = errors.New("invalid state proof")
+
+type stateProof struct {
+	RootHash       string          `json:"root_hash"`
+	ProofNodes     string          `json:"proof_nodes"`
+	MultiSignature *multiSignature `json:"multi_signature"`
+}
+
+type multiSignature struct {
+	Signature    string              `json:"signature"`
+	Participants []string            `json:"participants"`
+	Value        multiSignatureValue `json:"value"`
+}
+
+type multiSignatureValue struct {
+	LedgerId          int    `json:"ledger_id"`
+	PoolStateRootHash string `json:"pool_state_root_hash"`
+	StateRootHash     string `json:"state_root_hash"`
+	Timestamp         int64  `json:"timestamp"`
+	TxnRootHash       string `json:"txn_root_hash"`
+}
+
+// VerifyStateProof checks that the state proof of a reply to GET_NYM,
+// GET_ATTRIB, GET_SCHEMA or GET_CLAIM_DEF proves the reply's data, or its
+// absence, under the state root hash signed by the pool's multi-signature.
+// This lets a reply from a single validator be trusted, provided the
+// multi-signature itself is verified too.
+func VerifyStateProof(r *Reply) error {
+	if err := checkReply(r); err != nil {
+		return err
+	}
+	var res struct {
+		Type       protoId     `json:"type"`
+		StateProof *stateProof `json:"state_proof"`
+	}
+	if err := json.Unmarshal(r.Result, &res); err != nil {
+		return err
+	}
+	sp := res.StateProof
+	if sp == nil || sp.ProofNodes == "" {
+		return ErrNoStateProof
+	}
+	if sp.MultiSignature == nil || sp.MultiSignature.Value.StateRootHash != sp.RootHash {
+		return fmt.Errorf("%w: root hash is not multi-signed", ErrInvalidStateProof)
+	}
+
+	key, value, err := stateEntry(res.Type, r)
+	if err != nil {
+		return err
+	}
+	root, err := base58.Decode(sp.RootHash)
+	if err != nil {
+		return fmt.Errorf("%w: root hash: %v", ErrInvalidStateProof, err)
+	}
+	nodes, err := base64.StdEncoding.DecodeString(sp.ProofNodes)
+	if err != nil {
+		return fmt.Errorf("%w: proof nodes: %v", ErrInvalidStateProof, err)
+	}
+	got, err := trieGet(nodes, root, key)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidStateProof, err)
+	}
+	if !bytes.Equal(got, value) {
+		return fmt.Errorf("%w: proven value differs from reply", ErrInvalidStateProof)
+	}
+	return nil
+}
+
+// Markers of the state keys of the different objects in the domain state.
+const (
+	markerAttr     = "\x01"
+	markerSchema   = "\x02"
+	markerClaimDef = "\x03"
+)
+
+// stateEntry returns the key in the domain state of the object requested by
+// r and the value stored for it, as implied by the reply, or nil if the
+// reply says there is no such object.
+func stateEntry(typ protoId, r *Reply) ([]byte, []byte, error) {
+	var res struct {
+		Dest          string
+		Origin        string
+		Ref           int
+		SignatureType string `json:"signature_type"`
+		Tag           string
+		Raw           string
+		Hash          string
+		Enc           string
+		Data          json.RawMessage
+		SeqNo         int
+		TxnTime       int64
+	}
+	if err := json.Unmarshal(r.Result, &res); err != nil {
+		return nil, nil, err
+	}
+	var data json.RawMessage
+	err := decodeData(res.Data, &data)
+	if err != nil && err != ErrNoData {
+		return nil, nil, err
+	}
+	found := err == nil
+
+	switch typ {
+	case idGetNym:
+		key := sha256.Sum256([]byte(res.Dest))
+		if !found {
+			return key[:], nil, nil
+		}
+		var nym struct {
+			Identifier interface{} `json:"identifier"`
+			Role       interface{} `json:"role"`
+			SeqNo      interface{} `json:"seqNo"`
+			TxnTime    interface{} `json:"txnTime"`
+			Verkey     interface{} `json:"verkey"`
+		}
+		if err := unmarshalNumbers(data, &nym); err != nil {
+			return nil, nil, err
+		}
+		value, err := canonicalJSON(nym)
+		return key[:], value, err
+
+	case idGetAttr:
+		name, hashed := res.Raw, false
+		switch {
+		case res.Hash != "":
+			name, hashed = res.Hash, true
+		case res.Enc != "":
+			name = res.Enc
+		}
+		if !hashed {
+			name = sha256Hex(name)
+		}
+		key := []byte(res.Dest + ":" + markerAttr + ":" + name)
+		if !found {
+			return key, nil, nil
+		}
+		// The state holds the hash of raw and encrypted values.
+		var s string
+		if err := decodeString(res.Data, &s); err != nil {
+			return nil, nil, err
+		}
+		if !hashed {
+			s = sha256Hex(s)
+		}
+		value, err := stateValue(s, res.SeqNo, res.TxnTime)
+		return key, value, err
+
+	case idGetSchema:
+		var schema struct {
+			Name      string      `json:"name"`
+			Version   string      `json:"version"`
+			AttrNames interface{} `json:"attr_names"`
+		}
+		if err := unmarshalNumbers(res.Data, &schema); err != nil && err != ErrNoData {
+			return nil, nil, err
+		}
+		key := []byte(res.Dest + ":" + markerSchema + ":" + schema.Name + ":" + schema.Version)
+		if res.SeqNo == 0 {
+			return key, nil, nil
+		}
+		value, err := stateValue(map[string]interface{}{"attr_names": schema.AttrNames}, res.SeqNo, res.TxnTime)
+		return key, value, err
+
+	case idGetClaimDef:
+		key := []byte(fmt.Sprintf("%v:%v:%v:%v:%v", res.Origin, markerClaimDef, res.SignatureType, res.Ref, res.Tag))
+		if !found {
+			return key, nil, nil
+		}
+		var v interface{}
+		if err := unmarshalNumbers(data, &v); err != nil {
+			return nil, nil, err
+		}
+		value, err := stateValue(v, res.SeqNo, res.TxnTime)
+		return key, value, err
+	}
+	return nil, nil, ErrNoStateProof
+}
+
+// stateValue returns the encoding of the value val written at seqNo and
+// txnTime in the domain state.
+func stateValue(val interface{}, seqNo int, txnTime int64) ([]byte, error) {
+	return canonicalJSON(map[string]interface{}{
+		"lsn": seqNo,
+		"lut": txnTime,
+		"val": val,
+	})
+}
+
+// unmarshalNumbers decodes the JSON or JSON string data into v, keeping
+// numbers as json.Number so that they encode back unchanged.
+func unmarshalNumbers(data json.RawMessage, v interface{}) error {
+	var raw json.RawMessage
+	if err := decodeData(data, &raw); err != nil {
+		return err
+	}
+	d := json.NewDecoder(bytes.NewReader(raw))
+	d.UseNumber()
+	return d.Decode(v)
+}
+
+// canonicalJSON encodes v the way the validators serialize the state: with
+// sorted keys and without spaces.
+func canonicalJSON(v interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	e := json.NewEncoder(&b)
+	e.SetEscapeHTML(false)
+	if err := e.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n")), nil
+}
+
+func sha256Hex(s string) string {
+	h := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(h[:])
+}
+
+// trieGet looks key up in the Merkle Patricia trie with the given root
+// hash, whose nodes needed for the lookup are in proof, the RLP encoded
+// list of the nodes. It returns the value stored for the key, or nil if the
+// trie proves there is none.
+func trieGet(proof, root, key []byte) ([]byte, error) {
+	list, err := rlpDecode(proof)
+	if err != nil || !list.isList {
+		return nil, errors.New("malformed proof nodes")
+	}
+	nodes := make(map[[32]byte]rlpItem, len(list.list))
+	for _, n := range list.list {
+		nodes[sha3.Sum256(n.raw)] = n
+	}
+
+	path := make([]byte, 0, 2*len(key))
+	for _, b := range key {
+		path = append(path, b>>4, b&0xf)
+	}
+
+	ref := rlpItem{str: root}
+	for {
+		node, ok, err := resolveNode(nodes, ref)
+		if err != nil || !ok {
+			return nil, err
+		}
+		switch len(node.list) {
+		case 17:
+			if len(path) == 0 {
+				return leafValue(node.list[16].str)
+			}
+			ref, path = node.list[path[0]], path[1:]
+		case 2:
+			prefix, leaf := hexPrefixDecode(node.list[0].str)
+			if leaf {
+				if !bytes.Equal(prefix, path) {
+					return nil, nil
+				}
+				return leafValue(node.list[1].str)
+			}
+			if len(path) < len(prefix) || !bytes.Equal(prefix, path[:len(prefix)]) {
+				return nil, nil
+			}
+			ref, path = node.list[1], path[len(prefix):]
+		default:
+			return nil, errors.New("malformed trie node")
+		}
+	}
+}
+
+// resolveNode returns the node referenced by ref, which is either an
+// embedded node or the hash of a node in nodes. ok is false for an empty
+// reference.
+func resolveNode(nodes map[[32]byte]rlpItem, ref rlpItem) (node rlpItem, ok bool, err error) {
+	if ref.isList {
+		return ref, true, nil
+	}
+	if len(ref.str) == 0 {
+		return rlpItem{}, false, nil
+	}
+	if len(ref.str) != 32 {
+		return rlpItem{}, false, errors.New("malformed node reference")
+	}
+	var h [32]byte
+	copy(h[:], ref.str)
+	node, ok = nodes[h]
+	if !ok || !node.isList {
+		return rlpItem{}, false, errors.New("proof is missing a node")
+	}
+	return node, true, nil
+}
+
+// leafValue decodes a value stored in the trie, which is the RLP encoding
+// of a list holding the actual value.
+func leafValue(b []byte) ([]byte, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+	it, err := rlpDecode(b)
+	if err != nil || !it.isList || len(it.list) == 0 || it.list[0].isList {
+		return nil, errors.New("malformed trie value")
+	}
+	return it.list[0].str, nil
+}
+
+// hexPrefixDecode decodes the hex prefix encoded path of a leaf or extension
+// node into nibbles, and reports whether the node is a leaf.
+func hexPrefixDecode(b []byte) ([]byte, bool) {
+	if len(b) == 0 {
+		return nil, false
+	}
+	flags := b[0] >> 4
+	var nibbles []byte
+	if flags&1 != 0 {
+		nibbles = append(nibbles, b[0]&0xf)
+	}
+	for _, c := range b[1:] {
+		nibbles = append(nibbles, c>>4, c&0xf)
+	}
+	return nibbles, flags&2 != 0
+}
@@ -0,0 +1,79 @@
+//go:build docker
+// +build docker
+
+package indyclient_test
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/indyclient"
+	"go.dedis.ch/indyclient/indyclienttest"
+)
+
+var update = flag.Bool("update", false, "write the replies of TestCaptureStateProofs to testdata/stateproof")
+
+// TestCaptureStateProofs writes a NYM, an ATTRIB, a SCHEMA and a CLAIM_DEF
+// to a pool running in Docker and checks the state proofs of the replies to
+// reading them back. With -update, it stores the replies for
+// TestVerifyStateProof_Captured.
+func TestCaptureStateProofs(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	d, err := indyclienttest.StartDocker(ctx)
+	require.NoError(t, err)
+	defer d.Close()
+	pool := d.Pool
+
+	trustee, err := indyclient.SignerFromSeed([]byte("000000000000000000000000Trustee1"))
+	require.NoError(t, err)
+	// A new DID, so that the objects are new on every run.
+	seed := make([]byte, 32)
+	_, err = rand.Read(seed)
+	require.NoError(t, err)
+	_, verkey, did, err := indyclient.KeypairFromSeed(seed)
+	require.NoError(t, err)
+	signer, err := indyclient.SignerFromSeed(seed)
+	require.NoError(t, err)
+
+	_, err = pool.WriteNym(ctx, trustee, did, verkey, indyclient.RoleEndorser, "")
+	require.NoError(t, err)
+	_, err = pool.WriteAttrib(ctx, signer, did, "endpoint", map[string]string{"ha": "127.0.0.1:5555"})
+	require.NoError(t, err)
+	schema, err := pool.WriteSchema(ctx, signer, "degree", "1.0", []string{"name", "age"})
+	require.NoError(t, err)
+	_, err = pool.WriteCredDef(ctx, signer, schema.SeqNo, "default", json.RawMessage(
+		`{"primary":{"n":"1","s":"2","r":{"master_secret":"3","name":"4","age":"5"},"rctxt":"6","z":"7"}}`))
+	require.NoError(t, err)
+
+	for name, op := range map[string]interface{}{
+		"get_nym":    map[string]interface{}{"type": "105", "dest": did},
+		"get_attrib": map[string]interface{}{"type": "104", "dest": did, "raw": "endpoint"},
+		"get_schema": map[string]interface{}{"type": "107", "dest": did, "data": map[string]string{"name": "degree", "version": "1.0"}},
+		"get_claim_def": map[string]interface{}{"type": "108", "origin": did, "ref": schema.SeqNo,
+			"signature_type": "CL", "tag": "default"},
+	} {
+		r, err := pool.Submit(ctx, &indyclient.Request{Operation: op})
+		require.NoError(t, err, name)
+		require.NoError(t, indyclient.VerifyStateProof(r), name)
+
+		if *update {
+			m, err := json.Marshal(struct {
+				Op     string          `json:"op"`
+				Result json.RawMessage `json:"result"`
+			}{r.Op, r.Result})
+			require.NoError(t, err)
+			dir := filepath.Join("testdata", "stateproof")
+			require.NoError(t, os.MkdirAll(dir, 0755))
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name+".json"), append(m, '\n'), 0644))
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package indyclient
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mr-tron/base58"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+)
+
+func rlpString(b []byte) []byte {
+	if len(b) == 1 && b[0] < 0x80 {
+		return b
+	}
+	return append(rlpHeader(0x80, len(b)), b...)
+}
+
+func rlpList(items ...[]byte) []byte {
+	var payload []byte
+	for _, it := range items {
+		payload = append(payload, it...)
+	}
+	return append(rlpHeader(0xc0, len(payload)), payload...)
+}
+
+func rlpHeader(base byte, n int) []byte {
+	if n <= 55 {
+		return []byte{base + byte(n)}
+	}
+	var l []byte
+	for ; n > 0; n >>= 8 {
+		l = append([]byte{byte(n)}, l...)
+	}
+	return append([]byte{base + 55 + byte(len(l))}, l...)
+}
+
+// leafNode returns a leaf node for the nibbles path, holding value.
+func leafNode(path []byte, value []byte) []byte {
+	hp := []byte{0x20}
+	if len(path)%2 == 1 {
+		hp = []byte{0x30 | path[0]}
+		path = path[1:]
+	}
+	for i := 0; i < len(path); i += 2 {
+		hp = append(hp, path[i]<<4|path[i+1])
+	}
+	return rlpList(rlpString(hp), rlpString(rlpList(rlpString(value))))
+}
+
+func nibbles(key []byte) []byte {
+	var n []byte
+	for _, b := range key {
+		n = append(n, b>>4, b&0xf)
+	}
+	return n
+}
+
+// testTrie returns the root hash and proof of a trie holding the two
+// entries, whose keys must differ in their first nibble.
+func testTrie(k1, v1, k2, v2 []byte) ([]byte, []byte) {
+	n1, n2 := nibbles(k1), nibbles(k2)
+	l1, l2 := leafNode(n1[1:], v1), leafNode(n2[1:], v2)
+	h1, h2 := sha3.Sum256(l1), sha3.Sum256(l2)
+	branch := make([][]byte, 17)
+	for i := range branch {
+		branch[i] = rlpString(nil)
+	}
+	branch[n1[0]] = rlpString(h1[:])
+	branch[n2[0]] = rlpString(h2[:])
+	root := rlpList(branch...)
+	h := sha3.Sum256(root)
+	return h[:], rlpList(root, l1, l2)
+}
+
+func nymReply(dest, data, root string, proof []byte) *Reply {
+	return &Reply{Op: "REPLY", Result: []byte(fmt.Sprintf(
+		`{"type":"105","dest":"%v","data":%v,"seqNo":12,"txnTime":1500000000,"state_proof":{"root_hash":"%v","proof_nodes":"%v","multi_signature":{"value":{"state_root_hash":"%v","timestamp":1500000100}}}}`,
+		dest, data, root, base64.StdEncoding.EncodeToString(proof), root))}
+}
+
+func TestVerifyStateProof(t *testing.T) {
+	dest := "V4SGRU86Z58d6TV7PBUe6f"
+	value := `{"identifier":"V4SGRU86Z58d6TV7PBUe6f","role":"0","seqNo":12,"txnTime":1500000000,"verkey":"~CoRER63DVYnWZtK8uAzNbx"}`
+	key := sha256.Sum256([]byte(dest))
+	// A key of another DID, differing in the first nibble.
+	other := key
+	other[0] ^= 0xf0
+	rootHash, proof := testTrie(key[:], []byte(value), other[:], []byte(`{}`))
+	root := base58.Encode(rootHash)
+
+	data := `"{\"dest\":\"V4SGRU86Z58d6TV7PBUe6f\",\"identifier\":\"V4SGRU86Z58d6TV7PBUe6f\",\"role\":\"0\",\"seqNo\":12,\"txnTime\":1500000000,\"verkey\":\"~CoRER63DVYnWZtK8uAzNbx\"}"`
+	require.NoError(t, VerifyStateProof(nymReply(dest, data, root, proof)))
+
+	tampered := `"{\"dest\":\"V4SGRU86Z58d6TV7PBUe6f\",\"identifier\":\"V4SGRU86Z58d6TV7PBUe6f\",\"role\":\"0\",\"seqNo\":12,\"txnTime\":1500000000,\"verkey\":\"~AAAAAAAAAAAAAAAAAAAAAA\"}"`
+	err := VerifyStateProof(nymReply(dest, tampered, root, proof))
+	require.True(t, errors.Is(err, ErrInvalidStateProof), "%v", err)
+
+	// Claiming the DID does not exist is caught too.
+	err = VerifyStateProof(nymReply(dest, "null", root, proof))
+	require.True(t, errors.Is(err, ErrInvalidStateProof), "%v", err)
+
+	// A proof of absence for a DID whose key starts with a nibble which
+	// has no branch in the trie.
+	require.NoError(t, VerifyStateProof(nymReply("Th7MpTaRZVRYnPiabds81Y", "null", root, proof)))
+
+	// Proofs with missing nodes are rejected.
+	err = VerifyStateProof(nymReply(dest, data, root, rlpList()))
+	require.True(t, errors.Is(err, ErrInvalidStateProof), "%v", err)
+
+	r := &Reply{Op: "REPLY", Result: []byte(`{"type":"105","dest":"V4SGRU86Z58d6TV7PBUe6f","data":null}`)}
+	require.Equal(t, ErrNoStateProof, VerifyStateProof(r))
+}
+
+// TestVerifyStateProof_Captured checks the state proofs of the replies of
+// indy-node in testdata/stateproof, which TestCaptureStateProofs captures:
+//
+//	go test -tags docker -run TestCaptureStateProofs -update
+//
+// The replies are committed, so that the proofs are checked against the
+// encoding of the validators on every run, and missing ones are an error.
+func TestVerifyStateProof_Captured(t *testing.T) {
+	for _, name := range []string{"get_nym", "get_attrib", "get_schema", "get_claim_def"} {
+		f := filepath.Join("testdata", "stateproof", name+".json")
+		m, err := ioutil.ReadFile(f)
+		require.NoError(t, err, "capture it with TestCaptureStateProofs")
+		r, err := parseReply([]string{string(m)})
+		require.NoError(t, err, f)
+		require.NoError(t, VerifyStateProof(r), f)
+
+		r.Result = tamperData(t, r.Result)
+		err = VerifyStateProof(r)
+		require.Error(t, err, f)
+	}
+}
+
+// tamperData changes one byte of the data of result: its last letter or
+// digit, which is part of a value in every reply.
+func tamperData(t *testing.T, result json.RawMessage) json.RawMessage {
+	var fields map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(result, &fields))
+	data := append(json.RawMessage(nil), fields["data"]...)
+	i := bytes.LastIndexFunc(data, func(r rune) bool {
+		return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r))
+	})
+	require.True(t, i >= 0, "no data")
+	if data[i] == '9' || data[i] == 'z' || data[i] == 'Z' {
+		data[i]--
+	} else {
+		data[i]++
+	}
+	fields["data"] = data
+	m, err := json.Marshal(fields)
+	require.NoError(t, err)
+	return m
+}
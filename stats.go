@@ -0,0 +1,66 @@
+package indyclient
+
+import "time"
+
+// ValidatorStats are statistics of the requests a Pool sent to a validator.
+type ValidatorStats struct {
+	Alias     string
+	Connected bool          // whether the Pool has a connection open to it
+	Requests  int           // requests answered
+	Errors    int           // failed requests and connection attempts
+	Latency   time.Duration // moving average of the time to answer
+	LastError error
+}
+
+// latencyWeight is the weight of the latest request in the moving average
+// of the latency.
+const latencyWeight = 0.2
+
+// record updates the statistics of the validator alias with a request
+// answered after latency, or failed with err.
+func (p *Pool) record(alias string, latency time.Duration, err error) {
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+	if p.stats == nil {
+		p.stats = make(map[string]*ValidatorStats)
+	}
+	st := p.stats[alias]
+	if st == nil {
+		st = &ValidatorStats{Alias: alias}
+		p.stats[alias] = st
+	}
+	if err != nil {
+		st.Errors++
+		st.LastError = err
+		return
+	}
+	if st.Requests == 0 {
+		st.Latency = latency
+	} else {
+		st.Latency += time.Duration(latencyWeight * float64(latency-st.Latency))
+	}
+	st.Requests++
+}
+
+// Stats returns the statistics of the validators of the Pool, in the order
+// of Validators.
+func (p *Pool) Stats() []ValidatorStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	connected := make(map[string]bool)
+	for _, c := range p.conns {
+		connected[c.alias] = true
+	}
+
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+	stats := make([]ValidatorStats, len(p.Validators))
+	for i, v := range p.Validators {
+		if st := p.stats[v.Alias]; st != nil {
+			stats[i] = *st
+		}
+		stats[i].Alias = v.Alias
+		stats[i].Connected = connected[v.Alias]
+	}
+	return stats
+}
@@ -0,0 +1,27 @@
+package indyclient
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool_Stats(t *testing.T) {
+	p := &Pool{Validators: []Validator{{Alias: "Node1"}, {Alias: "Node2"}}}
+	p.conns = []*conn{newConn(nil, "Node2")}
+
+	p.record("Node2", 100*time.Millisecond, nil)
+	p.record("Node2", 200*time.Millisecond, nil)
+	failure := errors.New("connection refused")
+	p.record("Node1", 0, failure)
+
+	stats := p.Stats()
+	require.Len(t, stats, 2)
+	require.Equal(t, ValidatorStats{Alias: "Node1", Errors: 1, LastError: failure}, stats[0])
+	require.Equal(t, "Node2", stats[1].Alias)
+	require.True(t, stats[1].Connected)
+	require.Equal(t, 2, stats[1].Requests)
+	require.Equal(t, 120*time.Millisecond, stats[1].Latency)
+}
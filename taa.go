@@ -0,0 +1,145 @@
+package indyclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+type getTAAOp struct {
+	Type protoId `json:"type,string"`
+}
+
+type taaOp struct {
+	Type           protoId `json:"type,string"`
+	Text           string  `json:"text"`
+	Version        string  `json:"version"`
+	RatificationTs int64   `json:"ratification_ts,omitempty"`
+}
+
+type taaAMLOp struct {
+	Type    protoId           `json:"type,string"`
+	Version string            `json:"version"`
+	AML     map[string]string `json:"aml"`
+}
+
+// TAA is a transaction author agreement, which authors of transactions must
+// accept before writing to pools requiring it, such as the Sovrin MainNet.
+type TAA struct {
+	Text           string `json:"text"`
+	Version        string `json:"version"`
+	Digest         string `json:"digest"`
+	RatificationTs int64  `json:"ratification_ts"`
+}
+
+// AML is the acceptance mechanisms list of a pool: the ways in which a TAA
+// may be accepted, by label, with their descriptions.
+type AML struct {
+	AML        map[string]string `json:"aml"`
+	Version    string            `json:"version"`
+	AMLContext string            `json:"amlContext"`
+}
+
+// TAAAcceptance records the acceptance of a TAA, sent with write requests.
+type TAAAcceptance struct {
+	Mechanism string `json:"mechanism"`
+	TAADigest string `json:"taaDigest"`
+	Time      int64  `json:"time"`
+}
+
+// TAADigest returns the digest identifying the TAA of the given version and
+// text: the hex encoded SHA-256 of their concatenation.
+func TAADigest(version, text string) string {
+	h := sha256.Sum256([]byte(version + text))
+	return hex.EncodeToString(h[:])
+}
+
+// Accept returns the acceptance of t with mechanism, one of the labels of
+// the pool's AML, at time at. The time is truncated to the day, as
+// required by the validators.
+func (t *TAA) Accept(mechanism string, at time.Time) *TAAAcceptance {
+	digest := t.Digest
+	if digest == "" {
+		digest = TAADigest(t.Version, t.Text)
+	}
+	const day = 24 * 60 * 60
+	ts := at.Unix()
+	return &TAAAcceptance{
+		Mechanism: mechanism,
+		TAADigest: digest,
+		Time:      ts - ts%day,
+	}
+}
+
+// AcceptTAA makes all subsequent writes of p carry the acceptance a, or no
+// acceptance if a is nil.
+func (p *Pool) AcceptTAA(a *TAAAcceptance) {
+	p.mu.Lock()
+	p.taaAcceptance = a
+	p.mu.Unlock()
+}
+
+// GetTransactionAuthorAgreement fetches the TAA currently in force. It
+// returns ErrNoData if the pool has none.
+func (p *Pool) GetTransactionAuthorAgreement(ctx context.Context, opts ...ReadOption) (*TAA, error) {
+	r, err := p.read(ctx, getTAAOp{Type: idGetTAA}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkReply(r); err != nil {
+		return nil, err
+	}
+	taa := new(TAA)
+	if err := r.DecodeResult(taa); err != nil {
+		return nil, err
+	}
+	// Agreements are disabled by setting an empty text.
+	if taa.Text == "" {
+		return nil, ErrNoData
+	}
+	return taa, nil
+}
+
+// GetAcceptanceMechanisms fetches the AML currently in force. It returns
+// ErrNoData if the pool has none.
+func (p *Pool) GetAcceptanceMechanisms(ctx context.Context, opts ...ReadOption) (*AML, error) {
+	r, err := p.read(ctx, getTAAOp{Type: idGetTAAAML}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkReply(r); err != nil {
+		return nil, err
+	}
+	aml := new(AML)
+	if err := r.DecodeResult(aml); err != nil {
+		return nil, err
+	}
+	return aml, nil
+}
+
+// SetTxnAuthorAgreement writes a TXN_AUTHOR_AGREEMENT transaction, which
+// only trustees may do, making text the TAA of the given version, ratified
+// at ratified, in seconds since the epoch. An empty text disables the TAA.
+// If signer is not a trustee, or not enough trustees signed, the error
+// matches ErrRejected and carries the reason given by the pool.
+func (p *Pool) SetTxnAuthorAgreement(ctx context.Context, signer Signer, text, version string, ratified int64) (*Block, error) {
+	return p.write(ctx, taaOp{
+		Type:           idTAA,
+		Text:           text,
+		Version:        version,
+		RatificationTs: ratified,
+	}, signer)
+}
+
+// SetAcceptanceMechanisms writes a TXN_AUTHOR_AGREEMENT_AML transaction,
+// which only trustees may do, setting the AML of the given version. As with
+// SetTxnAuthorAgreement, a refusal for lack of permission matches
+// ErrRejected.
+func (p *Pool) SetAcceptanceMechanisms(ctx context.Context, signer Signer, aml map[string]string, version string) (*Block, error) {
+	return p.write(ctx, taaAMLOp{
+		Type:    idTAAAML,
+		Version: version,
+		AML:     aml,
+	}, signer)
+}
@@ -0,0 +1,49 @@
+package indyclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTAA_Accept(t *testing.T) {
+	taa := &TAA{Text: "some agreement text", Version: "1.0"}
+	a := taa.Accept("click_agreement", time.Unix(1577836800+3600, 0))
+	require.Equal(t, &TAAAcceptance{
+		Mechanism: "click_agreement",
+		TAADigest: "f1bf82dc3049c45aaf8ac313cc7328835e1f63c4a6c5b6744d67ab0262ddf033",
+		Time:      1577836800,
+	}, a)
+}
+
+func TestPool_SetTxnAuthorAgreement(t *testing.T) {
+	var sent []string
+	reject := func(m []byte) [][]byte {
+		var req struct {
+			ReqId     seqNo           `json:"reqId"`
+			Operation json.RawMessage `json:"operation"`
+		}
+		json.Unmarshal(m, &req)
+		sent = append(sent, string(req.Operation))
+		return [][]byte{[]byte(fmt.Sprintf(`{"op":"REJECT","reqId":%v,"identifier":"V4SGRU86Z58d6TV7PBUe6f","reason":"client request invalid: UnauthorizedClientRequest('Not enough TRUSTEE signatures',)"}`, req.ReqId))}
+	}
+	pool := testPool(t, fakeTransport{"Node1": reject})
+	signer, err := SignerFromSeed([]byte("000000000000000000000000Steward1"))
+	require.NoError(t, err)
+
+	_, err = pool.SetTxnAuthorAgreement(context.Background(), signer, "some agreement text", "1.0", 1577836800)
+	require.True(t, errors.Is(err, ErrRejected), "%v", err)
+	require.Contains(t, err.Error(), "Not enough TRUSTEE signatures")
+
+	_, err = pool.SetAcceptanceMechanisms(context.Background(), signer, map[string]string{"click_agreement": "Click to accept"}, "1.0")
+	require.True(t, errors.Is(err, ErrRejected), "%v", err)
+
+	require.Len(t, sent, 2)
+	require.JSONEq(t, `{"type":"4","text":"some agreement text","version":"1.0","ratification_ts":1577836800}`, sent[0])
+	require.JSONEq(t, `{"type":"5","version":"1.0","aml":{"click_agreement":"Click to accept"}}`, sent[1])
+}
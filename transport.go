@@ -0,0 +1,32 @@
+package indyclient
+
+import (
+	"context"
+	"time"
+)
+
+// A Transport connects a Pool to validators. The default is ZMQTransport
+// when the package is built with cgo, and ZMTPTransport otherwise.
+type Transport interface {
+	// Dial connects to the validator v and completes the CURVE handshake
+	// with it, giving up when ctx is done.
+	Dial(ctx context.Context, v Validator) (Connection, error)
+}
+
+// A Connection carries the messages exchanged with a validator. Its methods
+// are never called concurrently.
+type Connection interface {
+	// Send sends the message m to the validator.
+	Send(m []byte) error
+	// Receive waits up to timeout for a message from the validator. It
+	// returns a nil message if none arrived in time.
+	Receive(timeout time.Duration) ([]byte, error)
+	Close() error
+}
+
+// WithTransport makes the Pool connect to validators with t.
+func WithTransport(t Transport) Option {
+	return func(p *Pool) {
+		p.transport = t
+	}
+}
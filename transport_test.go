@@ -0,0 +1,255 @@
+package indyclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// A fakeValidator answers the message m with the messages it returns.
+type fakeValidator func(m []byte) [][]byte
+
+// fakeTransport is a Transport to in-memory validators, keyed by alias.
+// Validators missing from the map refuse connections.
+type fakeTransport map[string]fakeValidator
+
+func (t fakeTransport) Dial(ctx context.Context, v Validator) (Connection, error) {
+	handle, ok := t[v.Alias]
+	if !ok {
+		return nil, errors.New("connection refused")
+	}
+	return &fakeConn{handle: handle, in: make(chan []byte, 16)}, nil
+}
+
+type fakeConn struct {
+	handle fakeValidator
+	in     chan []byte
+}
+
+func (c *fakeConn) Send(m []byte) error {
+	for _, out := range c.handle(m) {
+		c.in <- out
+	}
+	return nil
+}
+
+func (c *fakeConn) Receive(timeout time.Duration) ([]byte, error) {
+	select {
+	case m := <-c.in:
+		return m, nil
+	case <-time.After(timeout):
+		return nil, nil
+	}
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+// ledgerValidator is a fakeValidator answering GET_TXN requests for the
+// transactions in ledger, indexed by seqNo - 1.
+func ledgerValidator(ledger []string) fakeValidator {
+	return func(m []byte) [][]byte {
+		var req struct {
+			ReqId     seqNo    `json:"reqId"`
+			Operation getTxnOp `json:"operation"`
+		}
+		if err := json.Unmarshal(m, &req); err != nil {
+			return nil
+		}
+		data := "null"
+		if seqNo := req.Operation.Data; seqNo >= 1 && seqNo <= len(ledger) {
+			data = ledger[seqNo-1]
+		}
+		return [][]byte{
+			[]byte(fmt.Sprintf(`{"op":"REQACK","reqId":%v}`, req.ReqId)),
+			[]byte(fmt.Sprintf(`{"op":"REPLY","result":{"type":"3","reqId":%v,"seqNo":%v,"data":%v}}`,
+				req.ReqId, req.Operation.Data, data)),
+		}
+	}
+}
+
+var testLedger = []string{
+	`{"txn":{"type":"1","data":{"dest":"V4SGRU86Z58d6TV7PBUe6f"}},"txnMetadata":{"seqNo":1}}`,
+	`{"txn":{"type":"1","data":{"dest":"Th7MpTaRZVRYnPiabds81Y"}},"txnMetadata":{"seqNo":2}}`,
+}
+
+func testPool(t *testing.T, transport fakeTransport, opts ...Option) *Pool {
+	g := testGenesis(t, "10.0.0.1:9702", "10.0.0.2:9702", "10.0.0.3:9702", "10.0.0.4:9702")
+	pool, err := NewPoolFromBytes(g, append([]Option{WithTransport(transport)}, opts...)...)
+	require.NoError(t, err)
+	return pool
+}
+
+func TestPool_FakeTransport(t *testing.T) {
+	v := ledgerValidator(testLedger)
+	pool := testPool(t, fakeTransport{"Node1": v, "Node2": v, "Node3": v, "Node4": v})
+
+	reply, err := pool.GetTransaction(context.Background(), DomainLedger, 2)
+	require.NoError(t, err)
+	require.Equal(t, "REPLY", reply.Op)
+	require.Equal(t, "Node1", reply.Node)
+	b, _, err := blockFromReply(reply)
+	require.NoError(t, err)
+	require.Equal(t, "Th7MpTaRZVRYnPiabds81Y", b.Txn.Data.Dest)
+}
+
+func TestPool_ReplyTimeout(t *testing.T) {
+	silent := func(m []byte) [][]byte { return nil }
+	pool := testPool(t, fakeTransport{
+		"Node1": silent,
+		"Node2": ledgerValidator(testLedger),
+	}, WithReplyTimeout(50*time.Millisecond))
+
+	reply, err := pool.GetTransaction(context.Background(), DomainLedger, 1)
+	require.NoError(t, err)
+	require.Equal(t, "Node2", reply.Node)
+
+	stats := pool.Stats()
+	require.Equal(t, 1, stats[0].Errors)
+	require.True(t, errors.Is(stats[0].LastError, ErrReplyTimeout))
+	require.Equal(t, 1, stats[1].Requests)
+}
+
+func TestPool_Concurrent(t *testing.T) {
+	// The validator holds back its replies until it got two requests, and
+	// then answers them in reverse order.
+	var mu sync.Mutex
+	var held [][][]byte
+	v := ledgerValidator(testLedger)
+	reversing := func(m []byte) [][]byte {
+		mu.Lock()
+		defer mu.Unlock()
+		held = append(held, v(m))
+		if len(held) < 2 {
+			return nil
+		}
+		out := append(held[1], held[0]...)
+		held = nil
+		return out
+	}
+	pool := testPool(t, fakeTransport{"Node1": reversing})
+
+	var wg sync.WaitGroup
+	dests := make([]string, 2)
+	errs := make([]error, 2)
+	for i := range dests {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			reply, err := pool.GetTransaction(context.Background(), DomainLedger, i+1)
+			if err == nil {
+				var b *Block
+				b, _, err = blockFromReply(reply)
+				if err == nil {
+					dests[i] = b.Txn.Data.Dest
+				}
+			}
+			errs[i] = err
+		}(i)
+	}
+	wg.Wait()
+	require.NoError(t, errs[0])
+	require.NoError(t, errs[1])
+	require.Equal(t, []string{"V4SGRU86Z58d6TV7PBUe6f", "Th7MpTaRZVRYnPiabds81Y"}, dests)
+}
+
+// hangingTransport is a fakeTransport whose connections to hang do not
+// complete until release is closed, signalling dialing when they start.
+type hangingTransport struct {
+	fakeTransport
+	hang             string
+	dialing, release chan struct{}
+}
+
+func (t *hangingTransport) Dial(ctx context.Context, v Validator) (Connection, error) {
+	if v.Alias == t.hang {
+		t.dialing <- struct{}{}
+		<-t.release
+		return nil, errors.New("connection refused")
+	}
+	return t.fakeTransport.Dial(ctx, v)
+}
+
+func TestPool_HangingDial(t *testing.T) {
+	v := ledgerValidator(testLedger)
+	transport := &hangingTransport{
+		fakeTransport: fakeTransport{"Node2": v, "Node3": v, "Node4": v},
+		hang:          "Node1",
+		dialing:       make(chan struct{}, 1),
+		release:       make(chan struct{}),
+	}
+	g := testGenesis(t, "10.0.0.1:9702", "10.0.0.2:9702", "10.0.0.3:9702", "10.0.0.4:9702")
+	pool, err := NewPoolFromBytes(g, WithTransport(transport))
+	require.NoError(t, err)
+
+	hung := make(chan error, 1)
+	go func() {
+		_, err := pool.GetTransaction(context.Background(), DomainLedger, 1)
+		hung <- err
+	}()
+	<-transport.dialing
+
+	// Other requests are served while Node1 is being dialed.
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		reply, err := pool.GetTransaction(ctx, DomainLedger, 2)
+		cancel()
+		require.NoError(t, err)
+		require.NotEqual(t, "Node1", reply.Node)
+	}
+
+	close(transport.release)
+	require.NoError(t, <-hung)
+}
+
+type countingMetrics struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (m *countingMetrics) count(event string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.counts == nil {
+		m.counts = make(map[string]int)
+	}
+	m.counts[event]++
+}
+
+func (m *countingMetrics) Connected(node string)             { m.count("connected " + node) }
+func (m *countingMetrics) DialFailed(node string, err error) { m.count("dial failed " + node) }
+func (m *countingMetrics) RequestSent(node string)           { m.count("sent " + node) }
+func (m *countingMetrics) ReplyReceived(node, op string, latency time.Duration) {
+	m.count(op + " " + node)
+}
+func (m *countingMetrics) RequestFailed(node string, err error) {
+	if errors.Is(err, ErrReplyTimeout) {
+		m.count("timeout " + node)
+	}
+}
+
+func TestPool_Metrics(t *testing.T) {
+	m := new(countingMetrics)
+	silent := func(m []byte) [][]byte { return nil }
+	pool := testPool(t, fakeTransport{
+		"Node2": silent,
+		"Node3": ledgerValidator(testLedger),
+	}, WithReplyTimeout(50*time.Millisecond), WithMetrics(m))
+
+	_, err := pool.GetTransaction(context.Background(), DomainLedger, 1)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{
+		"dial failed Node1": 1,
+		"connected Node2":   1,
+		"sent Node2":        1,
+		"timeout Node2":     1,
+		"connected Node3":   1,
+		"sent Node3":        1,
+		"REPLY Node3":       1,
+	}, m.counts)
+}
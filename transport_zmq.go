@@ -0,0 +1,134 @@
+//go:build cgo
+// +build cgo
+
+package indyclient
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mr-tron/base58"
+	"github.com/pebbe/zmq4"
+)
+
+var defaultTransport Transport = ZMQTransport{}
+
+// ZMQTransport is the Transport using libzmq through cgo, with a DEALER
+// socket per validator.
+type ZMQTransport struct{}
+
+// Dial implements Transport. The CURVE handshake is only awaited if ctx
+// has a deadline; otherwise libzmq completes it in the background.
+func (ZMQTransport) Dial(ctx context.Context, validator Validator) (Connection, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var wait time.Duration
+	if d, ok := ctx.Deadline(); ok {
+		wait = time.Until(d)
+		if wait <= 0 {
+			return nil, context.DeadlineExceeded
+		}
+	}
+
+	s, err := zmq4.NewSocket(zmq4.DEALER)
+	if err != nil {
+		return nil, err
+	}
+
+	pub, sec, err := zmq4.NewCurveKeypair()
+	if err != nil {
+		return nil, err
+	}
+	s.SetIdentity(base64.StdEncoding.EncodeToString([]byte(pub)))
+	err = s.SetCurvePublickey(pub)
+	if err != nil {
+		return nil, err
+	}
+	err = s.SetCurveSecretkey(sec)
+	if err != nil {
+		return nil, err
+	}
+
+	vk, err := base58.Decode(validator.VerKey)
+	if err != nil {
+		return nil, err
+	}
+	srv := ed25519PublicKeyToCurve25519(ed25519.PublicKey(vk))
+	err = s.SetCurveServerkey(zmq4.Z85encode(string(srv)))
+	if err != nil {
+		return nil, err
+	}
+
+	if wait > 0 {
+		// With ZMQ_IMMEDIATE set, the socket only becomes writable once
+		// the CURVE handshake with the validator has completed.
+		err = s.SetImmediate(true)
+		if err != nil {
+			return nil, err
+		}
+		err = s.SetConnectTimeout(wait)
+		if err != nil {
+			return nil, err
+		}
+		err = s.SetHandshakeIvl(wait)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.Connect("tcp://" + validator.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	if wait > 0 {
+		poller := zmq4.NewPoller()
+		poller.Add(s, zmq4.POLLOUT)
+		polled, err := poller.Poll(wait)
+		if err != nil || len(polled) == 0 {
+			s.SetLinger(0)
+			s.Close()
+			if err == nil {
+				err = fmt.Errorf("connection to %v timed out after %v", validator.Alias, wait)
+			}
+			return nil, err
+		}
+	}
+	return zmqConn{s}, nil
+}
+
+type zmqConn struct {
+	s *zmq4.Socket
+}
+
+func (c zmqConn) Send(m []byte) error {
+	_, err := c.s.SendMessageDontwait(m)
+	return err
+}
+
+func (c zmqConn) Receive(timeout time.Duration) ([]byte, error) {
+	poller := zmq4.NewPoller()
+	poller.Add(c.s, zmq4.POLLIN)
+	polled, err := poller.Poll(timeout)
+	if err != nil || len(polled) == 0 {
+		return nil, err
+	}
+	in, err := c.s.RecvMessageBytes(0)
+	if err != nil {
+		return nil, err
+	}
+	if len(in) != 1 {
+		return nil, errors.New("got wrong amount of input")
+	}
+	return in[0], nil
+}
+
+func (c zmqConn) Close() error {
+	c.s.SetLinger(0)
+	return c.s.Close()
+}
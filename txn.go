@@ -0,0 +1,198 @@
+package indyclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// NymTxn is a NYM transaction, which creates a DID or changes its verkey or
+// role. Role is nil if the transaction leaves the role unchanged, and points
+// to "" if it removes it.
+type NymTxn struct {
+	Dest   string  `json:"dest"`
+	Verkey string  `json:"verkey,omitempty"`
+	Role   *string `json:"role,omitempty"`
+	Alias  string  `json:"alias,omitempty"`
+}
+
+// AttribTxn is an ATTRIB transaction, which sets an attribute of a DID.
+// Exactly one of Raw, Hash and Enc is set: Raw holds a JSON object mapping
+// the name of the attribute to its value.
+type AttribTxn struct {
+	Dest string `json:"dest"`
+	Raw  string `json:"raw,omitempty"`
+	Hash string `json:"hash,omitempty"`
+	Enc  string `json:"enc,omitempty"`
+}
+
+// SchemaTxn is a SCHEMA transaction, which defines an anoncreds schema.
+type SchemaTxn struct {
+	Name      string   `json:"name"`
+	Version   string   `json:"version"`
+	AttrNames []string `json:"attr_names"`
+}
+
+// ClaimDefTxn is a CLAIM_DEF transaction, which defines an anoncreds
+// credential definition for the schema of seqNo Ref. Value holds the public
+// keys of the issuer.
+type ClaimDefTxn struct {
+	Ref           int             `json:"ref"`
+	SignatureType string          `json:"signature_type"`
+	Tag           string          `json:"tag"`
+	Value         json.RawMessage `json:"data"`
+}
+
+// NodeTxn is a NODE transaction, which adds a node to the pool or changes
+// it. Dest is the verkey of the node. Fields missing from the transaction
+// are left unchanged.
+type NodeTxn struct {
+	Dest string  `json:"dest"`
+	Node TxnNode `json:"data"`
+}
+
+// RevocRegDefTxn is a REVOC_REG_DEF transaction, which defines a revocation
+// registry.
+type RevocRegDefTxn struct {
+	Id           string          `json:"id"`
+	RevocDefType string          `json:"revocDefType"`
+	Tag          string          `json:"tag"`
+	CredDefId    string          `json:"credDefId"`
+	Value        json.RawMessage `json:"value"`
+}
+
+// RevocRegEntryTxn is a REVOC_REG_ENTRY transaction, which updates a
+// revocation registry.
+type RevocRegEntryTxn struct {
+	RevocRegDefId string        `json:"revocRegDefId"`
+	RevocDefType  string        `json:"revocDefType"`
+	Value         RevocRegEntry `json:"value"`
+}
+
+// TAATxn is a TXN_AUTHOR_AGREEMENT transaction, which sets a transaction
+// author agreement or retires it.
+type TAATxn struct {
+	Text           string `json:"text,omitempty"`
+	Version        string `json:"version"`
+	RatificationTs int64  `json:"ratification_ts,omitempty"`
+	RetirementTs   int64  `json:"retirement_ts,omitempty"`
+}
+
+// TAADisableTxn is a TXN_AUTHOR_AGREEMENT_DISABLE transaction, which
+// retires all transaction author agreements: writes no longer need an
+// acceptance until a new agreement is set.
+type TAADisableTxn struct{}
+
+// AuthRulesTxn is an AUTH_RULES transaction, which changes several rules of
+// the authorization map at once.
+type AuthRulesTxn struct {
+	Rules []AuthRule `json:"rules"`
+}
+
+// ErrUnknownTxnType is returned when decoding a transaction of a type this
+// package has no model for.
+var ErrUnknownTxnType = errors.New("unknown transaction type")
+
+// DecodeTxn decodes a ledger transaction, as found in genesis files, ledger
+// exports and the data of GET_TXN replies, and returns its typed data like
+// Block.Decode.
+func DecodeTxn(raw json.RawMessage) (interface{}, error) {
+	var b Block
+	if err := json.Unmarshal(raw, &b); err != nil {
+		return nil, err
+	}
+	return b.Decode()
+}
+
+// Decode returns the data of the transaction of b according to its type.
+// The concrete types returned are:
+//
+//	NODE                          *NodeTxn
+//	NYM                           *NymTxn
+//	ATTRIB                        *AttribTxn
+//	SCHEMA                        *SchemaTxn
+//	CLAIM_DEF                     *ClaimDefTxn
+//	REVOC_REG_DEF                 *RevocRegDefTxn
+//	REVOC_REG_ENTRY               *RevocRegEntryTxn
+//	TXN_AUTHOR_AGREEMENT          *TAATxn
+//	TXN_AUTHOR_AGREEMENT_AML      *AML
+//	TXN_AUTHOR_AGREEMENT_DISABLE  *TAADisableTxn
+//	AUTH_RULE                     *AuthRule
+//	AUTH_RULES                    *AuthRulesTxn
+//	POOL_UPGRADE                  *PoolUpgrade
+//	NODE_UPGRADE                  *NodeUpgrade
+//	POOL_CONFIG                   *PoolConfig
+//	LEDGERS_FREEZE                *LedgersFreezeTxn
+//	JSON_LD_CONTEXT, RICH_SCHEMA* *RichSchemaTxn
+//
+// Transactions of the types registered with RegisterTxnType are decoded by
+// their TxnDecoder. Other types return an error matching ErrUnknownTxnType.
+func (b *Block) Decode() (interface{}, error) {
+	var v interface{}
+	data := b.Txn.Data.Raw
+	switch b.Txn.Type {
+	case idNode:
+		v = new(NodeTxn)
+	case idNym:
+		n, err := decodeNymTxn(data)
+		if err != nil {
+			return nil, err
+		}
+		return n, nil
+	case idAttrib:
+		v = new(AttribTxn)
+	case idSchema:
+		// SCHEMA nests its fields in data.
+		v = new(SchemaTxn)
+		data = b.Txn.Data.Data
+	case idClaimDef:
+		v = new(ClaimDefTxn)
+	case idRevocRegDef:
+		v = new(RevocRegDefTxn)
+	case idRevocRegEntry:
+		v = new(RevocRegEntryTxn)
+	case idTAA:
+		v = new(TAATxn)
+	case idTAAAML:
+		v = new(AML)
+	case idTAADisable:
+		return new(TAADisableTxn), nil
+	case idAuthRule:
+		v = new(AuthRule)
+	case idAuthRules:
+		v = new(AuthRulesTxn)
+	case idPoolUpgrade, idNodeUpgrade, idPoolConfig:
+		return DecodeUpgrade(b)
+	case idLedgersFreeze:
+		v = new(LedgersFreezeTxn)
+	case idJSONLDContext, idRichSchema, idRichSchemaEncoding, idRichSchemaMapping,
+		idRichSchemaCredDef, idRichSchemaPresDef:
+		v = new(RichSchemaTxn)
+	default:
+		if decode, ok := pluginDecoder(b.Txn.Type); ok {
+			return decode(b)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrUnknownTxnType, b.Txn.Type)
+	}
+	if err := decodeData(data, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// decodeNymTxn decodes the data of a NYM transaction, telling a role set to
+// null, which removes it, from a missing role.
+func decodeNymTxn(data json.RawMessage) (*NymTxn, error) {
+	var n NymTxn
+	if err := decodeData(data, &n); err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := decodeData(data, &fields); err != nil {
+		return nil, err
+	}
+	if r, ok := fields["role"]; ok && string(r) == "null" {
+		n.Role = new(string)
+	}
+	return &n, nil
+}
@@ -0,0 +1,41 @@
+package indyclient
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeTxn(t *testing.T) {
+	v, err := DecodeTxn([]byte(`{"txn":{"type":"1","data":{"dest":"Th7MpTaRZVRYnPiabds81Y","verkey":"~7TYfekw4GUagBnBVCqPjiC","role":null},"metadata":{}},"txnMetadata":{"seqNo":9}}`))
+	require.NoError(t, err)
+	nym := v.(*NymTxn)
+	require.Equal(t, "Th7MpTaRZVRYnPiabds81Y", nym.Dest)
+	require.Equal(t, "", *nym.Role)
+
+	v, err = DecodeTxn([]byte(`{"txn":{"type":"1","data":{"dest":"Th7MpTaRZVRYnPiabds81Y","role":"101"}}}`))
+	require.NoError(t, err)
+	require.Equal(t, "101", *v.(*NymTxn).Role)
+
+	v, err = DecodeTxn([]byte(`{"txn":{"type":"101","data":{"data":{"attr_names":["age","name"],"name":"degree","version":"1.0"}}}}`))
+	require.NoError(t, err)
+	require.Equal(t, &SchemaTxn{Name: "degree", Version: "1.0", AttrNames: []string{"age", "name"}}, v)
+
+	v, err = DecodeTxn([]byte(`{"txn":{"type":"102","data":{"data":{"primary":{"n":"1"}},"ref":12,"signature_type":"CL","tag":"tag1"}}}`))
+	require.NoError(t, err)
+	cd := v.(*ClaimDefTxn)
+	require.Equal(t, 12, cd.Ref)
+	require.JSONEq(t, `{"primary":{"n":"1"}}`, string(cd.Value))
+
+	v, err = DecodeTxn([]byte(`{"txn":{"type":"0","data":{"data":{"alias":"Node1","client_ip":"10.0.0.1","client_port":9702,"services":["VALIDATOR"]},"dest":"Gw6pDLhcBcoQesN72qfotTgFa7cbuqZpkX3Xo6pLhPhv"}}}`))
+	require.NoError(t, err)
+	require.Equal(t, "Node1", v.(*NodeTxn).Node.Alias)
+
+	v, err = DecodeTxn([]byte(`{"txn":{"type":"114","data":{"revocDefType":"CL_ACCUM","revocRegDefId":"id","value":{"accum":"2","prevAccum":"1","revoked":[3]}}}}`))
+	require.NoError(t, err)
+	require.Equal(t, []int{3}, v.(*RevocRegEntryTxn).Value.Revoked)
+
+	_, err = DecodeTxn([]byte(`{"txn":{"type":"20000","data":{}}}`))
+	require.True(t, errors.Is(err, ErrUnknownTxnType))
+}
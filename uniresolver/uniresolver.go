@@ -0,0 +1,189 @@
+// Package uniresolver serves an indyclient.Resolver over the driver API of
+// the DIF Universal Resolver, so that it can be deployed as its did:sov
+// and did:indy driver:
+//
+//	r := indyclient.NewResolver(pool)
+//	http.ListenAndServe(":8080", uniresolver.NewHandler(r))
+//
+// GET /1.0/identifiers/{did} returns the DID resolution result of did, or
+// the DID Document alone if the request accepts application/did+ld+json.
+// DID URLs with a versionId, a versionTime or a fragment are dereferenced.
+package uniresolver
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.dedis.ch/indyclient"
+)
+
+// Prefix is the path under which the driver API is served.
+const Prefix = "/1.0/identifiers/"
+
+const (
+	contentTypeResult = `application/ld+json;profile="https://w3id.org/did-resolution"`
+	contentTypeDIDLD  = "application/did+ld+json"
+	contentTypeJSON   = "application/json"
+)
+
+// NewHandler returns a Handler serving the DID resolutions of r.
+func NewHandler(r *indyclient.Resolver) http.Handler {
+	return &handler{r: r}
+}
+
+type handler struct {
+	r *indyclient.Resolver
+}
+
+// resolutionResult is a DID resolution or dereferencing result.
+type resolutionResult struct {
+	Context               string                 `json:"@context"`
+	DIDDocument           interface{}            `json:"didDocument,omitempty"`
+	DIDResolutionMetadata map[string]interface{} `json:"didResolutionMetadata,omitempty"`
+	DIDDocumentMetadata   map[string]interface{} `json:"didDocumentMetadata,omitempty"`
+	ContentStream         interface{}            `json:"contentStream,omitempty"`
+	DereferencingMetadata map[string]interface{} `json:"dereferencingMetadata,omitempty"`
+	ContentMetadata       map[string]interface{} `json:"contentMetadata,omitempty"`
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	path := req.URL.EscapedPath()
+	if !strings.HasPrefix(path, Prefix) {
+		http.NotFound(w, req)
+		return
+	}
+	did, err := url.PathUnescape(strings.TrimPrefix(path, Prefix))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalidDid", err)
+		return
+	}
+	// The query of a DID URL which was not escaped ends up in the query of
+	// the request.
+	if req.URL.RawQuery != "" {
+		did += "?" + req.URL.RawQuery
+	}
+
+	wantDoc := false
+	switch accept(req.Header.Get("Accept")) {
+	case contentTypeDIDLD, contentTypeJSON:
+		wantDoc = true
+	case contentTypeResult, "":
+	default:
+		writeError(w, http.StatusNotAcceptable, "representationNotSupported", nil)
+		return
+	}
+
+	u, err := indyclient.ParseDidURL(did)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalidDid", err)
+		return
+	}
+	if u.Path != "" {
+		writeError(w, http.StatusBadRequest, "invalidDidUrl", errors.New("DID URL paths are not supported"))
+		return
+	}
+	res, meta, err := h.r.Dereference(req.Context(), did)
+	switch {
+	case errors.Is(err, indyclient.ErrDIDNotFound), errors.Is(err, indyclient.ErrDIDURLNotFound):
+		writeError(w, http.StatusNotFound, "notFound", err)
+		return
+	case errors.Is(err, indyclient.ErrUnknownNamespace):
+		writeError(w, http.StatusNotImplemented, "methodNotSupported", err)
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, "internalError", err)
+		return
+	}
+
+	docMeta := documentMetadata(meta)
+	status := http.StatusOK
+	if meta.Deactivated {
+		status = http.StatusGone
+	}
+	if wantDoc {
+		w.Header().Set("Content-Type", contentTypeDIDLD)
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(res)
+		return
+	}
+	result := resolutionResult{Context: "https://w3id.org/did-resolution/v1"}
+	if u.Fragment == "" {
+		result.DIDDocument = res
+		result.DIDResolutionMetadata = map[string]interface{}{"contentType": contentTypeDIDLD}
+		result.DIDDocumentMetadata = docMeta
+	} else {
+		result.ContentStream = res
+		result.DereferencingMetadata = map[string]interface{}{"contentType": contentTypeDIDLD}
+		result.ContentMetadata = docMeta
+	}
+	w.Header().Set("Content-Type", contentTypeResult)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
+
+// accept returns the media type of the Accept header a, keeping only the
+// first one listed.
+func accept(a string) string {
+	if a == "" {
+		return ""
+	}
+	first := strings.TrimSpace(strings.Split(a, ",")[0])
+	t, params, err := mime.ParseMediaType(first)
+	if err != nil {
+		return first
+	}
+	switch t {
+	case "*/*", "application/*":
+		return ""
+	case "application/ld+json":
+		if params["profile"] == "https://w3id.org/did-resolution" {
+			return contentTypeResult
+		}
+	}
+	return t
+}
+
+// documentMetadata returns the DID document metadata of the DID Resolution
+// specification for meta: versionId is the seqNo of the NYM.
+func documentMetadata(meta *indyclient.DocumentMetadata) map[string]interface{} {
+	m := make(map[string]interface{})
+	if meta.NymSeqNo != 0 {
+		m["versionId"] = strconv.Itoa(meta.NymSeqNo)
+	}
+	if meta.AttribSeqNo != 0 {
+		m["attribSeqNo"] = meta.AttribSeqNo
+	}
+	if meta.TxnTime != 0 {
+		m["updated"] = time.Unix(meta.TxnTime, 0).UTC().Format(time.RFC3339)
+	}
+	if meta.Deactivated {
+		m["deactivated"] = true
+	}
+	return m
+}
+
+// writeError writes a resolution result whose metadata holds the error
+// code of the DID Resolution specification.
+func writeError(w http.ResponseWriter, status int, code string, err error) {
+	meta := map[string]interface{}{"error": code}
+	if err != nil {
+		meta["errorMessage"] = err.Error()
+	}
+	w.Header().Set("Content-Type", contentTypeResult)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resolutionResult{
+		Context:               "https://w3id.org/did-resolution/v1",
+		DIDResolutionMetadata: meta,
+	})
+}
@@ -0,0 +1,65 @@
+package uniresolver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/indyclient"
+	"go.dedis.ch/indyclient/indyclienttest"
+)
+
+func TestHandler(t *testing.T) {
+	_, verkey, did, err := indyclient.KeypairFromSeed([]byte("00000000000000000000000000000001"))
+	require.NoError(t, err)
+	n := indyclienttest.NewNetwork(4)
+	require.NoError(t, n.LoadTxns(indyclient.DomainLedger, strings.NewReader(fmt.Sprintf(
+		`{"txn":{"type":"1","data":{"dest":"%v","verkey":"%v"}},"txnMetadata":{"seqNo":1,"txnTime":1500000000}}`, did, verkey))))
+	pool, err := n.Pool()
+	require.NoError(t, err)
+	srv := httptest.NewServer(NewHandler(indyclient.NewResolver(pool)))
+	defer srv.Close()
+
+	get := func(path, accept string) (*http.Response, map[string]interface{}) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		require.NoError(t, err)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return resp, body
+	}
+
+	resp, body := get(Prefix+"did:sov:"+did, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "did:sov:"+did, body["didDocument"].(map[string]interface{})["id"])
+	require.Equal(t, map[string]interface{}{"versionId": "1", "updated": "2017-07-14T02:40:00Z"}, body["didDocumentMetadata"])
+
+	resp, body = get(Prefix+"did:sov:"+did, "application/did+ld+json")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "application/did+ld+json", resp.Header.Get("Content-Type"))
+	require.Equal(t, "did:sov:"+did, body["id"])
+
+	resp, body = get(Prefix+"did:sov:"+did+"%23key-1", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, verkey, body["contentStream"].(map[string]interface{})["publicKeyBase58"])
+
+	resp, body = get(Prefix+"did:sov:V4SGRU86Z58d6TV7PBUe6f", "")
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	require.Equal(t, "notFound", body["didResolutionMetadata"].(map[string]interface{})["error"])
+
+	resp, _ = get(Prefix+"did:web:example.com", "")
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp, _ = get(Prefix+"did:indy:other:"+did, "")
+	require.Equal(t, http.StatusNotImplemented, resp.StatusCode)
+	resp, _ = get(Prefix+"did:sov:"+did, "text/html")
+	require.Equal(t, http.StatusNotAcceptable, resp.StatusCode)
+}
@@ -0,0 +1,96 @@
+package indyclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// PoolUpgrade is a POOL_UPGRADE transaction, which schedules the upgrade of
+// the validators to a new version of indy-node, or cancels it. Schedule
+// maps the verkey of each validator to the time of its upgrade, in the
+// ISO 8601 format; Timeout is in minutes.
+type PoolUpgrade struct {
+	Name          string            `json:"name"`
+	Version       string            `json:"version"`
+	Action        string            `json:"action"` // start or cancel
+	Sha256        string            `json:"sha256"`
+	Schedule      map[string]string `json:"schedule,omitempty"`
+	Timeout       int               `json:"timeout,omitempty"`
+	Justification string            `json:"justification,omitempty"`
+	Reinstall     bool              `json:"reinstall"`
+	Force         bool              `json:"force"`
+	Package       string            `json:"package,omitempty"`
+}
+
+// PoolConfig is a POOL_CONFIG transaction, which enables or disables writes
+// to the pool.
+type PoolConfig struct {
+	Writes bool `json:"writes"`
+	Force  bool `json:"force"`
+}
+
+type poolUpgradeOp struct {
+	Type protoId `json:"type,string"`
+	PoolUpgrade
+}
+
+type poolConfigOp struct {
+	Type protoId `json:"type,string"`
+	PoolConfig
+}
+
+// WritePoolUpgrade writes a POOL_UPGRADE transaction, which only trustees
+// may do.
+func (p *Pool) WritePoolUpgrade(ctx context.Context, signer Signer, u PoolUpgrade) (*Block, error) {
+	switch u.Action {
+	case "start":
+		if len(u.Schedule) == 0 {
+			return nil, errors.New("upgrade has no schedule")
+		}
+	case "cancel":
+	default:
+		return nil, fmt.Errorf("invalid upgrade action %q", u.Action)
+	}
+	if u.Name == "" || u.Version == "" || u.Sha256 == "" {
+		return nil, errors.New("upgrade needs a name, version and sha256")
+	}
+	return p.write(ctx, poolUpgradeOp{Type: idPoolUpgrade, PoolUpgrade: u}, signer)
+}
+
+// WritePoolConfig writes a POOL_CONFIG transaction, which only trustees may
+// do. With Force, the validators apply it without waiting for consensus.
+func (p *Pool) WritePoolConfig(ctx context.Context, signer Signer, c PoolConfig) (*Block, error) {
+	return p.write(ctx, poolConfigOp{Type: idPoolConfig, PoolConfig: c}, signer)
+}
+
+// NodeUpgrade is a NODE_UPGRADE transaction, which validators write to the
+// config ledger as they carry out a POOL_UPGRADE.
+type NodeUpgrade struct {
+	Action  string `json:"action"` // in_progress, complete or fail
+	Version string `json:"version"`
+}
+
+// DecodeUpgrade decodes the config ledger transaction b, which must be a
+// POOL_UPGRADE, NODE_UPGRADE or POOL_CONFIG, into a *PoolUpgrade,
+// *NodeUpgrade or *PoolConfig respectively.
+func DecodeUpgrade(b *Block) (interface{}, error) {
+	var v interface{}
+	data := b.Txn.Data.Raw
+	switch b.Txn.Type {
+	case idPoolUpgrade:
+		v = new(PoolUpgrade)
+	case idNodeUpgrade:
+		// NODE_UPGRADE nests its fields in data.
+		v = new(NodeUpgrade)
+		data = b.Txn.Data.Data
+	case idPoolConfig:
+		v = new(PoolConfig)
+	default:
+		return nil, fmt.Errorf("transaction of type %v is not an upgrade", b.Txn.Type)
+	}
+	if err := decodeData(data, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
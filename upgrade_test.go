@@ -0,0 +1,30 @@
+package indyclient
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeUpgrade(t *testing.T) {
+	var b Block
+	require.NoError(t, json.Unmarshal([]byte(`{"txn":{"type":"109","data":{"name":"upgrade-1.12","version":"1.12.0","action":"start","sha256":"ab","schedule":{"Gw6pDLhcBcoQesN72qfotTgFa7cbuqZpkX3Xo6pLhPhv":"2020-01-25T12:49:05.258870+00:00"},"timeout":10,"justification":null,"reinstall":false,"force":false,"package":"indy-node"},"metadata":{}},"txnMetadata":{"seqNo":3}}`), &b))
+	v, err := DecodeUpgrade(&b)
+	require.NoError(t, err)
+	u := v.(*PoolUpgrade)
+	require.Equal(t, "1.12.0", u.Version)
+	require.Equal(t, 10, u.Timeout)
+	require.Len(t, u.Schedule, 1)
+
+	b = Block{}
+	require.NoError(t, json.Unmarshal([]byte(`{"txn":{"type":"110","data":{"data":{"action":"complete","version":"1.12.0"}},"metadata":{}},"txnMetadata":{"seqNo":4}}`), &b))
+	v, err = DecodeUpgrade(&b)
+	require.NoError(t, err)
+	require.Equal(t, &NodeUpgrade{Action: "complete", Version: "1.12.0"}, v)
+
+	b = Block{}
+	require.NoError(t, json.Unmarshal([]byte(`{"txn":{"type":"1","data":{"dest":"V4SGRU86Z58d6TV7PBUe6f"}}}`), &b))
+	_, err = DecodeUpgrade(&b)
+	require.Error(t, err)
+}
@@ -0,0 +1,158 @@
+package indyclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+type validatorInfoOp struct {
+	Type protoId `json:"type,string"`
+}
+
+// ValidatorReport is the report of a validator about itself, as returned by
+// VALIDATOR_INFO. Only the most used parts of the report are decoded; Raw
+// holds all of it.
+type ValidatorReport struct {
+	Alias     string
+	Timestamp int64           // time of the report, in seconds since the epoch
+	Node      NodeInfo        `json:"Node_info"`
+	Pool      PoolInfo        `json:"Pool_info"`
+	Software  SoftwareInfo    `json:"Software"`
+	Raw       json.RawMessage `json:"-"`
+}
+
+// NodeInfo is the state of the validator.
+type NodeInfo struct {
+	Name    string
+	Mode    string // participating, syncing, discovering...
+	Metrics struct {
+		Uptime int64 `json:"uptime"` // in seconds
+		// TransactionCount is the size of each ledger, by name: pool,
+		// ledger (the domain ledger), config and audit.
+		TransactionCount map[string]int `json:"transaction-count"`
+		AveragePerSecond struct {
+			ReadTransactions  float64 `json:"read-transactions"`
+			WriteTransactions float64 `json:"write-transactions"`
+		} `json:"average-per-second"`
+	}
+	ViewChange struct {
+		ViewNo int `json:"View_No"`
+	} `json:"View_change_status"`
+}
+
+// PoolInfo is the view of the pool of the validator.
+type PoolInfo struct {
+	ReadOnly    bool     `json:"Read_only"`
+	TotalNodes  int      `json:"Total_nodes_count"`
+	F           int      `json:"f_value"`
+	Reachable   nodeList `json:"Reachable_nodes"`
+	Unreachable nodeList `json:"Unreachable_nodes"`
+	Blacklisted nodeList `json:"Blacklisted_nodes"`
+}
+
+// SoftwareInfo is the software the validator runs.
+type SoftwareInfo struct {
+	OS       string   `json:"OS_version"`
+	IndyNode string   `json:"indy-node"`
+	Sovrin   string   `json:"sovrin"` // empty on other networks
+	Packages []string `json:"Installed_packages"`
+}
+
+// nodeList is a list of validator aliases, which reports give either as
+// such or as pairs of an alias and the index of the replica of which it is
+// the primary, or null.
+type nodeList []string
+
+func (l *nodeList) UnmarshalJSON(b []byte) error {
+	var entries []json.RawMessage
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return err
+	}
+	*l = make(nodeList, 0, len(entries))
+	for _, e := range entries {
+		var alias string
+		if json.Unmarshal(e, &alias) != nil {
+			var pair []interface{}
+			if err := json.Unmarshal(e, &pair); err != nil {
+				return err
+			}
+			if len(pair) == 0 {
+				return errors.New("empty node entry")
+			}
+			alias, _ = pair[0].(string)
+		}
+		*l = append(*l, alias)
+	}
+	return nil
+}
+
+// ValidatorInfoResult is the answer of a validator to VALIDATOR_INFO.
+type ValidatorInfoResult struct {
+	Alias  string
+	Report *ValidatorReport
+	Err    error // why the validator did not report
+}
+
+// GetValidatorInfo sends a VALIDATOR_INFO request signed by signer, which
+// must be a trustee, a steward or a network monitor, to every validator,
+// each over its own connection. It returns the reports of the validators,
+// in the order of Validators, once they all answered or failed, or ctx is
+// done.
+func (p *Pool) GetValidatorInfo(ctx context.Context, signer Signer) ([]ValidatorInfoResult, error) {
+	reqId, m, err := p.signedRequest(Request{Operation: validatorInfoOp{Type: idValidatorInfo}}, signer)
+	if err != nil {
+		return nil, err
+	}
+	vs := p.validators()
+	index := make(map[string]int, len(vs))
+	results := make([]ValidatorInfoResult, len(vs))
+	for i, v := range vs {
+		index[v.Alias] = i
+		results[i] = ValidatorInfoResult{Alias: v.Alias}
+	}
+	answered := make(map[string]bool, len(vs))
+
+	p.fanOut(ctx, vs, func(ctx context.Context, v Validator) (interface{}, error) {
+		s, err := p.dial(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+		c := p.newConn(s, v)
+		defer c.close()
+		r, err := p.exchangeWith(ctx, c, reqId, m)
+		if err != nil {
+			return nil, err
+		}
+		return validatorInfoFromReply(r)
+	}, func(v Validator, val interface{}, err error) bool {
+		answered[v.Alias] = true
+		if err == nil {
+			results[index[v.Alias]].Report = val.(*ValidatorReport)
+		} else {
+			results[index[v.Alias]].Err = err
+		}
+		return false
+	})
+	for i := range results {
+		if !answered[results[i].Alias] {
+			results[i].Err = ctx.Err()
+		}
+	}
+	return results, nil
+}
+
+func validatorInfoFromReply(r *Reply) (*ValidatorReport, error) {
+	if err := checkReply(r); err != nil {
+		return nil, err
+	}
+	var raw json.RawMessage
+	if err := r.DecodeResult(&raw); err != nil {
+		return nil, err
+	}
+	report := &ValidatorReport{Raw: raw}
+	if err := json.Unmarshal(raw, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
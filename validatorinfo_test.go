@@ -0,0 +1,55 @@
+package indyclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool_GetValidatorInfo(t *testing.T) {
+	signer, err := SignerFromSeed([]byte("000000000000000000000000Trustee1"))
+	require.NoError(t, err)
+	info := func(alias string) fakeValidator {
+		return func(m []byte) [][]byte {
+			var req struct {
+				ReqId      seqNo  `json:"reqId"`
+				Identifier string `json:"identifier"`
+				Signature  string `json:"signature"`
+			}
+			json.Unmarshal(m, &req)
+			if req.Identifier != signer.Did() || req.Signature == "" {
+				return [][]byte{[]byte(fmt.Sprintf(`{"op":"REQNACK","reqId":%v,"reason":"not signed"}`, req.ReqId))}
+			}
+			return [][]byte{[]byte(fmt.Sprintf(`{"op":"REPLY","result":{"type":"119","reqId":%v,"data":{`+
+				`"alias":%q,"timestamp":1600000000,`+
+				`"Node_info":{"Name":%[2]q,"Mode":"participating","Metrics":{"uptime":3600,"transaction-count":{"ledger":120,"pool":4}},"View_change_status":{"View_No":2}},`+
+				`"Pool_info":{"Read_only":false,"Total_nodes_count":4,"f_value":1,"Reachable_nodes":[["Node1",0],["Node2",null],["Node3",null]],"Unreachable_nodes":[["Node4",null]]},`+
+				`"Software":{"indy-node":"1.12.4","sovrin":"1.1.89"}}}}`, req.ReqId, alias))}
+		}
+	}
+	pool := testPool(t, fakeTransport{"Node1": info("Node1"), "Node2": info("Node2"), "Node3": info("Node3")})
+
+	results, err := pool.GetValidatorInfo(context.Background(), signer)
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	for _, r := range results[:3] {
+		require.NoError(t, r.Err)
+		require.Equal(t, r.Alias, r.Report.Alias)
+	}
+	i := results[1].Report
+	require.Equal(t, "participating", i.Node.Mode)
+	require.Equal(t, 120, i.Node.Metrics.TransactionCount["ledger"])
+	require.Equal(t, 2, i.Node.ViewChange.ViewNo)
+	require.Equal(t, 1, i.Pool.F)
+	require.Equal(t, nodeList{"Node1", "Node2", "Node3"}, i.Pool.Reachable)
+	require.Equal(t, nodeList{"Node4"}, i.Pool.Unreachable)
+	require.Equal(t, "1.12.4", i.Software.IndyNode)
+	require.Contains(t, string(i.Raw), `"sovrin":"1.1.89"`)
+
+	require.Equal(t, "Node4", results[3].Alias)
+	require.Error(t, results[3].Err)
+	require.Nil(t, results[3].Report)
+}
@@ -0,0 +1,98 @@
+package indyclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// A WatchOption configures Watch.
+type WatchOption func(*watchConfig)
+
+type watchConfig struct {
+	from int
+}
+
+// WatchFrom makes Watch start with the transaction seqNo, sending the
+// transactions already in the ledger from there on before following its
+// tip. This lets a mirror of the ledger resume where it stopped.
+func WatchFrom(seqNo int) WatchOption {
+	return func(c *watchConfig) {
+		c.from = seqNo
+	}
+}
+
+// Watch follows the tip of ledger and sends every transaction appended to it
+// after the call on the returned Block channel. Indy nodes do not push
+// notifications to clients, so the ledger is polled every interval, and the
+// transactions appended since the last poll are fetched with
+// GetTransactions.
+//
+// Watching stops when ctx is done or when a request fails, including
+// because the Pool was closed; in the latter case the error is sent on the
+// error channel first. An interval which is not positive is an error too.
+// Both channels are closed when the watch stops.
+func (p *Pool) Watch(ctx context.Context, ledger LedgerId, interval time.Duration, opts ...WatchOption) (<-chan *Block, <-chan error) {
+	var cfg watchConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	blocks := make(chan *Block)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(blocks)
+
+		if interval <= 0 {
+			errs <- fmt.Errorf("invalid watch interval %v", interval)
+			return
+		}
+
+		last := cfg.from - 1
+		if cfg.from < 1 {
+			var err error
+			last, err = p.ledgerSize(ctx, ledger)
+			if err != nil {
+				errs <- err
+				return
+			}
+		}
+
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			for {
+				batch, err := p.GetTransactions(ctx, ledger, last+1, last+getTxnWindow)
+				if err != nil {
+					if ctx.Err() == nil {
+						errs <- err
+					}
+					return
+				}
+				for _, b := range batch {
+					select {
+					case blocks <- b:
+					case <-ctx.Done():
+						return
+					}
+					last++
+				}
+				if len(batch) < getTxnWindow {
+					break
+				}
+			}
+
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+				return
+			case <-p.done:
+				errs <- ErrClosed
+				return
+			}
+		}
+	}()
+
+	return blocks, errs
+}
@@ -0,0 +1,47 @@
+package indyclient
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool_Watch(t *testing.T) {
+	var mu sync.Mutex
+	ledger := numberedLedger(3)
+	growing := func(m []byte) [][]byte {
+		mu.Lock()
+		l := ledger
+		mu.Unlock()
+		return ledgerValidator(l)(m)
+	}
+	pool := testPool(t, fakeTransport{"Node1": growing})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	blocks, errs := pool.Watch(ctx, DomainLedger, 10*time.Millisecond, WatchFrom(2))
+	require.Equal(t, 2, (<-blocks).TxnMetadata.SeqNo)
+	require.Equal(t, 3, (<-blocks).TxnMetadata.SeqNo)
+
+	mu.Lock()
+	ledger = numberedLedger(4)
+	mu.Unlock()
+	require.Equal(t, "dest4", (<-blocks).Txn.Data.Dest)
+
+	cancel()
+	for range blocks {
+	}
+	require.NoError(t, <-errs)
+}
+
+func TestPool_WatchInterval(t *testing.T) {
+	pool := testPool(t, fakeTransport{"Node1": ledgerValidator(numberedLedger(3))})
+
+	blocks, errs := pool.Watch(context.Background(), DomainLedger, 0)
+	_, ok := <-blocks
+	require.False(t, ok)
+	require.Error(t, <-errs)
+}
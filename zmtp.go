@@ -0,0 +1,411 @@
+package indyclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/mr-tron/base58"
+	"golang.org/x/crypto/nacl/box"
+)
+
+// ZMTPTransport is a Transport written in pure Go. It speaks ZMTP 3.0 with
+// the CurveZMQ security mechanism (RFC 23 and RFC 26 of zeromq.org) like a
+// libzmq DEALER socket, so it needs neither cgo nor libzmq and the package
+// can be cross-compiled.
+type ZMTPTransport struct{}
+
+// ZMTP frame flags.
+const (
+	zmtpLong    = 0x02
+	zmtpCommand = 0x04
+)
+
+// zmtpMaxFrame bounds the size of the frames accepted from validators.
+const zmtpMaxFrame = 1 << 28
+
+// Dial implements Transport.
+func (ZMTPTransport) Dial(ctx context.Context, v Validator) (Connection, error) {
+	vk, err := base58.Decode(v.VerKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(vk) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid verkey of %v", v.Alias)
+	}
+	var server [32]byte
+	copy(server[:], ed25519PublicKeyToCurve25519(ed25519.PublicKey(vk)))
+
+	var d net.Dialer
+	nc, err := d.DialContext(ctx, "tcp", v.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	// Abort the handshake when ctx is done.
+	handshaken := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			nc.SetDeadline(time.Unix(1, 0))
+		case <-handshaken:
+		}
+	}()
+	c, err := curveHandshake(nc, &server)
+	close(handshaken)
+	if err == nil {
+		err = nc.SetDeadline(time.Time{})
+	}
+	if err != nil {
+		nc.Close()
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("connection to %v: %w", v.Alias, ctx.Err())
+		}
+		return nil, fmt.Errorf("connection to %v: %v", v.Alias, err)
+	}
+
+	go c.read()
+	return c, nil
+}
+
+// zmtpConn is a CurveZMQ connection to a validator.
+type zmtpConn struct {
+	nc     net.Conn
+	shared [32]byte // precomputed key of the transient key pairs
+
+	wMu       sync.Mutex // serializes writes, which read also makes
+	sendNonce uint64
+	recvNonce uint64 // only used by read
+
+	in        chan [][]byte // received messages, as their parts
+	done      chan struct{} // closed by Close
+	closeOnce sync.Once
+	err       error // why in was closed
+}
+
+// curveHandshake runs the ZMTP greeting and the CurveZMQ handshake as the
+// client of the validator whose permanent public key is server.
+func curveHandshake(nc net.Conn, server *[32]byte) (*zmtpConn, error) {
+	if err := zmtpGreet(nc); err != nil {
+		return nil, err
+	}
+
+	pub, sec, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	tpub, tsec, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	c := &zmtpConn{
+		nc:   nc,
+		in:   make(chan [][]byte, 16),
+		done: make(chan struct{}),
+	}
+
+	// HELLO proves that the client knows the permanent key of the server.
+	c.sendNonce++
+	nonce := curveNonce("CurveZMQHELLO---", c.sendNonce)
+	hello := []byte("\x05HELLO\x01\x00")
+	hello = append(hello, make([]byte, 72)...)
+	hello = append(hello, tpub[:]...)
+	hello = append(hello, nonce[16:]...)
+	hello = box.Seal(hello, make([]byte, 64), &nonce, server, tsec)
+	if err := writeFrame(nc, zmtpCommand, hello); err != nil {
+		return nil, err
+	}
+
+	// WELCOME holds the transient key of the server and a cookie.
+	welcome, err := readCommand(nc, "WELCOME")
+	if err != nil {
+		return nil, err
+	}
+	if len(welcome) != 16+144 {
+		return nil, errors.New("malformed WELCOME")
+	}
+	copy(nonce[:8], "WELCOME-")
+	copy(nonce[8:], welcome[:16])
+	plain, ok := box.Open(nil, welcome[16:], &nonce, server, tsec)
+	if !ok {
+		return nil, errors.New("cannot open WELCOME box")
+	}
+	var serverT [32]byte
+	copy(serverT[:], plain[:32])
+	cookie := plain[32:]
+	box.Precompute(&c.shared, &serverT, tsec)
+
+	// INITIATE vouches for the transient key with the permanent one.
+	var vouchNonce [24]byte
+	copy(vouchNonce[:], "VOUCH---")
+	if _, err := rand.Read(vouchNonce[8:]); err != nil {
+		return nil, err
+	}
+	vouch := box.Seal(nil, append(tpub[:], server[:]...), &vouchNonce, &serverT, sec)
+	identity := base64.StdEncoding.EncodeToString([]byte(z85Encode(pub[:])))
+	plain = append(append([]byte(nil), pub[:]...), vouchNonce[8:]...)
+	plain = append(plain, vouch...)
+	plain = appendProperty(plain, "Socket-Type", "DEALER")
+	plain = appendProperty(plain, "Identity", identity)
+	c.sendNonce++
+	nonce = curveNonce("CurveZMQINITIATE", c.sendNonce)
+	initiate := append([]byte("\x08INITIATE"), cookie...)
+	initiate = append(initiate, nonce[16:]...)
+	initiate = box.SealAfterPrecomputation(initiate, plain, &nonce, &c.shared)
+	if err := writeFrame(nc, zmtpCommand, initiate); err != nil {
+		return nil, err
+	}
+
+	ready, err := readCommand(nc, "READY")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.open("CurveZMQREADY---", ready); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// zmtpGreet exchanges the ZMTP greetings, announcing the CURVE mechanism.
+func zmtpGreet(nc net.Conn) error {
+	g := make([]byte, 64)
+	g[0], g[9] = 0xff, 0x7f
+	g[10] = 3 // version 3.0
+	copy(g[12:32], "CURVE")
+	if _, err := nc.Write(g); err != nil {
+		return err
+	}
+	if _, err := io.ReadFull(nc, g); err != nil {
+		return err
+	}
+	if g[0] != 0xff || g[9]&1 != 1 || g[10] < 3 {
+		return errors.New("peer does not speak ZMTP 3")
+	}
+	if mech := string(bytes.TrimRight(g[12:32], "\x00")); mech != "CURVE" {
+		return fmt.Errorf("peer uses the %v mechanism instead of CURVE", mech)
+	}
+	return nil
+}
+
+// curveNonce returns the 24 byte nonce made of prefix and the short nonce n.
+func curveNonce(prefix string, n uint64) [24]byte {
+	var nonce [24]byte
+	copy(nonce[:], prefix)
+	binary.BigEndian.PutUint64(nonce[16:], n)
+	return nonce
+}
+
+// open decrypts the body of a READY or MESSAGE command, made of a short
+// nonce and a box, checking that the nonces of the server increase.
+func (c *zmtpConn) open(prefix string, body []byte) ([]byte, error) {
+	if len(body) < 8+box.Overhead {
+		return nil, errors.New("malformed CurveZMQ box")
+	}
+	n := binary.BigEndian.Uint64(body[:8])
+	if n <= c.recvNonce {
+		return nil, errors.New("replayed CurveZMQ nonce")
+	}
+	c.recvNonce = n
+	nonce := curveNonce(prefix, n)
+	plain, ok := box.OpenAfterPrecomputation(nil, body[8:], &nonce, &c.shared)
+	if !ok {
+		return nil, errors.New("cannot open CurveZMQ box")
+	}
+	return plain, nil
+}
+
+// appendProperty appends the ZMTP metadata property name=value to b.
+func appendProperty(b []byte, name, value string) []byte {
+	b = append(b, byte(len(name)))
+	b = append(b, name...)
+	var l [4]byte
+	binary.BigEndian.PutUint32(l[:], uint32(len(value)))
+	b = append(b, l[:]...)
+	return append(b, value...)
+}
+
+func writeFrame(w io.Writer, flags byte, body []byte) error {
+	var hdr []byte
+	if len(body) > 255 {
+		hdr = make([]byte, 9)
+		hdr[0] = flags | zmtpLong
+		binary.BigEndian.PutUint64(hdr[1:], uint64(len(body)))
+	} else {
+		hdr = []byte{flags, byte(len(body))}
+	}
+	_, err := w.Write(append(hdr, body...))
+	return err
+}
+
+func readFrame(r io.Reader) (byte, []byte, error) {
+	var hdr [9]byte
+	if _, err := io.ReadFull(r, hdr[:2]); err != nil {
+		return 0, nil, err
+	}
+	flags := hdr[0]
+	size := uint64(hdr[1])
+	if flags&zmtpLong != 0 {
+		if _, err := io.ReadFull(r, hdr[2:]); err != nil {
+			return 0, nil, err
+		}
+		size = binary.BigEndian.Uint64(hdr[1:])
+	}
+	if size > zmtpMaxFrame {
+		return 0, nil, fmt.Errorf("frame of %v bytes is too large", size)
+	}
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return flags, body, nil
+}
+
+// readCommand reads the command name and returns its data. ERROR commands
+// of the peer are returned as errors.
+func readCommand(r io.Reader, name string) ([]byte, error) {
+	flags, body, err := readFrame(r)
+	if err != nil {
+		return nil, err
+	}
+	got, data := commandName(body)
+	if flags&zmtpCommand == 0 || got == "" {
+		return nil, fmt.Errorf("expected %v command", name)
+	}
+	if got == "ERROR" && len(data) > 0 {
+		return nil, fmt.Errorf("peer refused the handshake: %s", data[1:])
+	}
+	if got != name {
+		return nil, fmt.Errorf("expected %v command, got %v", name, got)
+	}
+	return data, nil
+}
+
+// commandName splits the body of a command into its name and data.
+func commandName(body []byte) (string, []byte) {
+	if len(body) == 0 || len(body) < 1+int(body[0]) {
+		return "", nil
+	}
+	return string(body[1 : 1+body[0]]), body[1+body[0]:]
+}
+
+// Send implements Connection.
+func (c *zmtpConn) Send(m []byte) error {
+	return c.sendMessage(0, m)
+}
+
+// sendMessage sends m in a MESSAGE command with the given flags.
+func (c *zmtpConn) sendMessage(flags byte, m []byte) error {
+	c.wMu.Lock()
+	defer c.wMu.Unlock()
+	c.sendNonce++
+	nonce := curveNonce("CurveZMQMESSAGEC", c.sendNonce)
+	body := append([]byte("\x07MESSAGE"), nonce[16:]...)
+	body = box.SealAfterPrecomputation(body, append([]byte{flags}, m...), &nonce, &c.shared)
+	// Like libzmq, MESSAGE commands are sent in plain frames.
+	return writeFrame(c.nc, 0, body)
+}
+
+// read receives the messages of the validator until the connection fails.
+func (c *zmtpConn) read() {
+	var parts [][]byte
+	for {
+		_, body, err := readFrame(c.nc)
+		if err != nil {
+			c.stop(err)
+			return
+		}
+		name, data := commandName(body)
+		if name != "MESSAGE" {
+			c.stop(fmt.Errorf("unexpected %q frame", name))
+			return
+		}
+		plain, err := c.open("CurveZMQMESSAGES", data)
+		if err != nil {
+			c.stop(err)
+			return
+		}
+		flags, m := plain[0], plain[1:]
+		if flags&0x02 != 0 {
+			// A command, such as a heartbeat.
+			if cmd, ctx := commandName(m); cmd == "PING" && len(ctx) >= 2 {
+				c.sendMessage(0x02, append([]byte("\x04PONG"), ctx[2:]...))
+			}
+			continue
+		}
+		parts = append(parts, m)
+		if flags&0x01 != 0 {
+			continue
+		}
+		select {
+		case c.in <- parts:
+		case <-c.done:
+			return
+		}
+		parts = nil
+	}
+}
+
+// stop closes in because of err.
+func (c *zmtpConn) stop(err error) {
+	select {
+	case <-c.done:
+		err = errConnClosed
+	default:
+	}
+	c.err = err
+	close(c.in)
+}
+
+// Receive implements Connection.
+func (c *zmtpConn) Receive(timeout time.Duration) ([]byte, error) {
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	select {
+	case parts, ok := <-c.in:
+		if !ok {
+			return nil, c.err
+		}
+		if len(parts) != 1 {
+			return nil, errors.New("got wrong amount of input")
+		}
+		return parts[0], nil
+	case <-t.C:
+		return nil, nil
+	}
+}
+
+// Close implements Connection.
+func (c *zmtpConn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.done)
+		err = c.nc.Close()
+	})
+	return err
+}
+
+const z85Chars = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ.-:+=^!/*?&<>()[]{}@%$#"
+
+// z85Encode encodes b, whose length must be a multiple of 4, in Z85.
+func z85Encode(b []byte) string {
+	out := make([]byte, 0, len(b)/4*5)
+	for i := 0; i+4 <= len(b); i += 4 {
+		v := binary.BigEndian.Uint32(b[i:])
+		var chunk [5]byte
+		for j := 4; j >= 0; j-- {
+			chunk[j] = z85Chars[v%85]
+			v /= 85
+		}
+		out = append(out, chunk[:]...)
+	}
+	return string(out)
+}
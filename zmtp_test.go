@@ -0,0 +1,160 @@
+package indyclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/mr-tron/base58"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/nacl/box"
+)
+
+// serveCurve runs the server side of the CurveZMQ handshake on nc, with
+// the permanent key pair pub/sec, then sends a PING and echoes messages.
+func serveCurve(nc net.Conn, pub, sec *[32]byte) error {
+	g := make([]byte, 64)
+	if _, err := nc.Read(g); err != nil {
+		return err
+	}
+	g = make([]byte, 64)
+	g[0], g[9], g[10], g[11], g[32] = 0xff, 0x7f, 3, 1, 1
+	copy(g[12:], "CURVE")
+	nc.Write(g)
+
+	hello, err := readCommand(nc, "HELLO")
+	if err != nil {
+		return err
+	}
+	var clientT [32]byte
+	copy(clientT[:], hello[74:106])
+	nonce := curveNonce("CurveZMQHELLO---", 0)
+	copy(nonce[16:], hello[106:114])
+	sig, ok := box.Open(nil, hello[114:], &nonce, &clientT, sec)
+	if !ok || !bytes.Equal(sig, make([]byte, 64)) {
+		return errors.New("bad HELLO")
+	}
+
+	tpub, tsec, _ := box.GenerateKey(rand.Reader)
+	cookie := make([]byte, 96)
+	rand.Read(cookie)
+	copy(nonce[:], "WELCOME-")
+	rand.Read(nonce[8:])
+	welcome := append([]byte("\x07WELCOME"), nonce[8:]...)
+	welcome = box.Seal(welcome, append(tpub[:], cookie...), &nonce, &clientT, sec)
+	writeFrame(nc, zmtpCommand, welcome)
+
+	initiate, err := readCommand(nc, "INITIATE")
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(initiate[:96], cookie) {
+		return errors.New("bad cookie")
+	}
+	var shared [32]byte
+	box.Precompute(&shared, &clientT, tsec)
+	copy(nonce[:], "CurveZMQINITIATE")
+	copy(nonce[16:], initiate[96:104])
+	plain, ok := box.OpenAfterPrecomputation(nil, initiate[104:], &nonce, &shared)
+	if !ok {
+		return errors.New("bad INITIATE")
+	}
+	var client [32]byte
+	copy(client[:], plain[:32])
+	copy(nonce[:], "VOUCH---")
+	copy(nonce[8:], plain[32:48])
+	vouch, ok := box.Open(nil, plain[48:128], &nonce, &client, tsec)
+	if !ok || !bytes.Equal(vouch, append(clientT[:], pub[:]...)) {
+		return errors.New("bad vouch")
+	}
+	if !bytes.Contains(plain[128:], []byte("\x0bSocket-Type\x00\x00\x00\x06DEALER")) {
+		return errors.New("bad metadata")
+	}
+
+	c := &zmtpConn{nc: nc, shared: shared}
+	send := func(prefix string, name string, plain []byte) error {
+		c.sendNonce++
+		nonce := curveNonce(prefix, c.sendNonce)
+		body := append([]byte{byte(len(name))}, name...)
+		body = append(body, nonce[16:]...)
+		return writeFrame(nc, zmtpCommand, box.SealAfterPrecomputation(body, plain, &nonce, &shared))
+	}
+	send("CurveZMQREADY---", "READY", appendProperty(nil, "Socket-Type", "ROUTER"))
+	send("CurveZMQMESSAGES", "MESSAGE", []byte("\x02\x04PING\x00\x0a42"))
+	for {
+		_, body, err := readFrame(nc)
+		if err != nil {
+			return nil
+		}
+		_, data := commandName(body)
+		m, err := c.open("CurveZMQMESSAGEC", data)
+		if err != nil {
+			return err
+		}
+		send("CurveZMQMESSAGES", "MESSAGE", m)
+	}
+}
+
+func TestZMTPTransport(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	rand.Read(seed)
+	key := ed25519.NewKeyFromSeed(seed)
+	h := sha512.Sum512(seed)
+	var sec, pub [32]byte
+	copy(sec[:], h[:32])
+	sec[0] &= 248
+	sec[31] &= 127
+	sec[31] |= 64
+	curve25519.ScalarBaseMult(&pub, &sec)
+	require.Equal(t, pub[:], ed25519PublicKeyToCurve25519(key.Public().(ed25519.PublicKey)))
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	served := make(chan error, 1)
+	go func() {
+		nc, err := l.Accept()
+		if err != nil {
+			served <- err
+			return
+		}
+		defer nc.Close()
+		served <- serveCurve(nc, &pub, &sec)
+	}()
+
+	v := Validator{
+		Alias:   "Node1",
+		Address: l.Addr().String(),
+		VerKey:  base58.Encode(key.Public().(ed25519.PublicKey)),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c, err := ZMTPTransport{}.Dial(ctx, v)
+	require.NoError(t, err)
+
+	require.NoError(t, c.Send([]byte(`{"op":"LEDGER_STATUS"}`)))
+	// The PING of the server is answered with a PONG, which is echoed.
+	// Commands are not handed out as messages.
+	m, err := c.Receive(5 * time.Second)
+	require.NoError(t, err)
+	require.Equal(t, `{"op":"LEDGER_STATUS"}`, string(m))
+
+	m, err = c.Receive(10 * time.Millisecond)
+	require.NoError(t, err)
+	require.Nil(t, m)
+
+	require.NoError(t, c.Close())
+	require.NoError(t, <-served)
+}
+
+func TestZ85Encode(t *testing.T) {
+	// The test vector of the Z85 specification.
+	require.Equal(t, "HelloWorld", z85Encode([]byte{0x86, 0x4F, 0xD2, 0x6F, 0xB5, 0x59, 0xF7, 0x5B}))
+}